@@ -84,4 +84,5 @@ func (api *API) Start() {
 	if err != nil {
 		api.Logger.Fatal("REST Server returned an error", zap.Error(err))
 	}
+	api.KafkaSvc.Close()
 }
@@ -17,7 +17,8 @@ type getAclsOverviewRequest struct {
 	// The resource name, or null to match any resource name.
 	ResourceName *string `schema:"resourceName"`
 
-	// The resource pattern to match.
+	// The resource pattern to match: 1 any, 2 match, 3 literal, 4 prefixed. With match, all ACLs that apply to the
+	// resource name are returned, including wildcard and prefixed ACLs.
 	ResourcePatternTypeFilter int `schema:"resourcePatternTypeFilter"`
 
 	// The principal to match, or null to match any principal.
@@ -38,7 +39,8 @@ func (g *getAclsOverviewRequest) OK() error {
 		return fmt.Errorf("resourceType filter is out of bounds")
 	}
 
-	if kmsg.ACLResourcePatternType(g.ResourcePatternTypeFilter).String() == kmsg.ACLResourcePatternTypeUnknown.String() {
+	patternType := kmsg.ACLResourcePatternType(g.ResourcePatternTypeFilter)
+	if patternType < owl.AclPatternTypeAny || patternType > owl.AclPatternTypePrefixed {
 		return fmt.Errorf("resourcePatternTypeFilter is out of bounds")
 	}
 
@@ -1,9 +1,14 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/cloudhut/kowl/backend/pkg/owl"
+	"github.com/go-chi/chi"
 	"net/http"
+	"strconv"
 )
 
 func (api *API) handleGetAPIVersions() http.HandlerFunc {
@@ -30,3 +35,52 @@ func (api *API) handleGetAPIVersions() http.HandlerFunc {
 		rest.SendResponse(w, r, api.Logger, http.StatusOK, response)
 	}
 }
+
+func (api *API) handleGetBrokerAPIVersions() http.HandlerFunc {
+	type response struct {
+		BrokerID    int32            `json:"brokerId"`
+		APIVersions []owl.APIVersion `json:"apiVersions"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		brokerID, err := strconv.ParseInt(chi.URLParam(r, "brokerID"), 10, 32)
+		if err != nil {
+			restErr := &rest.Error{
+				Err:      fmt.Errorf("failed to parse broker id: %w", err),
+				Status:   http.StatusBadRequest,
+				Message:  "Broker ID must be a valid int32",
+				IsSilent: true,
+			}
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+
+		versions, err := api.OwlSvc.GetBrokerAPIVersions(r.Context(), int32(brokerID))
+		if errors.Is(err, kafka.ErrBrokerNotFound) {
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusNotFound,
+				Message:  fmt.Sprintf("Broker with id '%v' does not exist", brokerID),
+				IsSilent: true,
+			}
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+		if err != nil {
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusInternalServerError,
+				Message:  fmt.Sprintf("Could not get Kafka API versions of broker '%v'", brokerID),
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+
+		response := response{
+			BrokerID:    int32(brokerID),
+			APIVersions: versions,
+		}
+		rest.SendResponse(w, r, api.Logger, http.StatusOK, response)
+	}
+}
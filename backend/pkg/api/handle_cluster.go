@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/cloudhut/kowl/backend/pkg/owl"
 	"net/http"
 )
@@ -30,3 +32,39 @@ func (api *API) handleDescribeCluster() http.HandlerFunc {
 		rest.SendResponse(w, r, api.Logger, http.StatusOK, response)
 	}
 }
+
+func (api *API) handleDescribeQuorum() http.HandlerFunc {
+	type response struct {
+		QuorumInfo *owl.QuorumInfo `json:"quorumInfo"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		quorumInfo, err := api.OwlSvc.DescribeQuorum(r.Context())
+		if err != nil {
+			if errors.Is(err, kafka.ErrUnsupportedRequest) {
+				restErr := &rest.Error{
+					Err:      err,
+					Status:   http.StatusNotImplemented,
+					Message:  "Describing the quorum is only supported by KRaft clusters",
+					IsSilent: true,
+				}
+				rest.SendRESTError(w, r, api.Logger, restErr)
+				return
+			}
+
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusInternalServerError,
+				Message:  "Could not describe quorum",
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+
+		response := response{
+			QuorumInfo: quorumInfo,
+		}
+		rest.SendResponse(w, r, api.Logger, http.StatusOK, response)
+	}
+}
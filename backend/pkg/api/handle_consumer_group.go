@@ -8,8 +8,10 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"net/http"
+	"strings"
 
 	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/cloudhut/kowl/backend/pkg/owl"
 )
 
@@ -76,7 +78,13 @@ func (api *API) handleGetConsumerGroup() http.HandlerFunc {
 			return
 		}
 
-		describedGroups, restErr := api.OwlSvc.GetConsumerGroupsOverview(r.Context(), []string{groupID})
+		// Topic-scoped views are only interested in the assignments of some topics
+		var opts owl.ConsumerGroupsOverviewOptions
+		if requestedTopics := r.URL.Query().Get("filterTopics"); requestedTopics != "" {
+			opts.FilterTopics = strings.Split(requestedTopics, ",")
+		}
+
+		describedGroups, restErr := api.OwlSvc.GetConsumerGroupsOverviewWithOptions(r.Context(), []string{groupID}, opts)
 		if restErr != nil {
 			rest.SendRESTError(w, r, api.Logger, restErr)
 			return
@@ -91,6 +99,55 @@ func (api *API) handleGetConsumerGroup() http.HandlerFunc {
 	}
 }
 
+func (api *API) handleGetConsumerGroupAssignments() http.HandlerFunc {
+	type response struct {
+		Assignments []owl.AssignmentRow `json:"assignments"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		groupID := chi.URLParam(r, "groupId")
+
+		canSee, restErr := api.Hooks.Owl.CanSeeConsumerGroup(r.Context(), groupID)
+		if restErr != nil {
+			rest.SendRESTError(w, r, api.Logger, restErr)
+			return
+		}
+		if !canSee {
+			rest.SendRESTError(w, r, api.Logger, &rest.Error{
+				Err:          fmt.Errorf("requester has no permissions to view consumer group"),
+				Status:       http.StatusForbidden,
+				Message:      "You don't have permissions to view this consumer group",
+				InternalLogs: []zapcore.Field{zap.String("group_id", groupID)},
+				IsSilent:     false,
+			})
+			return
+		}
+
+		assignments, err := api.OwlSvc.GetConsumerGroupAssignmentTable(r.Context(), groupID)
+		if errors.Is(err, kafka.ErrGroupNotFound) {
+			rest.SendRESTError(w, r, api.Logger, &rest.Error{
+				Err:          err,
+				Status:       http.StatusNotFound,
+				Message:      "The requested consumer group does not exist",
+				InternalLogs: []zapcore.Field{zap.String("group_id", groupID)},
+				IsSilent:     true,
+			})
+			return
+		}
+		if err != nil {
+			rest.SendRESTError(w, r, api.Logger, &rest.Error{
+				Err:          err,
+				Status:       http.StatusServiceUnavailable,
+				Message:      fmt.Sprintf("Failed to get consumer group assignments: %v", err.Error()),
+				InternalLogs: []zapcore.Field{zap.String("group_id", groupID)},
+				IsSilent:     false,
+			})
+			return
+		}
+
+		rest.SendResponse(w, r, api.Logger, http.StatusOK, response{Assignments: assignments})
+	}
+}
+
 type patchConsumerGroupRequest struct {
 	GroupID string `json:"groupId"`
 	Topics  []struct {
@@ -190,6 +190,17 @@ func (api *API) handlePatchPartitionAssignments() http.HandlerFunc {
 		// 4. Check response and pass it to the frontend
 		owlRes, err := api.OwlSvc.AlterPartitionAssignments(r.Context(), kmsgReq)
 		if err != nil {
+			var validationErr *owl.ValidationError
+			if errors.As(err, &validationErr) {
+				rest.SendRESTError(w, r, api.Logger, &rest.Error{
+					Err:      err,
+					Status:   http.StatusBadRequest,
+					Message:  fmt.Sprintf("Reassign partition request is invalid: %v", validationErr.Error()),
+					IsSilent: false,
+				})
+				return
+			}
+
 			restErr := &rest.Error{
 				Err:      err,
 				Status:   http.StatusInternalServerError,
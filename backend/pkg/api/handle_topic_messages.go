@@ -3,11 +3,17 @@ package api
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/go-chi/chi"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/cloudhut/kowl/backend/pkg/owl"
 
 	"github.com/cloudhut/common/rest"
@@ -22,11 +28,48 @@ type GetTopicMessagesResponse struct {
 // used in Kowl business to implement the hooks.
 type ListMessagesRequest struct {
 	TopicName             string `json:"topicName"`
-	StartOffset           int64  `json:"startOffset"`    // -1 for recent (newest - results), -2 for oldest offset, -3 for newest, -4 for timestamp
+	StartOffset           int64  `json:"startOffset"`    // -1 for recent (newest - results), -2 for oldest offset, -3 for newest, -4 for timestamp, -5 for since duration, -6 for group committed
 	StartTimestamp        int64  `json:"startTimestamp"` // Start offset by unix timestamp in ms (only considered if start offset is set to -4)
 	PartitionID           int32  `json:"partitionId"`    // -1 for all partition ids
 	MaxResults            int    `json:"maxResults"`
 	FilterInterpreterCode string `json:"filterInterpreterCode"` // Base64 encoded code
+	IsolationLevel        int8   `json:"isolationLevel"`        // 0 for read uncommitted (default), 1 for read committed
+	MaxPayloadBytes       int    `json:"maxPayloadBytes"`       // Truncate keys and values larger than this, 0 for no limit
+	KeysOnly              bool   `json:"keysOnly"`              // Omit message values, e.g. to list the keys of compacted topics
+	FormatJSON            bool   `json:"formatJson"`            // Add indented, key sorted JSON to decoded keys and values
+	Follow                bool   `json:"follow"`                // Keep streaming new messages once the requested messages have been sent
+	FetchMinBytes         int32  `json:"fetchMinBytes"`         // Bytes a broker waits for before responding to a fetch, 0 for default
+	FetchMaxBytes         int32  `json:"fetchMaxBytes"`         // Max bytes of a single fetch response, 0 for default
+	FetchMaxWaitMs        int    `json:"fetchMaxWaitMs"`        // Max time a broker waits for the min bytes, 0 for default
+	FetchLowLatency       bool   `json:"fetchLowLatency"`       // Use low latency fetch values instead of the defaults for all unset fetch options
+
+	// UseDefaultStartOffset ignores StartOffset and starts at the configured default start position instead
+	UseDefaultStartOffset bool `json:"useDefaultStartOffset"`
+
+	// HeaderFilters only returns messages with all given header key value pairs, an empty value matches any value
+	HeaderFilters map[string]string `json:"headerFilters"`
+
+	// ContinueOnDecodeError returns undecodable messages as raw bytes instead of failing the request, defaults to true
+	ContinueOnDecodeError *bool `json:"continueOnDecodeError"`
+
+	// SinceDurationMs starts consuming at the messages of the last n milliseconds (only considered if start offset is -5)
+	SinceDurationMs int64 `json:"sinceDurationMs"`
+
+	// GroupID and GroupOffsetDelta start consuming at the group's committed offset plus the (possibly negative) delta
+	// (only considered if start offset is -6)
+	GroupID          string `json:"groupId"`
+	GroupOffsetDelta int64  `json:"groupOffsetDelta"`
+
+	// SortByTimestamp returns the messages of all partitions ordered by timestamp, tolerating timestamps which are
+	// out of order within a partition by up to ReorderWindowMs
+	SortByTimestamp bool  `json:"sortByTimestamp"`
+	ReorderWindowMs int64 `json:"reorderWindowMs"`
+
+	// EndOffsets stops consuming the given partitions before the end offset (exclusive), indexed by partition ID
+	EndOffsets map[int32]int64 `json:"endOffsets"`
+
+	// Cursor continues after the messages of a previous page, it is returned with the "done" message of each page
+	Cursor string `json:"cursor"`
 }
 
 func (l *ListMessagesRequest) OK() error {
@@ -34,14 +77,52 @@ func (l *ListMessagesRequest) OK() error {
 		return fmt.Errorf("topic name is required")
 	}
 
-	if l.StartOffset < -4 {
-		return fmt.Errorf("start offset is smaller than -4")
+	if !l.UseDefaultStartOffset && l.StartOffset < -6 {
+		return fmt.Errorf("start offset is smaller than -6")
+	}
+
+	if !l.UseDefaultStartOffset && l.StartOffset == owl.StartOffsetSinceDuration && l.SinceDurationMs <= 0 {
+		return fmt.Errorf("since duration must be greater than zero if start offset is -5 (since duration)")
+	}
+
+	if !l.UseDefaultStartOffset && l.StartOffset == owl.StartOffsetGroupCommitted && l.GroupID == "" {
+		return fmt.Errorf("group id is required if start offset is -6 (group committed)")
 	}
 
 	if l.PartitionID < -1 {
 		return fmt.Errorf("partitionID is smaller than -1")
 	}
 
+	if l.IsolationLevel != int8(kafka.IsolationLevelReadUncommitted) && l.IsolationLevel != int8(kafka.IsolationLevelReadCommitted) {
+		return fmt.Errorf("isolation level must be either 0 (read uncommitted) or 1 (read committed)")
+	}
+
+	if l.MaxPayloadBytes < 0 {
+		return fmt.Errorf("max payload bytes must not be negative")
+	}
+
+	if l.ReorderWindowMs < 0 {
+		return fmt.Errorf("reorder window must not be negative")
+	}
+
+	if l.SortByTimestamp && l.Follow {
+		return fmt.Errorf("messages can not be sorted by timestamp while following the topic")
+	}
+
+	if len(l.EndOffsets) > 0 && l.Follow {
+		return fmt.Errorf("end offsets can not be used while following the topic")
+	}
+
+	if l.Cursor != "" {
+		if _, err := kafka.DecodeCursor(l.Cursor, l.TopicName); err != nil {
+			return err
+		}
+	}
+
+	if err := l.FetchOptions().Validate(0); err != nil {
+		return err
+	}
+
 	if l.MaxResults <= 0 || l.MaxResults > 500 {
 		return fmt.Errorf("max results must be between 1 and 500")
 	}
@@ -53,6 +134,29 @@ func (l *ListMessagesRequest) OK() error {
 	return nil
 }
 
+// CursorOffsets returns the next offset of each partition from the request's cursor, nil if there is no cursor. The
+// cursor has been validated in OK().
+func (l *ListMessagesRequest) CursorOffsets() map[int32]int64 {
+	if l.Cursor == "" {
+		return nil
+	}
+	cursor, err := kafka.DecodeCursor(l.Cursor, l.TopicName)
+	if err != nil {
+		return nil
+	}
+	return cursor.NextOffsets
+}
+
+// FetchOptions returns the requested fetch options. The topic specific validation is done when listing the messages.
+func (l *ListMessagesRequest) FetchOptions() kafka.FetchOptions {
+	return kafka.FetchOptions{
+		MinBytes:   l.FetchMinBytes,
+		MaxBytes:   l.FetchMaxBytes,
+		MaxWait:    time.Duration(l.FetchMaxWaitMs) * time.Millisecond,
+		LowLatency: l.FetchLowLatency,
+	}
+}
+
 func (l *ListMessagesRequest) DecodeInterpreterCode() (string, error) {
 	code, err := base64.StdEncoding.DecodeString(l.FilterInterpreterCode)
 	if err != nil {
@@ -118,7 +222,7 @@ func (api *API) handleGetMessages() http.HandlerFunc {
 			return
 		}
 
-		if len(req.FilterInterpreterCode) > 0 {
+		if len(req.FilterInterpreterCode) > 0 || len(req.HeaderFilters) > 0 {
 			canUseMessageSearchFilters, restErr := api.Hooks.Owl.CanUseMessageSearchFilters(r.Context(), req.TopicName)
 			if restErr != nil {
 				sendError(restErr.Message)
@@ -136,30 +240,38 @@ func (api *API) handleGetMessages() http.HandlerFunc {
 		listReq := owl.ListMessageRequest{
 			TopicName:             req.TopicName,
 			PartitionID:           req.PartitionID,
-			StartOffset:           req.StartOffset,
+			StartOffset:           api.OwlSvc.StartOffsetOrDefault(req.StartOffset, req.UseDefaultStartOffset),
 			StartTimestamp:        req.StartTimestamp,
 			MessageCount:          req.MaxResults,
 			FilterInterpreterCode: interpreterCode,
+			HeaderFilters:         req.HeaderFilters,
+			IsolationLevel:        kafka.IsolationLevel(req.IsolationLevel),
+			MaxPayloadBytes:       req.MaxPayloadBytes,
+			KeysOnly:              req.KeysOnly,
+			FormatJSON:            req.FormatJSON,
+			Follow:                req.Follow,
+			Fetch:                 req.FetchOptions(),
+
+			ContinueOnDecodeError: req.ContinueOnDecodeError,
+			SinceDuration:         time.Duration(req.SinceDurationMs) * time.Millisecond,
+			GroupID:               req.GroupID,
+			GroupOffsetDelta:      req.GroupOffsetDelta,
+			SortByTimestamp:       req.SortByTimestamp,
+			ReorderWindow:         time.Duration(req.ReorderWindowMs) * time.Millisecond,
+			EndOffsets:            req.EndOffsets,
+			CursorOffsets:         req.CursorOffsets(),
 		}
 		api.Hooks.Owl.PrintListMessagesAuditLog(r, &listReq)
 
 		// Use 30min duration if we want to search a whole topic or forward messages as they arrive
 		duration := 45 * time.Second
-		if listReq.FilterInterpreterCode != "" || listReq.StartOffset == owl.StartOffsetNewest {
+		if listReq.HasFilters() || listReq.StartOffset == owl.StartOffsetNewest || listReq.Follow {
 			duration = 30 * time.Minute
 		}
 		childCtx, cancel := context.WithTimeout(ctx, duration)
 		defer cancel()
 
-		progress := &progressReporter{
-			ctx:              childCtx,
-			logger:           api.Logger,
-			request:          &listReq,
-			websocket:        &wsClient,
-			statsMutex:       &sync.RWMutex{},
-			messagesConsumed: 0,
-			bytesConsumed:    0,
-		}
+		progress := newProgressReporter(childCtx, api.Logger, &listReq, &wsClient)
 		progress.Start()
 
 		err = api.OwlSvc.ListMessages(childCtx, listReq, progress)
@@ -168,3 +280,116 @@ func (api *API) handleGetMessages() http.HandlerFunc {
 		}
 	}
 }
+
+// handleGetMessage returns a single message by topic, partition and offset. This is used for deep links to a
+// specific record.
+func (api *API) handleGetMessage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topicName := chi.URLParam(r, "topicName")
+		logger := api.Logger.With(zap.String("topic_name", topicName))
+
+		partitionID, err := strconv.ParseInt(chi.URLParam(r, "partitionID"), 10, 32)
+		if err != nil {
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusBadRequest,
+				Message:  "The given partition id is not a valid number",
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+		offset, err := strconv.ParseInt(chi.URLParam(r, "offset"), 10, 64)
+		if err != nil {
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusBadRequest,
+				Message:  "The given offset is not a valid number",
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
+		canViewMessages, restErr := api.Hooks.Owl.CanViewTopicMessages(r.Context(), topicName)
+		if restErr != nil {
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+		if !canViewMessages {
+			restErr := &rest.Error{
+				Err:      fmt.Errorf("requester has no permissions to view messages in the requested topic"),
+				Status:   http.StatusForbidden,
+				Message:  "You don't have permissions to view messages in this topic",
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
+		// Optionally the message can be fetched from a follower replica rather than the leader
+		var msg *kafka.TopicMessage
+		if replicaIDStr := r.URL.Query().Get("replicaId"); replicaIDStr != "" {
+			replicaID, parseErr := strconv.ParseInt(replicaIDStr, 10, 32)
+			if parseErr != nil {
+				restErr := &rest.Error{
+					Err:      parseErr,
+					Status:   http.StatusBadRequest,
+					Message:  "The given replica id is not a valid number",
+					IsSilent: false,
+				}
+				rest.SendRESTError(w, r, logger, restErr)
+				return
+			}
+			msg, err = api.OwlSvc.GetMessageFromReplica(r.Context(), topicName, int32(partitionID), offset, int32(replicaID))
+		} else {
+			msg, err = api.OwlSvc.GetMessage(r.Context(), topicName, int32(partitionID), offset)
+		}
+		if err != nil {
+			var outOfRangeErr *owl.OffsetOutOfRangeError
+			var notAReplicaErr *kafka.NotAReplicaError
+			if errors.As(err, &notAReplicaErr) {
+				restErr := &rest.Error{
+					Err:      err,
+					Status:   http.StatusBadRequest,
+					Message:  err.Error(),
+					IsSilent: true,
+				}
+				rest.SendRESTError(w, r, logger, restErr)
+				return
+			}
+			if errors.Is(err, kafka.ErrUnsupportedRequest) {
+				restErr := &rest.Error{
+					Err:      err,
+					Status:   http.StatusNotImplemented,
+					Message:  err.Error(),
+					IsSilent: true,
+				}
+				rest.SendRESTError(w, r, logger, restErr)
+				return
+			}
+			if errors.As(err, &outOfRangeErr) || errors.Is(err, kafka.ErrMessageNotFound) || errors.Is(err, kafka.ErrTopicNotFound) ||
+				errors.Is(err, kafka.ErrPartitionNotFound) {
+				restErr := &rest.Error{
+					Err:      err,
+					Status:   http.StatusNotFound,
+					Message:  err.Error(),
+					IsSilent: true,
+				}
+				rest.SendRESTError(w, r, logger, restErr)
+				return
+			}
+
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusInternalServerError,
+				Message:  fmt.Sprintf("Could not fetch the requested message: %v", err.Error()),
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
+
+		rest.SendResponse(w, r, logger, http.StatusOK, msg)
+	}
+}
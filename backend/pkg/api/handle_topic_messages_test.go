@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/cloudhut/kowl/backend/pkg/owl"
+)
+
+func TestListMessagesRequest_Cursor(t *testing.T) {
+	token, err := kafka.EncodeCursor(kafka.Cursor{TopicName: "orders", NextOffsets: map[int32]int64{0: 10}})
+	require.NoError(t, err)
+
+	req := ListMessagesRequest{TopicName: "orders", PartitionID: -1, MaxResults: 50, Cursor: token}
+	require.NoError(t, req.OK())
+	assert.Equal(t, map[int32]int64{0: 10}, req.CursorOffsets())
+
+	req.TopicName = "payments"
+	assert.True(t, errors.Is(req.OK(), kafka.ErrInvalidCursor))
+
+	req.TopicName = "orders"
+	req.Cursor = token[:len(token)-2]
+	assert.True(t, errors.Is(req.OK(), kafka.ErrInvalidCursor))
+
+	req.Cursor = ""
+	assert.Nil(t, req.CursorOffsets())
+}
+
+func TestProgressReporter_Cursor(t *testing.T) {
+	listReq := &owl.ListMessageRequest{
+		TopicName:     "orders",
+		StartOffset:   owl.StartOffsetOldest,
+		CursorOffsets: map[int32]int64{0: 10, 1: 5},
+	}
+
+	// The server sends one page of messages the way handleGetMessages does
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		wsClient := websocketClient{Ctx: ctx, Cancel: cancel, Logger: zap.NewNop(), Mutex: &sync.RWMutex{}}
+		if restErr := wsClient.upgrade(w, r); restErr != nil {
+			t.Error(restErr.Err)
+			return
+		}
+		defer wsClient.Connection.Close()
+
+		progress := newProgressReporter(ctx, zap.NewNop(), listReq, &wsClient)
+		progress.OnMessage(&kafka.TopicMessage{PartitionID: 0, Offset: 10})
+		progress.OnMessage(&kafka.TopicMessage{PartitionID: 0, Offset: 11})
+		progress.OnMessage(&kafka.TopicMessage{PartitionID: 2, Offset: 4})
+		progress.OnMessage(&kafka.TopicMessage{PartitionID: 2, Offset: 3})
+		progress.OnComplete(5, false)
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var done struct {
+		Type   string `json:"type"`
+		Cursor string `json:"cursor"`
+	}
+	for done.Type != "done" {
+		require.NoError(t, conn.ReadJSON(&done))
+	}
+
+	// The cursor continues after the sent messages, partition 1 keeps the position of the request's cursor
+	cursor, err := kafka.DecodeCursor(done.Cursor, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, map[int32]int64{0: 12, 1: 5, 2: 5}, cursor.NextOffsets)
+
+	// The next page continues at the cursor
+	nextReq := ListMessagesRequest{TopicName: "orders", PartitionID: -1, MaxResults: 50, Cursor: done.Cursor}
+	require.NoError(t, nextReq.OK())
+	assert.Equal(t, cursor.NextOffsets, nextReq.CursorOffsets())
+}
+
+func TestListMessagesRequest_UseDefaultStartOffset(t *testing.T) {
+	// An explicit start offset is validated, including 0 which must not be mistaken for an unset start offset
+	req := ListMessagesRequest{TopicName: "orders", PartitionID: -1, MaxResults: 50, StartOffset: owl.StartOffsetSinceDuration}
+	assert.Error(t, req.OK())
+	req.StartOffset = 0
+	assert.NoError(t, req.OK())
+
+	// The start offset is ignored if the request opts into the configured default
+	req.StartOffset = owl.StartOffsetSinceDuration
+	req.UseDefaultStartOffset = true
+	assert.NoError(t, req.OK())
+}
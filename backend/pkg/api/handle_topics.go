@@ -2,6 +2,7 @@ package api
 
 import (
 	_ "context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap/zapcore"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/cloudhut/kowl/backend/pkg/owl"
 	"github.com/go-chi/chi"
 )
@@ -265,6 +267,16 @@ func (api *API) handleGetTopicConsumers() http.HandlerFunc {
 		}
 
 		consumers, err := api.OwlSvc.ListTopicConsumers(r.Context(), topicName)
+		if errors.Is(err, kafka.ErrTopicNotFound) {
+			restErr := &rest.Error{
+				Err:      err,
+				Status:   http.StatusNotFound,
+				Message:  fmt.Sprintf("The requested topic '%v' does not exist", topicName),
+				IsSilent: false,
+			}
+			rest.SendRESTError(w, r, logger, restErr)
+			return
+		}
 		if err != nil {
 			restErr := &rest.Error{
 				Err:      err,
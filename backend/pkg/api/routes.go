@@ -62,16 +62,20 @@ func (api *API) routes() *chi.Mux {
 			r.Route("/api", func(r chi.Router) {
 				r.Get("/api-versions", api.handleGetAPIVersions())
 				r.Get("/brokers/{brokerID}/config", api.handleBrokerConfig())
+				r.Get("/brokers/{brokerID}/api-versions", api.handleGetBrokerAPIVersions())
 				r.Get("/cluster", api.handleDescribeCluster())
+				r.Get("/cluster/quorum", api.handleDescribeQuorum())
 				r.Get("/topics", api.handleGetTopics())
 				r.Get("/acls", api.handleGetACLsOverview())
 				r.Get("/topics-configs", api.handleGetTopicsConfigs())
 				r.Get("/topics-offsets", api.handleGetTopicsOffsets())
 				r.Get("/topics/{topicName}/partitions", api.handleGetPartitions())
+				r.Get("/topics/{topicName}/partitions/{partitionID}/messages/{offset}", api.handleGetMessage())
 				r.Get("/topics/{topicName}/configuration", api.handleGetTopicConfig())
 				r.Get("/topics/{topicName}/consumers", api.handleGetTopicConsumers())
 				r.Get("/topics/{topicName}/documentation", api.handleGetTopicDocumentation())
 				r.Get("/consumer-groups/{groupId}", api.handleGetConsumerGroup())
+				r.Get("/consumer-groups/{groupId}/assignments", api.handleGetConsumerGroupAssignments())
 				r.Patch("/consumer-groups/{groupId}", api.handlePatchConsumerGroup())
 				r.Delete("/consumer-groups/{groupId}", api.handleDeleteConsumerGroupOffsets())
 				r.Get("/operations/topic-details", api.handleGetAllTopicDetails())
@@ -19,12 +19,35 @@ type progressReporter struct {
 	statsMutex       *sync.RWMutex
 	messagesConsumed int64
 	bytesConsumed    int64
+	messagesDropped  int64
+
+	// nextOffsets is the cursor of the page, it starts with the cursor of the request and advances with every sent
+	// message. Partitions without sent messages keep their position, so that the next page returns their messages.
+	nextOffsets map[int32]int64
+}
+
+func newProgressReporter(ctx context.Context, logger *zap.Logger, request *owl.ListMessageRequest, websocket *websocketClient) *progressReporter {
+	nextOffsets := make(map[int32]int64, len(request.CursorOffsets))
+	for partitionID, offset := range request.CursorOffsets {
+		nextOffsets[partitionID] = offset
+	}
+
+	return &progressReporter{
+		ctx:              ctx,
+		logger:           logger,
+		request:          request,
+		websocket:        websocket,
+		statsMutex:       &sync.RWMutex{},
+		messagesConsumed: 0,
+		bytesConsumed:    0,
+		nextOffsets:      nextOffsets,
+	}
 }
 
 func (p *progressReporter) Start() {
 	// If search is disabled do not report progress regularly as each consumed message will be sent through the socket
 	// anyways
-	if p.request.FilterInterpreterCode == "" {
+	if !p.request.HasFilters() {
 		return
 	}
 
@@ -71,23 +94,57 @@ func (p *progressReporter) OnMessageConsumed(size int64) {
 }
 
 func (p *progressReporter) OnMessage(message *kafka.TopicMessage) {
+	p.statsMutex.Lock()
+	// Messages of a partition may be sent out of order if they are filtered by multiple workers
+	if message.Offset >= p.nextOffsets[message.PartitionID] {
+		p.nextOffsets[message.PartitionID] = message.Offset + 1
+	}
+	p.statsMutex.Unlock()
+
 	_ = p.websocket.writeJSON(struct {
 		Type    string              `json:"type"`
 		Message *kafka.TopicMessage `json:"message"`
 	}{"message", message})
 }
 
+// OnMessagesDropped is called if messages have been dropped because the client received them too slowly
+func (p *progressReporter) OnMessagesDropped(count int64) {
+	p.statsMutex.Lock()
+	defer p.statsMutex.Unlock()
+
+	p.messagesDropped += count
+}
+
+// OnComplete sends the done message. Its cursor continues after the sent messages if it is passed with the next
+// request, it's omitted for requests which don't support cursors.
 func (p *progressReporter) OnComplete(elapsedMs int64, isCancelled bool) {
 	p.statsMutex.RLock()
 	defer p.statsMutex.RUnlock()
 
+	var cursor string
+	if p.supportsCursor() {
+		var err error
+		cursor, err = kafka.EncodeCursor(kafka.Cursor{TopicName: p.request.TopicName, NextOffsets: p.nextOffsets})
+		if err != nil {
+			p.logger.Warn("failed to encode cursor", zap.Error(err))
+		}
+	}
+
 	_ = p.websocket.writeJSON(struct {
 		Type             string `json:"type"`
 		ElapsedMs        int64  `json:"elapsedMs"`
 		IsCancelled      bool   `json:"isCancelled"`
 		MessagesConsumed int64  `json:"messagesConsumed"`
 		BytesConsumed    int64  `json:"bytesConsumed"`
-	}{"done", elapsedMs, isCancelled, p.messagesConsumed, p.bytesConsumed})
+		MessagesDropped  int64  `json:"messagesDropped"`
+		Cursor           string `json:"cursor,omitempty"`
+	}{"done", elapsedMs, isCancelled, p.messagesConsumed, p.bytesConsumed, p.messagesDropped, cursor})
+}
+
+// supportsCursor returns false for requests which don't page forward through the topic, see
+// owl.ListMessageRequest.CursorOffsets.
+func (p *progressReporter) supportsCursor() bool {
+	return p.request.StartOffset != owl.StartOffsetRecent && p.request.StartOffset != owl.StartOffsetNewest && !p.request.Follow
 }
 
 func (p *progressReporter) OnError(message string) {
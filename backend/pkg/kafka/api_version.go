@@ -2,10 +2,33 @@ package kafka
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/twmb/franz-go/pkg/kversion"
 )
 
+// ErrUnsupportedRequest is returned if the cluster does not support a request, for instance because the brokers
+// are too old. Check for it with errors.Is(), the actual error is an *UnsupportedRequestError.
+var ErrUnsupportedRequest = errors.New("request is not supported by the cluster")
+
+// UnsupportedRequestError is returned if the cluster does not support the Kafka API of a request.
+type UnsupportedRequestError struct {
+	RequestName string
+}
+
+func (e *UnsupportedRequestError) Error() string {
+	return fmt.Sprintf("the Kafka cluster does not support %v requests", e.RequestName)
+}
+
+// Is makes the error match ErrUnsupportedRequest
+func (e *UnsupportedRequestError) Is(target error) bool {
+	return target == ErrUnsupportedRequest
+}
+
 // GetAPIVersions returns the supported Kafka API versions
 func (s *Service) GetAPIVersions(ctx context.Context) (*kmsg.ApiVersionsResponse, error) {
 	req := kmsg.NewApiVersionsRequest()
@@ -14,3 +37,84 @@ func (s *Service) GetAPIVersions(ctx context.Context) (*kmsg.ApiVersionsResponse
 
 	return req.RequestWith(ctx, s.KafkaClient)
 }
+
+// GetBrokerAPIVersions returns the supported Kafka API versions of a single broker. Versions may differ between
+// brokers, for instance during a rolling upgrade. An error wrapping ErrBrokerNotFound is returned if the broker is
+// not part of the cluster.
+func (s *Service) GetBrokerAPIVersions(ctx context.Context, brokerID int32) (*kmsg.ApiVersionsResponse, error) {
+	if err := s.requireBrokerExists(ctx, brokerID); err != nil {
+		return nil, err
+	}
+
+	req := kmsg.NewApiVersionsRequest()
+	req.ClientSoftwareVersion = "NA"
+	req.ClientSoftwareName = "Kowl"
+
+	return req.RequestWith(ctx, s.KafkaClient.ForBroker(brokerID))
+}
+
+// clusterVersionsTTL is how long the supported API versions of the cluster are cached. Versions only change when
+// the brokers are upgraded, but a rolling upgrade shall be noticed without restarting Kowl.
+const clusterVersionsTTL = 5 * time.Minute
+
+// clusterVersionsTimeout bounds the ApiVersions request of getClusterVersions, which does not use the context of any
+// of its callers because the request is shared.
+const clusterVersionsTimeout = 10 * time.Second
+
+// getClusterVersions returns the supported Kafka API versions of the cluster. The versions are cached for
+// clusterVersionsTTL. Concurrent callers share a single ApiVersions request, which is sent without holding the lock,
+// so that callers with cached versions are not blocked by a slow request.
+func (s *Service) getClusterVersions(ctx context.Context) (*kversion.Versions, error) {
+	s.clusterVersionsMutex.Lock()
+	versions, fetchedAt := s.clusterVersions, s.clusterVersionsFetchedAt
+	s.clusterVersionsMutex.Unlock()
+	if versions != nil && time.Since(fetchedAt) < clusterVersionsTTL {
+		return versions, nil
+	}
+
+	resCh := s.clusterVersionsRequestGroup.DoChan("versions", func() (interface{}, error) {
+		requestCtx, cancel := context.WithTimeout(context.Background(), clusterVersionsTimeout)
+		defer cancel()
+
+		res, err := s.GetAPIVersions(requestCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to request api versions: %w", err)
+		}
+		err = kerr.ErrorForCode(res.ErrorCode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to request api versions. Inner kafka error: %w", err)
+		}
+		versions := kversion.FromApiVersionsResponse(res)
+
+		s.clusterVersionsMutex.Lock()
+		s.clusterVersions = versions
+		s.clusterVersionsFetchedAt = time.Now()
+		s.clusterVersionsMutex.Unlock()
+
+		return versions, nil
+	})
+
+	select {
+	case res := <-resCh:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*kversion.Versions), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// requireSupportedRequest returns an *UnsupportedRequestError if the cluster does not support the given request's
+// API key at all.
+func (s *Service) requireSupportedRequest(ctx context.Context, req kmsg.Request) error {
+	versions, err := s.getClusterVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if _, isSupported := versions.LookupMaxKeyVersion(req.Key()); !isSupported {
+		return &UnsupportedRequestError{RequestName: kmsg.NameForKey(req.Key())}
+	}
+
+	return nil
+}
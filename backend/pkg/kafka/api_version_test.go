@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestService_GetBrokerAPIVersions(t *testing.T) {
+	requestedBrokers := make([]int32, 0)
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch req.(type) {
+		case *kmsg.MetadataRequest:
+			return &kmsg.MetadataResponse{Brokers: []kmsg.MetadataResponseBroker{{NodeID: 1}, {NodeID: 2}}}, nil
+		case *kmsg.ApiVersionsRequest:
+			requestedBrokers = append(requestedBrokers, brokerID)
+			return apiVersionsResponse(kmsg.NewPtrFetchRequest()), nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{KafkaClient: client}
+
+	res, err := svc.GetBrokerAPIVersions(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, res.ApiKeys, 1)
+	assert.Equal(t, kmsg.NewPtrFetchRequest().Key(), res.ApiKeys[0].ApiKey)
+	assert.Equal(t, []int32{2}, requestedBrokers)
+
+	// Unknown brokers are rejected without sending the ApiVersions request
+	_, err = svc.GetBrokerAPIVersions(context.Background(), 3)
+	assert.True(t, errors.Is(err, ErrBrokerNotFound))
+	assert.Equal(t, []int32{2}, requestedBrokers)
+}
+
+func TestService_getClusterVersions(t *testing.T) {
+	requests := int32(0)
+	release := make(chan struct{})
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.ApiVersionsRequest); !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		atomic.AddInt32(&requests, 1)
+		<-release
+		return apiVersionsResponse(kmsg.NewPtrFetchRequest()), nil
+	}}
+	svc := &Service{KafkaClient: client}
+
+	// A caller whose context is done stops waiting, the other callers share the in-flight request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := svc.getClusterVersions(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			versions, err := svc.getClusterVersions(context.Background())
+			if assert.NoError(t, err) {
+				_, isSupported := versions.LookupMaxKeyVersion(kmsg.NewPtrFetchRequest().Key())
+				assert.True(t, isSupported)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// Cached versions are returned without a request until they expire
+	_, err = svc.getClusterVersions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	svc.clusterVersionsFetchedAt = time.Now().Add(-clusterVersionsTTL)
+	_, err = svc.getClusterVersions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,154 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// Operations of an IncrementalAlterConfigs request config
+const (
+	alterConfigOpSet    int8 = 0
+	alterConfigOpDelete int8 = 1
+)
+
+// ConfigChangeOperation describes how a config entry has been changed in order to converge to the desired state
+type ConfigChangeOperation string
+
+const (
+	// ConfigChangeAdd is an entry that has not been explicitly set on the topic before
+	ConfigChangeAdd ConfigChangeOperation = "add"
+	// ConfigChangeUpdate is an explicitly set entry whose value has been changed
+	ConfigChangeUpdate ConfigChangeOperation = "update"
+	// ConfigChangeDelete is an explicitly set entry which has been removed, so that the default applies again
+	ConfigChangeDelete ConfigChangeOperation = "delete"
+)
+
+// ConfigChange is a single config entry that has been altered. OldValue is nil if the entry has not been explicitly
+// set before or if it is sensitive, NewValue is nil for deleted entries.
+type ConfigChange struct {
+	Name      string                `json:"name"`
+	Operation ConfigChangeOperation `json:"operation"`
+	OldValue  *string               `json:"oldValue"`
+	NewValue  *string               `json:"newValue"`
+}
+
+// ConfigApplyResult lists the config entries that have been changed as well as the desired entries which already had
+// the desired value, both sorted by config name.
+type ConfigApplyResult struct {
+	TopicName string         `json:"topicName"`
+	Changes   []ConfigChange `json:"changes"`
+	Unchanged []string       `json:"unchanged"`
+}
+
+// ApplyTopicConfig converges the topic's configs towards the desired config values. Only entries whose current value
+// differs from the desired value are altered, configs which are not part of desired are left untouched. See
+// ReconcileTopicConfig for deleting them as well.
+func (s *Service) ApplyTopicConfig(ctx context.Context, topic string, desired map[string]string) (ConfigApplyResult, error) {
+	return s.ReconcileTopicConfig(ctx, topic, desired, false)
+}
+
+// ReconcileTopicConfig is like ApplyTopicConfig, but if strict is true it also deletes all explicitly set configs
+// which are not part of desired, so that the topic ends up with exactly the desired configs.
+//
+// An entry is unchanged if its current value equals the desired value, regardless of whether the value is set on
+// the topic or inherited from the broker. Sensitive values are not returned by Kafka, hence they are always set.
+// All changes are sent in a single IncrementalAlterConfigs request, which Kafka applies atomically per topic.
+func (s *Service) ReconcileTopicConfig(ctx context.Context, topic string, desired map[string]string, strict bool) (ConfigApplyResult, error) {
+	resources, err := s.DescribeTopicsConfigs(ctx, []string{topic}, nil)
+	if err != nil {
+		return ConfigApplyResult{}, err
+	}
+	resource := resources[topic]
+	if err := kerr.ErrorForCode(resource.ErrorCode); err != nil {
+		return ConfigApplyResult{}, fmt.Errorf("failed to describe configs of topic '%v': %w", topic, err)
+	}
+
+	result := diffTopicConfig(topic, resource.Configs, desired, strict)
+	if len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	alterResource := kmsg.NewIncrementalAlterConfigsRequestResource()
+	alterResource.ResourceType = kmsg.ConfigResourceTypeTopic
+	alterResource.ResourceName = topic
+	alterResource.Configs = make([]kmsg.IncrementalAlterConfigsRequestResourceConfig, len(result.Changes))
+	for i, change := range result.Changes {
+		cfg := kmsg.NewIncrementalAlterConfigsRequestResourceConfig()
+		cfg.Name = change.Name
+		cfg.Op = alterConfigOpSet
+		cfg.Value = change.NewValue
+		if change.Operation == ConfigChangeDelete {
+			cfg.Op = alterConfigOpDelete
+		}
+		alterResource.Configs[i] = cfg
+	}
+
+	res, err := s.IncrementalAlterConfigs(ctx, []kmsg.IncrementalAlterConfigsRequestResource{alterResource})
+	if err != nil {
+		return ConfigApplyResult{}, fmt.Errorf("failed to alter configs of topic '%v': %w", topic, err)
+	}
+	for _, alteredResource := range res.Resources {
+		err := kerr.ErrorForCode(alteredResource.ErrorCode)
+		if err == nil {
+			continue
+		}
+		if alteredResource.ErrorMessage != nil {
+			return ConfigApplyResult{}, fmt.Errorf("failed to alter configs of topic '%v': %w: %v", topic, err, *alteredResource.ErrorMessage)
+		}
+		return ConfigApplyResult{}, fmt.Errorf("failed to alter configs of topic '%v': %w", topic, err)
+	}
+
+	return result, nil
+}
+
+// diffTopicConfig computes the changes that are required to converge the current configs to the desired configs
+func diffTopicConfig(topic string, current []kmsg.DescribeConfigsResponseResourceConfig, desired map[string]string, strict bool) ConfigApplyResult {
+	currentByName := make(map[string]kmsg.DescribeConfigsResponseResourceConfig, len(current))
+	for _, cfg := range current {
+		currentByName[cfg.Name] = cfg
+	}
+
+	result := ConfigApplyResult{
+		TopicName: topic,
+		Changes:   make([]ConfigChange, 0),
+		Unchanged: make([]string, 0),
+	}
+	for name, value := range desired {
+		newValue := value
+		cfg, exists := currentByName[name]
+		isExplicitlySet := exists && cfg.Source == kmsg.ConfigSourceDynamicTopicConfig
+		switch {
+		case exists && !cfg.IsSensitive && cfg.Value != nil && *cfg.Value == value:
+			result.Unchanged = append(result.Unchanged, name)
+		case isExplicitlySet:
+			change := ConfigChange{Name: name, Operation: ConfigChangeUpdate, NewValue: &newValue}
+			if !cfg.IsSensitive {
+				change.OldValue = cfg.Value
+			}
+			result.Changes = append(result.Changes, change)
+		default:
+			result.Changes = append(result.Changes, ConfigChange{Name: name, Operation: ConfigChangeAdd, NewValue: &newValue})
+		}
+	}
+	if strict {
+		for name, cfg := range currentByName {
+			if _, isDesired := desired[name]; isDesired || cfg.Source != kmsg.ConfigSourceDynamicTopicConfig {
+				continue
+			}
+			change := ConfigChange{Name: name, Operation: ConfigChangeDelete}
+			if !cfg.IsSensitive {
+				change.OldValue = cfg.Value
+			}
+			result.Changes = append(result.Changes, change)
+		}
+	}
+
+	sort.Slice(result.Changes, func(i, j int) bool { return result.Changes[i].Name < result.Changes[j].Name })
+	sort.Strings(result.Unchanged)
+
+	return result
+}
@@ -0,0 +1,129 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// newApplyTopicConfigClient returns a client which describes the given topic configs and records all altered configs
+func newApplyTopicConfigClient(configs []kmsg.DescribeConfigsResponseResourceConfig, altered *[]kmsg.IncrementalAlterConfigsRequestResourceConfig) *mockKafkaClient {
+	return &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch r := req.(type) {
+		case *kmsg.DescribeConfigsRequest:
+			resource := kmsg.NewDescribeConfigsResponseResource()
+			resource.ResourceType = kmsg.ConfigResourceTypeTopic
+			resource.ResourceName = r.Resources[0].ResourceName
+			resource.Configs = configs
+			return &kmsg.DescribeConfigsResponse{Resources: []kmsg.DescribeConfigsResponseResource{resource}}, nil
+		case *kmsg.IncrementalAlterConfigsRequest:
+			*altered = append(*altered, r.Resources[0].Configs...)
+			resource := kmsg.NewIncrementalAlterConfigsResponseResource()
+			resource.ResourceType = kmsg.ConfigResourceTypeTopic
+			resource.ResourceName = r.Resources[0].ResourceName
+			return &kmsg.IncrementalAlterConfigsResponse{Resources: []kmsg.IncrementalAlterConfigsResponseResource{resource}}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+}
+
+func TestApplyTopicConfig(t *testing.T) {
+	retention := "604800000"
+	cleanupPolicy := "delete"
+	compression := "producer"
+	configs := []kmsg.DescribeConfigsResponseResourceConfig{
+		{Name: "retention.ms", Value: &retention, Source: kmsg.ConfigSourceDynamicTopicConfig},
+		{Name: "cleanup.policy", Value: &cleanupPolicy, Source: kmsg.ConfigSourceDefaultConfig},
+		{Name: "compression.type", Value: &compression, Source: kmsg.ConfigSourceDynamicTopicConfig},
+		{Name: "secret", Value: nil, Source: kmsg.ConfigSourceDynamicTopicConfig, IsSensitive: true},
+	}
+	desired := map[string]string{
+		"retention.ms":        "86400000", // changed
+		"cleanup.policy":      "delete",   // equals the default
+		"min.insync.replicas": "2",        // added
+		"secret":              "s3cret",   // sensitive, always set
+	}
+
+	t.Run("apply", func(t *testing.T) {
+		altered := make([]kmsg.IncrementalAlterConfigsRequestResourceConfig, 0)
+		svc := &Service{Logger: zap.NewNop(), KafkaClient: newApplyTopicConfigClient(configs, &altered)}
+
+		result, err := svc.ApplyTopicConfig(context.Background(), "orders", desired)
+		require.NoError(t, err)
+
+		assert.Equal(t, "orders", result.TopicName)
+		assert.Equal(t, []string{"cleanup.policy"}, result.Unchanged)
+		require.Len(t, result.Changes, 3)
+		assert.Equal(t, ConfigChange{Name: "min.insync.replicas", Operation: ConfigChangeAdd, NewValue: strPtr("2")}, result.Changes[0])
+		assert.Equal(t, ConfigChange{Name: "retention.ms", Operation: ConfigChangeUpdate, OldValue: &retention, NewValue: strPtr("86400000")}, result.Changes[1])
+		assert.Equal(t, ConfigChange{Name: "secret", Operation: ConfigChangeUpdate, NewValue: strPtr("s3cret")}, result.Changes[2])
+
+		// compression.type is not desired but must be left untouched
+		require.Len(t, altered, 3)
+		for _, cfg := range altered {
+			assert.Equal(t, alterConfigOpSet, cfg.Op, cfg.Name)
+			assert.NotEqual(t, "compression.type", cfg.Name)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		altered := make([]kmsg.IncrementalAlterConfigsRequestResourceConfig, 0)
+		svc := &Service{Logger: zap.NewNop(), KafkaClient: newApplyTopicConfigClient(configs, &altered)}
+
+		result, err := svc.ReconcileTopicConfig(context.Background(), "orders", desired, true)
+		require.NoError(t, err)
+
+		require.Len(t, result.Changes, 4)
+		assert.Equal(t, ConfigChange{Name: "compression.type", Operation: ConfigChangeDelete, OldValue: &compression}, result.Changes[0])
+		require.Len(t, altered, 4)
+		assert.Equal(t, "compression.type", altered[0].Name)
+		assert.Equal(t, alterConfigOpDelete, altered[0].Op)
+		assert.Nil(t, altered[0].Value)
+	})
+
+	t.Run("no-op", func(t *testing.T) {
+		altered := make([]kmsg.IncrementalAlterConfigsRequestResourceConfig, 0)
+		svc := &Service{Logger: zap.NewNop(), KafkaClient: newApplyTopicConfigClient(configs, &altered)}
+
+		result, err := svc.ApplyTopicConfig(context.Background(), "orders", map[string]string{
+			"retention.ms":     retention,
+			"cleanup.policy":   cleanupPolicy,
+			"compression.type": compression,
+		})
+		require.NoError(t, err)
+
+		// Without changes no alter request must be sent
+		assert.Empty(t, altered)
+		assert.Empty(t, result.Changes)
+		assert.Equal(t, []string{"cleanup.policy", "compression.type", "retention.ms"}, result.Unchanged)
+	})
+}
+
+func TestApplyTopicConfig_AlterError(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch r := req.(type) {
+		case *kmsg.DescribeConfigsRequest:
+			resource := kmsg.NewDescribeConfigsResponseResource()
+			resource.ResourceType = kmsg.ConfigResourceTypeTopic
+			resource.ResourceName = r.Resources[0].ResourceName
+			return &kmsg.DescribeConfigsResponse{Resources: []kmsg.DescribeConfigsResponseResource{resource}}, nil
+		case *kmsg.IncrementalAlterConfigsRequest:
+			resource := kmsg.NewIncrementalAlterConfigsResponseResource()
+			resource.ResourceName = r.Resources[0].ResourceName
+			resource.ErrorCode = kerr.InvalidConfig.Code
+			return &kmsg.IncrementalAlterConfigsResponse{Resources: []kmsg.IncrementalAlterConfigsResponseResource{resource}}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	_, err := svc.ApplyTopicConfig(context.Background(), "orders", map[string]string{"unknown.config": "1"})
+	assert.ErrorIs(t, err, kerr.InvalidConfig)
+}
+
+func strPtr(s string) *string { return &s }
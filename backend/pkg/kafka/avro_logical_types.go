@@ -0,0 +1,358 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// avroConverterCache caches an avroJSONConverter per schema ID, so that the schema of a codec is parsed only once
+// rather than for every record. Converters are not modified after their creation and can be used concurrently.
+type avroConverterCache struct {
+	mutex      sync.RWMutex
+	converters map[uint32]*avroJSONConverter
+}
+
+func newAvroConverterCache() *avroConverterCache {
+	return &avroConverterCache{converters: make(map[uint32]*avroJSONConverter)}
+}
+
+// get returns the cached converter of the schema ID. A new converter is created if none is cached or if the cached
+// converter was created for a different codec. A nil cache creates a new converter on each call.
+func (c *avroConverterCache) get(schemaID uint32, codec *goavro.Codec) *avroJSONConverter {
+	if c == nil {
+		return newAvroJSONConverter(codec)
+	}
+
+	c.mutex.RLock()
+	converter, exists := c.converters[schemaID]
+	c.mutex.RUnlock()
+	if exists && converter.codec == codec {
+		return converter
+	}
+
+	converter = newAvroJSONConverter(codec)
+	c.mutex.Lock()
+	c.converters[schemaID] = converter
+	c.mutex.Unlock()
+
+	return converter
+}
+
+// avroJSONConverter walks a native goavro datum along with its schema so that logical types can be identified
+type avroJSONConverter struct {
+	codec  *goavro.Codec
+	schema interface{}
+
+	// namedTypes contains all records, enums and fixed types by their name and their full name
+	namedTypes map[string]interface{}
+}
+
+// newAvroJSONConverter parses the schema of the codec and registers its named types
+func newAvroJSONConverter(codec *goavro.Codec) *avroJSONConverter {
+	var schema interface{}
+	err := json.Unmarshal([]byte(codec.Schema()), &schema)
+	if err != nil {
+		// Schemas for primitive types may be given without quotes
+		schema = codec.Schema()
+	}
+
+	converter := &avroJSONConverter{codec: codec, schema: schema, namedTypes: make(map[string]interface{})}
+	converter.registerNamedTypes(schema, "")
+
+	return converter
+}
+
+// textualFromNative returns the JSON representation of a native Avro datum. Unlike goavro's textual encoding,
+// which renders logical types as their underlying type (e.g. timestamps as long and decimals as bytes), logical types
+// are converted into readable representations:
+//   - decimals are rendered as string using the schema's scale
+//   - timestamps are rendered as RFC3339 strings in UTC, dates as YYYY-MM-DD
+//   - times of day are rendered as HH:MM:SS with fractional seconds
+//   - UUIDs are rendered in their canonical, lower case form
+func (c *avroJSONConverter) textualFromNative(native interface{}) ([]byte, error) {
+	return c.convert(c.schema, "", native)
+}
+
+func (c *avroJSONConverter) registerNamedTypes(schema interface{}, namespace string) {
+	switch s := schema.(type) {
+	case []interface{}:
+		for _, member := range s {
+			c.registerNamedTypes(member, namespace)
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error", "enum", "fixed":
+			name, fullName, ns := avroTypeNames(s, namespace)
+			c.namedTypes[name] = s
+			c.namedTypes[fullName] = s
+			if fields, ok := s["fields"].([]interface{}); ok {
+				for _, field := range fields {
+					if f, ok := field.(map[string]interface{}); ok {
+						c.registerNamedTypes(f["type"], ns)
+					}
+				}
+			}
+		case "array":
+			c.registerNamedTypes(s["items"], namespace)
+		case "map":
+			c.registerNamedTypes(s["values"], namespace)
+		default:
+			c.registerNamedTypes(s["type"], namespace)
+		}
+	}
+}
+
+func (c *avroJSONConverter) convert(schema interface{}, namespace string, datum interface{}) ([]byte, error) {
+	if datum == nil {
+		return []byte("null"), nil
+	}
+
+	switch s := schema.(type) {
+	case string:
+		if named, exists := c.namedTypes[s]; exists {
+			return c.convert(named, namespace, datum)
+		}
+		if named, exists := c.namedTypes[namespace+"."+s]; exists {
+			return c.convert(named, namespace, datum)
+		}
+		return avroPrimitiveToJSON(datum)
+	case []interface{}:
+		return c.convertUnion(s, namespace, datum)
+	case map[string]interface{}:
+		if logicalType, ok := s["logicalType"].(string); ok {
+			if converted, ok := convertAvroLogicalType(logicalType, s, datum); ok {
+				return marshalJSON(converted)
+			}
+		}
+
+		switch s["type"] {
+		case "record", "error":
+			_, _, ns := avroTypeNames(s, namespace)
+			return c.convertRecord(s, ns, datum)
+		case "array":
+			items, ok := datum.([]interface{})
+			if !ok {
+				return avroPrimitiveToJSON(datum)
+			}
+			converted := make([]json.RawMessage, len(items))
+			for i, item := range items {
+				raw, err := c.convert(s["items"], namespace, item)
+				if err != nil {
+					return nil, err
+				}
+				converted[i] = raw
+			}
+			return marshalJSON(converted)
+		case "map":
+			values, ok := datum.(map[string]interface{})
+			if !ok {
+				return avroPrimitiveToJSON(datum)
+			}
+			converted := make(map[string]json.RawMessage, len(values))
+			for key, value := range values {
+				raw, err := c.convert(s["values"], namespace, value)
+				if err != nil {
+					return nil, err
+				}
+				converted[key] = raw
+			}
+			return marshalJSON(converted)
+		case "enum", "fixed":
+			return avroPrimitiveToJSON(datum)
+		default:
+			// Type is either a primitive type or a nested type definition
+			return c.convert(s["type"], namespace, datum)
+		}
+	}
+
+	return avroPrimitiveToJSON(datum)
+}
+
+// convertRecord converts a record while retaining the field order of the schema
+func (c *avroJSONConverter) convertRecord(schema map[string]interface{}, namespace string, datum interface{}) ([]byte, error) {
+	values, ok := datum.(map[string]interface{})
+	if !ok {
+		return avroPrimitiveToJSON(datum)
+	}
+	fields, _ := schema["fields"].([]interface{})
+
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for _, field := range fields {
+		f, ok := field.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := f["name"].(string)
+		value, exists := values[name]
+		if !exists {
+			continue
+		}
+
+		raw, err := c.convert(f["type"], namespace, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert field '%v': %w", name, err)
+		}
+		key, err := marshalJSON(name)
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(raw)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// convertUnion converts a non null union value. Goavro represents these as a map with a single key that is the name
+// of the union member. The map is retained, so that the output has the same structure as goavro's textual encoding.
+func (c *avroJSONConverter) convertUnion(members []interface{}, namespace string, datum interface{}) ([]byte, error) {
+	values, ok := datum.(map[string]interface{})
+	if !ok || len(values) != 1 {
+		return avroPrimitiveToJSON(datum)
+	}
+
+	for typeName, value := range values {
+		var memberSchema interface{} = typeName
+		for _, member := range members {
+			if c.unionMemberName(member, namespace) == typeName {
+				memberSchema = member
+				break
+			}
+		}
+
+		raw, err := c.convert(memberSchema, namespace, value)
+		if err != nil {
+			return nil, err
+		}
+		return marshalJSON(map[string]json.RawMessage{typeName: raw})
+	}
+
+	return nil, nil // unreachable, the map has exactly one entry
+}
+
+// unionMemberName returns the name goavro uses for a union member, e.g. "long.timestamp-millis" or "com.example.User"
+func (c *avroJSONConverter) unionMemberName(member interface{}, namespace string) string {
+	switch m := member.(type) {
+	case string:
+		if named, exists := c.namedTypes[m]; exists {
+			return c.unionMemberName(named, namespace)
+		}
+		return m
+	case map[string]interface{}:
+		switch m["type"] {
+		case "record", "error", "enum", "fixed":
+			_, fullName, _ := avroTypeNames(m, namespace)
+			return fullName
+		case "array", "map":
+			return m["type"].(string)
+		}
+		typeName, _ := m["type"].(string)
+		if logicalType, ok := m["logicalType"].(string); ok {
+			return typeName + "." + logicalType
+		}
+		return typeName
+	}
+	return ""
+}
+
+// convertAvroLogicalType returns the readable representation of a logical type. False is returned if the datum
+// doesn't have the type that is expected for the logical type, in which case it should be rendered as its
+// underlying type.
+func convertAvroLogicalType(logicalType string, schema map[string]interface{}, datum interface{}) (interface{}, bool) {
+	switch logicalType {
+	case "decimal":
+		rat, ok := datum.(*big.Rat)
+		if !ok {
+			return nil, false
+		}
+		scale, _ := schema["scale"].(float64)
+		return rat.FloatString(int(scale)), true
+	case "timestamp-millis", "timestamp-micros":
+		ts, ok := datum.(time.Time)
+		if !ok {
+			return nil, false
+		}
+		return ts.UTC().Format(time.RFC3339Nano), true
+	case "date":
+		date, ok := datum.(time.Time)
+		if !ok {
+			return nil, false
+		}
+		return date.UTC().Format("2006-01-02"), true
+	case "time-millis", "time-micros":
+		duration, ok := datum.(time.Duration)
+		if !ok {
+			return nil, false
+		}
+		return time.Time{}.Add(duration).Format("15:04:05.999999"), true
+	case "uuid":
+		switch uuid := datum.(type) {
+		case string:
+			return strings.ToLower(uuid), true
+		case []byte:
+			if len(uuid) != 16 {
+				return nil, false
+			}
+			encoded := hex.EncodeToString(uuid)
+			return fmt.Sprintf("%v-%v-%v-%v-%v", encoded[0:8], encoded[8:12], encoded[12:16], encoded[16:20], encoded[20:]), true
+		}
+	}
+
+	return nil, false
+}
+
+// avroPrimitiveToJSON converts a datum without logical type. Bytes are rendered like goavro does, that is each byte
+// is a single character of the JSON string.
+func avroPrimitiveToJSON(datum interface{}) ([]byte, error) {
+	if b, ok := datum.([]byte); ok {
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return marshalJSON(string(runes))
+	}
+	return marshalJSON(datum)
+}
+
+// avroTypeNames returns the name, the full name and the namespace of a named Avro type
+func avroTypeNames(schema map[string]interface{}, enclosingNamespace string) (string, string, string) {
+	name, _ := schema["name"].(string)
+	namespace := enclosingNamespace
+	if ns, ok := schema["namespace"].(string); ok {
+		namespace = ns
+	}
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		// Name is already a full name
+		return name[idx+1:], name, name[:idx]
+	}
+	if namespace == "" {
+		return name, name, namespace
+	}
+	return name, namespace + "." + name, namespace
+}
+
+// marshalJSON marshals the given value without escaping HTML characters
+func marshalJSON(v interface{}) ([]byte, error) {
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
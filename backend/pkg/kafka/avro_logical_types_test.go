@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvroTextualFromNative_LogicalTypes(t *testing.T) {
+	schema := `{
+		"type": "record",
+		"name": "Payment",
+		"namespace": "com.example",
+		"fields": [
+			{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+			{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+			{"name": "createdAt", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "processedAt", "type": ["null", {"type": "long", "logicalType": "timestamp-micros"}]},
+			{"name": "bookingDate", "type": {"type": "int", "logicalType": "date"}},
+			{"name": "cutOff", "type": {"type": "int", "logicalType": "time-millis"}},
+			{"name": "reference", "type": {"type": "fixed", "name": "Reference", "size": 16, "logicalType": "uuid"}},
+			{"name": "note", "type": "string"}
+		]
+	}`
+
+	tests := []struct {
+		name     string
+		datum    map[string]interface{}
+		expected string
+	}{
+		{
+			name: "positive values",
+			datum: map[string]interface{}{
+				"id":          "1B4E28BA-2FA1-11D2-883F-0016D3CCA427",
+				"amount":      big.NewRat(1050, 100),
+				"createdAt":   time.Date(2021, 5, 3, 12, 30, 15, 123000000, time.UTC),
+				"processedAt": goavro.Union("long.timestamp-micros", time.Date(2021, 5, 3, 12, 30, 15, 123456000, time.UTC)),
+				"bookingDate": time.Date(2021, 5, 3, 0, 0, 0, 0, time.UTC),
+				"cutOff":      13*time.Hour + 45*time.Minute + 500*time.Millisecond,
+				"reference":   []byte{0x1b, 0x4e, 0x28, 0xba, 0x2f, 0xa1, 0x11, 0xd2, 0x88, 0x3f, 0x00, 0x16, 0xd3, 0xcc, 0xa4, 0x27},
+				"note":        "<b>",
+			},
+			expected: `{"id":"1b4e28ba-2fa1-11d2-883f-0016d3cca427","amount":"10.50","createdAt":"2021-05-03T12:30:15.123Z",` +
+				`"processedAt":{"long.timestamp-micros":"2021-05-03T12:30:15.123456Z"},"bookingDate":"2021-05-03","cutOff":"13:45:00.5",` +
+				`"reference":"1b4e28ba-2fa1-11d2-883f-0016d3cca427","note":"<b>"}`,
+		},
+		{
+			name: "negative values and edge timestamps",
+			datum: map[string]interface{}{
+				"id":          "00000000-0000-0000-0000-000000000000",
+				"amount":      big.NewRat(-5, 100),
+				"createdAt":   time.Unix(0, 0),
+				"processedAt": nil,
+				"bookingDate": time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+				"cutOff":      time.Duration(0),
+				"reference":   make([]byte, 16),
+				"note":        "",
+			},
+			expected: `{"id":"00000000-0000-0000-0000-000000000000","amount":"-0.05","createdAt":"1970-01-01T00:00:00Z",` +
+				`"processedAt":null,"bookingDate":"1969-12-31","cutOff":"00:00:00",` +
+				`"reference":"00000000-0000-0000-0000-000000000000","note":""}`,
+		},
+		{
+			name: "before epoch",
+			datum: map[string]interface{}{
+				"id":          "1b4e28ba-2fa1-11d2-883f-0016d3cca427",
+				"amount":      big.NewRat(-123456789, 1),
+				"createdAt":   time.Date(1900, 1, 1, 0, 0, 0, 1000000, time.UTC),
+				"processedAt": goavro.Union("long.timestamp-micros", time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC)),
+				"bookingDate": time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+				"cutOff":      24*time.Hour - time.Millisecond,
+				"reference":   make([]byte, 16),
+				"note":        "",
+			},
+			expected: `{"id":"1b4e28ba-2fa1-11d2-883f-0016d3cca427","amount":"-123456789.00","createdAt":"1900-01-01T00:00:00.001Z",` +
+				`"processedAt":{"long.timestamp-micros":"9999-12-31T23:59:59.999999Z"},"bookingDate":"0001-01-01","cutOff":"23:59:59.999",` +
+				`"reference":"00000000-0000-0000-0000-000000000000","note":""}`,
+		},
+	}
+
+	codec, err := goavro.NewCodec(schema)
+	require.NoError(t, err)
+
+	for _, tc := range tests {
+		if tc.datum["processedAt"] == nil {
+			tc.datum["processedAt"] = goavro.Union("null", nil)
+		}
+		binary, err := codec.BinaryFromNative(nil, tc.datum)
+		require.NoError(t, err, tc.name)
+		native, _, err := codec.NativeFromBinary(binary)
+		require.NoError(t, err, tc.name)
+
+		textual, err := newAvroJSONConverter(codec).textualFromNative(native)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, string(textual), tc.name)
+	}
+}
+
+func TestAvroTextualFromNative_NamedTypes(t *testing.T) {
+	schema := `{
+		"type": "record",
+		"name": "Order",
+		"fields": [
+			{"name": "price", "type": {"type": "fixed", "name": "Price", "size": 8, "logicalType": "decimal", "precision": 18, "scale": 3}},
+			{"name": "discount", "type": ["null", "Price"]},
+			{"name": "items", "type": {"type": "array", "items": {"type": "record", "name": "Item", "fields": [
+				{"name": "deliveredAt", "type": {"type": "long", "logicalType": "timestamp-millis"}}
+			]}}},
+			{"name": "lastItem", "type": "Item"}
+		]
+	}`
+	codec, err := goavro.NewCodec(schema)
+	require.NoError(t, err)
+
+	item := map[string]interface{}{"deliveredAt": time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+	binary, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"price":    big.NewRat(-1999, 1000),
+		"discount": goavro.Union("Price", big.NewRat(1, 2)),
+		"items":    []interface{}{item},
+		"lastItem": item,
+	})
+	require.NoError(t, err)
+	native, _, err := codec.NativeFromBinary(binary)
+	require.NoError(t, err)
+
+	textual, err := newAvroJSONConverter(codec).textualFromNative(native)
+	require.NoError(t, err)
+	assert.Equal(t, `{"price":"-1.999","discount":{"Price":"0.500"},"items":[{"deliveredAt":"2021-01-02T03:04:05Z"}],`+
+		`"lastItem":{"deliveredAt":"2021-01-02T03:04:05Z"}}`, string(textual))
+}
+
+func TestAvroConverterCache(t *testing.T) {
+	codec, err := goavro.NewCodec(`{"type": "long", "logicalType": "timestamp-millis"}`)
+	require.NoError(t, err)
+	otherCodec, err := goavro.NewCodec(`"string"`)
+	require.NoError(t, err)
+
+	cache := newAvroConverterCache()
+	converter := cache.get(1, codec)
+	assert.Same(t, converter, cache.get(1, codec))
+	assert.NotSame(t, converter, cache.get(2, codec))
+
+	// A different codec for a known schema ID replaces the cached converter
+	assert.Same(t, otherCodec, cache.get(1, otherCodec).codec)
+
+	var nilCache *avroConverterCache
+	assert.Same(t, codec, nilCache.get(1, codec).codec)
+}
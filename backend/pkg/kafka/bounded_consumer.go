@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// fetchPoller polls the records of the assigned partitions, it is implemented by *kgo.Client.
+type fetchPoller interface {
+	PollFetches(ctx context.Context) kgo.Fetches
+}
+
+// newBoundedConsumer creates a consumer for all records of the given partitions which exist at the time of the call,
+// that is the records between the low and high mark of each partition. Read committed consumers are bounded by the
+// last stable offsets (LSO) instead of the high water marks, because they don't receive records at or beyond the LSO
+// while a transaction is open. The returned end offsets are the last offset to consume by partition ID, partitions
+// without records are not consumed at all. No consumer is created if there are no records to consume, hence the
+// consumer is nil if the end offsets are empty. Use consumeBounded to consume the records.
+func (s *Service) newBoundedConsumer(ctx context.Context, topic string, partitionIDs []int32, isolationLevel IsolationLevel) (*kgo.Client, map[int32]int64, error) {
+	var marks map[int32]*PartitionMarks
+	var err error
+	if isolationLevel == IsolationLevelReadCommitted {
+		marks, err = s.GetLastStablePartitionMarks(ctx, topic, partitionIDs)
+	} else {
+		marks, err = s.GetPartitionMarks(ctx, topic, partitionIDs)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	endOffsets := make(map[int32]int64)
+	startOffsets := make(map[int32]kgo.Offset)
+	for partitionID, mark := range marks {
+		if mark.Error != "" {
+			return nil, nil, fmt.Errorf("failed to get watermarks of partition '%v': %v", partitionID, mark.Error)
+		}
+		if mark.High <= mark.Low {
+			continue
+		}
+		endOffsets[partitionID] = mark.High - 1
+		startOffsets[partitionID] = kgo.NewOffset().At(mark.Low)
+	}
+	if len(endOffsets) == 0 {
+		return nil, endOffsets, nil
+	}
+
+	kgoIsolationLevel := kgo.ReadUncommitted()
+	if isolationLevel == IsolationLevelReadCommitted {
+		kgoIsolationLevel = kgo.ReadCommitted()
+	}
+	consumer, err := s.NewKgoClient(append(clientIDOpts(ctx), kgo.FetchIsolationLevel(kgoIsolationLevel))...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+	consumer.AssignPartitions(kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{topic: startOffsets}))
+
+	return consumer, endOffsets, nil
+}
+
+// consumeBounded polls records and passes them to onRecord until each partition has reached its end offset or
+// onRecord returns false. Control records are passed as well. A partition is done once a record at or beyond its end
+// offset has been consumed, so that a missing record at the end offset (e.g. because it has been compacted away or
+// belongs to an aborted transaction) does not block the partition. Records beyond the end offset are not passed. The
+// context's error is returned if it is done before.
+func consumeBounded(ctx context.Context, consumer fetchPoller, endOffsets map[int32]int64, onRecord func(record *kgo.Record) bool) error {
+	for len(endOffsets) > 0 {
+		fetches := consumer.PollFetches(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if fetchErrs := fetches.Errors(); len(fetchErrs) > 0 {
+			return fmt.Errorf("failed to fetch records of partition '%v': %w", fetchErrs[0].Partition, fetchErrs[0].Err)
+		}
+
+		iter := fetches.RecordIter()
+		for !iter.Done() {
+			record := iter.Next()
+			endOffset, isRemaining := endOffsets[record.Partition]
+			if !isRemaining {
+				continue
+			}
+			if record.Offset >= endOffset {
+				delete(endOffsets, record.Partition)
+			}
+			if record.Offset > endOffset {
+				continue
+			}
+			if !onRecord(record) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// mockFetchPoller returns the given records of the topic 'orders' in one fetch per poll. Once all fetches have been
+// returned it blocks until the context is done, like a consumer which has reached the end of all partitions.
+type mockFetchPoller struct {
+	fetches [][]*kgo.Record
+}
+
+func (p *mockFetchPoller) PollFetches(ctx context.Context) kgo.Fetches {
+	if len(p.fetches) == 0 {
+		<-ctx.Done()
+		return nil
+	}
+	records := p.fetches[0]
+	p.fetches = p.fetches[1:]
+
+	partitions := make([]kgo.FetchPartition, 0, len(records))
+	for _, record := range records {
+		record.Topic = "orders"
+		partitions = append(partitions, kgo.FetchPartition{Partition: record.Partition, Records: []*kgo.Record{record}})
+	}
+	return kgo.Fetches{{Topics: []kgo.FetchTopic{{Topic: "orders", Partitions: partitions}}}}
+}
+
+func TestConsumeBounded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	consumer := &mockFetchPoller{fetches: [][]*kgo.Record{
+		{{Partition: 0, Offset: 8}, {Partition: 1, Offset: 3}},
+		// The record at partition 0's end offset 9 has been compacted away, the next one still completes the partition
+		{{Partition: 0, Offset: 11}, {Partition: 1, Offset: 4}},
+		// Records after the end offsets have been produced after the consumer was created
+		{{Partition: 1, Offset: 5}, {Partition: 1, Offset: 6}, {Partition: 2, Offset: 0}},
+	}}
+	endOffsets := map[int32]int64{0: 9, 1: 5}
+
+	var consumed []int64
+	err := consumeBounded(ctx, consumer, endOffsets, func(record *kgo.Record) bool {
+		consumed = append(consumed, int64(record.Partition)*100+record.Offset)
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{8, 103, 104, 105}, consumed)
+	assert.Empty(t, endOffsets)
+}
+
+func TestConsumeBounded_Stop(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	consumer := &mockFetchPoller{fetches: [][]*kgo.Record{{{Partition: 0, Offset: 0}, {Partition: 0, Offset: 1}}}}
+	consumed := 0
+	err := consumeBounded(ctx, consumer, map[int32]int64{0: 9}, func(record *kgo.Record) bool {
+		consumed++
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, consumed)
+
+	// A partition which never reaches its end offset is consumed until the context is done
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = consumeBounded(ctx, &mockFetchPoller{}, map[int32]int64{0: 9}, func(*kgo.Record) bool { return true })
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
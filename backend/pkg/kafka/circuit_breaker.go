@@ -0,0 +1,122 @@
+package kafka
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBrokerUnavailable is returned for requests that target a broker which has failed too many times in a row. Use
+// errors.Is() to check for it, as it's usually wrapped with more context.
+var ErrBrokerUnavailable = errors.New("broker is unavailable")
+
+// brokerCircuitBreaker keeps track of consecutive request failures per broker. Once a broker has exceeded the failure
+// threshold we stop sending requests to it for the configured cooldown, so that a single dead broker doesn't cause
+// long timeouts on every request until the client notices the broker is gone.
+type brokerCircuitBreaker struct {
+	failureThreshold int
+	cooldown         time.Duration
+	now              func() time.Time
+
+	mutex               sync.Mutex
+	failuresByBrokerID  map[int32]int
+	openUntilByBrokerID map[int32]time.Time
+
+	// coordinatorIDByGroup remembers the last known coordinator for each group, so that we can short circuit
+	// requests for groups that are coordinated by an unavailable broker before sending them.
+	coordinatorIDByGroup map[string]int32
+}
+
+func newBrokerCircuitBreaker(cfg CircuitBreakerConfig) *brokerCircuitBreaker {
+	return &brokerCircuitBreaker{
+		failureThreshold:     cfg.FailureThreshold,
+		cooldown:             cfg.Cooldown,
+		now:                  time.Now,
+		failuresByBrokerID:   make(map[int32]int),
+		openUntilByBrokerID:  make(map[int32]time.Time),
+		coordinatorIDByGroup: make(map[string]int32),
+	}
+}
+
+// IsOpen returns true if requests to the given broker shall not be sent.
+func (b *brokerCircuitBreaker) IsOpen(brokerID int32) bool {
+	if b.failureThreshold <= 0 {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	openUntil, exists := b.openUntilByBrokerID[brokerID]
+	if !exists {
+		return false
+	}
+	if b.now().Before(openUntil) {
+		return true
+	}
+
+	// Cooldown is over, next request can try again. A single further failure will open the breaker again.
+	delete(b.openUntilByBrokerID, brokerID)
+	b.failuresByBrokerID[brokerID] = b.failureThreshold - 1
+	return false
+}
+
+// RecordSuccess resets the failure counter for the given broker.
+func (b *brokerCircuitBreaker) RecordSuccess(brokerID int32) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.failuresByBrokerID, brokerID)
+	delete(b.openUntilByBrokerID, brokerID)
+}
+
+// RecordFailure increments the failure counter for the given broker. It returns true if this failure opened the
+// breaker.
+func (b *brokerCircuitBreaker) RecordFailure(brokerID int32) bool {
+	if b.failureThreshold <= 0 {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failuresByBrokerID[brokerID]++
+	if b.failuresByBrokerID[brokerID] < b.failureThreshold {
+		return false
+	}
+	if _, isOpen := b.openUntilByBrokerID[brokerID]; isOpen {
+		return false
+	}
+	b.openUntilByBrokerID[brokerID] = b.now().Add(b.cooldown)
+	return true
+}
+
+// SetGroupCoordinator remembers the coordinator ID for the given groups.
+func (b *brokerCircuitBreaker) SetGroupCoordinator(brokerID int32, groups []string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, group := range groups {
+		b.coordinatorIDByGroup[group] = brokerID
+	}
+}
+
+// SplitGroupsByAvailability returns the groups whose coordinator is not known to be unavailable and the groups
+// by the unavailable coordinator's ID.
+func (b *brokerCircuitBreaker) SplitGroupsByAvailability(groups []string) ([]string, map[int32][]string) {
+	available := make([]string, 0, len(groups))
+	unavailable := make(map[int32][]string)
+	for _, group := range groups {
+		b.mutex.Lock()
+		coordinatorID, exists := b.coordinatorIDByGroup[group]
+		b.mutex.Unlock()
+
+		if exists && b.IsOpen(coordinatorID) {
+			unavailable[coordinatorID] = append(unavailable[coordinatorID], group)
+			continue
+		}
+		available = append(available, group)
+	}
+
+	return available, unavailable
+}
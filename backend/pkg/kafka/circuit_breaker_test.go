@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrokerCircuitBreaker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	b := newBrokerCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, Cooldown: 10 * time.Second})
+	b.now = func() time.Time { return now }
+
+	assert.False(t, b.RecordFailure(1))
+	assert.False(t, b.RecordFailure(1))
+	assert.False(t, b.IsOpen(1), "expected breaker to be closed below the failure threshold")
+
+	assert.True(t, b.RecordFailure(1), "expected third consecutive failure to open the breaker")
+	assert.True(t, b.IsOpen(1))
+	assert.False(t, b.IsOpen(2), "expected other brokers to be unaffected")
+
+	// After the cooldown a single request is let through, another failure opens the breaker again
+	now = now.Add(11 * time.Second)
+	assert.False(t, b.IsOpen(1))
+	assert.True(t, b.RecordFailure(1))
+	assert.True(t, b.IsOpen(1))
+
+	b.RecordSuccess(1)
+	assert.False(t, b.IsOpen(1), "expected a success to close the breaker")
+}
+
+func TestBrokerCircuitBreaker_SplitGroupsByAvailability(t *testing.T) {
+	b := newBrokerCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	b.SetGroupCoordinator(1, []string{"group-a", "group-b"})
+	b.SetGroupCoordinator(2, []string{"group-c"})
+	b.RecordFailure(1)
+
+	available, unavailable := b.SplitGroupsByAvailability([]string{"group-a", "group-b", "group-c", "group-unknown"})
+	assert.Equal(t, []string{"group-c", "group-unknown"}, available)
+	assert.Equal(t, map[int32][]string{1: {"group-a", "group-b"}}, unavailable)
+}
+
+func TestBrokerCircuitBreaker_Disabled(t *testing.T) {
+	b := newBrokerCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 0})
+	for i := 0; i < 10; i++ {
+		assert.False(t, b.RecordFailure(1))
+	}
+	assert.False(t, b.IsOpen(1))
+}
@@ -0,0 +1,185 @@
+package kafka
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// maxClientIDPoolSize limits the number of additional clients which are created for request scoped client IDs
+const maxClientIDPoolSize = 16
+
+type clientIDContextKey struct{}
+
+// ContextWithClientID returns a context which makes all Kafka requests sent with it use the given client ID instead
+// of the configured one. Brokers log the client ID and use it for quotas, so that this allows to attribute requests
+// to e.g. a specific UI action.
+//
+// The client ID is set once per client in Kafka clients, hence requests with a custom client ID are sent by a pool of
+// additional clients, one per client ID. Each of these clients has its own broker connections, which is cheaper than
+// opening new connections for each request, but means that only a few distinct client IDs should be used at a time.
+// Once the pool is full, the least recently used client is closed to make room for an unknown client ID.
+// Consumers which are created for a single request (e.g. for listing messages) always use the given client ID.
+func ContextWithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDContextKey{}, clientID)
+}
+
+// ClientIDFromContext returns the client ID which has been set with ContextWithClientID
+func ClientIDFromContext(ctx context.Context) (string, bool) {
+	clientID, ok := ctx.Value(clientIDContextKey{}).(string)
+	return clientID, ok && clientID != ""
+}
+
+// clientIDOpts returns the kgo option to set the request scoped client ID, if there is one, for clients that are
+// created for a single request.
+func clientIDOpts(ctx context.Context) []kgo.Opt {
+	clientID, ok := ClientIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []kgo.Opt{kgo.ClientID(clientID)}
+}
+
+// closableKafkaClient is a KafkaClient which holds resources such as broker connections until it is closed
+type closableKafkaClient interface {
+	KafkaClient
+	Close()
+}
+
+// clientIDPool is a KafkaClient which sends requests with the default client, unless the request's context carries
+// a client ID. For each client ID a client is created on first use. Once the pool is full, the least recently used
+// client is evicted to make room for a new client ID. Evicted clients are closed as soon as no request is in flight
+// anymore, so that their broker connections are released. All pooled clients are closed with Close, the default
+// client is owned by the caller and never closed.
+type clientIDPool struct {
+	defaultClient KafkaClient
+	newClient     func(clientID string) (closableKafkaClient, error)
+	maxSize       int
+	logger        *zap.Logger
+
+	mutex sync.Mutex
+	// clients are the pooled clients by client ID, leastRecentlyUsed orders them by their last use (front is oldest)
+	clients           map[string]*pooledClient
+	leastRecentlyUsed *list.List
+	isClosed          bool
+}
+
+// pooledClient is a client of the pool along with the number of requests which are currently sent with it
+type pooledClient struct {
+	clientID  string
+	client    closableKafkaClient
+	element   *list.Element
+	inFlight  int
+	isEvicted bool
+}
+
+func newClientIDPool(defaultClient KafkaClient, newClient func(clientID string) (closableKafkaClient, error), maxSize int, logger *zap.Logger) *clientIDPool {
+	return &clientIDPool{
+		defaultClient:     defaultClient,
+		newClient:         newClient,
+		maxSize:           maxSize,
+		logger:            logger,
+		clients:           make(map[string]*pooledClient),
+		leastRecentlyUsed: list.New(),
+	}
+}
+
+// acquire returns the client for the context's client ID or the default client. The returned release func must be
+// called once the request has been sent, so that evicted clients can be closed.
+func (p *clientIDPool) acquire(ctx context.Context) (KafkaClient, func()) {
+	noop := func() {}
+	clientID, ok := ClientIDFromContext(ctx)
+	if !ok {
+		return p.defaultClient, noop
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.isClosed {
+		return p.defaultClient, noop
+	}
+	pooled, exists := p.clients[clientID]
+	if exists {
+		p.leastRecentlyUsed.MoveToBack(pooled.element)
+	} else {
+		client, err := p.newClient(clientID)
+		if err != nil {
+			p.logger.Warn("failed to create client for client id, sending request with default client",
+				zap.String("client_id", clientID),
+				zap.Error(err))
+			return p.defaultClient, noop
+		}
+		if len(p.clients) >= p.maxSize {
+			p.evict(p.leastRecentlyUsed.Front().Value.(*pooledClient))
+		}
+		pooled = &pooledClient{clientID: clientID, client: client}
+		pooled.element = p.leastRecentlyUsed.PushBack(pooled)
+		p.clients[clientID] = pooled
+	}
+
+	pooled.inFlight++
+	return pooled.client, func() { p.release(pooled) }
+}
+
+func (p *clientIDPool) release(pooled *pooledClient) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pooled.inFlight--
+	if pooled.isEvicted && pooled.inFlight == 0 {
+		pooled.client.Close()
+	}
+}
+
+// evict removes the client from the pool and closes it unless requests are in flight. The mutex must be held.
+func (p *clientIDPool) evict(pooled *pooledClient) {
+	p.logger.Debug("evicting least recently used client from client id pool", zap.String("client_id", pooled.clientID))
+	delete(p.clients, pooled.clientID)
+	p.leastRecentlyUsed.Remove(pooled.element)
+	pooled.isEvicted = true
+	if pooled.inFlight == 0 {
+		pooled.client.Close()
+	}
+}
+
+// Close closes all pooled clients, clients with requests in flight are closed once these are done. Requests with a
+// client ID are sent with the default client afterwards.
+func (p *clientIDPool) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.isClosed = true
+	for _, pooled := range p.clients {
+		p.evict(pooled)
+	}
+}
+
+func (p *clientIDPool) Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	client, release := p.acquire(ctx)
+	defer release()
+	return client.Request(ctx, req)
+}
+
+func (p *clientIDPool) RequestSharded(ctx context.Context, req kmsg.Request) []kgo.ResponseShard {
+	client, release := p.acquire(ctx)
+	defer release()
+	return client.RequestSharded(ctx, req)
+}
+
+func (p *clientIDPool) ForBroker(brokerID int32) kmsg.Requestor {
+	return &clientIDPoolBrokerRequestor{pool: p, brokerID: brokerID}
+}
+
+// clientIDPoolBrokerRequestor resolves the client when the request is sent, because only then the context is known
+type clientIDPoolBrokerRequestor struct {
+	pool     *clientIDPool
+	brokerID int32
+}
+
+func (r *clientIDPoolBrokerRequestor) Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	client, release := r.pool.acquire(ctx)
+	defer release()
+	return client.ForBroker(r.brokerID).Request(ctx, req)
+}
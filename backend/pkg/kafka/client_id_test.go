@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// clientIDMockClient responds to metadata requests with the given client ID as cluster ID, so that tests can tell
+// which client sent a request. Closing the client is recorded in closed.
+func clientIDMockClient(clientID string, closed map[string]bool) *closableMockKafkaClient {
+	return &closableMockKafkaClient{
+		mockKafkaClient: &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+			if _, ok := req.(*kmsg.MetadataRequest); !ok {
+				return nil, unexpectedRequestError(brokerID, req)
+			}
+			id := clientID
+			return &kmsg.MetadataResponse{ClusterID: &id}, nil
+		}},
+		close: func() { closed[clientID] = true },
+	}
+}
+
+type closableMockKafkaClient struct {
+	*mockKafkaClient
+	close func()
+}
+
+func (c *closableMockKafkaClient) Close() { c.close() }
+
+func TestClientIDPool(t *testing.T) {
+	created := make([]string, 0)
+	closed := make(map[string]bool)
+	pool := newClientIDPool(clientIDMockClient("default", closed), func(clientID string) (closableKafkaClient, error) {
+		if clientID == "broken" {
+			return nil, errors.New("failed to create client")
+		}
+		created = append(created, clientID)
+		return clientIDMockClient(clientID, closed), nil
+	}, 2, zap.NewNop())
+
+	sentBy := func(ctx context.Context) string {
+		res, err := kmsg.NewPtrMetadataRequest().RequestWith(ctx, pool)
+		require.NoError(t, err)
+		return *res.ClusterID
+	}
+
+	assert.Equal(t, "default", sentBy(context.Background()))
+	assert.Equal(t, "default", sentBy(ContextWithClientID(context.Background(), "")))
+	assert.Equal(t, "topic-list", sentBy(ContextWithClientID(context.Background(), "topic-list")))
+	assert.Equal(t, "topic-list", sentBy(ContextWithClientID(context.Background(), "topic-list")))
+	assert.Equal(t, "default", sentBy(ContextWithClientID(context.Background(), "broken")))
+	assert.Equal(t, "group-list", sentBy(ContextWithClientID(context.Background(), "group-list")))
+
+	assert.Equal(t, []string{"topic-list", "group-list"}, created)
+
+	// Broker requestors resolve the client when the request is sent
+	res, err := kmsg.NewPtrMetadataRequest().RequestWith(ContextWithClientID(context.Background(), "group-list"), pool.ForBroker(1))
+	require.NoError(t, err)
+	assert.Equal(t, "group-list", *res.ClusterID)
+
+	// The pool is full, the least recently used client is closed to make room for the new client ID
+	assert.Equal(t, "topic-list", sentBy(ContextWithClientID(context.Background(), "topic-list")))
+	assert.Equal(t, "message-search", sentBy(ContextWithClientID(context.Background(), "message-search")))
+	assert.Equal(t, map[string]bool{"group-list": true}, closed)
+	assert.Equal(t, "group-list", sentBy(ContextWithClientID(context.Background(), "group-list")))
+	assert.Equal(t, map[string]bool{"group-list": true, "topic-list": true}, closed)
+	assert.Equal(t, []string{"topic-list", "group-list", "message-search", "group-list"}, created)
+
+	// Closing the pool closes all pooled clients but never the default client
+	pool.Close()
+	assert.Equal(t, map[string]bool{"group-list": true, "topic-list": true, "message-search": true}, closed)
+	assert.Equal(t, "default", sentBy(ContextWithClientID(context.Background(), "topic-list")))
+}
+
+func TestClientIDPool_EvictInFlight(t *testing.T) {
+	closed := make(map[string]bool)
+	pool := newClientIDPool(clientIDMockClient("default", closed), func(clientID string) (closableKafkaClient, error) {
+		return clientIDMockClient(clientID, closed), nil
+	}, 1, zap.NewNop())
+
+	// A client which is evicted while a request is in flight is closed once the request is done
+	client, release := pool.acquire(ContextWithClientID(context.Background(), "topic-list"))
+	_, evictingRelease := pool.acquire(ContextWithClientID(context.Background(), "group-list"))
+	evictingRelease()
+	assert.Empty(t, closed)
+	res, err := kmsg.NewPtrMetadataRequest().RequestWith(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, "topic-list", *res.ClusterID)
+	release()
+	assert.Equal(t, map[string]bool{"topic-list": true}, closed)
+}
@@ -3,12 +3,20 @@ package kafka
 import (
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/cloudhut/kowl/backend/pkg/msgpack"
 	"github.com/cloudhut/kowl/backend/pkg/proto"
 	"github.com/cloudhut/kowl/backend/pkg/schema"
 )
 
+// Background metadata refresh intervals must be within these bounds. The client rejects intervals above an hour, shorter
+// intervals than a second would allow to flood the brokers with metadata requests, see NewKgoConfig.
+const (
+	minBackgroundMetadataRefreshInterval = time.Second
+	maxBackgroundMetadataRefreshInterval = time.Hour
+)
+
 // Config required for opening a connection to Kafka
 type Config struct {
 	// General
@@ -24,6 +32,25 @@ type Config struct {
 
 	TLS  TLSConfig  `yaml:"tls"`
 	SASL SASLConfig `yaml:"sasl"`
+
+	// Net configures the dial, read and write timeouts of the broker connections
+	Net NetConfig `yaml:"net"`
+
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuitBreaker"`
+
+	// TopicEncodings forces the deserialization of the listed topics' keys and values to a specific encoding
+	TopicEncodings []TopicEncodingConfig `yaml:"topicEncodings"`
+
+	// Redaction redacts fields and headers of all consumed messages
+	Redaction RedactionConfig `yaml:"redaction"`
+
+	// SlowDescribeThreshold is the duration after which describing consumer groups is logged as slow. Set to 0 to
+	// disable the log.
+	SlowDescribeThreshold time.Duration `yaml:"slowDescribeThreshold"`
+
+	// MetadataRefreshInterval is how often the client refreshes the cluster metadata in the background, in order to
+	// detect new topics, partitions and moved partition leaders
+	MetadataRefreshInterval time.Duration `yaml:"metadataRefreshInterval"`
 }
 
 // RegisterFlags registers all nested config flags.
@@ -40,6 +67,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("you must specify at least one broker to connect to")
 	}
 
+	if c.ClientID == "" {
+		return fmt.Errorf("client id must not be empty")
+	}
+
+	if c.MetadataRefreshInterval < minBackgroundMetadataRefreshInterval || c.MetadataRefreshInterval > maxBackgroundMetadataRefreshInterval {
+		return fmt.Errorf("metadata refresh interval must be between %v and %v", minBackgroundMetadataRefreshInterval, maxBackgroundMetadataRefreshInterval)
+	}
+
 	err := c.Schema.Validate()
 	if err != nil {
 		return err
@@ -55,11 +90,37 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("failed to validate sasl config: %w", err)
 	}
 
+	err = c.Net.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate net config: %w", err)
+	}
+
 	err = c.MessagePack.Validate()
 	if err != nil {
 		return fmt.Errorf("failed to validate msgpack config: %w", err)
 	}
 
+	err = c.CircuitBreaker.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate circuit breaker config: %w", err)
+	}
+
+	for i, topicEncoding := range c.TopicEncodings {
+		err = topicEncoding.Validate()
+		if err != nil {
+			return fmt.Errorf("failed to validate topic encoding at index '%v': %w", i, err)
+		}
+	}
+
+	err = c.Redaction.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate redaction config: %w", err)
+	}
+
+	if c.SlowDescribeThreshold < 0 {
+		return fmt.Errorf("slow describe threshold must not be negative")
+	}
+
 	return nil
 }
 
@@ -67,8 +128,12 @@ func (c *Config) Validate() error {
 func (c *Config) SetDefaults() {
 	c.ClientID = "kowl"
 	c.ClusterVersion = "1.0.0"
+	c.SlowDescribeThreshold = 10 * time.Second
+	c.MetadataRefreshInterval = 5 * time.Minute
 
 	c.SASL.SetDefaults()
+	c.Net.SetDefaults()
 	c.Protobuf.SetDefaults()
 	c.MessagePack.SetDefaults()
+	c.CircuitBreaker.SetDefaults()
 }
@@ -0,0 +1,33 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+)
+
+// CircuitBreakerConfig configures after how many consecutive failures we stop sending requests to a broker and
+// for how long.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed requests after which a broker is considered unavailable.
+	// Set to 0 to disable the circuit breaker.
+	FailureThreshold int           `yaml:"failureThreshold"`
+	Cooldown         time.Duration `yaml:"cooldown"`
+}
+
+// SetDefaults for the circuit breaker config
+func (c *CircuitBreakerConfig) SetDefaults() {
+	c.FailureThreshold = 3
+	c.Cooldown = 30 * time.Second
+}
+
+// Validate the circuit breaker config
+func (c *CircuitBreakerConfig) Validate() error {
+	if c.FailureThreshold < 0 {
+		return fmt.Errorf("failure threshold must not be negative")
+	}
+	if c.FailureThreshold > 0 && c.Cooldown <= 0 {
+		return fmt.Errorf("cooldown must be greater than 0 if the circuit breaker is enabled")
+	}
+
+	return nil
+}
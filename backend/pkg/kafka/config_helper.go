@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
@@ -28,13 +29,33 @@ func NewKgoConfig(cfg *Config, logger *zap.Logger, hooks kgo.Hook) ([]kgo.Opt, e
 		kgo.SeedBrokers(cfg.Brokers...),
 		kgo.MaxVersions(kversion.V2_6_0()),
 		kgo.ClientID(cfg.ClientID),
-		kgo.FetchMaxBytes(5 * 1000 * 1000), // 5MB
+		kgo.FetchMaxBytes(DefaultFetchMaxBytes),
 		kgo.AllowedConcurrentFetches(12),
 		// We keep control records because we need to consume them in order to know whether the last message in a
 		// a partition is worth waiting for or not (because it's a control record which we would never receive otherwise)
 		kgo.KeepControlRecords(),
 	}
 
+	// The client refreshes metadata at most every 10s by default, which would cap shorter refresh intervals. The
+	// minimum age also limits the refreshes which are triggered by failing requests, that's why the config validation
+	// doesn't allow intervals below a second.
+	if cfg.MetadataRefreshInterval > 0 {
+		opts = append(opts, kgo.MetadataMaxAge(cfg.MetadataRefreshInterval))
+		if cfg.MetadataRefreshInterval < 10*time.Second {
+			opts = append(opts, kgo.MetadataMinAge(cfg.MetadataRefreshInterval))
+		}
+	}
+
+	// Configure connection timeouts. The dialer is reused for TLS connections below.
+	netDialer := &net.Dialer{Timeout: 10 * time.Second}
+	if cfg.Net.DialTimeout > 0 {
+		netDialer.Timeout = cfg.Net.DialTimeout
+		opts = append(opts, kgo.Dialer(netDialer.DialContext))
+	}
+	if connTimeout := cfg.Net.connTimeout(); connTimeout > 0 {
+		opts = append(opts, kgo.ConnTimeoutOverhead(connTimeout))
+	}
+
 	// Create Logger
 	kgoLogger := KgoZapLogger{
 		logger: logger.With(zap.String("source", "kafka_client")).Sugar(),
@@ -57,6 +78,12 @@ func NewKgoConfig(cfg *Config, logger *zap.Logger, hooks kgo.Hook) ([]kgo.Opt, e
 				User: cfg.SASL.Username,
 				Pass: cfg.SASL.Password,
 			}.AsMechanism()
+			if provider := cfg.SASL.PlainCredentialsProvider; provider != nil {
+				mechanism = plain.Plain(func(context.Context) (plain.Auth, error) {
+					user, pass := provider()
+					return plain.Auth{User: user, Pass: pass}, nil
+				})
+			}
 			opts = append(opts, kgo.SASL(mechanism))
 		}
 
@@ -165,7 +192,7 @@ func NewKgoConfig(cfg *Config, logger *zap.Logger, hooks kgo.Hook) ([]kgo.Opt, e
 		}
 
 		tlsDialer := &tls.Dialer{
-			NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+			NetDialer: netDialer,
 			Config: &tls.Config{
 				InsecureSkipVerify: cfg.TLS.InsecureSkipTLSVerify,
 				Certificates:       certificates,
@@ -0,0 +1,256 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// readRequestClientID reads the first request sent on the connection and returns the client ID of its header
+func readRequestClientID(conn net.Conn) (string, error) {
+	// Size (4 bytes), api key (2), api version (2), correlation id (4), client id length (2)
+	header := make([]byte, 14)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	clientID := make([]byte, binary.BigEndian.Uint16(header[12:]))
+	if _, err := io.ReadFull(conn, clientID); err != nil {
+		return "", err
+	}
+	return string(clientID), nil
+}
+
+func TestNewKgoConfig_ClientIDAndMetadataRefreshInterval(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := Config{}
+	cfg.SetDefaults()
+	cfg.Brokers = []string{listener.Addr().String()}
+	cfg.ClientID = "kowl-test"
+	cfg.MetadataRefreshInterval = time.Second
+	require.NoError(t, cfg.Validate())
+
+	opts, err := NewKgoConfig(&cfg, zap.NewNop(), nil)
+	require.NoError(t, err)
+	client, err := kgo.NewClient(opts...)
+	require.NoError(t, err)
+	defer func() {
+		// Closing the listener first makes pending connection attempts fail fast, so that closing the client doesn't block
+		listener.Close()
+		client.Close()
+	}()
+
+	// No request is sent, so the client only connects because of the background metadata refresh
+	clientIDs := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		clientID, err := readRequestClientID(conn)
+		if err != nil {
+			return
+		}
+		clientIDs <- clientID
+	}()
+
+	select {
+	case clientID := <-clientIDs:
+		assert.Equal(t, "kowl-test", clientID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("client did not refresh the metadata in the background")
+	}
+}
+
+// readRequestFrame reads a whole request from the connection and returns its api key, version and correlation ID
+func readRequestFrame(conn net.Conn) (apiKey int16, version int16, correlationID int32, err error) {
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(conn, size); err != nil {
+		return 0, 0, 0, err
+	}
+	frame := make([]byte, binary.BigEndian.Uint32(size))
+	if _, err := io.ReadFull(conn, frame); err != nil {
+		return 0, 0, 0, err
+	}
+	return int16(binary.BigEndian.Uint16(frame)), int16(binary.BigEndian.Uint16(frame[2:])), int32(binary.BigEndian.Uint32(frame[4:])), nil
+}
+
+// writeResponseFrame writes the response with a non-flexible header, which is what ApiVersions and SASLHandshake use
+func writeResponseFrame(conn net.Conn, correlationID int32, res kmsg.Response) error {
+	body := res.AppendTo(nil)
+	frame := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(frame, uint32(4+len(body)))
+	binary.BigEndian.PutUint32(frame[4:], uint32(correlationID))
+	_, err := conn.Write(append(frame, body...))
+	return err
+}
+
+func TestNewKgoConfig_PlainCredentialsProvider(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	providedCredentials := make(chan struct{}, 1)
+	cfg := Config{}
+	cfg.SetDefaults()
+	cfg.Brokers = []string{listener.Addr().String()}
+	cfg.SASL.Enabled = true
+	cfg.SASL.Username = "static"
+	cfg.SASL.PlainCredentialsProvider = func() (string, string) {
+		select {
+		case providedCredentials <- struct{}{}:
+		default:
+		}
+		return "rotated", "s3cret"
+	}
+	require.NoError(t, cfg.Validate())
+
+	opts, err := NewKgoConfig(&cfg, zap.NewNop(), nil)
+	require.NoError(t, err)
+	client, err := kgo.NewClient(opts...)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		listener.Close()
+		client.Close()
+	}()
+
+	apiVersionsKey := kmsg.NewPtrApiVersionsRequest().Key()
+	saslHandshakeKey := kmsg.NewPtrSASLHandshakeRequest().Key()
+	saslAuthenticateKey := kmsg.NewPtrSASLAuthenticateRequest().Key()
+
+	// Fake broker which answers the requests that precede the SASL authentication
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			apiKey, version, correlationID, err := readRequestFrame(conn)
+			if err != nil {
+				return
+			}
+			var res kmsg.Response
+			switch apiKey {
+			case apiVersionsKey:
+				res = &kmsg.ApiVersionsResponse{Version: version, ApiKeys: []kmsg.ApiVersionsResponseApiKey{
+					{ApiKey: apiVersionsKey, MaxVersion: 3},
+					{ApiKey: saslHandshakeKey, MaxVersion: 1},
+					{ApiKey: saslAuthenticateKey, MaxVersion: 0},
+				}}
+			case saslHandshakeKey:
+				res = &kmsg.SASLHandshakeResponse{Version: version, SupportedMechanisms: []string{SASLMechanismPlain}}
+			default:
+				return
+			}
+			if err := writeResponseFrame(conn, correlationID, res); err != nil {
+				return
+			}
+		}
+	}()
+	go client.Request(ctx, kmsg.NewPtrMetadataRequest())
+
+	select {
+	case <-providedCredentials:
+	case <-time.After(5 * time.Second):
+		t.Fatal("credentials provider has not been called when connecting")
+	}
+}
+
+func TestNewKgoConfig_NetTimeouts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := Config{}
+	cfg.SetDefaults()
+	cfg.Brokers = []string{listener.Addr().String()}
+	cfg.Net.ReadTimeout = time.Second
+	cfg.Net.WriteTimeout = time.Second
+	require.NoError(t, cfg.Validate())
+
+	opts, err := NewKgoConfig(&cfg, zap.NewNop(), nil)
+	require.NoError(t, err)
+	client, err := kgo.NewClient(opts...)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		listener.Close()
+		client.Close()
+	}()
+
+	// Broker which never responds, the client must give up on the connection after the read timeout rather than
+	// the default of 20s
+	closedConnections := make(chan struct{}, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, _, err := readRequestFrame(conn); err != nil {
+				closedConnections <- struct{}{}
+				return
+			}
+		}
+	}()
+	go client.Request(ctx, kmsg.NewPtrMetadataRequest())
+
+	select {
+	case <-closedConnections:
+	case <-time.After(5 * time.Second):
+		t.Fatal("client did not time out the connection to the unresponsive broker")
+	}
+}
+
+func TestConfig_Validate_Net(t *testing.T) {
+	cfg := NetConfig{}
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, 20*time.Second, cfg.connTimeout())
+
+	cfg.WriteTimeout = 30 * time.Second
+	assert.Equal(t, 30*time.Second, cfg.connTimeout())
+
+	invalidConfigs := []NetConfig{
+		{DialTimeout: 0, ReadTimeout: time.Second, WriteTimeout: time.Second},
+		{DialTimeout: time.Second, ReadTimeout: 0, WriteTimeout: time.Second},
+		{DialTimeout: time.Second, ReadTimeout: time.Second, WriteTimeout: -time.Second},
+		{DialTimeout: time.Second, ReadTimeout: time.Hour, WriteTimeout: time.Second},
+	}
+	for _, invalidCfg := range invalidConfigs {
+		assert.Error(t, invalidCfg.Validate(), invalidCfg)
+	}
+}
+
+func TestConfig_Validate_ClientIdentity(t *testing.T) {
+	cfg := Config{}
+	cfg.SetDefaults()
+	cfg.Brokers = []string{"localhost:9092"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.ClientID = ""
+	assert.Error(t, cfg.Validate())
+
+	cfg.SetDefaults()
+	for _, interval := range []time.Duration{0, -time.Second, 100 * time.Millisecond, 2 * time.Hour} {
+		cfg.MetadataRefreshInterval = interval
+		assert.Error(t, cfg.Validate(), interval)
+	}
+}
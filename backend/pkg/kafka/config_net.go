@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+)
+
+// The Kafka client rejects connection timeouts outside of these bounds
+const (
+	minConnTimeout = time.Second
+	maxConnTimeout = 15 * time.Minute
+)
+
+// NetConfig configures the timeouts of the connections to the brokers, which helps on slow networks.
+//
+// These timeouts apply in addition to the deadline of the context which is passed to each request, whichever ends
+// first aborts the request. Connections are dialed with the context of the request that caused the dial, so a
+// shorter context deadline also cuts the dial short. The read and write timeouts bound each request on an already
+// established connection, so that a broker which stops responding doesn't hang requests whose context has no
+// deadline. For requests that tell the broker how long it may take (e.g. fetch requests and their max wait time),
+// that time is added to the read timeout.
+type NetConfig struct {
+	DialTimeout time.Duration `yaml:"dialTimeout"`
+
+	// ReadTimeout and WriteTimeout can not be configured independently by the client library, which uses the same
+	// timeout for reading and writing. The larger of both is used.
+	ReadTimeout  time.Duration `yaml:"readTimeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+}
+
+// SetDefaults for the net config, these match the client library's defaults
+func (c *NetConfig) SetDefaults() {
+	c.DialTimeout = 10 * time.Second
+	c.ReadTimeout = 20 * time.Second
+	c.WriteTimeout = 20 * time.Second
+}
+
+// Validate the net config
+func (c *NetConfig) Validate() error {
+	if c.DialTimeout <= 0 {
+		return fmt.Errorf("dial timeout must be greater than 0")
+	}
+	if c.ReadTimeout < minConnTimeout || c.ReadTimeout > maxConnTimeout {
+		return fmt.Errorf("read timeout must be between %v and %v", minConnTimeout, maxConnTimeout)
+	}
+	if c.WriteTimeout < minConnTimeout || c.WriteTimeout > maxConnTimeout {
+		return fmt.Errorf("write timeout must be between %v and %v", minConnTimeout, maxConnTimeout)
+	}
+
+	return nil
+}
+
+// connTimeout returns the timeout of requests on established connections, see ReadTimeout
+func (c *NetConfig) connTimeout() time.Duration {
+	if c.WriteTimeout > c.ReadTimeout {
+		return c.WriteTimeout
+	}
+	return c.ReadTimeout
+}
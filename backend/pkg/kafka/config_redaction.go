@@ -0,0 +1,22 @@
+package kafka
+
+// RedactionConfig configures the redaction of all consumed messages before they are returned, e.g. to hide personal
+// data in compliance-sensitive deployments. See JSONFieldRedactor for which payloads can be redacted.
+type RedactionConfig struct {
+	// JSONPaths select the fields of keys and values which are replaced by "[REDACTED]", e.g. $.customer.email
+	JSONPaths []string `yaml:"jsonPaths"`
+
+	// Headers are the keys of the headers whose values are replaced by "[REDACTED]"
+	Headers []string `yaml:"headers"`
+}
+
+// Validate the redaction config
+func (c *RedactionConfig) Validate() error {
+	_, err := NewJSONFieldRedactor(c.JSONPaths, c.Headers)
+	return err
+}
+
+// isEnabled returns true if anything shall be redacted
+func (c *RedactionConfig) isEnabled() bool {
+	return len(c.JSONPaths) > 0 || len(c.Headers) > 0
+}
@@ -20,6 +20,12 @@ type SASLConfig struct {
 	Password     string           `yaml:"password"`
 	Mechanism    string           `yaml:"mechanism"`
 	GSSAPIConfig SASLGSSAPIConfig `yaml:"gssapi"`
+
+	// PlainCredentialsProvider returns the SASL/PLAIN credentials and takes precedence over Username and Password.
+	// It's called whenever a new connection authenticates, so that rotated credentials take effect without
+	// restarting Kowl. Existing connections keep using the credentials they authenticated with until they reconnect.
+	// It can only be set programmatically, nil uses the static Username and Password.
+	PlainCredentialsProvider func() (user, pass string) `yaml:"-"`
 }
 
 // RegisterFlags for all sensitive Kafka SASL configs.
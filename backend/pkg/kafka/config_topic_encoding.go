@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"fmt"
+)
+
+// TopicEncodingConfig forces the encoding that is used to deserialize the keys and values of a topic, rather than
+// detecting it automatically. This is useful if the automatic detection guesses wrong. Leave an encoding empty to
+// keep detecting it automatically.
+type TopicEncodingConfig struct {
+	TopicName string `yaml:"topicName"`
+
+	// KeyEncoding and ValueEncoding must be one of json, xml, avro, protobuf, msgpack, text or binary
+	KeyEncoding   string `yaml:"keyEncoding"`
+	ValueEncoding string `yaml:"valueEncoding"`
+}
+
+// forcibleEncodings are all encodings which can be configured for a topic
+var forcibleEncodings = map[messageEncoding]struct{}{
+	messageEncodingJSON:     {},
+	messageEncodingXML:      {},
+	messageEncodingAvro:     {},
+	messageEncodingProtobuf: {},
+	messageEncodingMsgP:     {},
+	messageEncodingText:     {},
+	messageEncodingBinary:   {},
+}
+
+// Validate the topic encoding config
+func (c *TopicEncodingConfig) Validate() error {
+	if c.TopicName == "" {
+		return fmt.Errorf("topic name must be set")
+	}
+	for _, encoding := range []string{c.KeyEncoding, c.ValueEncoding} {
+		if encoding == "" {
+			continue
+		}
+		if _, exists := forcibleEncodings[messageEncoding(encoding)]; !exists {
+			return fmt.Errorf("encoding '%v' of topic '%v' is not supported", encoding, c.TopicName)
+		}
+	}
+
+	return nil
+}
+
+// topicEncodingsByName converts the configured topic encodings into a map by topic name
+func topicEncodingsByName(configs []TopicEncodingConfig) map[string]topicEncodings {
+	encodingsByTopic := make(map[string]topicEncodings, len(configs))
+	for _, cfg := range configs {
+		encodingsByTopic[cfg.TopicName] = topicEncodings{
+			Key:   messageEncoding(cfg.KeyEncoding),
+			Value: messageEncoding(cfg.ValueEncoding),
+		}
+	}
+	return encodingsByTopic
+}
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,9 +10,14 @@ import (
 	"github.com/cloudhut/kowl/backend/pkg/interpreter"
 	"github.com/dop251/goja"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
 	"go.uber.org/zap"
 )
 
+// ErrDecodeFailed is returned by FetchMessages if a message could not be deserialized and the consume request does
+// not continue on decode errors.
+var ErrDecodeFailed = errors.New("failed to deserialize message")
+
 // IListMessagesProgress specifies the methods 'ListMessages' will call on your progress-object.
 type IListMessagesProgress interface {
 	OnPhase(name string) // todo(?): eventually we might want to convert this into an enum
@@ -36,6 +42,25 @@ type TopicMessage struct {
 
 	IsValueNull bool `json:"isValueNull"` // true = tombstone
 
+	// KeyTruncated and ValueTruncated are true if the payload exceeded the requested max payload bytes. Truncated
+	// payloads are not deserialized, but returned as (partial) text or binary.
+	KeyTruncated   bool `json:"isKeyTruncated"`
+	ValueTruncated bool `json:"isValueTruncated"`
+
+	// KeySize and ValueSize are the number of raw bytes of the record's key and value, -1 if they are null.
+	KeySize   int `json:"keySize"`
+	ValueSize int `json:"valueSize"`
+
+	// LeaderEpoch is the epoch of the partition leader which wrote the record. PartitionLeaderEpoch is the epoch of the
+	// partition's leader when consuming started. Both are -1 if the record format or broker version does not provide
+	// them. They are only meant for diagnosing reads across a leader change, most users can ignore them.
+	LeaderEpoch          int32 `json:"leaderEpoch"`
+	PartitionLeaderEpoch int32 `json:"partitionLeaderEpoch"`
+
+	// DeserializeError is set if the key, the value or a header could not be deserialized. The affected payloads are
+	// returned as raw bytes, see the DeserializeError of each payload.
+	DeserializeError string `json:"deserializeError,omitempty"`
+
 	// Below properties are used for the internal communication via Go channels
 	IsMessageOk  bool   `json:"-"`
 	ErrorMessage string `json:"-"`
@@ -61,11 +86,73 @@ type PartitionConsumeRequest struct {
 	MaxMessageCount int64 // If either EndOffset or MaxMessageCount is reached the Consumer will stop.
 }
 
+// IsolationLevel controls whether transactional records that have not been committed (yet) are visible to the consumer.
+type IsolationLevel int8
+
+const (
+	// IsolationLevelReadUncommitted returns all records, including records of aborted and still open transactions.
+	IsolationLevelReadUncommitted IsolationLevel = 0
+	// IsolationLevelReadCommitted only returns records of committed transactions (and non-transactional records).
+	// Consuming stops at the last stable offset (LSO) rather than the high water mark.
+	IsolationLevelReadCommitted IsolationLevel = 1
+)
+
 type TopicConsumeRequest struct {
 	TopicName             string
 	MaxMessageCount       int
 	Partitions            map[int32]*PartitionConsumeRequest
 	FilterInterpreterCode string
+	IsolationLevel        IsolationLevel
+
+	// HeaderFilters only returns messages which have all the given headers. A message header matches if its raw
+	// value equals the filter's value, an empty value only requires the header to exist.
+	HeaderFilters map[string]string
+
+	// MaxPayloadBytes limits the size of returned keys and values, 0 means no limit.
+	MaxPayloadBytes int
+
+	// KeysOnly omits the values of all returned messages. See ListMessageRequest.KeysOnly.
+	KeysOnly bool
+
+	// FormatJSON adds an indented, key sorted JSON representation to keys and values which can be converted to JSON.
+	FormatJSON bool
+
+	// MessageBufferSize is the number of messages which are buffered if the progress receiver handles messages slower
+	// than they are consumed. 0 disables the buffer, so that a slow receiver blocks the consumer. Dropped messages
+	// still count towards MaxMessageCount.
+	MessageBufferSize int
+	// OverflowPolicy decides what happens if the message buffer is full. Defaults to OverflowPolicyBlock.
+	OverflowPolicy OverflowPolicy
+
+	// Follow keeps consuming once all partition requests have been satisfied. All messages after a partition's
+	// EndOffset or at and after its HighWaterMark are emitted as they arrive until the context is cancelled,
+	// regardless of the partition's MaxMessageCount and the MaxMessageCount of the whole request. Because the very
+	// same consumer continues, no message that is produced while catching up is missed, and neither are the messages
+	// between a bounded partition's EndOffset and its HighWaterMark.
+	Follow bool
+
+	// Fetch tunes the consumer's fetch requests, e.g. to trade latency for throughput when scanning whole topics
+	Fetch FetchOptions
+
+	// PartitionLeaderEpochs are the current leader epochs by partition ID as reported by the topic's metadata, see
+	// PartitionLeaderEpochs. They are only used for diagnostics, messages of missing partitions report -1.
+	PartitionLeaderEpochs map[int32]int32
+
+	// ContinueOnDecodeError returns messages that could not be deserialized with their raw payloads and the
+	// DeserializeError set. Otherwise consuming stops and an error is returned at the first such message. Defaults to
+	// true if nil.
+	ContinueOnDecodeError *bool
+
+	// SortByTimestamp emits the messages of all partitions in ascending timestamp order instead of the order in which
+	// they are consumed. Timestamps within a partition may be out of order by up to ReorderWindow. See
+	// timestampMerger for the exact ordering guarantees. Sorting is not supported when following the topic, because
+	// an idle partition would hold back all messages.
+	SortByTimestamp bool
+	ReorderWindow   time.Duration
+
+	// Transformers are applied in the given order to each consumed message, e.g. to redact personal data. They run
+	// after the transformers of the service, i.e. the configured redaction. See MessageTransformer.
+	Transformers []MessageTransformer
 }
 
 type interpreterArguments struct {
@@ -75,11 +162,31 @@ type interpreterArguments struct {
 	Key          interface{}
 	Value        interface{}
 	HeadersByKey map[string]interface{}
+
+	// Headers are the message's headers after all transformers have been applied, see recordHeaders
+	Headers []kgo.RecordHeader
+}
+
+// isFollowing returns true if the message at the given offset is emitted because the request follows the topic. This
+// is the case for all messages after the partition's end offset or at and after its high water mark, whichever
+// comes first.
+func (c *TopicConsumeRequest) isFollowing(partitionReq *PartitionConsumeRequest, offset int64) bool {
+	return c.Follow && (offset > partitionReq.EndOffset || offset >= partitionReq.HighWaterMark)
 }
 
 func (s *Service) FetchMessages(ctx context.Context, progress IListMessagesProgress, consumeRequest TopicConsumeRequest) error {
+	if consumeRequest.SortByTimestamp && consumeRequest.Follow {
+		return fmt.Errorf("messages can not be sorted by timestamp while following the topic")
+	}
+
 	// 1. Create new kgo client
-	client, err := s.NewKgoClient()
+	isolationLevel := kgo.ReadUncommitted()
+	if consumeRequest.IsolationLevel == IsolationLevelReadCommitted {
+		isolationLevel = kgo.ReadCommitted()
+	}
+	opts := append([]kgo.Opt{kgo.FetchIsolationLevel(isolationLevel)}, consumeRequest.Fetch.kgoOpts()...)
+	opts = append(opts, clientIDOpts(ctx)...)
+	client, err := s.NewKgoClient(opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create new kafka client: %w", err)
 	}
@@ -95,9 +202,15 @@ func (s *Service) FetchMessages(ctx context.Context, progress IListMessagesProgr
 	// If we use more than one worker the order of messages in each partition gets lost. Hence we only use it where
 	// multiple workers are actually beneficial - for potentially high throughput stream requests.
 	workerCount := 1
-	if consumeRequest.FilterInterpreterCode != "" {
+	if consumeRequest.FilterInterpreterCode != "" || len(consumeRequest.HeaderFilters) > 0 {
 		workerCount = 6
 	}
+	deserializeOpts := deserializeOptions{
+		MaxPayloadBytes: consumeRequest.MaxPayloadBytes,
+		KeysOnly:        consumeRequest.KeysOnly,
+		FormatJSON:      consumeRequest.FormatJSON,
+	}
+	transformers := s.transformersFor(consumeRequest.Transformers)
 	for i := 0; i < workerCount; i++ {
 		// Setup JavaScript interpreter
 		isMessageOK, err := s.setupInterpreter(consumeRequest.FilterInterpreterCode)
@@ -106,9 +219,10 @@ func (s *Service) FetchMessages(ctx context.Context, progress IListMessagesProgr
 			progress.OnError(fmt.Sprintf("failed to setup interpreter: %v", err.Error()))
 			return err
 		}
+		isMessageOK = withHeaderFilters(isMessageOK, consumeRequest.HeaderFilters)
 
 		wg.Add(1)
-		go s.startMessageWorker(workerCtx, &wg, isMessageOK, jobs, resultsCh)
+		go s.startMessageWorker(workerCtx, &wg, isMessageOK, deserializeOpts, consumeRequest.PartitionLeaderEpochs, transformers, jobs, resultsCh)
 	}
 	// Close the results channel once all workers have finished processing jobs and therefore no senders are left anymore
 	go func() {
@@ -122,6 +236,21 @@ func (s *Service) FetchMessages(ctx context.Context, progress IListMessagesProgr
 
 	// 4. Receive decoded messages until our request is satisfied. Once that's the case we will cancel the context
 	// that propagate to all the launched go routines.
+	onMessage := progress.OnMessage
+	if consumeRequest.MessageBufferSize > 0 {
+		buffer := newMessageBuffer(workerCtx, progress, consumeRequest.MessageBufferSize, consumeRequest.OverflowPolicy)
+		defer buffer.Close(ctx)
+		onMessage = func(msg *TopicMessage) { buffer.Push(workerCtx, msg) }
+	}
+	var merger *timestampMerger
+	if consumeRequest.SortByTimestamp {
+		partitionIDs := make([]int32, 0, len(consumeRequest.Partitions))
+		for partitionID := range consumeRequest.Partitions {
+			partitionIDs = append(partitionIDs, partitionID)
+		}
+		merger = newTimestampMerger(partitionIDs, consumeRequest.ReorderWindow, defaultTimestampMergeMaxBuffered, onMessage)
+		onMessage = merger.Push
+	}
 	messageCount := 0
 	messageCountByPartition := make(map[int32]int64)
 	remainingPartitionRequests := len(consumeRequest.Partitions)
@@ -129,27 +258,62 @@ func (s *Service) FetchMessages(ctx context.Context, progress IListMessagesProgr
 		// Since a 'kafka message' is likely transmitted in compressed batches this size is not really accurate
 		progress.OnMessageConsumed(msg.MessageSize)
 
+		if err := checkDeserializeError(msg, consumeRequest.continueOnDecodeError()); err != nil {
+			return err
+		}
+
 		partitionReq := consumeRequest.Partitions[msg.PartitionID]
+		if consumeRequest.isFollowing(partitionReq, msg.Offset) {
+			// The message is beyond the partition's requested range
+			if msg.IsMessageOk {
+				onMessage(msg)
+			}
+			continue
+		}
+		if merger != nil {
+			merger.Advance(msg.PartitionID, msg.Timestamp)
+		}
 		if msg.IsMessageOk && messageCountByPartition[msg.PartitionID] < partitionReq.MaxMessageCount {
 			messageCount++
 			messageCountByPartition[msg.PartitionID]++
-			progress.OnMessage(msg)
+			onMessage(msg)
 		}
 
 		if msg.Offset >= partitionReq.EndOffset {
 			remainingPartitionRequests--
 		}
+		if merger != nil && (msg.Offset >= partitionReq.EndOffset || messageCountByPartition[msg.PartitionID] >= partitionReq.MaxMessageCount) {
+			merger.FinishPartition(msg.PartitionID)
+		}
 
 		// Do we need more messages to satisfy the user request? Return if request is satisfied
 		isRequestSatisfied := messageCount == consumeRequest.MaxMessageCount || remainingPartitionRequests == 0
-		if isRequestSatisfied {
-			return nil
+		if isRequestSatisfied && !consumeRequest.Follow {
+			break
 		}
 	}
+	if merger != nil {
+		merger.Flush()
+	}
 
 	return nil
 }
 
+// checkDeserializeError returns an error if the message could not be deserialized, unless we continue on decode
+// errors. Control records are never deserialized and therefore never fail.
+// continueOnDecodeError returns whether messages that could not be deserialized shall be returned, which is the
+// default so that a single corrupt record doesn't prevent consuming the rest of the topic.
+func (c *TopicConsumeRequest) continueOnDecodeError() bool {
+	return c.ContinueOnDecodeError == nil || *c.ContinueOnDecodeError
+}
+
+func checkDeserializeError(msg *TopicMessage, continueOnDecodeError bool) error {
+	if continueOnDecodeError || msg.DeserializeError == "" {
+		return nil
+	}
+	return fmt.Errorf("%w at partition %v, offset %v: %v", ErrDecodeFailed, msg.PartitionID, msg.Offset, msg.DeserializeError)
+}
+
 func (s *Service) consumeKafkaMessages(ctx context.Context, client *kgo.Client, consumeReq TopicConsumeRequest, jobs chan<- *kgo.Record) {
 	defer close(jobs)
 	defer client.Close()
@@ -185,7 +349,7 @@ func (s *Service) consumeKafkaMessages(ctx context.Context, client *kgo.Client,
 				record := iter.Next()
 				partitionReq := consumeReq.Partitions[record.Partition]
 
-				if record.Offset > partitionReq.EndOffset {
+				if record.Offset > partitionReq.EndOffset && !consumeReq.isFollowing(partitionReq, record.Offset) {
 					// reached end offset within this partition, we strive to fulfil the consume request so that we achieve
 					// equal distribution across the partitions
 					continue
@@ -202,6 +366,25 @@ func (s *Service) consumeKafkaMessages(ctx context.Context, client *kgo.Client,
 	}
 }
 
+// PartitionLeaderEpochs returns the current leader epoch of each of the topic's partitions by partition ID, so that
+// metadata which has been fetched anyways can be passed on to TopicConsumeRequest.PartitionLeaderEpochs.
+func PartitionLeaderEpochs(topicMetadata kmsg.MetadataResponseTopic) map[int32]int32 {
+	epochs := make(map[int32]int32, len(topicMetadata.Partitions))
+	for _, partition := range topicMetadata.Partitions {
+		epochs[partition.Partition] = partition.LeaderEpoch
+	}
+	return epochs
+}
+
+// PartitionLeaderEpoch returns the current leader epoch of the given partition, -1 if it is not part of the metadata.
+func PartitionLeaderEpoch(topicMetadata kmsg.MetadataResponseTopic, partitionID int32) int32 {
+	epoch, exists := PartitionLeaderEpochs(topicMetadata)[partitionID]
+	if !exists {
+		return -1
+	}
+	return epoch
+}
+
 type isMessageOkFunc = func(args interpreterArguments) (bool, error)
 
 // SetupInterpreter initializes the JavaScript interpreter along with the given JS code. It returns a wrapper function
@@ -266,6 +449,47 @@ func (s *Service) setupInterpreter(interpreterCode string) (isMessageOkFunc, err
 	return isMessageOk, nil
 }
 
+// withHeaderFilters wraps the given filter func so that messages which do not match all header filters are filtered
+// before the (possibly expensive) interpreter code is run.
+func withHeaderFilters(isMessageOK isMessageOkFunc, headerFilters map[string]string) isMessageOkFunc {
+	if len(headerFilters) == 0 {
+		return isMessageOK
+	}
+
+	return func(args interpreterArguments) (bool, error) {
+		if !matchesHeaderFilters(args.Headers, headerFilters) {
+			return false, nil
+		}
+		return isMessageOK(args)
+	}
+}
+
+// matchesHeaderFilters returns true if all header filters match at least one of the given headers. A filter with an
+// empty value matches any header with that key.
+func matchesHeaderFilters(headers []kgo.RecordHeader, headerFilters map[string]string) bool {
+	for key, value := range headerFilters {
+		isMatch := false
+		for _, header := range headers {
+			if header.Key == key && (value == "" || string(header.Value) == value) {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+	return true
+}
+
+// payloadSize returns the number of bytes of the given payload or -1 if it is nil
+func payloadSize(payload []byte) int {
+	if payload == nil {
+		return -1
+	}
+	return len(payload)
+}
+
 func compressionTypeDisplayname(compressionType uint8) string {
 	switch compressionType {
 	case 0:
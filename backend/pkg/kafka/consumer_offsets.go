@@ -0,0 +1,324 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf16"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// ConsumerOffsetsTopic is Kafka's internal topic which stores the committed offsets and the metadata of all groups
+const ConsumerOffsetsTopic = "__consumer_offsets"
+
+// ConsumerOffsetsRecordType is the kind of record stored in the __consumer_offsets topic, which is derived from the
+// version of the record's key.
+type ConsumerOffsetsRecordType string
+
+const (
+	ConsumerOffsetsRecordTypeOffsetCommit  ConsumerOffsetsRecordType = "offsetCommit"
+	ConsumerOffsetsRecordTypeGroupMetadata ConsumerOffsetsRecordType = "groupMetadata"
+)
+
+// ConsumerOffsetsRecord is a decoded record of the __consumer_offsets topic. Either OffsetCommit or GroupMetadata is
+// set, depending on the type. Tombstones (committed offsets which have been expired or deleted, groups which have
+// been removed) have IsTombstone set and only contain the information of the record's key.
+type ConsumerOffsetsRecord struct {
+	PartitionID  int32                     `json:"partitionId"`
+	Offset       int64                     `json:"offset"`
+	Timestamp    int64                     `json:"timestamp"`
+	Type         ConsumerOffsetsRecordType `json:"type"`
+	KeyVersion   int16                     `json:"keyVersion"`
+	ValueVersion int16                     `json:"valueVersion"`
+	IsTombstone  bool                      `json:"isTombstone"`
+
+	OffsetCommit  *OffsetCommitRecord  `json:"offsetCommit,omitempty"`
+	GroupMetadata *GroupMetadataRecord `json:"groupMetadata,omitempty"`
+}
+
+// OffsetCommitRecord is a committed offset of a group for a single partition
+type OffsetCommitRecord struct {
+	Group     string `json:"group"`
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+
+	Offset int64 `json:"offset"`
+	// LeaderEpoch is -1 if not committed by the client or if the value version is < 3
+	LeaderEpoch     int32  `json:"leaderEpoch"`
+	Metadata        string `json:"metadata"`
+	CommitTimestamp int64  `json:"commitTimestamp"`
+	// ExpireTimestamp is only stored by value version 1, it's -1 otherwise
+	ExpireTimestamp int64 `json:"expireTimestamp"`
+}
+
+// GroupMetadataRecord is the state of a group after a rebalance has completed or the group became empty
+type GroupMetadataRecord struct {
+	Group        string  `json:"group"`
+	ProtocolType string  `json:"protocolType"`
+	Generation   int32   `json:"generation"`
+	Protocol     *string `json:"protocol"`
+	Leader       *string `json:"leader"`
+	// CurrentStateTimestamp is only stored by value version 2+, it's -1 otherwise
+	CurrentStateTimestamp int64                 `json:"currentStateTimestamp"`
+	Members               []GroupMetadataMember `json:"members"`
+}
+
+// GroupMetadataMember is a member of a group as stored in the group metadata. The assignment is only decoded for
+// groups using the consumer protocol.
+type GroupMetadataMember struct {
+	MemberID           string  `json:"memberId"`
+	InstanceID         *string `json:"instanceId"`
+	ClientID           string  `json:"clientId"`
+	ClientHost         string  `json:"clientHost"`
+	RebalanceTimeoutMs int32   `json:"rebalanceTimeoutMs"`
+	SessionTimeoutMs   int32   `json:"sessionTimeoutMs"`
+
+	Assignments []GroupMetadataMemberAssignment `json:"assignments"`
+}
+
+// GroupMetadataMemberAssignment are the partitions of a topic assigned to a group member
+type GroupMetadataMemberAssignment struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"`
+}
+
+// UnknownSchemaVersionError is returned if a __consumer_offsets record uses a key or value schema version which we
+// can not decode, e.g. because it has been introduced by a newer Kafka version.
+type UnknownSchemaVersionError struct {
+	Schema  string
+	Version int16
+}
+
+func (e *UnknownSchemaVersionError) Error() string {
+	return fmt.Sprintf("unknown %v schema version '%v'", e.Schema, e.Version)
+}
+
+// Highest known schema versions. Versions 0 and 1 of the key are offset commit keys, version 2 is a group metadata
+// key. Later versions of the values are flexible versions, which are not supported yet.
+const (
+	maxOffsetCommitKeyVersion    int16 = 1
+	groupMetadataKeyVersion      int16 = 2
+	maxOffsetCommitValueVersion  int16 = 3
+	maxGroupMetadataValueVersion int16 = 3
+)
+
+// ConsumeConsumerOffsets consumes and decodes the records of the __consumer_offsets topic from the oldest offset up to
+// the last stable offsets at the time of the request, until maxRecords have been consumed. If a group is given, only the
+// partition which stores the group's records is consumed and records of other groups are skipped. Records using a
+// schema version we do not know are skipped with a warning.
+func (s *Service) ConsumeConsumerOffsets(ctx context.Context, group string, maxRecords int) ([]ConsumerOffsetsRecord, error) {
+	if maxRecords <= 0 {
+		return nil, fmt.Errorf("max records must be greater than zero")
+	}
+
+	partitionIDs, err := s.ListPartitionIDs(ctx, ConsumerOffsetsTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	if group != "" {
+		partitionIDs = []int32{ConsumerOffsetsPartition(group, int32(len(partitionIDs)))}
+	}
+	// Commits of transactional producers are only visible once the transaction is committed
+	consumer, endOffsets, err := s.newBoundedConsumer(ctx, ConsumerOffsetsTopic, partitionIDs, IsolationLevelReadCommitted)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]ConsumerOffsetsRecord, 0)
+	if len(endOffsets) == 0 {
+		return records, nil
+	}
+	defer consumer.Close()
+
+	skipped := 0
+	err = consumeBounded(ctx, consumer, endOffsets, func(record *kgo.Record) bool {
+		if record.Attrs.IsControl() {
+			return true
+		}
+		decoded, err := decodeConsumerOffsetsRecord(record)
+		if err != nil {
+			skipped++
+			s.Logger.Warn("skipping consumer offsets record which can not be decoded",
+				zap.Int32("partition_id", record.Partition),
+				zap.Int64("offset", record.Offset),
+				zap.Error(err))
+			return true
+		}
+		if group == "" || decoded.groupID() == group {
+			records = append(records, decoded)
+		}
+		return len(records) < maxRecords
+	})
+	if err != nil {
+		return nil, err
+	}
+	if skipped > 0 {
+		s.Logger.Warn("skipped consumer offsets records which could not be decoded", zap.Int("skipped_records", skipped))
+	}
+
+	return records, nil
+}
+
+// groupID returns the group the record belongs to
+func (r *ConsumerOffsetsRecord) groupID() string {
+	if r.OffsetCommit != nil {
+		return r.OffsetCommit.Group
+	}
+	if r.GroupMetadata != nil {
+		return r.GroupMetadata.Group
+	}
+	return ""
+}
+
+// decodeConsumerOffsetsRecord decodes the key and the value of a __consumer_offsets record. The key's version decides
+// whether it's an offset commit or group metadata record. An *UnknownSchemaVersionError is returned for versions we
+// can't decode.
+func decodeConsumerOffsetsRecord(record *kgo.Record) (ConsumerOffsetsRecord, error) {
+	decoded := ConsumerOffsetsRecord{
+		PartitionID:  record.Partition,
+		Offset:       record.Offset,
+		Timestamp:    record.Timestamp.UnixNano() / int64(time.Millisecond),
+		ValueVersion: -1,
+		IsTombstone:  record.Value == nil,
+	}
+	if len(record.Key) < 2 {
+		return decoded, fmt.Errorf("record key is too short to contain a schema version")
+	}
+	decoded.KeyVersion = int16(binary.BigEndian.Uint16(record.Key))
+	if !decoded.IsTombstone {
+		if len(record.Value) < 2 {
+			return decoded, fmt.Errorf("record value is too short to contain a schema version")
+		}
+		decoded.ValueVersion = int16(binary.BigEndian.Uint16(record.Value))
+	}
+
+	switch {
+	case decoded.KeyVersion >= 0 && decoded.KeyVersion <= maxOffsetCommitKeyVersion:
+		decoded.Type = ConsumerOffsetsRecordTypeOffsetCommit
+		offsetCommit, err := decodeOffsetCommit(record.Key, record.Value)
+		if err != nil {
+			return decoded, err
+		}
+		decoded.OffsetCommit = offsetCommit
+	case decoded.KeyVersion == groupMetadataKeyVersion:
+		decoded.Type = ConsumerOffsetsRecordTypeGroupMetadata
+		groupMetadata, err := decodeGroupMetadata(record.Key, record.Value)
+		if err != nil {
+			return decoded, err
+		}
+		decoded.GroupMetadata = groupMetadata
+	default:
+		return decoded, &UnknownSchemaVersionError{Schema: "key", Version: decoded.KeyVersion}
+	}
+
+	return decoded, nil
+}
+
+func decodeOffsetCommit(key []byte, value []byte) (*OffsetCommitRecord, error) {
+	decodedKey := kmsg.NewOffsetCommitKey()
+	if err := decodedKey.ReadFrom(key); err != nil {
+		return nil, fmt.Errorf("failed to decode offset commit key: %w", err)
+	}
+	offsetCommit := &OffsetCommitRecord{
+		Group:           decodedKey.Group,
+		Topic:           decodedKey.Topic,
+		Partition:       decodedKey.Partition,
+		Offset:          -1,
+		LeaderEpoch:     -1,
+		CommitTimestamp: -1,
+		ExpireTimestamp: -1,
+	}
+	if value == nil {
+		return offsetCommit, nil
+	}
+
+	decodedValue := kmsg.NewOffsetCommitValue()
+	if version := int16(binary.BigEndian.Uint16(value)); version < 0 || version > maxOffsetCommitValueVersion {
+		return nil, &UnknownSchemaVersionError{Schema: "offset commit value", Version: version}
+	}
+	if err := decodedValue.ReadFrom(value); err != nil {
+		return nil, fmt.Errorf("failed to decode offset commit value: %w", err)
+	}
+	offsetCommit.Offset = decodedValue.Offset
+	offsetCommit.Metadata = decodedValue.Metadata
+	offsetCommit.CommitTimestamp = decodedValue.CommitTimestamp
+	if decodedValue.Version >= 3 {
+		offsetCommit.LeaderEpoch = decodedValue.LeaderEpoch
+	}
+	if decodedValue.Version == 1 {
+		offsetCommit.ExpireTimestamp = decodedValue.ExpireTimestamp
+	}
+
+	return offsetCommit, nil
+}
+
+func decodeGroupMetadata(key []byte, value []byte) (*GroupMetadataRecord, error) {
+	decodedKey := kmsg.NewGroupMetadataKey()
+	if err := decodedKey.ReadFrom(key); err != nil {
+		return nil, fmt.Errorf("failed to decode group metadata key: %w", err)
+	}
+	groupMetadata := &GroupMetadataRecord{Group: decodedKey.Group, CurrentStateTimestamp: -1}
+	if value == nil {
+		return groupMetadata, nil
+	}
+
+	decodedValue := kmsg.NewGroupMetadataValue()
+	if version := int16(binary.BigEndian.Uint16(value)); version < 0 || version > maxGroupMetadataValueVersion {
+		return nil, &UnknownSchemaVersionError{Schema: "group metadata value", Version: version}
+	}
+	if err := decodedValue.ReadFrom(value); err != nil {
+		return nil, fmt.Errorf("failed to decode group metadata value: %w", err)
+	}
+	groupMetadata.ProtocolType = decodedValue.ProtocolType
+	groupMetadata.Generation = decodedValue.Generation
+	groupMetadata.Protocol = decodedValue.Protocol
+	groupMetadata.Leader = decodedValue.Leader
+	if decodedValue.Version >= 2 {
+		groupMetadata.CurrentStateTimestamp = decodedValue.CurrentStateTimestamp
+	}
+
+	groupMetadata.Members = make([]GroupMetadataMember, len(decodedValue.Members))
+	for i, member := range decodedValue.Members {
+		assignments := make([]GroupMetadataMemberAssignment, 0)
+		if decodedValue.ProtocolType == "consumer" {
+			// Assignments which can not be decoded are not worth failing the whole record
+			if assignment, err := DecodeMemberAssignment(member.Assignment); err == nil {
+				for _, topic := range assignment.Topics {
+					assignments = append(assignments, GroupMetadataMemberAssignment{Topic: topic.Topic, Partitions: topic.Partitions})
+				}
+			}
+		}
+		groupMetadata.Members[i] = GroupMetadataMember{
+			MemberID:           member.MemberID,
+			InstanceID:         member.InstanceID,
+			ClientID:           member.ClientID,
+			ClientHost:         member.ClientHost,
+			RebalanceTimeoutMs: member.RebalanceTimeoutMillis,
+			SessionTimeoutMs:   member.SessionTimeoutMillis,
+			Assignments:        assignments,
+		}
+	}
+
+	return groupMetadata, nil
+}
+
+// ConsumerOffsetsPartition returns the partition of the __consumer_offsets topic which stores the offsets and metadata
+// of the given group. Kafka picks the partition by the Java hash code of the group ID.
+func ConsumerOffsetsPartition(group string, partitionCount int32) int32 {
+	var hash int32
+	for _, c := range utf16.Encode([]rune(group)) {
+		hash = 31*hash + int32(c)
+	}
+	// Like Kafka's Utils.abs(), which maps math.MinInt32 to 0
+	if hash == math.MinInt32 {
+		hash = 0
+	}
+	if hash < 0 {
+		hash = -hash
+	}
+	return hash % partitionCount
+}
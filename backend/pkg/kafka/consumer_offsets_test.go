@@ -0,0 +1,127 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestDecodeConsumerOffsetsRecord_OffsetCommit(t *testing.T) {
+	key := kmsg.OffsetCommitKey{Version: 1, Group: "billing", Topic: "orders", Partition: 3}
+
+	tests := []struct {
+		name  string
+		value kmsg.OffsetCommitValue
+		want  OffsetCommitRecord
+	}{
+		{
+			name:  "v0",
+			value: kmsg.OffsetCommitValue{Version: 0, Offset: 42, Metadata: "m", CommitTimestamp: 1000},
+			want:  OffsetCommitRecord{Group: "billing", Topic: "orders", Partition: 3, Offset: 42, LeaderEpoch: -1, Metadata: "m", CommitTimestamp: 1000, ExpireTimestamp: -1},
+		},
+		{
+			name:  "v1 with expire timestamp",
+			value: kmsg.OffsetCommitValue{Version: 1, Offset: 42, CommitTimestamp: 1000, ExpireTimestamp: 2000},
+			want:  OffsetCommitRecord{Group: "billing", Topic: "orders", Partition: 3, Offset: 42, LeaderEpoch: -1, CommitTimestamp: 1000, ExpireTimestamp: 2000},
+		},
+		{
+			name:  "v3 with leader epoch",
+			value: kmsg.OffsetCommitValue{Version: 3, Offset: 42, LeaderEpoch: 7, CommitTimestamp: 1000},
+			want:  OffsetCommitRecord{Group: "billing", Topic: "orders", Partition: 3, Offset: 42, LeaderEpoch: 7, CommitTimestamp: 1000, ExpireTimestamp: -1},
+		},
+	}
+
+	for _, tc := range tests {
+		record := &kgo.Record{Partition: 5, Offset: 100, Timestamp: time.Unix(1, 0), Key: key.AppendTo(nil), Value: tc.value.AppendTo(nil)}
+		decoded, err := decodeConsumerOffsetsRecord(record)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, ConsumerOffsetsRecordTypeOffsetCommit, decoded.Type, tc.name)
+		assert.Equal(t, int16(1), decoded.KeyVersion, tc.name)
+		assert.Equal(t, tc.value.Version, decoded.ValueVersion, tc.name)
+		assert.Equal(t, int64(1000), decoded.Timestamp, tc.name)
+		require.NotNil(t, decoded.OffsetCommit, tc.name)
+		assert.Equal(t, tc.want, *decoded.OffsetCommit, tc.name)
+	}
+}
+
+func TestDecodeConsumerOffsetsRecord_GroupMetadata(t *testing.T) {
+	assignment := kmsg.GroupMemberAssignment{Topics: []kmsg.GroupMemberAssignmentTopic{{Topic: "orders", Partitions: []int32{0, 1}}}}
+	protocol := "range"
+	leader := "consumer-1"
+	instanceID := "instance-1"
+	key := kmsg.GroupMetadataKey{Version: 2, Group: "billing"}
+	value := kmsg.GroupMetadataValue{
+		Version:               3,
+		ProtocolType:          "consumer",
+		Generation:            4,
+		Protocol:              &protocol,
+		Leader:                &leader,
+		CurrentStateTimestamp: 5000,
+		Members: []kmsg.GroupMetadataValueMember{{
+			MemberID:               "consumer-1",
+			InstanceID:             &instanceID,
+			ClientID:               "client",
+			ClientHost:             "/10.0.0.1",
+			RebalanceTimeoutMillis: 300000,
+			SessionTimeoutMillis:   10000,
+			Assignment:             assignment.AppendTo(nil),
+		}},
+	}
+
+	decoded, err := decodeConsumerOffsetsRecord(&kgo.Record{Key: key.AppendTo(nil), Value: value.AppendTo(nil)})
+	require.NoError(t, err)
+	assert.Equal(t, ConsumerOffsetsRecordTypeGroupMetadata, decoded.Type)
+	assert.Equal(t, &GroupMetadataRecord{
+		Group:                 "billing",
+		ProtocolType:          "consumer",
+		Generation:            4,
+		Protocol:              &protocol,
+		Leader:                &leader,
+		CurrentStateTimestamp: 5000,
+		Members: []GroupMetadataMember{{
+			MemberID:           "consumer-1",
+			InstanceID:         &instanceID,
+			ClientID:           "client",
+			ClientHost:         "/10.0.0.1",
+			RebalanceTimeoutMs: 300000,
+			SessionTimeoutMs:   10000,
+			Assignments:        []GroupMetadataMemberAssignment{{Topic: "orders", Partitions: []int32{0, 1}}},
+		}},
+	}, decoded.GroupMetadata)
+
+	// Group removal
+	decoded, err = decodeConsumerOffsetsRecord(&kgo.Record{Key: key.AppendTo(nil)})
+	require.NoError(t, err)
+	assert.True(t, decoded.IsTombstone)
+	assert.Equal(t, int16(-1), decoded.ValueVersion)
+	assert.Equal(t, "billing", decoded.GroupMetadata.Group)
+}
+
+func TestDecodeConsumerOffsetsRecord_UnknownVersions(t *testing.T) {
+	var unknownErr *UnknownSchemaVersionError
+
+	_, err := decodeConsumerOffsetsRecord(&kgo.Record{Key: []byte{0x00, 0x05, 0x00}})
+	require.True(t, errors.As(err, &unknownErr), "expected unknown schema version error, got: %v", err)
+	assert.Equal(t, "key", unknownErr.Schema)
+	assert.Equal(t, int16(5), unknownErr.Version)
+
+	key := kmsg.OffsetCommitKey{Version: 1, Group: "billing", Topic: "orders"}
+	value := kmsg.OffsetCommitValue{Version: 4, Offset: 1}
+	_, err = decodeConsumerOffsetsRecord(&kgo.Record{Key: key.AppendTo(nil), Value: value.AppendTo(nil)})
+	require.True(t, errors.As(err, &unknownErr), "expected unknown schema version error, got: %v", err)
+	assert.Equal(t, int16(4), unknownErr.Version)
+}
+
+func TestConsumerOffsetsPartition(t *testing.T) {
+	// Values as computed by Kafka: Utils.abs("...".hashCode()) % 50
+	assert.Equal(t, int32(0), ConsumerOffsetsPartition("", 50))
+	assert.Equal(t, int32(47), ConsumerOffsetsPartition("a", 50)) // "a".hashCode() = 97
+	assert.Equal(t, int32(12), ConsumerOffsetsPartition("test-group", 50))
+	assert.Equal(t, int32(5), ConsumerOffsetsPartition("billing-service-consumers", 50)) // Negative hash code
+	assert.Equal(t, int32(34), ConsumerOffsetsPartition("kowl-group-ä", 50))
+}
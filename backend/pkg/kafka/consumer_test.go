@@ -0,0 +1,168 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestMatchesHeaderFilters(t *testing.T) {
+	headers := []kgo.RecordHeader{
+		{Key: "traceId", Value: []byte("abc")},
+		{Key: "region", Value: []byte("eu-west")},
+		{Key: "region", Value: []byte("us-east")},
+		{Key: "retry", Value: nil},
+	}
+
+	tests := []struct {
+		name          string
+		headerFilters map[string]string
+		want          bool
+	}{
+		{name: "no filters", headerFilters: nil, want: true},
+		{name: "single header", headerFilters: map[string]string{"traceId": "abc"}, want: true},
+		{name: "single header other value", headerFilters: map[string]string{"traceId": "xyz"}, want: false},
+		{name: "single header missing", headerFilters: map[string]string{"tenant": "a"}, want: false},
+		{name: "header exists", headerFilters: map[string]string{"retry": ""}, want: true},
+		{name: "header does not exist", headerFilters: map[string]string{"tenant": ""}, want: false},
+		{name: "repeated header", headerFilters: map[string]string{"region": "us-east"}, want: true},
+		{name: "multiple headers", headerFilters: map[string]string{"traceId": "abc", "region": "eu-west", "retry": ""}, want: true},
+		{name: "multiple headers one mismatch", headerFilters: map[string]string{"traceId": "abc", "region": "ap-south"}, want: false},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, matchesHeaderFilters(headers, tc.headerFilters), tc.name)
+	}
+}
+
+func TestWithHeaderFilters(t *testing.T) {
+	interpreterCalls := 0
+	isMessageOK := withHeaderFilters(func(args interpreterArguments) (bool, error) {
+		interpreterCalls++
+		return args.Offset%2 == 0, nil
+	}, map[string]string{"traceId": "abc", "retry": ""})
+
+	matching := []kgo.RecordHeader{{Key: "traceId", Value: []byte("abc")}, {Key: "retry"}}
+	isOK, err := isMessageOK(interpreterArguments{Offset: 2, Headers: matching})
+	assert.NoError(t, err)
+	assert.True(t, isOK)
+
+	isOK, err = isMessageOK(interpreterArguments{Offset: 3, Headers: matching})
+	assert.NoError(t, err)
+	assert.False(t, isOK)
+
+	// The interpreter must not run for messages which do not match the header filters
+	isOK, err = isMessageOK(interpreterArguments{Offset: 2, Headers: matching[:1]})
+	assert.NoError(t, err)
+	assert.False(t, isOK)
+	assert.Equal(t, 2, interpreterCalls)
+}
+
+func TestStartMessageWorker_DeserializeError(t *testing.T) {
+	svc := &Service{
+		Logger:       zap.NewNop(),
+		Deserializer: deserializer{TopicEncodings: map[string]topicEncodings{"orders": {Value: messageEncodingJSON}}},
+	}
+	isMessageOK := func(args interpreterArguments) (bool, error) { return true, nil }
+
+	jobs := make(chan *kgo.Record, 2)
+	jobs <- &kgo.Record{Topic: "orders", Partition: 0, Offset: 1, Value: []byte(`{"id":1}`)}
+	jobs <- &kgo.Record{Topic: "orders", Partition: 0, Offset: 2, Value: []byte("not json")}
+	close(jobs)
+	resultsCh := make(chan *TopicMessage, 2)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	svc.startMessageWorker(context.Background(), &wg, isMessageOK, deserializeOptions{}, map[int32]int32{}, nil, jobs, resultsCh)
+	close(resultsCh)
+
+	valid := <-resultsCh
+	assert.Empty(t, valid.DeserializeError)
+	assert.NoError(t, checkDeserializeError(valid, false))
+
+	corrupt := <-resultsCh
+	require.NotNil(t, corrupt.Value)
+	assert.Contains(t, corrupt.DeserializeError, "value: ")
+	assert.Equal(t, messageEncodingBinary, corrupt.Value.RecognizedEncoding)
+	assert.Equal(t, []byte("not json"), corrupt.Value.Payload.Payload)
+	assert.True(t, corrupt.IsMessageOk)
+
+	// Continuing returns the message with its raw payload, otherwise consuming stops with an error
+	assert.NoError(t, checkDeserializeError(corrupt, true))
+	err := checkDeserializeError(corrupt, false)
+	assert.True(t, errors.Is(err, ErrDecodeFailed))
+	assert.Contains(t, err.Error(), "offset 2")
+
+	// Consume requests continue unless they opt out
+	assert.True(t, (&TopicConsumeRequest{}).continueOnDecodeError())
+	continueOnDecodeError := false
+	assert.False(t, (&TopicConsumeRequest{ContinueOnDecodeError: &continueOnDecodeError}).continueOnDecodeError())
+}
+
+func TestTopicConsumeRequest_isFollowing(t *testing.T) {
+	// The partition request ends before the high water mark, e.g. because of a round robin distribution
+	bounded := &PartitionConsumeRequest{PartitionID: 0, StartOffset: 0, EndOffset: 4, HighWaterMark: 10, MaxMessageCount: 5}
+	// Live tail requests have no end offset
+	newest := &PartitionConsumeRequest{PartitionID: 0, StartOffset: -1, EndOffset: math.MaxInt64, HighWaterMark: 10, MaxMessageCount: 5}
+
+	tests := []struct {
+		name         string
+		follow       bool
+		partitionReq *PartitionConsumeRequest
+		offset       int64
+		want         bool
+	}{
+		{name: "within the requested range", follow: true, partitionReq: bounded, offset: 4, want: false},
+		{name: "after the end offset", follow: true, partitionReq: bounded, offset: 5, want: true},
+		{name: "before the high water mark", follow: true, partitionReq: bounded, offset: 9, want: true},
+		{name: "at the high water mark", follow: true, partitionReq: bounded, offset: 10, want: true},
+		{name: "not following", follow: false, partitionReq: bounded, offset: 10, want: false},
+		{name: "live tail before the high water mark", follow: true, partitionReq: newest, offset: 9, want: false},
+		{name: "live tail at the high water mark", follow: true, partitionReq: newest, offset: 10, want: true},
+	}
+
+	for _, tc := range tests {
+		consumeReq := TopicConsumeRequest{Follow: tc.follow}
+		assert.Equal(t, tc.want, consumeReq.isFollowing(tc.partitionReq, tc.offset), tc.name)
+	}
+}
+
+func TestStartMessageWorker_LeaderEpochs(t *testing.T) {
+	svc := &Service{Logger: zap.NewNop(), Deserializer: deserializer{}}
+	isMessageOK := func(args interpreterArguments) (bool, error) { return true, nil }
+	topicMetadata := kmsg.MetadataResponseTopic{
+		Topic:      "orders",
+		Partitions: []kmsg.MetadataResponseTopicPartition{{Partition: 0, LeaderEpoch: 7}, {Partition: 1, LeaderEpoch: 2}},
+	}
+	assert.Equal(t, int32(7), PartitionLeaderEpoch(topicMetadata, 0))
+	assert.Equal(t, int32(-1), PartitionLeaderEpoch(topicMetadata, 5))
+
+	jobs := make(chan *kgo.Record, 2)
+	jobs <- &kgo.Record{Topic: "orders", Partition: 0, Offset: 1, LeaderEpoch: 5, Value: []byte("a")}
+	jobs <- &kgo.Record{Topic: "orders", Partition: 3, Offset: 1, LeaderEpoch: 1, Value: []byte("b")}
+	close(jobs)
+	resultsCh := make(chan *TopicMessage, 2)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	svc.startMessageWorker(context.Background(), &wg, isMessageOK, deserializeOptions{}, PartitionLeaderEpochs(topicMetadata), nil, jobs, resultsCh)
+	close(resultsCh)
+
+	// The record's epoch is the one of the leader which wrote it, the partition's epoch is the one of its current leader
+	msg := <-resultsCh
+	assert.Equal(t, int32(5), msg.LeaderEpoch)
+	assert.Equal(t, int32(7), msg.PartitionLeaderEpoch)
+
+	// Partitions which are not part of the metadata have no known leader epoch
+	msg = <-resultsCh
+	assert.Equal(t, int32(1), msg.LeaderEpoch)
+	assert.Equal(t, int32(-1), msg.PartitionLeaderEpoch)
+}
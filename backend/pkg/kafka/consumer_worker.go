@@ -9,10 +9,17 @@ import (
 	"time"
 )
 
-func (s *Service) startMessageWorker(ctx context.Context, wg *sync.WaitGroup, isMessageOK isMessageOkFunc, jobs <-chan *kgo.Record, resultsCh chan<- *TopicMessage) {
+func (s *Service) startMessageWorker(ctx context.Context, wg *sync.WaitGroup, isMessageOK isMessageOkFunc, deserializeOpts deserializeOptions, partitionLeaderEpochs map[int32]int32, transformers []MessageTransformer, jobs <-chan *kgo.Record, resultsCh chan<- *TopicMessage) {
 	defer wg.Done()
 
 	for record := range jobs {
+		keySize := payloadSize(record.Key)
+		valueSize := payloadSize(record.Value)
+		partitionLeaderEpoch, exists := partitionLeaderEpochs[record.Partition]
+		if !exists {
+			partitionLeaderEpoch = -1
+		}
+
 		// We consume control records because the last message in a partition we expect might be a control record.
 		// We need to acknowledge that we received the message but it is ineligible to be sent to the frontend.
 		// Quit early if it is a control record!
@@ -22,8 +29,13 @@ func (s *Service) startMessageWorker(ctx context.Context, wg *sync.WaitGroup, is
 				PartitionID: record.Partition,
 				Offset:      record.Offset,
 				Timestamp:   record.Timestamp.UnixNano() / int64(time.Millisecond),
+				KeySize:     keySize,
+				ValueSize:   valueSize,
 				IsMessageOk: false,
 				MessageSize: int64(len(record.Key) + len(record.Value)),
+
+				LeaderEpoch:          record.LeaderEpoch,
+				PartitionLeaderEpoch: partitionLeaderEpoch,
 			}
 
 			select {
@@ -35,49 +47,71 @@ func (s *Service) startMessageWorker(ctx context.Context, wg *sync.WaitGroup, is
 		}
 
 		// Run Interpreter filter and check if message passes the filter
-		deserializedRec := s.Deserializer.DeserializeRecord(record)
-
-		headersByKey := make(map[string]interface{}, len(deserializedRec.Headers))
-		headers := make([]MessageHeader, 0)
-		for key, header := range deserializedRec.Headers {
-			headersByKey[key] = header.Object
-			headers = append(headers, MessageHeader{
-				Key:   key,
-				Value: header,
-			})
+		deserializedRec := s.Deserializer.DeserializeRecord(record, deserializeOpts)
+
+		topicMessage := &TopicMessage{
+			PartitionID:     record.Partition,
+			Offset:          record.Offset,
+			Timestamp:       record.Timestamp.UnixNano() / int64(time.Millisecond),
+			Headers:         deserializedRec.Headers,
+			Compression:     compressionTypeDisplayname(record.Attrs.CompressionType()),
+			IsTransactional: record.Attrs.IsTransactional(),
+			Key:             deserializedRec.Key,
+			Value:           deserializedRec.Value,
+			IsValueNull:     record.Value == nil,
+			KeyTruncated:    deserializedRec.KeyTruncated,
+			ValueTruncated:  deserializedRec.ValueTruncated,
+			KeySize:         keySize,
+			ValueSize:       valueSize,
+			LeaderEpoch:     record.LeaderEpoch,
+			MessageSize:     int64(len(record.Key) + len(record.Value)),
+
+			PartitionLeaderEpoch: partitionLeaderEpoch,
+			DeserializeError:     deserializeErrorOf(deserializedRec),
+		}
+
+		// Transform the message before it's filtered, so that the filter can't reveal transformed data either
+		if err := applyMessageTransformers(topicMessage, transformers); err != nil {
+			s.Logger.Debug("failed to transform message", zap.Error(err))
+			dropTransformedMessage(topicMessage, err)
+			select {
+			case <-ctx.Done():
+				return
+			case resultsCh <- topicMessage:
+				continue
+			}
 		}
 
 		// Check if message passes filter code
+		headersByKey := make(map[string]interface{}, len(topicMessage.Headers))
+		for _, header := range topicMessage.Headers {
+			if header.Value != nil {
+				headersByKey[header.Key] = header.Value.Object
+			}
+		}
+		var key, value interface{}
+		if topicMessage.Key != nil {
+			key = topicMessage.Key.Object
+		}
+		if topicMessage.Value != nil {
+			value = topicMessage.Value.Object
+		}
 		args := interpreterArguments{
 			PartitionID:  record.Partition,
 			Offset:       record.Offset,
 			Timestamp:    record.Timestamp,
-			Key:          deserializedRec.Key.Object,
-			Value:        deserializedRec.Value.Object,
+			Key:          key,
+			Value:        value,
 			HeadersByKey: headersByKey,
+			Headers:      recordHeaders(topicMessage.Headers),
 		}
 
 		isOK, err := isMessageOK(args)
-		var errMessage string
 		if err != nil {
 			s.Logger.Debug("failed to check if message is ok", zap.Error(err))
-			errMessage = fmt.Sprintf("Failed to check if message is ok (partition: '%v', offset: '%v'). Error: %v", record.Partition, record.Offset, err)
-		}
-
-		topicMessage := &TopicMessage{
-			PartitionID:     record.Partition,
-			Offset:          record.Offset,
-			Timestamp:       record.Timestamp.UnixNano() / int64(time.Millisecond),
-			Headers:         headers,
-			Compression:     compressionTypeDisplayname(record.Attrs.CompressionType()),
-			IsTransactional: record.Attrs.IsTransactional(),
-			Key:             deserializedRec.Key,
-			Value:           deserializedRec.Value,
-			IsValueNull:     record.Value == nil,
-			IsMessageOk:     isOK,
-			ErrorMessage:    errMessage,
-			MessageSize:     int64(len(record.Key) + len(record.Value)),
+			topicMessage.ErrorMessage = fmt.Sprintf("Failed to check if message is ok (partition: '%v', offset: '%v'). Error: %v", record.Partition, record.Offset, err)
 		}
+		topicMessage.IsMessageOk = isOK
 
 		select {
 		case <-ctx.Done():
@@ -86,3 +120,36 @@ func (s *Service) startMessageWorker(ctx context.Context, wg *sync.WaitGroup, is
 		}
 	}
 }
+
+// deserializeErrorOf returns the first deserialize error of the record's key, value or headers, so that a single
+// message's error can be reported without inspecting each payload.
+func deserializeErrorOf(rec *deserializedRecord) string {
+	if rec.Key != nil && rec.Key.DeserializeError != "" {
+		return fmt.Sprintf("key: %v", rec.Key.DeserializeError)
+	}
+	if rec.Value != nil && rec.Value.DeserializeError != "" {
+		return fmt.Sprintf("value: %v", rec.Value.DeserializeError)
+	}
+
+	for _, header := range rec.Headers {
+		if header.Value != nil && header.Value.DeserializeError != "" {
+			return fmt.Sprintf("header '%v': %v", header.Key, header.Value.DeserializeError)
+		}
+	}
+
+	return ""
+}
+
+// recordHeaders returns the message's headers the way they are passed to the filter, so that header filters match
+// the transformed headers as they are returned rather than the record's headers. Header values are compared in
+// their normalized representation, which is the raw value for text and binary headers.
+func recordHeaders(headers []MessageHeader) []kgo.RecordHeader {
+	recordHeaders := make([]kgo.RecordHeader, len(headers))
+	for i, header := range headers {
+		recordHeaders[i] = kgo.RecordHeader{Key: header.Key}
+		if header.Value != nil {
+			recordHeaders[i].Value = header.Value.Payload.Payload
+		}
+	}
+	return recordHeaders
+}
@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// defaultCoordinatorCacheTTL is how long a resolved coordinator is reused. Coordinators only move if the partition
+// of the internal topic that stores the group or transaction moves, which is rare. Requests that fail because the
+// coordinator moved invalidate the cached coordinator anyways.
+const defaultCoordinatorCacheTTL = time.Minute
+
+type coordinatorCacheKey struct {
+	coordinatorType int8
+	key             string
+}
+
+type coordinatorCacheEntry struct {
+	coordinator kgo.BrokerMetadata
+	expiresAt   time.Time
+}
+
+// coordinatorCache caches the coordinators of groups and transactional IDs, so that describing the same groups
+// repeatedly does not send a FindCoordinator request for each group every time. Group requests which are not sent
+// to a specific broker (e.g. OffsetFetch) are routed by the Kafka client, which caches coordinators on its own.
+// A nil cache is valid and caches nothing.
+type coordinatorCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex   sync.RWMutex
+	entries map[coordinatorCacheKey]coordinatorCacheEntry
+}
+
+func newCoordinatorCache(ttl time.Duration) *coordinatorCache {
+	return &coordinatorCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[coordinatorCacheKey]coordinatorCacheEntry),
+	}
+}
+
+// Get returns the cached coordinator, unless it's unknown or expired
+func (c *coordinatorCache) Get(coordinatorType int8, key string) (kgo.BrokerMetadata, bool) {
+	if c == nil {
+		return kgo.BrokerMetadata{}, false
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.entries[coordinatorCacheKey{coordinatorType: coordinatorType, key: key}]
+	if !exists || !c.now().Before(entry.expiresAt) {
+		return kgo.BrokerMetadata{}, false
+	}
+	return entry.coordinator, true
+}
+
+// Set caches the coordinator for the key
+func (c *coordinatorCache) Set(coordinatorType int8, key string, coordinator kgo.BrokerMetadata) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[coordinatorCacheKey{coordinatorType: coordinatorType, key: key}] = coordinatorCacheEntry{
+		coordinator: coordinator,
+		expiresAt:   c.now().Add(c.ttl),
+	}
+	// Drop expired entries once in a while, so that deleted groups don't stay in the cache forever
+	if len(c.entries)%1000 == 0 {
+		c.removeExpired()
+	}
+}
+
+// Invalidate removes the cached coordinator of the keys, so that it's resolved again with the next request
+func (c *coordinatorCache) Invalidate(coordinatorType int8, keys ...string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, coordinatorCacheKey{coordinatorType: coordinatorType, key: key})
+	}
+}
+
+// removeExpired must be called with the mutex being held
+func (c *coordinatorCache) removeExpired() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// InvalidateGroupCoordinator forgets the cached coordinator of the group. Call this if a request that has been sent
+// to the group's coordinator failed with an error for which isCoordinatorMovedError returns true.
+func (s *Service) InvalidateGroupCoordinator(groupID string) {
+	s.coordinatorCache.Invalidate(coordinatorTypeGroup, groupID)
+}
+
+// isCoordinatorMovedError returns true if the error indicates that the request has been sent to a broker which is
+// not the coordinator (anymore), so that a cached coordinator must be resolved again.
+func isCoordinatorMovedError(err error) bool {
+	return errors.Is(err, kerr.NotCoordinator) || errors.Is(err, kerr.CoordinatorNotAvailable)
+}
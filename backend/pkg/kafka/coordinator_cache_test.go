@@ -0,0 +1,118 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestCoordinatorCache(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	cache := newCoordinatorCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Set(coordinatorTypeGroup, "group-a", kgo.BrokerMetadata{NodeID: 1})
+	coordinator, isCached := cache.Get(coordinatorTypeGroup, "group-a")
+	assert.True(t, isCached)
+	assert.Equal(t, int32(1), coordinator.NodeID)
+
+	// Groups and transactional IDs with the same name are different keys
+	_, isCached = cache.Get(coordinatorTypeTransaction, "group-a")
+	assert.False(t, isCached)
+
+	now = now.Add(time.Minute)
+	_, isCached = cache.Get(coordinatorTypeGroup, "group-a")
+	assert.False(t, isCached, "expired coordinators must not be returned")
+
+	cache.Set(coordinatorTypeGroup, "group-a", kgo.BrokerMetadata{NodeID: 2})
+	cache.Invalidate(coordinatorTypeGroup, "group-a")
+	_, isCached = cache.Get(coordinatorTypeGroup, "group-a")
+	assert.False(t, isCached)
+
+	var disabled *coordinatorCache
+	disabled.Set(coordinatorTypeGroup, "group-a", kgo.BrokerMetadata{NodeID: 1})
+	_, isCached = disabled.Get(coordinatorTypeGroup, "group-a")
+	assert.False(t, isCached)
+}
+
+func TestDescribeConsumerGroups_CoordinatorCache(t *testing.T) {
+	var findCoordinatorCalls int32
+	coordinatorID := int32(1)
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.FindCoordinatorRequest:
+			atomic.AddInt32(&findCoordinatorCalls, 1)
+			return &kmsg.FindCoordinatorResponse{NodeID: coordinatorID}, nil
+		case *kmsg.DescribeGroupsRequest:
+			res := &kmsg.DescribeGroupsResponse{}
+			for _, group := range typedReq.Groups {
+				describedGroup := kmsg.DescribeGroupsResponseGroup{Group: group, State: "Stable"}
+				if brokerID != coordinatorID {
+					describedGroup.ErrorCode = kerr.NotCoordinator.Code
+				}
+				res.Groups = append(res.Groups, describedGroup)
+			}
+			return res, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{
+		Logger:            zap.NewNop(),
+		KafkaClient:       client,
+		circuitBreaker:    newBrokerCircuitBreaker(CircuitBreakerConfig{}),
+		metadataRefresher: newMetadataRefresher(minMetadataRefreshInterval),
+		coordinatorCache:  newCoordinatorCache(time.Minute),
+	}
+	groups := []string{"group-a", "group-b"}
+
+	_, err := svc.DescribeConsumerGroups(context.Background(), groups)
+	require.NoError(t, err)
+	_, err = svc.DescribeConsumerGroups(context.Background(), groups)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&findCoordinatorCalls), "coordinators must be resolved once")
+
+	// Once the coordinator moved, the cached coordinator is invalidated and resolved again with the next request
+	coordinatorID = 2
+	_, err = svc.DescribeConsumerGroups(context.Background(), groups)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&findCoordinatorCalls))
+	res, err := svc.DescribeConsumerGroups(context.Background(), groups)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&findCoordinatorCalls))
+	assert.ElementsMatch(t, groups, res.GetGroupIDs())
+}
+
+func BenchmarkFindGroupCoordinators_Cache(b *testing.B) {
+	groups := make([]string, 1000)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("group-%d", i)
+	}
+
+	for _, isCached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached-%v", isCached), func(b *testing.B) {
+			var findCoordinatorCalls int64
+			client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+				atomic.AddInt64(&findCoordinatorCalls, 1)
+				return &kmsg.FindCoordinatorResponse{NodeID: int32(len(req.(*kmsg.FindCoordinatorRequest).CoordinatorKey) % 3)}, nil
+			}}
+			svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+			if isCached {
+				svc.coordinatorCache = newCoordinatorCache(time.Hour)
+			}
+
+			for i := 0; i < b.N; i++ {
+				svc.findGroupCoordinators(context.Background(), groups)
+			}
+			b.ReportMetric(float64(atomic.LoadInt64(&findCoordinatorCalls))/float64(b.N), "find-coordinator-calls/op")
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// copyTopicMaxBufferedRecords limits the number of copied records which are waiting to be produced, so that copying
+// a large topic does not hold the whole topic in memory.
+const copyTopicMaxBufferedRecords = 1000
+
+// CopyTopic creates the topic dst with the same number of partitions, replication factor and explicitly set topic
+// configs as src. Kafka does not support renaming topics, thus copying the topic and deleting the source topic is
+// the closest workaround.
+//
+// If includeData is true all records which exist when the copy starts are copied into the same partition of dst,
+// including their keys, values and headers. Offsets and timestamps are NOT preserved, the copied records get new
+// offsets and the time of the copy as timestamp. Hence consumer group offsets of src can not be applied to dst.
+// Only committed records are copied and a partition is copied up to its last stable offset, that is records from the
+// start of a transaction which is still open when the copy starts are not copied. Records are streamed from src to
+// dst, so that memory usage is bounded regardless of the topic size.
+func (s *Service) CopyTopic(ctx context.Context, src string, dst string, includeData bool) error {
+	// Reject invalid destination names before the source topic is inspected
+	if err := ValidateTopicName(dst); err != nil {
+		return err
+	}
+
+	srcMetadata, err := s.getTopicMetadata(ctx, src)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata of source topic: %w", err)
+	}
+	if len(srcMetadata.Partitions) == 0 {
+		return fmt.Errorf("source topic '%v' has no partitions", src)
+	}
+	replicationFactor := int16(len(srcMetadata.Partitions[0].Replicas))
+
+	configs, err := s.explicitTopicConfigs(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	err = s.CreateTopic(ctx, dst, int32(len(srcMetadata.Partitions)), replicationFactor, configs)
+	if err != nil {
+		return err
+	}
+	if !includeData {
+		return nil
+	}
+
+	partitionIDs := make([]int32, len(srcMetadata.Partitions))
+	for i, partition := range srcMetadata.Partitions {
+		partitionIDs[i] = partition.Partition
+	}
+
+	return s.copyTopicRecords(ctx, src, dst, partitionIDs)
+}
+
+// explicitTopicConfigs returns all configs which are explicitly set on the given topic. Sensitive configs can not
+// be copied, because their values are not returned by Kafka.
+func (s *Service) explicitTopicConfigs(ctx context.Context, topicName string) (map[string]*string, error) {
+	resources, err := s.DescribeTopicsConfigs(ctx, []string{topicName}, nil)
+	if err != nil {
+		return nil, err
+	}
+	resource := resources[topicName]
+	if err := kerr.ErrorForCode(resource.ErrorCode); err != nil {
+		return nil, fmt.Errorf("failed to describe configs of topic '%v': %w", topicName, err)
+	}
+
+	configs := make(map[string]*string)
+	for _, config := range resource.Configs {
+		if config.Source != kmsg.ConfigSourceDynamicTopicConfig {
+			continue
+		}
+		if config.IsSensitive {
+			s.Logger.Warn("skipping sensitive topic config while copying topic",
+				zap.String("topic_name", topicName),
+				zap.String("config_name", config.Name))
+			continue
+		}
+		configs[config.Name] = config.Value
+	}
+
+	return configs, nil
+}
+
+// copyTopicRecords consumes all committed records which exist in the given partitions of src and produces them into
+// the same partition of dst.
+func (s *Service) copyTopicRecords(ctx context.Context, src string, dst string, partitionIDs []int32) error {
+	consumer, endOffsets, err := s.newBoundedConsumer(ctx, src, partitionIDs, IsolationLevelReadCommitted)
+	if err != nil {
+		return fmt.Errorf("failed to consume source topic: %w", err)
+	}
+	if len(endOffsets) == 0 {
+		return nil
+	}
+	defer consumer.Close()
+
+	producer, err := s.NewKgoClient(append(clientIDOpts(ctx),
+		kgo.RecordPartitioner(recordPartitioner{}),
+		kgo.MaxBufferedRecords(copyTopicMaxBufferedRecords),
+	)...)
+	if err != nil {
+		return fmt.Errorf("failed to create producer: %w", err)
+	}
+	defer producer.Close()
+
+	return copyRecords(ctx, consumer, endOffsets, producer, dst)
+}
+
+// recordProducer produces records asynchronously, it is implemented by *kgo.Client.
+type recordProducer interface {
+	Produce(ctx context.Context, r *kgo.Record, promise func(*kgo.Record, error)) error
+	Flush(ctx context.Context) error
+}
+
+// copyRecords produces the consumed records up to the end offsets into the same partition of dst. Control records
+// mark the end of transactions and are not copied.
+func copyRecords(ctx context.Context, consumer fetchPoller, endOffsets map[int32]int64, producer recordProducer, dst string) error {
+	var produceErr error
+	produceErrMutex := sync.Mutex{}
+	promise := func(_ *kgo.Record, err error) {
+		if err == nil {
+			return
+		}
+		produceErrMutex.Lock()
+		defer produceErrMutex.Unlock()
+		if produceErr == nil {
+			produceErr = err
+		}
+	}
+	firstProduceErr := func() error {
+		produceErrMutex.Lock()
+		defer produceErrMutex.Unlock()
+		return produceErr
+	}
+
+	var copyErr error
+	err := consumeBounded(ctx, consumer, endOffsets, func(record *kgo.Record) bool {
+		if record.Attrs.IsControl() {
+			return true
+		}
+		copied := &kgo.Record{
+			Topic:     dst,
+			Partition: record.Partition,
+			Key:       record.Key,
+			Value:     record.Value,
+			Headers:   record.Headers,
+		}
+		if err := producer.Produce(ctx, copied, promise); err != nil {
+			copyErr = err
+			return false
+		}
+		copyErr = firstProduceErr()
+		return copyErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if copyErr != nil {
+		return fmt.Errorf("failed to produce record: %w", copyErr)
+	}
+
+	err = producer.Flush(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to flush produced records: %w", err)
+	}
+	if err := firstProduceErr(); err != nil {
+		return fmt.Errorf("failed to produce record: %w", err)
+	}
+
+	return nil
+}
+
+// recordPartitioner produces each record into the partition that is set in the record's Partition field.
+type recordPartitioner struct{}
+
+func (recordPartitioner) ForTopic(string) kgo.TopicPartitioner { return recordTopicPartitioner{} }
+
+type recordTopicPartitioner struct{}
+
+func (recordTopicPartitioner) OnNewBatch()                          {}
+func (recordTopicPartitioner) RequiresConsistency(*kgo.Record) bool { return true }
+func (recordTopicPartitioner) Partition(r *kgo.Record, _ int) int   { return int(r.Partition) }
@@ -0,0 +1,154 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestCreateTopic(t *testing.T) {
+	var createdTopic kmsg.CreateTopicsRequestTopic
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		createReq, ok := req.(*kmsg.CreateTopicsRequest)
+		if !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		createdTopic = createReq.Topics[0]
+		res := &kmsg.CreateTopicsResponse{Topics: []kmsg.CreateTopicsResponseTopic{{Topic: createdTopic.Topic}}}
+		if createdTopic.Topic == "existing" {
+			res.Topics[0].ErrorCode = kerr.TopicAlreadyExists.Code
+		}
+		return res, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	retention := "1000"
+	err := svc.CreateTopic(context.Background(), "orders", 6, 3, map[string]*string{"retention.ms": &retention})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), createdTopic.NumPartitions)
+	assert.Equal(t, int16(3), createdTopic.ReplicationFactor)
+	require.Len(t, createdTopic.Configs, 1)
+	assert.Equal(t, "retention.ms", createdTopic.Configs[0].Name)
+
+	err = svc.CreateTopic(context.Background(), "existing", 1, 1, nil)
+	assert.True(t, errors.Is(err, ErrTopicAlreadyExists))
+}
+
+func TestValidateTopicName(t *testing.T) {
+	tests := []struct {
+		name      string
+		topicName string
+		wantErr   string
+	}{
+		{name: "valid", topicName: "orders.v1_eu-west"},
+		{name: "max length", topicName: strings.Repeat("a", 249)},
+		{name: "empty", topicName: "", wantErr: "must not be empty"},
+		{name: "dot", topicName: ".", wantErr: "must not be '.' or '..'"},
+		{name: "double dot", topicName: "..", wantErr: "must not be '.' or '..'"},
+		{name: "too long", topicName: strings.Repeat("a", 250), wantErr: "must not be longer than 249 characters"},
+		{name: "whitespace", topicName: "my topic", wantErr: "illegal character ' '"},
+		{name: "slash", topicName: "orders/eu", wantErr: "illegal character '/'"},
+		{name: "non ascii", topicName: "bestellungen-ä", wantErr: "illegal character 'ä'"},
+	}
+
+	for _, tc := range tests {
+		err := ValidateTopicName(tc.topicName)
+		if tc.wantErr == "" {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrInvalidTopicName), tc.name)
+		assert.Contains(t, err.Error(), tc.wantErr, tc.name)
+	}
+
+	// Invalid names are rejected without sending a request
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		return nil, unexpectedRequestError(brokerID, req)
+	}}}
+	err := svc.CreateTopic(context.Background(), "..", 1, 1, nil)
+	assert.True(t, errors.Is(err, ErrInvalidTopicName))
+	err = svc.CopyTopic(context.Background(), "orders", "orders copy", false)
+	assert.True(t, errors.Is(err, ErrInvalidTopicName))
+}
+
+func TestExplicitTopicConfigs(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.DescribeConfigsRequest); !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		retention := "1000"
+		cleanupPolicy := "delete"
+		resource := kmsg.NewDescribeConfigsResponseResource()
+		resource.ResourceType = kmsg.ConfigResourceTypeTopic
+		resource.ResourceName = "orders"
+		resource.Configs = []kmsg.DescribeConfigsResponseResourceConfig{
+			{Name: "retention.ms", Value: &retention, Source: kmsg.ConfigSourceDynamicTopicConfig},
+			{Name: "cleanup.policy", Value: &cleanupPolicy, Source: kmsg.ConfigSourceDefaultConfig},
+			{Name: "secret", Value: nil, Source: kmsg.ConfigSourceDynamicTopicConfig, IsSensitive: true},
+		}
+		return &kmsg.DescribeConfigsResponse{Resources: []kmsg.DescribeConfigsResponseResource{resource}}, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	configs, err := svc.explicitTopicConfigs(context.Background(), "orders")
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "1000", *configs["retention.ms"])
+}
+
+// mockRecordProducer collects the produced records and fails records with the given value
+type mockRecordProducer struct {
+	produced  []*kgo.Record
+	failValue string
+}
+
+func (p *mockRecordProducer) Produce(_ context.Context, r *kgo.Record, promise func(*kgo.Record, error)) error {
+	if string(r.Value) == p.failValue {
+		promise(r, kerr.MessageTooLarge)
+		return nil
+	}
+	p.produced = append(p.produced, r)
+	promise(r, nil)
+	return nil
+}
+
+func (p *mockRecordProducer) Flush(context.Context) error { return nil }
+
+func TestCopyRecords(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	headers := []kgo.RecordHeader{{Key: "traceId", Value: []byte("abc")}}
+	consumer := &mockFetchPoller{fetches: [][]*kgo.Record{
+		{
+			{Partition: 0, Offset: 0, Key: []byte("a"), Value: []byte("1"), Headers: headers},
+			{Partition: 1, Offset: 7, Key: []byte("b"), Value: []byte("2")},
+		},
+		{
+			{Partition: 0, Offset: 2, Value: []byte("3")},
+			{Partition: 1, Offset: 8, Value: []byte("produced after the copy started")},
+		},
+	}}
+	producer := &mockRecordProducer{}
+
+	err := copyRecords(ctx, consumer, map[int32]int64{0: 2, 1: 7}, producer, "orders-copy")
+	require.NoError(t, err)
+	require.Len(t, producer.produced, 3)
+	assert.Equal(t, &kgo.Record{Topic: "orders-copy", Partition: 0, Key: []byte("a"), Value: []byte("1"), Headers: headers}, producer.produced[0])
+	assert.Equal(t, &kgo.Record{Topic: "orders-copy", Partition: 1, Key: []byte("b"), Value: []byte("2")}, producer.produced[1])
+	assert.Equal(t, &kgo.Record{Topic: "orders-copy", Partition: 0, Value: []byte("3")}, producer.produced[2])
+
+	// A failed record stops the copy
+	consumer = &mockFetchPoller{fetches: [][]*kgo.Record{{{Partition: 0, Offset: 0, Value: []byte("too large")}}}}
+	err = copyRecords(ctx, consumer, map[int32]int64{0: 5}, &mockRecordProducer{failValue: "too large"}, "orders-copy")
+	assert.True(t, errors.Is(err, kerr.MessageTooLarge))
+}
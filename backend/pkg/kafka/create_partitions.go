@@ -0,0 +1,40 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// CreatePartitions increases the number of partitions of the topic to the given total partition count. The replicas
+// of the new partitions are assigned by the brokers. Kafka does not support decreasing the partition count.
+func (s *Service) CreatePartitions(ctx context.Context, topicName string, partitionCount int32) error {
+	topicReq := kmsg.NewCreatePartitionsRequestTopic()
+	topicReq.Topic = topicName
+	topicReq.Count = partitionCount
+
+	req := kmsg.NewCreatePartitionsRequest()
+	req.Topics = []kmsg.CreatePartitionsRequestTopic{topicReq}
+	req.TimeoutMillis = 30 * 1000
+
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return fmt.Errorf("failed to request partition creation: %w", err)
+	}
+	if len(res.Topics) != 1 {
+		return fmt.Errorf("expected one topic in create partitions response, but got '%v'", len(res.Topics))
+	}
+
+	topicRes := res.Topics[0]
+	err = kerr.ErrorForCode(topicRes.ErrorCode)
+	if err != nil {
+		if topicRes.ErrorMessage != nil {
+			return fmt.Errorf("failed to create partitions: %w: %v", err, *topicRes.ErrorMessage)
+		}
+		return fmt.Errorf("failed to create partitions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// ErrTopicAlreadyExists is returned if a topic should be created, but a topic with that name exists already.
+var ErrTopicAlreadyExists = errors.New("topic already exists")
+
+// ErrInvalidTopicName is returned if a topic name does not comply with Kafka's naming rules.
+var ErrInvalidTopicName = errors.New("invalid topic name")
+
+// maxTopicNameLength is the maximum length of topic names which is enforced by Kafka
+const maxTopicNameLength = 249
+
+// ValidateTopicName checks the topic name against Kafka's naming rules, so that invalid names can be rejected
+// before a request is sent to the brokers. Topic names must not be empty, "." or "..", must not be longer than
+// 249 characters and may only contain ASCII alphanumerics, '.', '_' and '-'. The returned error wraps
+// ErrInvalidTopicName and names the violated rule.
+func ValidateTopicName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: topic name must not be empty", ErrInvalidTopicName)
+	}
+	if name == "." || name == ".." {
+		return fmt.Errorf("%w: topic name must not be '.' or '..'", ErrInvalidTopicName)
+	}
+	if len(name) > maxTopicNameLength {
+		return fmt.Errorf("%w: topic name is %v characters long, but must not be longer than %v characters",
+			ErrInvalidTopicName, len(name), maxTopicNameLength)
+	}
+	for _, c := range name {
+		isLegal := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '.' || c == '_' || c == '-'
+		if !isLegal {
+			return fmt.Errorf("%w: topic name '%v' contains the illegal character '%c', only ASCII alphanumerics, '.', '_' and '-' are allowed",
+				ErrInvalidTopicName, name, c)
+		}
+	}
+	return nil
+}
+
+// CreateTopic creates a topic with the given number of partitions, replication factor and topic configs. If the
+// topic exists already the returned error wraps ErrTopicAlreadyExists, an invalid topic name wraps
+// ErrInvalidTopicName.
+func (s *Service) CreateTopic(ctx context.Context, topicName string, partitionCount int32, replicationFactor int16, configs map[string]*string) error {
+	if err := ValidateTopicName(topicName); err != nil {
+		return err
+	}
+
+	topicReq := kmsg.NewCreateTopicsRequestTopic()
+	topicReq.Topic = topicName
+	topicReq.NumPartitions = partitionCount
+	topicReq.ReplicationFactor = replicationFactor
+	for name, value := range configs {
+		config := kmsg.NewCreateTopicsRequestTopicConfig()
+		config.Name = name
+		config.Value = value
+		topicReq.Configs = append(topicReq.Configs, config)
+	}
+
+	req := kmsg.NewCreateTopicsRequest()
+	req.Topics = []kmsg.CreateTopicsRequestTopic{topicReq}
+	req.TimeoutMillis = 30 * 1000
+
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return fmt.Errorf("failed to request topic creation: %w", err)
+	}
+	if len(res.Topics) != 1 {
+		return fmt.Errorf("expected one topic in create topics response, but got '%v'", len(res.Topics))
+	}
+
+	topicRes := res.Topics[0]
+	err = kerr.ErrorForCode(topicRes.ErrorCode)
+	if err == kerr.TopicAlreadyExists {
+		return fmt.Errorf("%w: %v", ErrTopicAlreadyExists, topicName)
+	}
+	if err != nil {
+		if topicRes.ErrorMessage != nil {
+			return fmt.Errorf("failed to create topic: %w: %v", err, *topicRes.ErrorMessage)
+		}
+		return fmt.Errorf("failed to create topic: %w", err)
+	}
+
+	return nil
+}
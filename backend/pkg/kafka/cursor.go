@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// ErrInvalidCursor is returned if a cursor token can not be decoded, either because it has been modified or because
+// it belongs to a different topic. Use errors.Is() to check for it.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const cursorVersion = 1
+
+// Cursor is the position of a paginated browse request in a topic. It contains the next offset to consume for each
+// partition, so that the next page continues where the previous one ended.
+type Cursor struct {
+	TopicName   string
+	NextOffsets map[int32]int64
+}
+
+type cursorPayload struct {
+	Version     int             `json:"v"`
+	TopicName   string          `json:"t"`
+	NextOffsets map[int32]int64 `json:"o"`
+}
+
+// EncodeCursor serializes the cursor into an opaque, URL safe token. The token contains a checksum so that
+// modified tokens are rejected by DecodeCursor. The checksum does not protect against deliberately crafted tokens,
+// which is fine as these can only contain offsets the user could request directly anyways.
+func EncodeCursor(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursorPayload{
+		Version:     cursorVersion,
+		TopicName:   cursor.TopicName,
+		NextOffsets: cursor.NextOffsets,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize cursor: %w", err)
+	}
+
+	token := make([]byte, 4, 4+len(payload))
+	binary.BigEndian.PutUint32(token, crc32.ChecksumIEEE(payload))
+	token = append(token, payload...)
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// DecodeCursor parses a token which has been created by EncodeCursor. An error wrapping ErrInvalidCursor is returned
+// if the token is malformed, has been modified or does not belong to the given topic.
+func DecodeCursor(token string, topicName string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: failed to decode token: %v", ErrInvalidCursor, err)
+	}
+	if len(raw) < 4 {
+		return Cursor{}, fmt.Errorf("%w: token is too short", ErrInvalidCursor)
+	}
+
+	checksum, payload := binary.BigEndian.Uint32(raw[:4]), raw[4:]
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return Cursor{}, fmt.Errorf("%w: checksum mismatch", ErrInvalidCursor)
+	}
+
+	var decoded cursorPayload
+	err = json.Unmarshal(payload, &decoded)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: failed to deserialize token: %v", ErrInvalidCursor, err)
+	}
+	if decoded.Version != cursorVersion {
+		return Cursor{}, fmt.Errorf("%w: unsupported version '%v'", ErrInvalidCursor, decoded.Version)
+	}
+	if decoded.TopicName != topicName {
+		return Cursor{}, fmt.Errorf("%w: token belongs to topic '%v' rather than '%v'", ErrInvalidCursor, decoded.TopicName, topicName)
+	}
+	for partitionID, offset := range decoded.NextOffsets {
+		if offset < 0 {
+			return Cursor{}, fmt.Errorf("%w: negative offset '%v' for partition '%v'", ErrInvalidCursor, offset, partitionID)
+		}
+	}
+
+	return Cursor{
+		TopicName:   decoded.TopicName,
+		NextOffsets: decoded.NextOffsets,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{
+		TopicName:   "orders",
+		NextOffsets: map[int32]int64{0: 15, 1: 0, 7: 123456789},
+	}
+
+	token, err := EncodeCursor(cursor)
+	require.NoError(t, err)
+
+	decoded, err := DecodeCursor(token, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	token, err := EncodeCursor(Cursor{TopicName: "orders", NextOffsets: map[int32]int64{0: 15}})
+	require.NoError(t, err)
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	raw[len(raw)-3]++
+	tampered := base64.RawURLEncoding.EncodeToString(raw)
+
+	negativeOffset, err := EncodeCursor(Cursor{TopicName: "orders", NextOffsets: map[int32]int64{0: -1}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		token     string
+		topicName string
+	}{
+		{name: "other topic", token: token, topicName: "payments"},
+		{name: "tampered", token: tampered, topicName: "orders"},
+		{name: "no base64", token: "not a cursor!", topicName: "orders"},
+		{name: "too short", token: "AAA", topicName: "orders"},
+		{name: "empty", token: "", topicName: "orders"},
+		{name: "negative offset", token: negativeOffset, topicName: "orders"},
+	}
+
+	for _, tc := range tests {
+		_, err := DecodeCursor(tc.token, tc.topicName)
+		assert.True(t, errors.Is(err, ErrInvalidCursor), "%v: expected invalid cursor error, got: %v", tc.name, err)
+	}
+}
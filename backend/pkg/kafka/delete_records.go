@@ -0,0 +1,209 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// DeleteRecordsOffsetHighWaterMark can be passed as offset to DeleteRecords in order to delete all records of a
+// partition, that is to advance the log start offset to the partition's high water mark.
+const DeleteRecordsOffsetHighWaterMark int64 = -1
+
+// DeleteRecordsPartitionResult is the result of deleting the records of a single partition.
+type DeleteRecordsPartitionResult struct {
+	PartitionID int32 `json:"partitionId"`
+
+	// LowWaterMark is the partition's new log start offset
+	LowWaterMark int64 `json:"lowWaterMark"`
+
+	// Error is set if the records of this partition could not be deleted
+	Error error `json:"-"`
+}
+
+// DeleteRecords deletes all records before the given offset of each partition by advancing the partitions' log start
+// offset. Use DeleteRecordsOffsetHighWaterMark to delete all records of a partition. All offsets are validated
+// before any records are deleted: an error wrapping ErrPartitionNotFound or ErrInvalidOffset is returned for
+// unknown partitions or offsets above the high water mark. The results are sorted by partition ID. Partitions whose
+// records could not be deleted have their Error set, an error is only returned if this is the case for all of them.
+func (s *Service) DeleteRecords(ctx context.Context, topicName string, beforeOffset map[int32]int64) ([]DeleteRecordsPartitionResult, error) {
+	if len(beforeOffset) == 0 {
+		return []DeleteRecordsPartitionResult{}, nil
+	}
+
+	partitionIDs := make([]int32, 0, len(beforeOffset))
+	for partitionID := range beforeOffset {
+		partitionIDs = append(partitionIDs, partitionID)
+	}
+	sort.Slice(partitionIDs, func(i, j int) bool { return partitionIDs[i] < partitionIDs[j] })
+
+	existingPartitionIDs, err := s.ListPartitionIDs(ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[int32]bool, len(existingPartitionIDs))
+	for _, partitionID := range existingPartitionIDs {
+		exists[partitionID] = true
+	}
+	for _, partitionID := range partitionIDs {
+		if !exists[partitionID] {
+			return nil, fmt.Errorf("%w: topic '%v' has no partition '%v'", ErrPartitionNotFound, topicName, partitionID)
+		}
+	}
+
+	marks, err := s.GetPartitionMarks(ctx, topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition watermarks: %w", err)
+	}
+	err = validateDeleteRecordsOffsets(beforeOffset, marks)
+	if err != nil {
+		return nil, err
+	}
+
+	partitionReqs := make([]kmsg.DeleteRecordsRequestTopicPartition, len(partitionIDs))
+	for i, partitionID := range partitionIDs {
+		partitionReq := kmsg.NewDeleteRecordsRequestTopicPartition()
+		partitionReq.Partition = partitionID
+		partitionReq.Offset = beforeOffset[partitionID]
+		partitionReqs[i] = partitionReq
+	}
+	topicReq := kmsg.NewDeleteRecordsRequestTopic()
+	topicReq.Topic = topicName
+	topicReq.Partitions = partitionReqs
+	req := kmsg.NewDeleteRecordsRequest()
+	req.Topics = []kmsg.DeleteRecordsRequestTopic{topicReq}
+	req.TimeoutMillis = 30 * 1000
+
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request deletion of records: %w", err)
+	}
+
+	resultByPartitionID := make(map[int32]DeleteRecordsPartitionResult, len(partitionIDs))
+	for _, topic := range res.Topics {
+		for _, partition := range topic.Partitions {
+			resultByPartitionID[partition.Partition] = DeleteRecordsPartitionResult{
+				PartitionID:  partition.Partition,
+				LowWaterMark: partition.LowWatermark,
+				Error:        kerr.ErrorForCode(partition.ErrorCode),
+			}
+		}
+	}
+
+	results := make([]DeleteRecordsPartitionResult, len(partitionIDs))
+	errs := make([]error, len(partitionIDs))
+	for i, partitionID := range partitionIDs {
+		result, exists := resultByPartitionID[partitionID]
+		if !exists {
+			result = DeleteRecordsPartitionResult{
+				PartitionID:  partitionID,
+				LowWaterMark: -1,
+				Error:        fmt.Errorf("partition is missing in the delete records response"),
+			}
+		}
+		results[i] = result
+		errs[i] = result.Error
+	}
+	if err := lastErrorIfAllFailed(errs); err != nil {
+		return results, fmt.Errorf("failed to delete records of all '%v' partitions, last error: %w", len(errs), err)
+	}
+
+	return results, nil
+}
+
+// validateDeleteRecordsOffsets returns an error if an offset is neither DeleteRecordsOffsetHighWaterMark nor between
+// 0 and the partition's high water mark.
+func validateDeleteRecordsOffsets(beforeOffset map[int32]int64, marks map[int32]*PartitionMarks) error {
+	for partitionID, offset := range beforeOffset {
+		if offset == DeleteRecordsOffsetHighWaterMark {
+			continue
+		}
+		mark, exists := marks[partitionID]
+		if !exists {
+			return fmt.Errorf("no watermarks for partition '%v' available", partitionID)
+		}
+		if mark.Error != "" {
+			return fmt.Errorf("failed to get watermarks of partition '%v': %v", partitionID, mark.Error)
+		}
+		if offset < 0 || offset > mark.High {
+			return fmt.Errorf("%w: offset '%v' of partition '%v' is not between 0 and the high water mark '%v'",
+				ErrInvalidOffset, offset, partitionID, mark.High)
+		}
+	}
+
+	return nil
+}
+
+// DeleteRecordsBefore deletes all records of the topic's partitions which have been written before the given
+// timestamp. The offset for the timestamp is resolved per partition via ListOffsets. Partitions which have no records
+// before the timestamp are left untouched. The new low water mark of each partition is returned. If the records of
+// some partitions could not be deleted, the low water marks of the remaining partitions are returned along with an
+// error.
+func (s *Service) DeleteRecordsBefore(ctx context.Context, topicName string, ts time.Time) (map[int32]int64, error) {
+	partitionIDs, err := s.ListPartitionIDs(ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	marks, err := s.GetPartitionMarks(ctx, topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition watermarks: %w", err)
+	}
+	offsetsRes, err := s.ListOffsets(ctx, map[string][]int32{topicName: partitionIDs}, ts.UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve offsets for timestamp: %w", err)
+	}
+	offsetByPartitionID := make(map[int32]int64, len(partitionIDs))
+	for _, topic := range offsetsRes.Topics {
+		for _, partition := range topic.Partitions {
+			err := kerr.ErrorForCode(partition.ErrorCode)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve offset for timestamp of partition '%v': %w", partition.Partition, err)
+			}
+			offsetByPartitionID[partition.Partition] = partition.Offset
+		}
+	}
+
+	lowWaterMarks := make(map[int32]int64, len(partitionIDs))
+	beforeOffset := make(map[int32]int64)
+	for _, partitionID := range partitionIDs {
+		mark := marks[partitionID]
+		if mark.Error != "" {
+			return nil, fmt.Errorf("failed to get watermarks of partition '%v': %v", partitionID, mark.Error)
+		}
+		offset, exists := offsetByPartitionID[partitionID]
+		if !exists {
+			return nil, fmt.Errorf("no offset for timestamp of partition '%v' returned", partitionID)
+		}
+		if offset < 0 {
+			// All records have been written before the timestamp
+			offset = mark.High
+		}
+		if offset <= mark.Low {
+			lowWaterMarks[partitionID] = mark.Low
+			continue
+		}
+		beforeOffset[partitionID] = offset
+	}
+
+	results, err := s.DeleteRecords(ctx, topicName, beforeOffset)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	for _, result := range results {
+		if result.Error != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete records of partition '%v': %w", result.PartitionID, result.Error)
+			}
+			continue
+		}
+		lowWaterMarks[result.PartitionID] = result.LowWaterMark
+	}
+
+	return lowWaterMarks, firstErr
+}
@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestDeleteRecords(t *testing.T) {
+	deleted := make(map[int32]int64)
+	// Partition 1's leader rejects deletions
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.MetadataRequest:
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{{
+				Topic:      "orders",
+				Partitions: []kmsg.MetadataResponseTopicPartition{{Partition: 0}, {Partition: 1}},
+			}}}, nil
+		case *kmsg.ListOffsetsRequest:
+			// Both partitions have the watermarks 10 and 100
+			topicRes := kmsg.ListOffsetsResponseTopic{Topic: "orders"}
+			for _, partition := range typedReq.Topics[0].Partitions {
+				offset := int64(10)
+				if partition.Timestamp == TimestampLatest {
+					offset = 100
+				}
+				topicRes.Partitions = append(topicRes.Partitions, kmsg.ListOffsetsResponseTopicPartition{Partition: partition.Partition, Offset: offset})
+			}
+			return &kmsg.ListOffsetsResponse{Topics: []kmsg.ListOffsetsResponseTopic{topicRes}}, nil
+		case *kmsg.DeleteRecordsRequest:
+			topicRes := kmsg.DeleteRecordsResponseTopic{Topic: "orders"}
+			for _, partition := range typedReq.Topics[0].Partitions {
+				partitionRes := kmsg.DeleteRecordsResponseTopicPartition{Partition: partition.Partition, LowWatermark: partition.Offset}
+				if partition.Partition == 1 {
+					partitionRes.ErrorCode = kerr.PolicyViolation.Code
+					partitionRes.LowWatermark = -1
+				} else {
+					deleted[partition.Partition] = partition.Offset
+				}
+				topicRes.Partitions = append(topicRes.Partitions, partitionRes)
+			}
+			return &kmsg.DeleteRecordsResponse{Topics: []kmsg.DeleteRecordsResponseTopic{topicRes}}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	results, err := svc.DeleteRecords(context.Background(), "orders", map[int32]int64{0: 50, 1: DeleteRecordsOffsetHighWaterMark})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, int32(0), results[0].PartitionID)
+	assert.Equal(t, int64(50), results[0].LowWaterMark)
+	assert.NoError(t, results[0].Error)
+	assert.Equal(t, int32(1), results[1].PartitionID)
+	assert.Error(t, results[1].Error)
+	assert.Equal(t, map[int32]int64{0: 50}, deleted)
+
+	// All partitions failing is reported as error
+	_, err = svc.DeleteRecords(context.Background(), "orders", map[int32]int64{1: 50})
+	assert.Error(t, err)
+}
+
+func TestDeleteRecords_Validation(t *testing.T) {
+	deleted := make(map[int32]int64)
+	// Invalid offsets must be rejected before any records are deleted
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.MetadataRequest:
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{{
+				Topic:      "orders",
+				Partitions: []kmsg.MetadataResponseTopicPartition{{Partition: 0}, {Partition: 1}},
+			}}}, nil
+		case *kmsg.ListOffsetsRequest:
+			// Both partitions have the watermarks 10 and 100
+			topicRes := kmsg.ListOffsetsResponseTopic{Topic: "orders"}
+			for _, partition := range typedReq.Topics[0].Partitions {
+				offset := int64(10)
+				if partition.Timestamp == TimestampLatest {
+					offset = 100
+				}
+				topicRes.Partitions = append(topicRes.Partitions, kmsg.ListOffsetsResponseTopicPartition{Partition: partition.Partition, Offset: offset})
+			}
+			return &kmsg.ListOffsetsResponse{Topics: []kmsg.ListOffsetsResponseTopic{topicRes}}, nil
+		case *kmsg.DeleteRecordsRequest:
+			topicRes := kmsg.DeleteRecordsResponseTopic{Topic: "orders"}
+			for _, partition := range typedReq.Topics[0].Partitions {
+				partitionRes := kmsg.DeleteRecordsResponseTopicPartition{Partition: partition.Partition, LowWatermark: partition.Offset}
+				deleted[partition.Partition] = partition.Offset
+				topicRes.Partitions = append(topicRes.Partitions, partitionRes)
+			}
+			return &kmsg.DeleteRecordsResponse{Topics: []kmsg.DeleteRecordsResponseTopic{topicRes}}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	_, err := svc.DeleteRecords(context.Background(), "orders", map[int32]int64{0: 101})
+	assert.True(t, errors.Is(err, ErrInvalidOffset))
+
+	_, err = svc.DeleteRecords(context.Background(), "orders", map[int32]int64{0: -5})
+	assert.True(t, errors.Is(err, ErrInvalidOffset))
+
+	_, err = svc.DeleteRecords(context.Background(), "orders", map[int32]int64{0: 20, 2: 20})
+	assert.True(t, errors.Is(err, ErrPartitionNotFound))
+
+	assert.Empty(t, deleted)
+}
+
+func TestDeleteRecordsBefore(t *testing.T) {
+	deleted := make(map[int32]int64)
+	// Timestamps resolve to offset 50 in partition 0 and to the low water mark in partition 1
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.MetadataRequest:
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{{
+				Topic:      "orders",
+				Partitions: []kmsg.MetadataResponseTopicPartition{{Partition: 0}, {Partition: 1}},
+			}}}, nil
+		case *kmsg.ListOffsetsRequest:
+			// Both partitions have the watermarks 10 and 100
+			topicRes := kmsg.ListOffsetsResponseTopic{Topic: "orders"}
+			for _, partition := range typedReq.Topics[0].Partitions {
+				offset := int64(10)
+				switch {
+				case partition.Timestamp == TimestampLatest:
+					offset = 100
+				case partition.Timestamp > 0 && partition.Partition == 0:
+					offset = 50
+				}
+				topicRes.Partitions = append(topicRes.Partitions, kmsg.ListOffsetsResponseTopicPartition{Partition: partition.Partition, Offset: offset})
+			}
+			return &kmsg.ListOffsetsResponse{Topics: []kmsg.ListOffsetsResponseTopic{topicRes}}, nil
+		case *kmsg.DeleteRecordsRequest:
+			topicRes := kmsg.DeleteRecordsResponseTopic{Topic: "orders"}
+			for _, partition := range typedReq.Topics[0].Partitions {
+				partitionRes := kmsg.DeleteRecordsResponseTopicPartition{Partition: partition.Partition, LowWatermark: partition.Offset}
+				deleted[partition.Partition] = partition.Offset
+				topicRes.Partitions = append(topicRes.Partitions, partitionRes)
+			}
+			return &kmsg.DeleteRecordsResponse{Topics: []kmsg.DeleteRecordsResponseTopic{topicRes}}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	lowWaterMarks, err := svc.DeleteRecordsBefore(context.Background(), "orders", time.Unix(1600000000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, map[int32]int64{0: 50, 1: 10}, lowWaterMarks)
+
+	// Partition 1 has no records before the timestamp and must not be touched
+	assert.Equal(t, map[int32]int64{0: 50}, deleted)
+}
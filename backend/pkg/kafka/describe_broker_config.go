@@ -7,8 +7,13 @@ import (
 )
 
 // DescribeBrokerConfig fetches config entries which apply at the Broker Scope (e.g. offset.retention.minutes).
-// Use nil for configNames in order to get all config entries.
+// Use nil for configNames in order to get all config entries. An error wrapping ErrBrokerNotFound is returned if the
+// broker is not part of the cluster.
 func (s *Service) DescribeBrokerConfig(ctx context.Context, brokerID int32, configNames []string) (*kmsg.DescribeConfigsResponse, error) {
+	if err := s.requireBrokerExists(ctx, brokerID); err != nil {
+		return nil, err
+	}
+
 	resourceReq := kmsg.NewDescribeConfigsRequestResource()
 	resourceReq.ResourceType = kmsg.ConfigResourceTypeBroker
 	resourceReq.ResourceName = strconv.Itoa(int(brokerID)) // Empty string for all brokers (only works for dynamic broker configs)
@@ -0,0 +1,120 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// ClusterInfo describes the cluster as a whole, independent of any topic metadata.
+type ClusterInfo struct {
+	// ClusterID is empty if the cluster does not report one (Kafka < v0.10.1)
+	ClusterID    string          `json:"clusterId"`
+	ControllerID int32           `json:"controllerId"`
+	Brokers      []ClusterBroker `json:"brokers"`
+
+	// AuthorizedOperations are the ACL operations the client is allowed to perform on the cluster resource, sorted
+	// by name. It is nil if the cluster does not report them (Kafka < v2.3).
+	AuthorizedOperations []string `json:"authorizedOperations"`
+}
+
+// ClusterBroker is a single alive broker of the cluster
+type ClusterBroker struct {
+	BrokerID int32   `json:"brokerId"`
+	Host     string  `json:"host"`
+	Port     int32   `json:"port"`
+	Rack     *string `json:"rack"`
+}
+
+// DescribeCluster returns the cluster ID, controller, alive brokers and authorized cluster operations. The
+// DescribeCluster API is used if the cluster supports it (Kafka >= v2.8), otherwise the info is taken from a
+// metadata request without any topics.
+func (s *Service) DescribeCluster(ctx context.Context) (*ClusterInfo, error) {
+	err := s.requireSupportedRequest(ctx, &kmsg.DescribeClusterRequest{})
+	if errors.Is(err, ErrUnsupportedRequest) {
+		return s.describeClusterByMetadata(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req := kmsg.NewDescribeClusterRequest()
+	req.IncludeClusterAuthorizedOperations = true
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+	if err := kerr.ErrorForCode(res.ErrorCode); err != nil {
+		if res.ErrorMessage != nil {
+			return nil, fmt.Errorf("failed to describe cluster: %w: %v", err, *res.ErrorMessage)
+		}
+		return nil, fmt.Errorf("failed to describe cluster: %w", err)
+	}
+
+	brokers := make([]ClusterBroker, len(res.Brokers))
+	for i, broker := range res.Brokers {
+		brokers[i] = ClusterBroker{BrokerID: broker.NodeID, Host: broker.Host, Port: broker.Port, Rack: broker.Rack}
+	}
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i].BrokerID < brokers[j].BrokerID })
+
+	return &ClusterInfo{
+		ClusterID:            res.ClusterID,
+		ControllerID:         res.ControllerID,
+		Brokers:              brokers,
+		AuthorizedOperations: AuthorizedOperations(res.ClusterAuthorizedOperations),
+	}, nil
+}
+
+// describeClusterByMetadata derives the cluster info from a metadata response for clusters which do not support the
+// DescribeCluster API.
+func (s *Service) describeClusterByMetadata(ctx context.Context) (*ClusterInfo, error) {
+	req := kmsg.NewMetadataRequest()
+	req.Topics = []kmsg.MetadataRequestTopic{}
+	req.IncludeClusterAuthorizedOperations = true
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request metadata: %w", err)
+	}
+
+	brokers := make([]ClusterBroker, len(res.Brokers))
+	for i, broker := range res.Brokers {
+		brokers[i] = ClusterBroker{BrokerID: broker.NodeID, Host: broker.Host, Port: broker.Port, Rack: broker.Rack}
+	}
+	sort.Slice(brokers, func(i, j int) bool { return brokers[i].BrokerID < brokers[j].BrokerID })
+
+	clusterID := ""
+	if res.ClusterID != nil {
+		clusterID = *res.ClusterID
+	}
+
+	return &ClusterInfo{
+		ClusterID:            clusterID,
+		ControllerID:         res.ControllerID,
+		Brokers:              brokers,
+		AuthorizedOperations: AuthorizedOperations(res.AuthorizedOperations),
+	}, nil
+}
+
+// AuthorizedOperations converts Kafka's authorized operations bitfield into the operation names, where bit n is set
+// if the operation with the ID n is allowed. Kafka reports math.MinInt32 if the operations have not been requested or
+// the broker does not support them, in which case nil is returned.
+func AuthorizedOperations(bitfield int32) []string {
+	if bitfield == math.MinInt32 {
+		return nil
+	}
+
+	operations := make([]string, 0)
+	for op := kmsg.ACLOperationRead; op <= kmsg.ACLOperationIdempotentWrite; op++ {
+		if bitfield&(1<<uint(op)) != 0 {
+			operations = append(operations, op.String())
+		}
+	}
+	sort.Strings(operations)
+
+	return operations
+}
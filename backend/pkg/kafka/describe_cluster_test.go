@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestDescribeCluster(t *testing.T) {
+	rack := "eu-west-1a"
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			return apiVersionsResponse(&kmsg.DescribeClusterRequest{}), nil
+		case *kmsg.DescribeClusterRequest:
+			assert.True(t, typedReq.IncludeClusterAuthorizedOperations)
+			return &kmsg.DescribeClusterResponse{
+				ClusterID:    "cluster-a",
+				ControllerID: 2,
+				Brokers: []kmsg.DescribeClusterResponseBroker{
+					{NodeID: 2, Host: "broker-2", Port: 9092},
+					{NodeID: 1, Host: "broker-1", Port: 9092, Rack: &rack},
+				},
+				ClusterAuthorizedOperations: 1<<kmsg.ACLOperationDescribe | 1<<kmsg.ACLOperationAlter,
+			}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	info, err := svc.DescribeCluster(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &ClusterInfo{
+		ClusterID:    "cluster-a",
+		ControllerID: 2,
+		Brokers: []ClusterBroker{
+			{BrokerID: 1, Host: "broker-1", Port: 9092, Rack: &rack},
+			{BrokerID: 2, Host: "broker-2", Port: 9092},
+		},
+		AuthorizedOperations: []string{"ALTER", "DESCRIBE"},
+	}, info)
+}
+
+func TestDescribeCluster_MetadataFallback(t *testing.T) {
+	clusterID := "cluster-b"
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			return apiVersionsResponse(&kmsg.MetadataRequest{}), nil
+		case *kmsg.MetadataRequest:
+			assert.Empty(t, typedReq.Topics)
+			return &kmsg.MetadataResponse{
+				ClusterID:            &clusterID,
+				ControllerID:         1,
+				Brokers:              []kmsg.MetadataResponseBroker{{NodeID: 1, Host: "broker-1", Port: 9092}},
+				AuthorizedOperations: math.MinInt32,
+			}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	info, err := svc.DescribeCluster(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &ClusterInfo{
+		ClusterID:    "cluster-b",
+		ControllerID: 1,
+		Brokers:      []ClusterBroker{{BrokerID: 1, Host: "broker-1", Port: 9092}},
+	}, info)
+}
@@ -6,6 +6,11 @@ import (
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"sort"
+	"sync"
+	"time"
 )
 
 type DescribeConsumerGroupsResponseSharded struct {
@@ -47,47 +52,384 @@ type DescribeConsumerGroupsResponse struct {
 	BrokerMetadata kgo.BrokerMetadata
 	Groups         *kmsg.DescribeGroupsResponse
 	Error          error
+
+	// Duration is the time it took to describe the groups at this broker
+	Duration time.Duration
+}
+
+// DescribeConsumerGroupsOptions scope a DescribeConsumerGroups request
+type DescribeConsumerGroupsOptions struct {
+	// OnlyBrokerID restricts the request to the groups coordinated by the broker with this ID, all other groups are
+	// skipped and not part of the response. This allows to diagnose a single misbehaving coordinator without
+	// waiting for (or being affected by) the others. Nil describes the groups of all coordinators.
+	OnlyBrokerID *int32
 }
 
+// maxConcurrentDescribeGroupsRequests limits the number of DescribeGroups requests that are in flight at the same time.
+const maxConcurrentDescribeGroupsRequests = 10
+
 // DescribeConsumerGroups fetches additional information from Kafka about one or more Consumer groups.
-// It returns a map where the coordinator BrokerID is the key.
+// It returns one response for each coordinator broker, sorted by the coordinator's BrokerID.
+//
+// Groups are described with the classic DescribeGroups API only. Groups of the server side assigned consumer group
+// protocol (KIP-848) have to be described with the ConsumerGroupDescribe API (key 69), which the used franz-go version
+// can not send because it only knows request keys up to 66. Supporting these groups requires upgrading franz-go.
+//
+// Groups whose coordinator has failed repeatedly are not requested until the broker's circuit breaker cooldown is
+// over. These groups are reported as failed response with an error that wraps ErrBrokerUnavailable.
+//
+// If describing the groups takes longer than the configured SlowDescribeThreshold a warning with the slowest broker
+// is logged.
 func (s *Service) DescribeConsumerGroups(ctx context.Context, groups []string) (*DescribeConsumerGroupsResponseSharded, error) {
-	req := kmsg.DescribeGroupsRequest{
-		Groups:                      groups,
-		IncludeAuthorizedOperations: false,
+	return s.DescribeConsumerGroupsWithOptions(ctx, groups, DescribeConsumerGroupsOptions{})
+}
+
+// DescribeConsumerGroupsWithOptions is like DescribeConsumerGroups, but the described groups can be restricted via
+// the options. If OnlyBrokerID is set, the coordinators of all groups are still looked up, but only the groups of this
+// broker are described. Groups whose coordinator could not be found are skipped then, because they can't be
+// attributed to a broker.
+func (s *Service) DescribeConsumerGroupsWithOptions(ctx context.Context, groups []string, opts DescribeConsumerGroupsOptions) (*DescribeConsumerGroupsResponseSharded, error) {
+	isSkippedBroker := func(brokerID int32) bool {
+		return opts.OnlyBrokerID != nil && *opts.OnlyBrokerID != brokerID
 	}
-	shardedResp := s.KafkaClient.RequestSharded(ctx, &req)
 
+	startedAt := time.Now()
 	result := &DescribeConsumerGroupsResponseSharded{
 		Groups:         make([]DescribeConsumerGroupsResponse, 0),
 		RequestsSent:   0,
 		RequestsFailed: 0,
 	}
+
+	availableGroups, unavailableGroups := s.circuitBreaker.SplitGroupsByAvailability(groups)
 	var lastErr error
-	for _, kresp := range shardedResp {
-		result.RequestsSent++
-		if kresp.Err != nil {
-			result.RequestsFailed++
-			lastErr = kresp.Err
+	for brokerID, skippedGroups := range unavailableGroups {
+		if isSkippedBroker(brokerID) {
 			continue
 		}
-		res := kresp.Resp.(*kmsg.DescribeGroupsResponse)
+		result.RequestsSent++
+		result.RequestsFailed++
+		lastErr = fmt.Errorf("skipped describing '%v' groups coordinated by broker '%v': %w", len(skippedGroups), brokerID, ErrBrokerUnavailable)
+		result.Groups = append(result.Groups, DescribeConsumerGroupsResponse{
+			BrokerMetadata: kgo.BrokerMetadata{NodeID: brokerID},
+			Groups:         nil,
+			Error:          lastErr,
+		})
+	}
+	if len(availableGroups) == 0 && result.RequestsFailed > 0 {
+		return result, fmt.Errorf("all '%v' requests have failed, last error: %w", result.RequestsSent, lastErr)
+	}
 
+	// 1. Bucket groups by their coordinator
+	batches, coordinatorErrs := s.findGroupCoordinators(ctx, availableGroups)
+	for group, err := range coordinatorErrs {
+		if opts.OnlyBrokerID != nil {
+			continue
+		}
+		result.RequestsSent++
+		result.RequestsFailed++
+		lastErr = fmt.Errorf("failed to find coordinator for group '%v': %w", group, err)
 		result.Groups = append(result.Groups, DescribeConsumerGroupsResponse{
-			BrokerMetadata: kresp.Meta,
-			Groups:         res,
-			Error:          kresp.Err,
+			BrokerMetadata: kgo.BrokerMetadata{NodeID: -1},
+			Groups:         nil,
+			Error:          lastErr,
 		})
 	}
+	describedBatches := make([]coordinatorBatch, 0, len(batches))
+	for _, batch := range batches {
+		s.circuitBreaker.SetGroupCoordinator(batch.Coordinator.NodeID, batch.Keys)
+		if isSkippedBroker(batch.Coordinator.NodeID) {
+			continue
+		}
+		describedBatches = append(describedBatches, batch)
+	}
+	batches = describedBatches
+
+	// 2. Describe all groups at their coordinator
+	describedGroups, err := describeGroupsConcurrently(ctx, batches, s.describeGroupsAtBroker, maxConcurrentDescribeGroupsRequests)
+	if err != nil {
+		return result, fmt.Errorf("failed to describe consumer groups: %w", err)
+	}
+	s.logSlowDescribe(time.Since(startedAt), len(groups), describedGroups)
+	for _, resp := range describedGroups {
+		result.RequestsSent++
+		if resp.Error != nil {
+			result.RequestsFailed++
+			lastErr = resp.Error
+			s.recordBrokerFailure(ctx, resp.BrokerMetadata.NodeID)
+			s.coordinatorCache.Invalidate(coordinatorTypeGroup, describedGroupIDs(batches, resp.BrokerMetadata.NodeID)...)
+			continue
+		}
+		for _, group := range resp.Groups.Groups {
+			if isCoordinatorMovedError(kerr.ErrorForCode(group.ErrorCode)) {
+				s.InvalidateGroupCoordinator(group.Group)
+			}
+		}
+		s.circuitBreaker.RecordSuccess(resp.BrokerMetadata.NodeID)
+		result.Groups = append(result.Groups, resp)
+	}
 	if result.RequestsSent > 0 && result.RequestsSent == result.RequestsFailed {
-		return result, fmt.Errorf("all '%v' requests have failed, last error: %w", len(shardedResp), lastErr)
+		return result, fmt.Errorf("all '%v' requests have failed, last error: %w", result.RequestsSent, lastErr)
 	}
 
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		return result.Groups[i].BrokerMetadata.NodeID < result.Groups[j].BrokerMetadata.NodeID
+	})
+
 	return result, nil
 }
 
+// coordinatorBatch is a set of group or transactional IDs which share the same coordinator broker.
+type coordinatorBatch struct {
+	Coordinator kgo.BrokerMetadata
+	Keys        []string
+}
+
+// describedGroupIDs returns the keys of the batch that has been sent to the given coordinator
+func describedGroupIDs(batches []coordinatorBatch, coordinatorID int32) []string {
+	for _, batch := range batches {
+		if batch.Coordinator.NodeID == coordinatorID {
+			return batch.Keys
+		}
+	}
+	return nil
+}
+
+// maxConcurrentFindCoordinatorRequests limits the number of FindCoordinator requests that are in flight at the same
+// time.
+const maxConcurrentFindCoordinatorRequests = 20
+
+// findGroupCoordinators resolves the coordinator for each given group and buckets the groups by coordinator. Groups
+// whose coordinator could not be found are returned in the error map.
+func (s *Service) findGroupCoordinators(ctx context.Context, groups []string) ([]coordinatorBatch, map[string]error) {
+	return s.findGroupCoordinatorsConcurrently(ctx, groups, maxConcurrentFindCoordinatorRequests)
+}
+
+// findGroupCoordinatorsConcurrently resolves the group coordinators with at most maxConcurrency FindCoordinator
+// requests in flight.
+func (s *Service) findGroupCoordinatorsConcurrently(ctx context.Context, groups []string, maxConcurrency int) ([]coordinatorBatch, map[string]error) {
+	return s.findCoordinatorsConcurrently(ctx, groups, coordinatorTypeGroup, maxConcurrency)
+}
+
+// The coordinator types of FindCoordinator requests
+const (
+	coordinatorTypeGroup       int8 = 0
+	coordinatorTypeTransaction int8 = 1
+)
+
+// findCoordinatorsConcurrently resolves the coordinators of group or transactional IDs with at most maxConcurrency
+// FindCoordinator requests in flight. Cached coordinators are reused without sending a request. Batches are sorted by
+// the coordinator's BrokerID and the keys inside a batch are sorted by name, so that the result does not depend on the
+// order in which the responses arrived. Keys whose coordinator could not be found are returned in the error map.
+func (s *Service) findCoordinatorsConcurrently(ctx context.Context, keys []string, coordinatorType int8, maxConcurrency int) ([]coordinatorBatch, map[string]error) {
+	batchByBrokerID := make(map[int32]*coordinatorBatch)
+	errByKey := make(map[string]error)
+	mutex := sync.Mutex{}
+
+	addToBatch := func(key string, coordinator kgo.BrokerMetadata) {
+		batch, exists := batchByBrokerID[coordinator.NodeID]
+		if !exists {
+			batch = &coordinatorBatch{Coordinator: coordinator, Keys: make([]string, 0)}
+			batchByBrokerID[coordinator.NodeID] = batch
+		}
+		batch.Keys = append(batch.Keys, key)
+	}
+
+	uncachedKeys := make(chan string, len(keys))
+	for _, key := range keys {
+		if coordinator, isCached := s.coordinatorCache.Get(coordinatorType, key); isCached {
+			addToBatch(key, coordinator)
+			continue
+		}
+		uncachedKeys <- key
+	}
+	close(uncachedKeys)
+
+	// A fixed number of workers sends the requests, so that there's no goroutine per key. Once the context is done
+	// the workers drain the remaining keys without sending further requests.
+	workerCount := maxConcurrency
+	if len(uncachedKeys) < workerCount {
+		workerCount = len(uncachedKeys)
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range uncachedKeys {
+				coordinator, err := s.findCoordinator(ctx, key, coordinatorType)
+
+				mutex.Lock()
+				if err != nil {
+					errByKey[key] = err
+				} else {
+					s.coordinatorCache.Set(coordinatorType, key, coordinator)
+					addToBatch(key, coordinator)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	batches := make([]coordinatorBatch, 0, len(batchByBrokerID))
+	for _, batch := range batchByBrokerID {
+		sort.Strings(batch.Keys)
+		batches = append(batches, *batch)
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].Coordinator.NodeID < batches[j].Coordinator.NodeID })
+
+	return batches, errByKey
+}
+
+// findCoordinator sends a single FindCoordinator request, unless the context is done already
+func (s *Service) findCoordinator(ctx context.Context, key string, coordinatorType int8) (kgo.BrokerMetadata, error) {
+	if ctx.Err() != nil {
+		return kgo.BrokerMetadata{}, ctx.Err()
+	}
+
+	req := kmsg.NewFindCoordinatorRequest()
+	req.CoordinatorKey = key
+	req.CoordinatorType = coordinatorType
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return kgo.BrokerMetadata{}, err
+	}
+	err = kerr.ErrorForCode(res.ErrorCode)
+	if err != nil {
+		return kgo.BrokerMetadata{}, err
+	}
+
+	return kgo.BrokerMetadata{NodeID: res.NodeID, Host: res.Host, Port: res.Port}, nil
+}
+
+// describeGroupsAtBroker sends a single DescribeGroups request to the given broker
+func (s *Service) describeGroupsAtBroker(ctx context.Context, brokerID int32, groups []string) (*kmsg.DescribeGroupsResponse, error) {
+	req := kmsg.NewDescribeGroupsRequest()
+	req.Groups = groups
+	req.IncludeAuthorizedOperations = true
+
+	return req.RequestWith(ctx, s.KafkaClient.ForBroker(brokerID))
+}
+
+type describeGroupsFunc func(ctx context.Context, brokerID int32, groups []string) (*kmsg.DescribeGroupsResponse, error)
+
+// describeGroupsConcurrently sends one describe request per batch with at most maxConcurrency requests in flight.
+// A failed request does not abort the other requests, instead its error is reported in the response for that batch.
+// An error is only returned if the context has been cancelled before all requests completed.
+func describeGroupsConcurrently(ctx context.Context, batches []coordinatorBatch, describe describeGroupsFunc, maxConcurrency int) ([]DescribeConsumerGroupsResponse, error) {
+	// All groups share one coordinator in small clusters, there's nothing to fan out then
+	if len(batches) == 1 {
+		return describeGroupsAtSingleCoordinator(ctx, batches[0], describe)
+	}
+	return describeGroupsFanOut(ctx, batches, describe, maxConcurrency)
+}
+
+// describeGroupsAtSingleCoordinator sends the describe request of a single batch synchronously. The result is the
+// same as the one of describeGroupsFanOut for a single batch.
+func describeGroupsAtSingleCoordinator(ctx context.Context, batch coordinatorBatch, describe describeGroupsFunc) ([]DescribeConsumerGroupsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	res, err := describe(ctx, batch.Coordinator.NodeID, batch.Keys)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return []DescribeConsumerGroupsResponse{{
+		BrokerMetadata: batch.Coordinator,
+		Groups:         res,
+		Error:          err,
+		Duration:       time.Since(startedAt),
+	}}, nil
+}
+
+// describeGroupsFanOut sends the describe requests of all batches concurrently, see describeGroupsConcurrently
+func describeGroupsFanOut(ctx context.Context, batches []coordinatorBatch, describe describeGroupsFunc, maxConcurrency int) ([]DescribeConsumerGroupsResponse, error) {
+	responses := make([]DescribeConsumerGroupsResponse, len(batches))
+	semaphore := make(chan struct{}, maxConcurrency)
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i, batch := range batches {
+		i, batch := i, batch
+		g.Go(func() error {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-semaphore }()
+
+			startedAt := time.Now()
+			res, err := describe(ctx, batch.Coordinator.NodeID, batch.Keys)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			responses[i] = DescribeConsumerGroupsResponse{
+				BrokerMetadata: batch.Coordinator,
+				Groups:         res,
+				Error:          err,
+				Duration:       time.Since(startedAt),
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// logSlowDescribe logs a warning including the slowest broker if describing the groups took longer than the
+// configured threshold. A threshold of 0 disables the log.
+func (s *Service) logSlowDescribe(elapsed time.Duration, groupCount int, responses []DescribeConsumerGroupsResponse) {
+	threshold := s.Config.SlowDescribeThreshold
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.Duration("duration", elapsed),
+		zap.Duration("threshold", threshold),
+		zap.Int("group_count", groupCount),
+		zap.Int("broker_count", len(responses)),
+	}
+	var slowest *DescribeConsumerGroupsResponse
+	for i := range responses {
+		if slowest == nil || responses[i].Duration > slowest.Duration {
+			slowest = &responses[i]
+		}
+	}
+	if slowest != nil {
+		fields = append(fields,
+			zap.Int32("slowest_broker_id", slowest.BrokerMetadata.NodeID),
+			zap.Duration("slowest_broker_duration", slowest.Duration))
+	}
+	s.Logger.Warn("describing consumer groups was slow", fields...)
+}
+
+// recordBrokerFailure records a failed request for the circuit breaker. If the breaker opens because of this failure
+// we refresh the client's broker list, so that it notices if the broker has been removed or moved. See RefreshMetadata
+// for what is not refreshed.
+func (s *Service) recordBrokerFailure(ctx context.Context, brokerID int32) {
+	opened := s.circuitBreaker.RecordFailure(brokerID)
+	if !opened {
+		return
+	}
+
+	s.Logger.Warn("broker failed repeatedly, circuit breaker opened",
+		zap.Int32("broker_id", brokerID),
+		zap.Duration("cooldown", s.circuitBreaker.cooldown))
+	err := s.RefreshMetadata(ctx)
+	if err != nil {
+		s.Logger.Warn("failed to refresh metadata after circuit breaker opened", zap.Error(err))
+	}
+}
+
 // DescribeConsumerGroup from Kafka and checks all possible errors that can occur for that request. If either the
-// request fails or the group could not be described (e.g. due to ACLs) an error will be returned.
+// request fails or the group could not be described (e.g. due to ACLs) an error will be returned. If the group does
+// not exist the error wraps ErrGroupNotFound.
 func (s *Service) DescribeConsumerGroup(ctx context.Context, groupID string) (kmsg.DescribeGroupsResponseGroup, error) {
 	req := kmsg.NewDescribeGroupsRequest()
 	req.Groups = []string{groupID}
@@ -102,8 +444,15 @@ func (s *Service) DescribeConsumerGroup(ctx context.Context, groupID string) (km
 
 	describedGroup := res.Groups[0]
 	err = kerr.ErrorForCode(describedGroup.ErrorCode)
+	if err == kerr.GroupIDNotFound {
+		return kmsg.DescribeGroupsResponseGroup{}, fmt.Errorf("%w: %v", ErrGroupNotFound, groupID)
+	}
 	if err != nil {
-		return kmsg.DescribeGroupsResponseGroup{}, fmt.Errorf("failed to describe consumer group: %w", err)
+		return kmsg.DescribeGroupsResponseGroup{}, fmt.Errorf("failed to describe consumer group: %w", &GroupError{GroupID: groupID, Err: err})
+	}
+	// Brokers describe groups which they don't know as dead groups without any members
+	if describedGroup.State == "Dead" {
+		return kmsg.DescribeGroupsResponseGroup{}, fmt.Errorf("%w: %v", ErrGroupNotFound, groupID)
 	}
 
 	return describedGroup, nil
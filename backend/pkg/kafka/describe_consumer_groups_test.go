@@ -0,0 +1,384 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDescribeGroupsConcurrently_IsolatesBrokerErrors(t *testing.T) {
+	describe := func(_ context.Context, brokerID int32, groups []string) (*kmsg.DescribeGroupsResponse, error) {
+		if brokerID == 2 {
+			return nil, errors.New("broker down")
+		}
+		return &kmsg.DescribeGroupsResponse{}, nil
+	}
+
+	batches := []coordinatorBatch{
+		{Coordinator: kgo.BrokerMetadata{NodeID: 1}, Keys: []string{"group-a"}},
+		{Coordinator: kgo.BrokerMetadata{NodeID: 2}, Keys: []string{"group-b"}},
+		{Coordinator: kgo.BrokerMetadata{NodeID: 3}, Keys: []string{"group-c"}},
+	}
+	responses, err := describeGroupsConcurrently(context.Background(), batches, describe, 2)
+	require.NoError(t, err)
+	require.Len(t, responses, 3)
+	for _, resp := range responses {
+		if resp.BrokerMetadata.NodeID == 2 {
+			assert.Error(t, resp.Error)
+			continue
+		}
+		assert.NoError(t, resp.Error)
+		assert.NotNil(t, resp.Groups)
+	}
+}
+
+func TestDescribeGroupsConcurrently_LimitsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	describe := func(_ context.Context, _ int32, _ []string) (*kmsg.DescribeGroupsResponse, error) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return &kmsg.DescribeGroupsResponse{}, nil
+	}
+
+	batches := make([]coordinatorBatch, 6)
+	for i := range batches {
+		batches[i] = coordinatorBatch{Coordinator: kgo.BrokerMetadata{NodeID: int32(i)}, Keys: []string{fmt.Sprintf("group-%d", i)}}
+	}
+	_, err := describeGroupsConcurrently(context.Background(), batches, describe, 2)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestDescribeGroupsConcurrently_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{}, 3)
+	describe := func(ctx context.Context, _ int32, _ []string) (*kmsg.DescribeGroupsResponse, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	batches := []coordinatorBatch{
+		{Coordinator: kgo.BrokerMetadata{NodeID: 1}, Keys: []string{"group-a"}},
+		{Coordinator: kgo.BrokerMetadata{NodeID: 2}, Keys: []string{"group-b"}},
+		{Coordinator: kgo.BrokerMetadata{NodeID: 3}, Keys: []string{"group-c"}},
+	}
+	done := make(chan error)
+	go func() {
+		_, err := describeGroupsConcurrently(ctx, batches, describe, 1)
+		done <- err
+	}()
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, context.Canceled), "expected context cancelled error, got: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("describe did not return after the context has been cancelled")
+	}
+}
+
+func TestDescribeGroupsConcurrently_SingleCoordinator(t *testing.T) {
+	describe := func(_ context.Context, brokerID int32, groups []string) (*kmsg.DescribeGroupsResponse, error) {
+		if groups[0] == "failing" {
+			return nil, errors.New("broker down")
+		}
+		return &kmsg.DescribeGroupsResponse{Groups: []kmsg.DescribeGroupsResponseGroup{{Group: groups[0]}}}, nil
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		group string
+	}{
+		{name: "success", ctx: context.Background(), group: "group"},
+		{name: "request error", ctx: context.Background(), group: "failing"},
+		{name: "cancelled", ctx: cancelled, group: "group"},
+	}
+	for _, tc := range tests {
+		batches := []coordinatorBatch{{Coordinator: kgo.BrokerMetadata{NodeID: 1, Host: "broker-1"}, Keys: []string{tc.group}}}
+
+		// The fast path must return exactly what the fan-out returns
+		expected, expectedErr := describeGroupsFanOut(tc.ctx, batches, describe, maxConcurrentDescribeGroupsRequests)
+		actual, err := describeGroupsConcurrently(tc.ctx, batches, describe, maxConcurrentDescribeGroupsRequests)
+		assert.Equal(t, expectedErr, err, tc.name)
+		require.Len(t, actual, len(expected), tc.name)
+		for i := range expected {
+			expected[i].Duration, actual[i].Duration = 0, 0
+		}
+		assert.Equal(t, expected, actual, tc.name)
+	}
+}
+
+func TestDescribeConsumerGroups(t *testing.T) {
+	coordinatorByGroup := map[string]int32{"group-a": 1, "group-b": 1, "group-c": 2}
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.FindCoordinatorRequest:
+			coordinatorID, exists := coordinatorByGroup[typedReq.CoordinatorKey]
+			if !exists {
+				return &kmsg.FindCoordinatorResponse{ErrorCode: kerr.CoordinatorNotAvailable.Code}, nil
+			}
+			return &kmsg.FindCoordinatorResponse{NodeID: coordinatorID}, nil
+		case *kmsg.DescribeGroupsRequest:
+			if brokerID == 2 {
+				return nil, errors.New("connection refused")
+			}
+			res := &kmsg.DescribeGroupsResponse{}
+			for _, group := range typedReq.Groups {
+				res.Groups = append(res.Groups, kmsg.DescribeGroupsResponseGroup{Group: group, State: "Stable"})
+			}
+			return res, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{
+		Logger:            zap.NewNop(),
+		KafkaClient:       client,
+		circuitBreaker:    newBrokerCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, Cooldown: time.Minute}),
+		metadataRefresher: newMetadataRefresher(minMetadataRefreshInterval),
+	}
+
+	res, err := svc.DescribeConsumerGroups(context.Background(), []string{"group-a", "group-b", "group-c", "group-d"})
+	require.NoError(t, err)
+
+	// One request for the failed coordinator lookup of group-d and one describe request per coordinator
+	assert.Equal(t, 3, res.RequestsSent)
+	assert.Equal(t, 2, res.RequestsFailed)
+	require.Len(t, res.Groups, 2)
+	assert.Equal(t, int32(-1), res.Groups[0].BrokerMetadata.NodeID)
+	assert.Error(t, res.Groups[0].Error)
+	assert.Equal(t, int32(1), res.Groups[1].BrokerMetadata.NodeID)
+	assert.ElementsMatch(t, []string{"group-a", "group-b"}, res.GetGroupIDs())
+
+	// All requests failing must be reported as error
+	coordinatorByGroup = map[string]int32{"group-c": 2}
+	_, err = svc.DescribeConsumerGroups(context.Background(), []string{"group-c"})
+	assert.Error(t, err)
+}
+
+func TestDescribeConsumerGroupsWithOptions_OnlyBrokerID(t *testing.T) {
+	coordinatorByGroup := map[string]int32{"group-a": 1, "group-b": 2, "group-c": 2, "group-d": 3}
+	describedBrokerIDs := make([]int32, 0)
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.FindCoordinatorRequest:
+			coordinatorID, exists := coordinatorByGroup[typedReq.CoordinatorKey]
+			if !exists {
+				return &kmsg.FindCoordinatorResponse{ErrorCode: kerr.CoordinatorNotAvailable.Code}, nil
+			}
+			return &kmsg.FindCoordinatorResponse{NodeID: coordinatorID}, nil
+		case *kmsg.DescribeGroupsRequest:
+			describedBrokerIDs = append(describedBrokerIDs, brokerID)
+			res := &kmsg.DescribeGroupsResponse{}
+			for _, group := range typedReq.Groups {
+				res.Groups = append(res.Groups, kmsg.DescribeGroupsResponseGroup{Group: group, State: "Stable"})
+			}
+			return res, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{
+		Logger:            zap.NewNop(),
+		KafkaClient:       client,
+		circuitBreaker:    newBrokerCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, Cooldown: time.Minute}),
+		metadataRefresher: newMetadataRefresher(minMetadataRefreshInterval),
+	}
+
+	brokerID := int32(2)
+	groups := []string{"group-a", "group-b", "group-c", "group-d", "group-unknown"}
+	res, err := svc.DescribeConsumerGroupsWithOptions(context.Background(), groups, DescribeConsumerGroupsOptions{OnlyBrokerID: &brokerID})
+	require.NoError(t, err)
+
+	// Neither the other brokers' groups nor the group without coordinator are part of the response
+	assert.Equal(t, []int32{2}, describedBrokerIDs)
+	assert.Equal(t, 1, res.RequestsSent)
+	assert.Equal(t, 0, res.RequestsFailed)
+	require.Len(t, res.Groups, 1)
+	assert.Equal(t, int32(2), res.Groups[0].BrokerMetadata.NodeID)
+	assert.ElementsMatch(t, []string{"group-b", "group-c"}, res.GetGroupIDs())
+
+	// A broker which coordinates none of the groups returns an empty response
+	brokerID = 4
+	res, err = svc.DescribeConsumerGroupsWithOptions(context.Background(), groups, DescribeConsumerGroupsOptions{OnlyBrokerID: &brokerID})
+	require.NoError(t, err)
+	assert.Empty(t, res.Groups)
+	assert.Equal(t, []int32{2}, describedBrokerIDs)
+}
+
+func TestLogSlowDescribe(t *testing.T) {
+	responses := []DescribeConsumerGroupsResponse{
+		{BrokerMetadata: kgo.BrokerMetadata{NodeID: 1}, Duration: time.Second},
+		{BrokerMetadata: kgo.BrokerMetadata{NodeID: 2}, Duration: 3 * time.Second},
+	}
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		elapsed   time.Duration
+		logged    bool
+	}{
+		{name: "disabled", threshold: 0, elapsed: time.Minute, logged: false},
+		{name: "fast", threshold: 5 * time.Second, elapsed: 4 * time.Second, logged: false},
+		{name: "slow", threshold: 2 * time.Second, elapsed: 4 * time.Second, logged: true},
+	}
+
+	for _, tc := range tests {
+		core, logs := observer.New(zap.WarnLevel)
+		svc := &Service{Config: Config{SlowDescribeThreshold: tc.threshold}, Logger: zap.New(core)}
+
+		svc.logSlowDescribe(tc.elapsed, 5, responses)
+		if !tc.logged {
+			assert.Equal(t, 0, logs.Len(), tc.name)
+			continue
+		}
+		require.Equal(t, 1, logs.Len(), tc.name)
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, int64(5), fields["group_count"], tc.name)
+		assert.Equal(t, int64(2), fields["broker_count"], tc.name)
+		assert.Equal(t, int32(2), fields["slowest_broker_id"], tc.name)
+		assert.Equal(t, 3*time.Second, fields["slowest_broker_duration"], tc.name)
+	}
+}
+
+func TestFindGroupCoordinators(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		findReq, ok := req.(*kmsg.FindCoordinatorRequest)
+		if !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		switch findReq.CoordinatorKey {
+		case "group-d":
+			return &kmsg.FindCoordinatorResponse{ErrorCode: kerr.CoordinatorNotAvailable.Code}, nil
+		case "group-e":
+			return nil, errors.New("connection refused")
+		case "group-c":
+			return &kmsg.FindCoordinatorResponse{NodeID: 2}, nil
+		}
+		return &kmsg.FindCoordinatorResponse{NodeID: 1}, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	batches, errByGroup := svc.findGroupCoordinatorsConcurrently(context.Background(),
+		[]string{"group-e", "group-c", "group-b", "group-d", "group-a"}, 2)
+	assert.Equal(t, []coordinatorBatch{
+		{Coordinator: kgo.BrokerMetadata{NodeID: 1}, Keys: []string{"group-a", "group-b"}},
+		{Coordinator: kgo.BrokerMetadata{NodeID: 2}, Keys: []string{"group-c"}},
+	}, batches)
+	require.Len(t, errByGroup, 2)
+	assert.True(t, errors.Is(errByGroup["group-d"], kerr.CoordinatorNotAvailable))
+	assert.EqualError(t, errByGroup["group-e"], "connection refused")
+}
+
+func TestFindGroupCoordinators_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	requests := int32(0)
+	client := &mockKafkaClient{handle: func(_ context.Context, _ int32, _ kmsg.Request) (kmsg.Response, error) {
+		// The first request cancels the context, no further requests shall be sent
+		atomic.AddInt32(&requests, 1)
+		cancel()
+		return &kmsg.FindCoordinatorResponse{NodeID: 1}, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	groups := make([]string, 100)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("group-%d", i)
+	}
+	_, errByGroup := svc.findGroupCoordinatorsConcurrently(ctx, groups, 1)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Len(t, errByGroup, len(groups)-1)
+	for _, err := range errByGroup {
+		assert.True(t, errors.Is(err, context.Canceled))
+	}
+}
+
+func BenchmarkFindGroupCoordinators(b *testing.B) {
+	groups := make([]string, 1000)
+	for i := range groups {
+		groups[i] = fmt.Sprintf("group-%d", i)
+	}
+	// Simulate the network round trip of each FindCoordinator request
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		time.Sleep(100 * time.Microsecond)
+		return &kmsg.FindCoordinatorResponse{NodeID: int32(len(req.(*kmsg.FindCoordinatorRequest).CoordinatorKey) % 3)}, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	for _, maxConcurrency := range []int{1, maxConcurrentFindCoordinatorRequests} {
+		b.Run(fmt.Sprintf("concurrency-%d", maxConcurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				svc.findGroupCoordinatorsConcurrently(context.Background(), groups, maxConcurrency)
+			}
+		})
+	}
+}
+
+func BenchmarkDescribeGroupsConcurrently_SingleCoordinator(b *testing.B) {
+	describe := func(_ context.Context, _ int32, _ []string) (*kmsg.DescribeGroupsResponse, error) {
+		return &kmsg.DescribeGroupsResponse{}, nil
+	}
+	batches := []coordinatorBatch{{Coordinator: kgo.BrokerMetadata{NodeID: 1}, Keys: []string{"group"}}}
+
+	b.Run("fast-path", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			describeGroupsConcurrently(context.Background(), batches, describe, maxConcurrentDescribeGroupsRequests)
+		}
+	})
+	b.Run("fan-out", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			describeGroupsFanOut(context.Background(), batches, describe, maxConcurrentDescribeGroupsRequests)
+		}
+	})
+}
+
+func TestDescribeConsumerGroup_NotFound(t *testing.T) {
+	descriptions := map[string]kmsg.DescribeGroupsResponseGroup{
+		"stable":       {Group: "stable", State: "Stable"},
+		"dead":         {Group: "dead", State: "Dead"},
+		"not-found":    {Group: "not-found", ErrorCode: kerr.GroupIDNotFound.Code},
+		"unauthorized": {Group: "unauthorized", ErrorCode: kerr.GroupAuthorizationFailed.Code},
+	}
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		describeReq, ok := req.(*kmsg.DescribeGroupsRequest)
+		if !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		return &kmsg.DescribeGroupsResponse{Groups: []kmsg.DescribeGroupsResponseGroup{descriptions[describeReq.Groups[0]]}}, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	_, err := svc.DescribeConsumerGroup(context.Background(), "stable")
+	assert.NoError(t, err)
+	_, err = svc.DescribeConsumerGroup(context.Background(), "dead")
+	assert.True(t, errors.Is(err, ErrGroupNotFound), "expected group not found error, got: %v", err)
+	_, err = svc.DescribeConsumerGroup(context.Background(), "not-found")
+	assert.True(t, errors.Is(err, ErrGroupNotFound), "expected group not found error, got: %v", err)
+	_, err = svc.DescribeConsumerGroup(context.Background(), "unauthorized")
+	assert.True(t, errors.Is(err, kerr.GroupAuthorizationFailed))
+	assert.False(t, errors.Is(err, ErrGroupNotFound))
+	var groupErr *GroupError
+	require.True(t, errors.As(err, &groupErr))
+	assert.Equal(t, "unauthorized", groupErr.GroupID)
+}
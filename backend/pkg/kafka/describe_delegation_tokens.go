@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// DelegationToken describes a delegation token. The token's HMAC, which is its secret, is deliberately not part of it.
+type DelegationToken struct {
+	TokenID  string   `json:"tokenId"`
+	Owner    string   `json:"owner"`
+	Renewers []string `json:"renewers"`
+
+	// IssueTimestamp, ExpiryTimestamp and MaxTimestamp are unix timestamps in ms. The expiry timestamp moves forward
+	// whenever the token is renewed, but never beyond the max timestamp.
+	IssueTimestamp  int64 `json:"issueTimestamp"`
+	ExpiryTimestamp int64 `json:"expiryTimestamp"`
+	MaxTimestamp    int64 `json:"maxTimestamp"`
+}
+
+// DescribeDelegationTokens returns the delegation tokens of the given owners, sorted by token ID. Owners are
+// principals like "User:alice", the principal type defaults to "User" if it's omitted. If no owners are given, all
+// tokens the requester is allowed to describe are returned. An *UnsupportedRequestError is returned if the cluster
+// does not support delegation tokens (Kafka < v1.1).
+func (s *Service) DescribeDelegationTokens(ctx context.Context, owners []string) ([]DelegationToken, error) {
+	if err := s.requireSupportedRequest(ctx, &kmsg.DescribeDelegationTokenRequest{}); err != nil {
+		return nil, err
+	}
+
+	req := kmsg.NewDescribeDelegationTokenRequest()
+	for _, owner := range owners {
+		principalType, principalName, err := parsePrincipal(owner)
+		if err != nil {
+			return nil, err
+		}
+		reqOwner := kmsg.NewDescribeDelegationTokenRequestOwner()
+		reqOwner.PrincipalType = principalType
+		reqOwner.PrincipalName = principalName
+		req.Owners = append(req.Owners, reqOwner)
+	}
+
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe delegation tokens: %w", err)
+	}
+	if err := kerr.ErrorForCode(res.ErrorCode); err != nil {
+		return nil, fmt.Errorf("failed to describe delegation tokens. Inner kafka error: %w", err)
+	}
+
+	tokens := make([]DelegationToken, len(res.TokenDetails))
+	for i, detail := range res.TokenDetails {
+		renewers := make([]string, len(detail.Renewers))
+		for j, renewer := range detail.Renewers {
+			renewers[j] = renewer.PrincipalType + ":" + renewer.PrincipalName
+		}
+		tokens[i] = DelegationToken{
+			TokenID:         detail.TokenID,
+			Owner:           detail.PrincipalType + ":" + detail.PrincipalName,
+			Renewers:        renewers,
+			IssueTimestamp:  detail.IssueTimestamp,
+			ExpiryTimestamp: detail.ExpiryTimestamp,
+			MaxTimestamp:    detail.MaxTimestamp,
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].TokenID < tokens[j].TokenID })
+
+	return tokens, nil
+}
+
+// parsePrincipal splits a principal like "User:alice" into its type and name. The type defaults to "User".
+func parsePrincipal(principal string) (string, string, error) {
+	principalType, principalName := "User", principal
+	if i := strings.Index(principal, ":"); i >= 0 {
+		principalType, principalName = principal[:i], principal[i+1:]
+	}
+	if principalType == "" || principalName == "" {
+		return "", "", fmt.Errorf("invalid principal '%v', expected format is 'Type:Name'", principal)
+	}
+	return principalType, principalName, nil
+}
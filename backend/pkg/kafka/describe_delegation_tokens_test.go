@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestDescribeDelegationTokens(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			return apiVersionsResponse(&kmsg.DescribeDelegationTokenRequest{}), nil
+		case *kmsg.DescribeDelegationTokenRequest:
+			assert.Equal(t, []kmsg.DescribeDelegationTokenRequestOwner{
+				{PrincipalType: "User", PrincipalName: "alice"},
+				{PrincipalType: "Group", PrincipalName: "ops"},
+			}, typedReq.Owners)
+			return &kmsg.DescribeDelegationTokenResponse{TokenDetails: []kmsg.DescribeDelegationTokenResponseTokenDetail{
+				{
+					PrincipalType:   "User",
+					PrincipalName:   "alice",
+					TokenID:         "token-b",
+					HMAC:            []byte("secret"),
+					IssueTimestamp:  1000,
+					ExpiryTimestamp: 2000,
+					MaxTimestamp:    3000,
+					Renewers:        []kmsg.DescribeDelegationTokenResponseTokenDetailRenewer{{PrincipalType: "User", PrincipalName: "bob"}},
+				},
+				{PrincipalType: "Group", PrincipalName: "ops", TokenID: "token-a"},
+			}}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	tokens, err := svc.DescribeDelegationTokens(context.Background(), []string{"alice", "Group:ops"})
+	require.NoError(t, err)
+	assert.Equal(t, []DelegationToken{
+		{TokenID: "token-a", Owner: "Group:ops", Renewers: []string{}},
+		{TokenID: "token-b", Owner: "User:alice", Renewers: []string{"User:bob"}, IssueTimestamp: 1000, ExpiryTimestamp: 2000, MaxTimestamp: 3000},
+	}, tokens)
+
+	_, err = svc.DescribeDelegationTokens(context.Background(), []string{"User:"})
+	assert.Error(t, err)
+}
+
+func TestDescribeDelegationTokens_Errors(t *testing.T) {
+	supported := true
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			if !supported {
+				return apiVersionsResponse(&kmsg.MetadataRequest{}), nil
+			}
+			return apiVersionsResponse(&kmsg.DescribeDelegationTokenRequest{}), nil
+		case *kmsg.DescribeDelegationTokenRequest:
+			return &kmsg.DescribeDelegationTokenResponse{ErrorCode: kerr.DelegationTokenAuthDisabled.Code}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+	_, err := svc.DescribeDelegationTokens(context.Background(), nil)
+	assert.True(t, errors.Is(err, kerr.DelegationTokenAuthDisabled))
+
+	supported = false
+	svc = &Service{Logger: zap.NewNop(), KafkaClient: client}
+	_, err = svc.DescribeDelegationTokens(context.Background(), nil)
+	var unsupportedErr *UnsupportedRequestError
+	require.True(t, errors.As(err, &unsupportedErr), "expected unsupported request error, got: %v", err)
+	assert.Equal(t, "DescribeDelegationToken", unsupportedErr.RequestName)
+}
@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// FeatureVersion is the version range of a feature (e.g. metadata.version) which the broker supports, along with the
+// version level that has been finalized for the whole cluster. The finalized version is nil if the feature has not
+// been finalized yet, e.g. because not all brokers have been upgraded.
+type FeatureVersion struct {
+	Name             string `json:"name"`
+	MinVersion       int16  `json:"minVersion"`
+	MaxVersion       int16  `json:"maxVersion"`
+	FinalizedVersion *int16 `json:"finalizedVersion"`
+}
+
+// DescribeFeatures returns the features (KIP-584) reported in the ApiVersions response, sorted by name. The supported
+// versions are the ones of the broker that answered the request, the finalized versions apply to the whole cluster.
+// Brokers before Kafka 2.7 don't report any supported features, an *UnsupportedRequestError is returned for these.
+func (s *Service) DescribeFeatures(ctx context.Context) ([]FeatureVersion, error) {
+	res, err := s.GetAPIVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request api versions: %w", err)
+	}
+	if err := kerr.ErrorForCode(res.ErrorCode); err != nil {
+		return nil, fmt.Errorf("failed to request api versions. Inner kafka error: %w", err)
+	}
+	// Features are tagged fields of ApiVersions v3+, which brokers answer since Kafka 2.4 already. Brokers which support
+	// features always report at least one, hence no supported features means that the broker predates features.
+	if len(res.SupportedFeatures) == 0 {
+		return nil, &UnsupportedRequestError{RequestName: "DescribeFeatures"}
+	}
+
+	featuresByName := make(map[string]*FeatureVersion)
+	for _, supported := range res.SupportedFeatures {
+		featuresByName[supported.Name] = &FeatureVersion{
+			Name:       supported.Name,
+			MinVersion: supported.MinVersion,
+			MaxVersion: supported.MaxVersion,
+		}
+	}
+	for _, finalized := range res.FinalizedFeatures {
+		feature, exists := featuresByName[finalized.Name]
+		if !exists {
+			// Finalized, but not supported by this broker (anymore)
+			feature = &FeatureVersion{Name: finalized.Name, MinVersion: -1, MaxVersion: -1}
+			featuresByName[finalized.Name] = feature
+		}
+		finalizedVersion := finalized.MaxVersionLevel
+		feature.FinalizedVersion = &finalizedVersion
+	}
+
+	features := make([]FeatureVersion, 0, len(featuresByName))
+	for _, feature := range featuresByName {
+		features = append(features, *feature)
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+
+	return features, nil
+}
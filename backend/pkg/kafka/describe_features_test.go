@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestDescribeFeatures(t *testing.T) {
+	var res *kmsg.ApiVersionsResponse
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.ApiVersionsRequest); !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		return res, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	res = &kmsg.ApiVersionsResponse{
+		Version: 3,
+		SupportedFeatures: []kmsg.ApiVersionsResponseSupportedFeature{
+			{Name: "metadata.version", MinVersion: 1, MaxVersion: 7},
+			{Name: "kraft.version", MinVersion: 0, MaxVersion: 1},
+		},
+		FinalizedFeaturesEpoch: 5,
+		FinalizedFeatures: []kmsg.ApiVersionsResponseFinalizedFeature{
+			{Name: "metadata.version", MinVersionLevel: 1, MaxVersionLevel: 6},
+			{Name: "removed.feature", MinVersionLevel: 1, MaxVersionLevel: 1},
+		},
+	}
+	features, err := svc.DescribeFeatures(context.Background())
+	require.NoError(t, err)
+
+	metadataVersion, removedVersion := int16(6), int16(1)
+	assert.Equal(t, []FeatureVersion{
+		{Name: "kraft.version", MinVersion: 0, MaxVersion: 1},
+		{Name: "metadata.version", MinVersion: 1, MaxVersion: 7, FinalizedVersion: &metadataVersion},
+		{Name: "removed.feature", MinVersion: -1, MaxVersion: -1, FinalizedVersion: &removedVersion},
+	}, features)
+
+	// Brokers that predate features, they may still answer ApiVersions v3 without reporting any features
+	for _, version := range []int16{2, 3} {
+		res = &kmsg.ApiVersionsResponse{Version: version, FinalizedFeaturesEpoch: -1}
+		_, err = svc.DescribeFeatures(context.Background())
+		assert.True(t, errors.Is(err, ErrUnsupportedRequest), "expected unsupported request error for v%v, got: %v", version, err)
+	}
+}
@@ -0,0 +1,139 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// PartitionProducers contains the active producers of a single partition as reported by the partition leader. The
+// LeaderID is -1 if the partition has no leader or its metadata has an error.
+type PartitionProducers struct {
+	PartitionID int32            `json:"partitionId"`
+	LeaderID    int32            `json:"leaderId"`
+	Producers   []ActiveProducer `json:"producers"`
+
+	// Error is set if the producers of this partition could not be described
+	Error error `json:"-"`
+}
+
+// ActiveProducer is a producer which has written to a partition recently, that is its producer state has not expired
+// yet on the partition leader.
+type ActiveProducer struct {
+	ProducerID    int64 `json:"producerId"`
+	ProducerEpoch int32 `json:"producerEpoch"`
+	LastSequence  int32 `json:"lastSequence"`
+
+	// LastTimestamp is the unix timestamp in ms of the last record written by this producer
+	LastTimestamp    int64 `json:"lastTimestamp"`
+	CoordinatorEpoch int32 `json:"coordinatorEpoch"`
+
+	// CurrentTxnStartOffset is the first offset of the producer's ongoing transaction, -1 if there is none
+	CurrentTxnStartOffset int64 `json:"currentTxnStartOffset"`
+}
+
+// DescribeProducers returns the active producers of each partition of the given topic, sorted by partition ID. One
+// request is sent to each partition leader concurrently. Partitions whose leader could not be asked have their Error
+// set, an error is only returned if the metadata could not be fetched, the producers of no partition could be
+// described or if the cluster does not support describing producers (Kafka < v2.8), in which case the error is an
+// *UnsupportedRequestError.
+func (s *Service) DescribeProducers(ctx context.Context, topicName string) ([]PartitionProducers, error) {
+	if err := s.requireSupportedRequest(ctx, &kmsg.DescribeProducersRequest{}); err != nil {
+		return nil, err
+	}
+
+	partitionIDsByLeader, errByPartitionID, err := s.PartitionsByLeader(ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the partition leader knows the producer state of a partition
+	producers := make([]PartitionProducers, 0)
+	for partitionID, err := range errByPartitionID {
+		producers = append(producers, PartitionProducers{
+			PartitionID: partitionID,
+			LeaderID:    noLeaderID,
+			Error:       fmt.Errorf("partition metadata has an error: %w", err),
+		})
+	}
+	for _, partitionID := range partitionIDsByLeader[noLeaderID] {
+		producers = append(producers, PartitionProducers{
+			PartitionID: partitionID,
+			LeaderID:    noLeaderID,
+			Error:       fmt.Errorf("partition has no available leader: %w", kerr.LeaderNotAvailable),
+		})
+	}
+	delete(partitionIDsByLeader, noLeaderID)
+
+	resCh := make(chan []PartitionProducers, len(partitionIDsByLeader))
+	for leaderID, partitionIDs := range partitionIDsByLeader {
+		go func(leaderID int32, partitionIDs []int32) {
+			resCh <- s.describeProducersAtBroker(ctx, topicName, leaderID, partitionIDs)
+		}(leaderID, partitionIDs)
+	}
+	for i := 0; i < cap(resCh); i++ {
+		producers = append(producers, <-resCh...)
+	}
+
+	sort.Slice(producers, func(i, j int) bool { return producers[i].PartitionID < producers[j].PartitionID })
+
+	errs := make([]error, len(producers))
+	for i, partition := range producers {
+		errs[i] = partition.Error
+	}
+	if err := lastErrorIfAllFailed(errs); err != nil {
+		return producers, fmt.Errorf("failed to describe producers of all '%v' partitions, last error: %w", len(errs), err)
+	}
+
+	return producers, nil
+}
+
+// describeProducersAtBroker sends a single DescribeProducers request for the given partitions to their leader. A
+// failed request is reported as error on each of the partitions.
+func (s *Service) describeProducersAtBroker(ctx context.Context, topicName string, leaderID int32, partitionIDs []int32) []PartitionProducers {
+	topicReq := kmsg.NewDescribeProducersRequestTopic()
+	topicReq.Topic = topicName
+	topicReq.Partitions = partitionIDs
+	req := kmsg.NewDescribeProducersRequest()
+	req.Topics = []kmsg.DescribeProducersRequestTopic{topicReq}
+
+	producers := make([]PartitionProducers, 0, len(partitionIDs))
+	res, err := req.RequestWith(ctx, s.KafkaClient.ForBroker(leaderID))
+	if err != nil {
+		for _, partitionID := range partitionIDs {
+			producers = append(producers, PartitionProducers{
+				PartitionID: partitionID,
+				LeaderID:    leaderID,
+				Error:       fmt.Errorf("failed to describe producers at broker '%v': %w", leaderID, err),
+			})
+		}
+		return producers
+	}
+
+	for _, topic := range res.Topics {
+		for _, partition := range topic.Partitions {
+			activeProducers := make([]ActiveProducer, len(partition.ActiveProducers))
+			for i, producer := range partition.ActiveProducers {
+				activeProducers[i] = ActiveProducer{
+					ProducerID:            producer.ProducerID,
+					ProducerEpoch:         producer.ProducerEpoch,
+					LastSequence:          producer.LastSequence,
+					LastTimestamp:         producer.LastTimestamp,
+					CoordinatorEpoch:      producer.CoordinatorEpoch,
+					CurrentTxnStartOffset: producer.CurrentTxnStartOffset,
+				}
+			}
+			producers = append(producers, PartitionProducers{
+				PartitionID: partition.Partition,
+				LeaderID:    leaderID,
+				Producers:   activeProducers,
+				Error:       kerr.ErrorForCode(partition.ErrorCode),
+			})
+		}
+	}
+
+	return producers
+}
@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestDescribeProducers(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			return apiVersionsResponse(&kmsg.DescribeProducersRequest{}), nil
+		case *kmsg.MetadataRequest:
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{{
+				Topic: "orders",
+				Partitions: []kmsg.MetadataResponseTopicPartition{
+					{Partition: 0, Leader: 1},
+					{Partition: 1, Leader: 2},
+					{Partition: 2, Leader: 1},
+					{Partition: 3, Leader: -1, ErrorCode: kerr.LeaderNotAvailable.Code},
+				},
+			}}}, nil
+		case *kmsg.DescribeProducersRequest:
+			if brokerID == 2 {
+				return nil, errors.New("connection refused")
+			}
+			assert.ElementsMatch(t, []int32{0, 2}, typedReq.Topics[0].Partitions)
+			res := &kmsg.DescribeProducersResponse{Topics: []kmsg.DescribeProducersResponseTopic{{Topic: "orders"}}}
+			for _, partitionID := range typedReq.Topics[0].Partitions {
+				partition := kmsg.DescribeProducersResponseTopicPartition{Partition: partitionID}
+				if partitionID == 0 {
+					partition.ActiveProducers = []kmsg.DescribeProducersResponseTopicPartitionActiveProducer{
+						{ProducerID: 42, ProducerEpoch: 3, LastSequence: 17, LastTimestamp: 1620000000000, CurrentTxnStartOffset: -1},
+					}
+				}
+				res.Topics[0].Partitions = append(res.Topics[0].Partitions, partition)
+			}
+			return res, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	producers, err := svc.DescribeProducers(context.Background(), "orders")
+	require.NoError(t, err)
+	require.Len(t, producers, 4)
+
+	assert.Equal(t, PartitionProducers{
+		PartitionID: 0,
+		LeaderID:    1,
+		Producers:   []ActiveProducer{{ProducerID: 42, ProducerEpoch: 3, LastSequence: 17, LastTimestamp: 1620000000000, CurrentTxnStartOffset: -1}},
+	}, producers[0])
+	assert.Equal(t, int32(1), producers[1].PartitionID)
+	assert.EqualError(t, errors.Unwrap(producers[1].Error), "connection refused")
+	assert.Equal(t, PartitionProducers{PartitionID: 2, LeaderID: 1, Producers: []ActiveProducer{}}, producers[2])
+	assert.True(t, errors.Is(producers[3].Error, kerr.LeaderNotAvailable))
+}
+
+func TestDescribeProducers_Unsupported(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.ApiVersionsRequest); ok {
+			return apiVersionsResponse(&kmsg.MetadataRequest{}), nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	_, err := svc.DescribeProducers(context.Background(), "orders")
+	var unsupportedErr *UnsupportedRequestError
+	require.True(t, errors.As(err, &unsupportedErr), "expected unsupported request error, got: %v", err)
+	assert.Equal(t, "DescribeProducers", unsupportedErr.RequestName)
+}
@@ -0,0 +1,28 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// clusterMetadataTopic is the internal topic that contains the metadata log of KRaft clusters
+const clusterMetadataTopic = "__cluster_metadata"
+
+// DescribeQuorum describes the Raft quorum of the cluster metadata log. This is only supported by KRaft (ZooKeeper
+// less) clusters, other clusters return an *UnsupportedRequestError.
+func (s *Service) DescribeQuorum(ctx context.Context) (*kmsg.DescribeQuorumResponse, error) {
+	req := kmsg.NewDescribeQuorumRequest()
+	if err := s.requireSupportedRequest(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	partitionReq := kmsg.NewDescribeQuorumRequestTopicPartition()
+	partitionReq.Partition = 0
+	topicReq := kmsg.NewDescribeQuorumRequestTopic()
+	topicReq.Topic = clusterMetadataTopic
+	topicReq.Partitions = []kmsg.DescribeQuorumRequestTopicPartition{partitionReq}
+	req.Topics = []kmsg.DescribeQuorumRequestTopic{topicReq}
+
+	return req.RequestWith(ctx, s.KafkaClient)
+}
@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestService_DescribeQuorum(t *testing.T) {
+	var describedTopics []kmsg.DescribeQuorumRequestTopic
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			return apiVersionsResponse(kmsg.NewPtrDescribeQuorumRequest()), nil
+		case *kmsg.DescribeQuorumRequest:
+			describedTopics = typedReq.Topics
+			return &kmsg.DescribeQuorumResponse{}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{KafkaClient: client}
+
+	_, err := svc.DescribeQuorum(context.Background())
+	require.NoError(t, err)
+	require.Len(t, describedTopics, 1)
+	assert.Equal(t, clusterMetadataTopic, describedTopics[0].Topic)
+	require.Len(t, describedTopics[0].Partitions, 1)
+	assert.Equal(t, int32(0), describedTopics[0].Partitions[0].Partition)
+}
+
+func TestService_DescribeQuorum_ZooKeeperCluster(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.ApiVersionsRequest); ok {
+			return apiVersionsResponse(kmsg.NewPtrMetadataRequest()), nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{KafkaClient: client}
+
+	_, err := svc.DescribeQuorum(context.Background())
+	var unsupportedErr *UnsupportedRequestError
+	require.True(t, errors.As(err, &unsupportedErr))
+	assert.Equal(t, "DescribeQuorum", unsupportedErr.RequestName)
+}
@@ -3,21 +3,32 @@ package kafka
 import (
 	"context"
 	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
 	"go.uber.org/zap"
 )
 
-// DescribeTopicsConfigs fetches all topic config options for the given set of topic names and config names.
-// Use nil for configNames to fetch all configs.
-func (s *Service) DescribeTopicsConfigs(ctx context.Context, topicNames []string, configNames []string) (*kmsg.DescribeConfigsResponse, error) {
-	resources := make([]kmsg.DescribeConfigsRequestResource, len(topicNames))
-	for i, topicName := range topicNames {
+// DescribeTopicsConfigs fetches all topic config options for the given set of topic names and config names in a
+// single request. Use nil for configNames to fetch all configs. The described resources are returned by topic name.
+// Errors of a single topic (e.g. an unknown or invalid topic name) are reported via the resource's error code, so
+// that one bad topic does not fail the whole batch. An error is only returned if the request itself failed.
+func (s *Service) DescribeTopicsConfigs(ctx context.Context, topicNames []string, configNames []string) (map[string]kmsg.DescribeConfigsResponseResource, error) {
+	resources := make([]kmsg.DescribeConfigsRequestResource, 0, len(topicNames))
+	requested := make(map[string]struct{}, len(topicNames))
+	for _, topicName := range topicNames {
+		// Each topic must only be described once
+		if _, exists := requested[topicName]; exists {
+			continue
+		}
+		requested[topicName] = struct{}{}
+
 		r := kmsg.DescribeConfigsRequestResource{
 			ResourceType: kmsg.ConfigResourceTypeTopic,
 			ResourceName: topicName,
 			ConfigNames:  configNames,
 		}
-		resources[i] = r
+		resources = append(resources, r)
 	}
 
 	req := kmsg.NewDescribeConfigsRequest()
@@ -31,5 +42,27 @@ func (s *Service) DescribeTopicsConfigs(ctx context.Context, topicNames []string
 		return nil, fmt.Errorf("failed to request topic configs: %w", err)
 	}
 
-	return res, nil
+	resourcesByTopic := make(map[string]kmsg.DescribeConfigsResponseResource, len(requested))
+	for _, resource := range res.Resources {
+		if resource.ResourceType != kmsg.ConfigResourceTypeTopic {
+			continue
+		}
+		resourcesByTopic[resource.ResourceName] = resource
+	}
+
+	// Topics the broker did not respond with are reported as unknown server error
+	for topicName := range requested {
+		if _, exists := resourcesByTopic[topicName]; exists {
+			continue
+		}
+		missing := kmsg.NewDescribeConfigsResponseResource()
+		missing.ResourceType = kmsg.ConfigResourceTypeTopic
+		missing.ResourceName = topicName
+		missing.ErrorCode = kerr.UnknownServerError.Code
+		errMsg := "topic is missing in the describe configs response"
+		missing.ErrorMessage = &errMsg
+		resourcesByTopic[topicName] = missing
+	}
+
+	return resourcesByTopic, nil
 }
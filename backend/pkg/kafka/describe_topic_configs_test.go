@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestDescribeTopicsConfigs_MixedTopics(t *testing.T) {
+	requests := 0
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		describeReq, ok := req.(*kmsg.DescribeConfigsRequest)
+		if !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		requests++
+
+		res := &kmsg.DescribeConfigsResponse{}
+		for _, resource := range describeReq.Resources {
+			resourceRes := kmsg.NewDescribeConfigsResponseResource()
+			resourceRes.ResourceType = resource.ResourceType
+			resourceRes.ResourceName = resource.ResourceName
+			switch resource.ResourceName {
+			case "orders", "payments":
+				value := "delete"
+				resourceRes.Configs = []kmsg.DescribeConfigsResponseResourceConfig{{Name: "cleanup.policy", Value: &value}}
+			case "unknown":
+				resourceRes.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			case "in valid":
+				resourceRes.ErrorCode = kerr.InvalidTopicException.Code
+			case "dropped":
+				continue
+			}
+			res.Resources = append(res.Resources, resourceRes)
+		}
+		return res, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	topicNames := []string{"orders", "unknown", "in valid", "payments", "orders", "dropped"}
+	resources, err := svc.DescribeTopicsConfigs(context.Background(), topicNames, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	require.Len(t, resources, 5)
+
+	for _, topicName := range []string{"orders", "payments"} {
+		assert.Equal(t, int16(0), resources[topicName].ErrorCode, topicName)
+		require.Len(t, resources[topicName].Configs, 1, topicName)
+		assert.Equal(t, "cleanup.policy", resources[topicName].Configs[0].Name, topicName)
+	}
+	assert.Equal(t, kerr.UnknownTopicOrPartition.Code, resources["unknown"].ErrorCode)
+	assert.Equal(t, kerr.InvalidTopicException.Code, resources["in valid"].ErrorCode)
+	assert.Equal(t, kerr.UnknownServerError.Code, resources["dropped"].ErrorCode)
+}
@@ -0,0 +1,198 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"golang.org/x/sync/errgroup"
+)
+
+// TransactionDescription describes the state of a single transactional producer as reported by its transaction
+// coordinator.
+type TransactionDescription struct {
+	TransactionalID string `json:"transactionalId"`
+	CoordinatorID   int32  `json:"coordinatorId"`
+	State           string `json:"state"`
+	ProducerID      int64  `json:"producerId"`
+	ProducerEpoch   int16  `json:"producerEpoch"`
+	TimeoutMs       int32  `json:"timeoutMs"`
+
+	// StartTimestamp is the unix timestamp in ms when the ongoing transaction started, -1 if there is none
+	StartTimestamp int64 `json:"startTimestamp"`
+
+	// Topics contains the partitions that have been added to the ongoing transaction
+	Topics []TransactionTopic `json:"topics"`
+
+	// Error is set if the transaction could not be described, e.g. because the transactional ID is unknown
+	Error error `json:"-"`
+}
+
+// TransactionTopic contains the partitions of a topic which are part of a transaction.
+type TransactionTopic struct {
+	TopicName    string  `json:"topicName"`
+	PartitionIDs []int32 `json:"partitionIds"`
+}
+
+// TransactionListing is a transactional producer as returned by ListTransactions.
+type TransactionListing struct {
+	TransactionalID string `json:"transactionalId"`
+	BrokerID        int32  `json:"brokerId"`
+	ProducerID      int64  `json:"producerId"`
+	State           string `json:"state"`
+}
+
+// maxConcurrentDescribeTransactionsRequests limits the number of DescribeTransactions requests that are in flight at
+// the same time.
+const maxConcurrentDescribeTransactionsRequests = 10
+
+// DescribeTransactions describes the given transactional IDs. The IDs are bucketed by their transaction coordinator,
+// so that only one request per coordinator is sent. One description is returned for each transactional ID, sorted by
+// ID. Transactional IDs whose coordinator could not be found or described have their Error set. An error is returned
+// if the cluster does not support describing transactions (Kafka < v3.0) or if no transaction could be described.
+func (s *Service) DescribeTransactions(ctx context.Context, transactionalIDs []string) ([]TransactionDescription, error) {
+	if err := s.requireSupportedRequest(ctx, &kmsg.DescribeTransactionsRequest{}); err != nil {
+		return nil, err
+	}
+
+	descriptions := make([]TransactionDescription, 0, len(transactionalIDs))
+
+	// 1. Bucket transactional IDs by their coordinator
+	batches, coordinatorErrs := s.findCoordinatorsConcurrently(ctx, transactionalIDs, coordinatorTypeTransaction, maxConcurrentFindCoordinatorRequests)
+	for transactionalID, err := range coordinatorErrs {
+		descriptions = append(descriptions, TransactionDescription{
+			TransactionalID: transactionalID,
+			CoordinatorID:   -1,
+			Error:           fmt.Errorf("failed to find transaction coordinator: %w", err),
+		})
+	}
+
+	// 2. Describe all transactions at their coordinator
+	responses := make([][]TransactionDescription, len(batches))
+	semaphore := make(chan struct{}, maxConcurrentDescribeTransactionsRequests)
+	g, groupCtx := errgroup.WithContext(ctx)
+	for i, batch := range batches {
+		i, batch := i, batch
+		g.Go(func() error {
+			select {
+			case semaphore <- struct{}{}:
+			case <-groupCtx.Done():
+				return groupCtx.Err()
+			}
+			defer func() { <-semaphore }()
+
+			responses[i] = s.describeTransactionsAtBroker(groupCtx, batch)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to describe transactions: %w", err)
+	}
+	for _, res := range responses {
+		descriptions = append(descriptions, res...)
+	}
+
+	sort.Slice(descriptions, func(i, j int) bool { return descriptions[i].TransactionalID < descriptions[j].TransactionalID })
+
+	errs := make([]error, len(descriptions))
+	for i, description := range descriptions {
+		errs[i] = description.Error
+	}
+	if err := lastErrorIfAllFailed(errs); err != nil {
+		return descriptions, fmt.Errorf("all '%v' transactions could not be described, last error: %w", len(errs), err)
+	}
+
+	return descriptions, nil
+}
+
+// describeTransactionsAtBroker sends a single DescribeTransactions request to the batch's coordinator. A failed
+// request is reported as error on each of the batch's transactional IDs.
+func (s *Service) describeTransactionsAtBroker(ctx context.Context, batch coordinatorBatch) []TransactionDescription {
+	req := kmsg.NewDescribeTransactionsRequest()
+	req.TransactionalIDs = batch.Keys
+
+	descriptions := make([]TransactionDescription, 0, len(batch.Keys))
+	res, err := req.RequestWith(ctx, s.KafkaClient.ForBroker(batch.Coordinator.NodeID))
+	if err != nil {
+		s.coordinatorCache.Invalidate(coordinatorTypeTransaction, batch.Keys...)
+		for _, transactionalID := range batch.Keys {
+			descriptions = append(descriptions, TransactionDescription{
+				TransactionalID: transactionalID,
+				CoordinatorID:   batch.Coordinator.NodeID,
+				Error:           fmt.Errorf("failed to describe transactions at broker '%v': %w", batch.Coordinator.NodeID, err),
+			})
+		}
+		return descriptions
+	}
+
+	for _, state := range res.TransactionStates {
+		if isCoordinatorMovedError(kerr.ErrorForCode(state.ErrorCode)) {
+			s.coordinatorCache.Invalidate(coordinatorTypeTransaction, state.TransactionalID)
+		}
+		topics := make([]TransactionTopic, len(state.Topics))
+		for i, topic := range state.Topics {
+			topics[i] = TransactionTopic{TopicName: topic.Topic, PartitionIDs: topic.Partitions}
+		}
+		descriptions = append(descriptions, TransactionDescription{
+			TransactionalID: state.TransactionalID,
+			CoordinatorID:   batch.Coordinator.NodeID,
+			State:           state.State,
+			ProducerID:      state.ProducerID,
+			ProducerEpoch:   state.ProducerEpoch,
+			TimeoutMs:       state.TimeoutMillis,
+			StartTimestamp:  state.StartTimestamp,
+			Topics:          topics,
+			Error:           kerr.ErrorForCode(state.ErrorCode),
+		})
+	}
+
+	return descriptions
+}
+
+// ListTransactions returns the transactional producers of all brokers. If stateFilters is not empty only
+// transactions in one of the given states (e.g. "Ongoing") are returned. Failed broker requests are skipped, unless
+// all of them fail in which case an error is returned. An error is also returned if the cluster does not support
+// listing transactions (Kafka < v3.0).
+func (s *Service) ListTransactions(ctx context.Context, stateFilters []string) ([]TransactionListing, error) {
+	req := kmsg.NewListTransactionsRequest()
+	if err := s.requireSupportedRequest(ctx, &req); err != nil {
+		return nil, err
+	}
+	req.StateFilters = stateFilters
+	shardedResp := s.KafkaClient.RequestSharded(ctx, &req)
+
+	listings := make([]TransactionListing, 0)
+	errs := make([]error, len(shardedResp))
+	for i, kresp := range shardedResp {
+		err := kresp.Err
+		res, _ := kresp.Resp.(*kmsg.ListTransactionsResponse)
+		if err == nil && res == nil {
+			err = fmt.Errorf("unexpected response type %T", kresp.Resp)
+		}
+		if err == nil {
+			err = kerr.ErrorForCode(res.ErrorCode)
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		for _, state := range res.TransactionStates {
+			listings = append(listings, TransactionListing{
+				TransactionalID: state.TransactionalID,
+				BrokerID:        kresp.Meta.NodeID,
+				ProducerID:      state.ProducerID,
+				State:           state.TransactionState,
+			})
+		}
+	}
+	if err := lastErrorIfAllFailed(errs); err != nil {
+		return nil, fmt.Errorf("all '%v' requests have failed, last error: %w", len(errs), err)
+	}
+
+	sort.Slice(listings, func(i, j int) bool { return listings[i].TransactionalID < listings[j].TransactionalID })
+
+	return listings, nil
+}
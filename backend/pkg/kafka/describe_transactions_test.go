@@ -0,0 +1,125 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestDescribeTransactions(t *testing.T) {
+	coordinatorByID := map[string]int32{"tx-a": 1, "tx-b": 2, "tx-c": 1, "tx-unknown": 1}
+	describeRequests := make(map[int32][]string)
+	mutex := sync.Mutex{}
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			return apiVersionsResponse(&kmsg.DescribeTransactionsRequest{}), nil
+		case *kmsg.FindCoordinatorRequest:
+			if typedReq.CoordinatorType != coordinatorTypeTransaction {
+				return nil, errors.New("expected transaction coordinator type")
+			}
+			coordinatorID, exists := coordinatorByID[typedReq.CoordinatorKey]
+			if !exists {
+				return &kmsg.FindCoordinatorResponse{ErrorCode: kerr.CoordinatorNotAvailable.Code}, nil
+			}
+			return &kmsg.FindCoordinatorResponse{NodeID: coordinatorID}, nil
+		case *kmsg.DescribeTransactionsRequest:
+			mutex.Lock()
+			describeRequests[brokerID] = typedReq.TransactionalIDs
+			mutex.Unlock()
+			if brokerID == 2 {
+				return nil, errors.New("connection refused")
+			}
+			res := &kmsg.DescribeTransactionsResponse{}
+			for _, transactionalID := range typedReq.TransactionalIDs {
+				state := kmsg.DescribeTransactionsResponseTransactionState{
+					TransactionalID: transactionalID,
+					State:           "Ongoing",
+					ProducerID:      int64(len(transactionalID)),
+					Topics: []kmsg.DescribeTransactionsResponseTransactionStateTopic{
+						{Topic: "orders", Partitions: []int32{0, 2}},
+					},
+				}
+				if transactionalID == "tx-unknown" {
+					state = kmsg.DescribeTransactionsResponseTransactionState{
+						TransactionalID: transactionalID,
+						ErrorCode:       kerr.TransactionalIDNotFound.Code,
+					}
+				}
+				res.TransactionStates = append(res.TransactionStates, state)
+			}
+			return res, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	descriptions, err := svc.DescribeTransactions(context.Background(), []string{"tx-unknown", "tx-c", "tx-b", "tx-a", "tx-d"})
+	require.NoError(t, err)
+
+	// Transactional IDs are bucketed so that there is one request per coordinator
+	assert.Equal(t, map[int32][]string{1: {"tx-a", "tx-c", "tx-unknown"}, 2: {"tx-b"}}, describeRequests)
+
+	require.Len(t, descriptions, 5)
+	assert.Equal(t, TransactionDescription{
+		TransactionalID: "tx-a",
+		CoordinatorID:   1,
+		State:           "Ongoing",
+		ProducerID:      4,
+		Topics:          []TransactionTopic{{TopicName: "orders", PartitionIDs: []int32{0, 2}}},
+	}, descriptions[0])
+	assert.Equal(t, "tx-b", descriptions[1].TransactionalID)
+	assert.EqualError(t, errors.Unwrap(descriptions[1].Error), "connection refused")
+	assert.Equal(t, "tx-c", descriptions[2].TransactionalID)
+	assert.NoError(t, descriptions[2].Error)
+	assert.Equal(t, "tx-d", descriptions[3].TransactionalID)
+	assert.True(t, errors.Is(descriptions[3].Error, kerr.CoordinatorNotAvailable))
+	assert.Equal(t, "tx-unknown", descriptions[4].TransactionalID)
+	assert.True(t, errors.Is(descriptions[4].Error, kerr.TransactionalIDNotFound))
+}
+
+func TestListTransactions(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			return apiVersionsResponse(&kmsg.ListTransactionsRequest{}), nil
+		case *kmsg.ListTransactionsRequest:
+			assert.Equal(t, []string{"Ongoing"}, typedReq.StateFilters)
+			return &kmsg.ListTransactionsResponse{TransactionStates: []kmsg.ListTransactionsResponseTransactionState{
+				{TransactionalID: "tx-b", ProducerID: 2, TransactionState: "Ongoing"},
+				{TransactionalID: "tx-a", ProducerID: 1, TransactionState: "Ongoing"},
+			}}, nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	listings, err := svc.ListTransactions(context.Background(), []string{"Ongoing"})
+	require.NoError(t, err)
+	assert.Equal(t, []TransactionListing{
+		{TransactionalID: "tx-a", BrokerID: -1, ProducerID: 1, State: "Ongoing"},
+		{TransactionalID: "tx-b", BrokerID: -1, ProducerID: 2, State: "Ongoing"},
+	}, listings)
+}
+
+func TestDescribeTransactions_Unsupported(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.ApiVersionsRequest); ok {
+			return apiVersionsResponse(&kmsg.DescribeGroupsRequest{}), nil
+		}
+		return nil, unexpectedRequestError(brokerID, req)
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	_, err := svc.DescribeTransactions(context.Background(), []string{"tx-a"})
+	assert.True(t, errors.Is(err, ErrUnsupportedRequest), "expected unsupported request error, got: %v", err)
+	_, err = svc.ListTransactions(context.Background(), nil)
+	assert.True(t, errors.Is(err, ErrUnsupportedRequest), "expected unsupported request error, got: %v", err)
+}
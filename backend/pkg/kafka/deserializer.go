@@ -25,6 +25,19 @@ type deserializer struct {
 	SchemaService  *schema.Service
 	ProtoService   *proto.Service
 	MsgPackService *kmsgpack.Service
+
+	// TopicEncodings contains the forced key and value encodings by topic name. These are used instead of detecting
+	// the encoding automatically.
+	TopicEncodings map[string]topicEncodings
+
+	// avroConverters caches the converters which render decoded Avro records as JSON by schema ID
+	avroConverters *avroConverterCache
+}
+
+// topicEncodings are the forced encodings of a topic's keys and values. Empty encodings are detected automatically.
+type topicEncodings struct {
+	Key   messageEncoding
+	Value messageEncoding
 }
 
 type messageEncoding string
@@ -72,12 +85,37 @@ type deserializedPayload struct {
 	RecognizedEncoding messageEncoding `json:"encoding"`
 	SchemaID           uint32          `json:"schemaId"`
 	Size               int             `json:"size"` // number of 'raw' bytes
+
+	// FormattedPayload is the indented JSON representation with sorted object keys. It's only set if requested and
+	// if the payload could be converted to JSON.
+	FormattedPayload string `json:"formattedPayload,omitempty"`
+
+	// DeserializeError is set if the payload could not be decoded with the encoding that is forced for the topic. The
+	// payload is returned as binary content in this case.
+	DeserializeError string `json:"deserializeError,omitempty"`
 }
 
 type deserializedRecord struct {
-	Key     *deserializedPayload
-	Value   *deserializedPayload
-	Headers map[string]*deserializedPayload
+	Key   *deserializedPayload
+	Value *deserializedPayload
+	// Headers are in the order of the record's headers, including repeated headers
+	Headers []MessageHeader
+
+	KeyTruncated   bool
+	ValueTruncated bool
+}
+
+// deserializeOptions are per request options that control how records are deserialized.
+type deserializeOptions struct {
+	// MaxPayloadBytes limits the size of keys and values. Larger payloads are truncated and won't be deserialized.
+	// 0 means no limit.
+	MaxPayloadBytes int
+
+	// KeysOnly skips the deserialization of values. The record's value will be nil.
+	KeysOnly bool
+
+	// FormatJSON sets the FormattedPayload of keys and values that could be converted to JSON.
+	FormatJSON bool
 }
 
 // DeserializeRecord tries to deserialize a whole record.
@@ -86,26 +124,136 @@ type deserializedRecord struct {
 //  - UTF-8 Text
 //  - Binary content
 // Idea: Add encoding hint where user can suggest the backend to test this encoding first.
-func (d *deserializer) DeserializeRecord(record *kgo.Record) *deserializedRecord {
+func (d *deserializer) DeserializeRecord(record *kgo.Record, opts deserializeOptions) *deserializedRecord {
 	// 1. Test if it's a known binary Format
 	if record.Topic == "__consumer_offsets" {
 		rec, err := d.deserializeConsumerOffset(record)
 		if err == nil {
+			if opts.KeysOnly {
+				rec.Value = nil
+			}
 			return rec
 		}
 	}
 
-	headers := make(map[string]*deserializedPayload)
-	for _, header := range record.Headers {
-		headers[header.Key] = d.deserializePayload(header.Value, record.Topic, proto.RecordValue)
+	headers := make([]MessageHeader, len(record.Headers))
+	for i, header := range record.Headers {
+		headers[i] = MessageHeader{Key: header.Key, Value: d.deserializePayload(header.Value, record.Topic, proto.RecordValue)}
+	}
+	key, keyTruncated := d.deserializePayloadWithLimit(record.Key, record.Topic, proto.RecordKey, opts.MaxPayloadBytes)
+	var value *deserializedPayload
+	valueTruncated := false
+	if !opts.KeysOnly {
+		value, valueTruncated = d.deserializePayloadWithLimit(record.Value, record.Topic, proto.RecordValue, opts.MaxPayloadBytes)
+	}
+	if opts.FormatJSON {
+		setFormattedPayload(key)
+		setFormattedPayload(value)
 	}
 	return &deserializedRecord{
-		Key:     d.deserializePayload(record.Key, record.Topic, proto.RecordKey),
-		Value:   d.deserializePayload(record.Value, record.Topic, proto.RecordValue),
-		Headers: headers,
+		Key:            key,
+		Value:          value,
+		Headers:        headers,
+		KeyTruncated:   keyTruncated,
+		ValueTruncated: valueTruncated,
 	}
 }
 
+// setFormattedPayload sets the formatted payload for all encodings whose normalized payload is JSON
+func setFormattedPayload(payload *deserializedPayload) {
+	if payload == nil {
+		return
+	}
+	switch payload.RecognizedEncoding {
+	case messageEncodingJSON, messageEncodingXML, messageEncodingAvro, messageEncodingProtobuf, messageEncodingMsgP:
+	default:
+		return
+	}
+
+	formatted, err := formatJSON(payload.Payload.Payload)
+	if err != nil {
+		return
+	}
+	payload.FormattedPayload = string(formatted)
+}
+
+// formatJSON returns the given JSON indented and with sorted object keys. Numbers are kept in their original
+// representation, so that large integers and decimals don't lose precision due to a float64 conversion.
+func formatJSON(payload []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var obj interface{}
+	err := decoder.Decode(&obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode json: %w", err)
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// deserializePayloadWithLimit deserializes the payload unless it exceeds maxBytes. Payloads that exceed the limit are
+// truncated and returned as text or binary, because the truncated prefix of an encoded message can not be decoded.
+// The second return value indicates whether the payload has been truncated.
+func (d *deserializer) deserializePayloadWithLimit(payload []byte, topicName string, recordType proto.RecordPropertyType, maxBytes int) (*deserializedPayload, bool) {
+	if maxBytes <= 0 || len(payload) <= maxBytes {
+		if encoding := d.forcedEncoding(topicName, recordType); encoding != "" {
+			return d.deserializePayloadAs(payload, topicName, recordType, encoding), false
+		}
+		return d.deserializePayload(payload, topicName, recordType), false
+	}
+
+	return truncatePayload(payload, maxBytes), true
+}
+
+// forcedEncoding returns the encoding that is configured for the given topic's keys or values, or an empty encoding
+// if it shall be detected automatically.
+func (d *deserializer) forcedEncoding(topicName string, recordType proto.RecordPropertyType) messageEncoding {
+	encodings, exists := d.TopicEncodings[topicName]
+	if !exists {
+		return ""
+	}
+	if recordType == proto.RecordKey {
+		return encodings.Key
+	}
+	return encodings.Value
+}
+
+// truncatePayload returns the first maxBytes of the given payload. Size still reports the original payload size.
+func truncatePayload(payload []byte, maxBytes int) *deserializedPayload {
+	prefix := payload[:maxBytes]
+
+	// The limit may have been hit in the middle of a multi byte UTF-8 character, which would render the whole
+	// text invalid. Hence we cut off incomplete runes at the end before testing for UTF-8 validity.
+	trimmed := prefix
+	for i := 0; i < utf8.UTFMax && len(trimmed) > 0; i++ {
+		r, size := utf8.DecodeLastRune(trimmed)
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+		trimmed = trimmed[:len(trimmed)-1]
+	}
+	if utf8.Valid(trimmed) {
+		return &deserializedPayload{Payload: normalizedPayload{
+			Payload:            trimmed,
+			RecognizedEncoding: messageEncodingText,
+		}, Object: string(trimmed), RecognizedEncoding: messageEncodingText, Size: len(payload)}
+	}
+
+	return &deserializedPayload{Payload: normalizedPayload{
+		Payload:            prefix,
+		RecognizedEncoding: messageEncodingBinary,
+	}, Object: prefix, RecognizedEncoding: messageEncodingBinary, Size: len(payload)}
+}
+
 func (d *deserializer) deserializePayload(payload []byte, topicName string, recordType proto.RecordPropertyType) *deserializedPayload {
 	// 0. Check if payload is empty / whitespace only
 	if len(payload) == 0 {
@@ -126,102 +274,234 @@ func (d *deserializer) deserializePayload(payload []byte, topicName string, reco
 	// 1. Test for valid JSON
 	startsWithJSON := trimmed[0] == '[' || trimmed[0] == '{'
 	if startsWithJSON {
-		var obj interface{}
-		err := json.Unmarshal(payload, &obj)
+		deserialized, err := decodeJSON(payload)
 		if err == nil {
-			return &deserializedPayload{Payload: normalizedPayload{
-				Payload:            trimmed,
-				RecognizedEncoding: messageEncodingJSON,
-			}, Object: obj, RecognizedEncoding: messageEncodingJSON, Size: len(payload)}
+			return deserialized
 		}
 	}
 
 	// 2. Test for valid XML
 	startsWithXML := trimmed[0] == '<'
 	if startsWithXML {
-		r := strings.NewReader(string(trimmed))
-		jsonPayload, err := xj.Convert(r)
+		deserialized, err := decodeXML(payload)
 		if err == nil {
-			var obj interface{}
-			_ = json.Unmarshal(jsonPayload.Bytes(), &obj) // no err possible unless the xml2json package is buggy
-			return &deserializedPayload{Payload: normalizedPayload{
-				Payload:            jsonPayload.Bytes(),
-				RecognizedEncoding: messageEncodingXML,
-			}, Object: obj, RecognizedEncoding: messageEncodingXML, Size: len(payload)}
+			return deserialized
 		}
 	}
 
 	// 3. Test for Avro (reference: https://docs.confluent.io/current/schema-registry/serdes-develop/index.html#wire-format)
-	if d.SchemaService != nil && len(payload) > 5 {
-		// Check if magic byte is set
-		if payload[0] == byte(0) {
-			schemaID := binary.BigEndian.Uint32(payload[1:5])
-			codec, err := d.SchemaService.GetAvroSchemaByID(schemaID)
-			if err == nil {
-				native, _, err := codec.NativeFromBinary(payload[5:])
-				if err == nil {
-					normalized, _ := codec.TextualFromNative(nil, native)
-					return &deserializedPayload{
-						Payload: normalizedPayload{
-							Payload:            normalized,
-							RecognizedEncoding: messageEncodingAvro,
-						},
-						Object:             native,
-						RecognizedEncoding: messageEncodingAvro,
-						SchemaID:           schemaID,
-						Size:               len(payload),
-					}
-				}
-			}
+	// Payloads with the wire format header are very likely meant to be decoded with the schema registry, hence their
+	// decode errors are reported along with the fallback encoding below.
+	var schemaRegistryErrs []string
+	if d.SchemaService != nil {
+		deserialized, err := d.decodeAvro(payload)
+		if err == nil {
+			return deserialized
+		}
+		if hasSchemaRegistryHeader(payload) {
+			schemaRegistryErrs = append(schemaRegistryErrs, err.Error())
 		}
 	}
 
 	// 4. Test for Protobuf
 	if d.ProtoService != nil {
-		jsonBytes, schemaID, err := d.ProtoService.UnmarshalPayload(payload, topicName, recordType)
+		deserialized, err := d.decodeProtobuf(payload, topicName, recordType)
 		if err == nil {
-			var native interface{}
-			err := json.Unmarshal(jsonBytes, &native)
-			if err == nil {
-				return &deserializedPayload{
-					Payload: normalizedPayload{
-						Payload:            jsonBytes,
-						RecognizedEncoding: messageEncodingProtobuf,
-					},
-					Object:             native,
-					RecognizedEncoding: messageEncodingProtobuf,
-					SchemaID:           uint32(schemaID),
-					Size:               len(payload),
-				}
-			}
+			return deserialized
+		}
+		if hasSchemaRegistryHeader(payload) {
+			schemaRegistryErrs = append(schemaRegistryErrs, err.Error())
 		}
 	}
 
 	// 5. Test for MessagePack (only if enabled and topic allowed)
 	if d.MsgPackService != nil && d.MsgPackService.IsTopicAllowed(topicName) {
-		var obj interface{}
-		err := msgpack.Unmarshal(payload, &obj)
+		deserialized, err := decodeMsgPack(payload)
 		if err == nil {
-			data, err := json.Marshal(obj)
-			if err == nil {
-				return &deserializedPayload{Payload: normalizedPayload{
-					Payload:            data,
-					RecognizedEncoding: messageEncodingMsgP,
-				}, Object: string(payload), RecognizedEncoding: messageEncodingMsgP, Size: len(payload)}
-			}
+			return withSchemaRegistryErrors(deserialized, schemaRegistryErrs)
 		}
 	}
 
 	// 6. Test for UTF-8 validity
-	isUTF8 := utf8.Valid(payload)
-	if isUTF8 {
-		return &deserializedPayload{Payload: normalizedPayload{
-			Payload:            payload,
-			RecognizedEncoding: messageEncodingText,
-		}, Object: string(payload), RecognizedEncoding: messageEncodingText, Size: len(payload)}
+	deserialized, err := decodeText(payload)
+	if err == nil {
+		return withSchemaRegistryErrors(deserialized, schemaRegistryErrs)
 	}
 
 	// Anything else is considered as binary content
+	return withSchemaRegistryErrors(decodeBinary(payload), schemaRegistryErrs)
+}
+
+// hasSchemaRegistryHeader returns true if the payload starts with the magic byte and schema ID of the schema registry
+// wire format.
+func hasSchemaRegistryHeader(payload []byte) bool {
+	return len(payload) > 5 && payload[0] == byte(0)
+}
+
+// withSchemaRegistryErrors sets the DeserializeError of a payload which has been decoded with a fallback encoding
+// even though it starts with the schema registry wire format header, so that real decode failures (e.g. a deleted
+// schema or a payload that does not match its schema) don't go unnoticed.
+func withSchemaRegistryErrors(deserialized *deserializedPayload, schemaRegistryErrs []string) *deserializedPayload {
+	if len(schemaRegistryErrs) > 0 {
+		deserialized.DeserializeError = fmt.Sprintf("payload has the schema registry wire format header, but could not be decoded: %v",
+			strings.Join(schemaRegistryErrs, "; "))
+	}
+	return deserialized
+}
+
+// deserializePayloadAs deserializes the payload with the given encoding instead of detecting it. If the payload can
+// not be decoded with that encoding, it is returned as binary content along with the error, so that a wrongly
+// configured encoding does not go unnoticed.
+func (d *deserializer) deserializePayloadAs(payload []byte, topicName string, recordType proto.RecordPropertyType, encoding messageEncoding) *deserializedPayload {
+	if len(payload) == 0 {
+		return d.deserializePayload(payload, topicName, recordType)
+	}
+
+	var deserialized *deserializedPayload
+	var err error
+	switch encoding {
+	case messageEncodingJSON:
+		deserialized, err = decodeJSON(payload)
+	case messageEncodingXML:
+		deserialized, err = decodeXML(payload)
+	case messageEncodingAvro:
+		deserialized, err = d.decodeAvro(payload)
+	case messageEncodingProtobuf:
+		deserialized, err = d.decodeProtobuf(payload, topicName, recordType)
+	case messageEncodingMsgP:
+		deserialized, err = decodeMsgPack(payload)
+	case messageEncodingText:
+		deserialized, err = decodeText(payload)
+	case messageEncodingBinary:
+		deserialized = decodeBinary(payload)
+	default:
+		err = fmt.Errorf("unsupported encoding '%v'", encoding)
+	}
+	if err != nil {
+		deserialized = decodeBinary(payload)
+		deserialized.DeserializeError = fmt.Sprintf("failed to deserialize payload as %v: %v", encoding, err)
+	}
+
+	return deserialized
+}
+
+func decodeJSON(payload []byte) (*deserializedPayload, error) {
+	var obj interface{}
+	err := json.Unmarshal(payload, &obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+
+	return &deserializedPayload{Payload: normalizedPayload{
+		Payload:            bytes.TrimLeft(payload, " \t\r\n"),
+		RecognizedEncoding: messageEncodingJSON,
+	}, Object: obj, RecognizedEncoding: messageEncodingJSON, Size: len(payload)}, nil
+}
+
+func decodeXML(payload []byte) (*deserializedPayload, error) {
+	r := bytes.NewReader(bytes.TrimLeft(payload, " \t\r\n"))
+	jsonPayload, err := xj.Convert(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode xml: %w", err)
+	}
+
+	var obj interface{}
+	_ = json.Unmarshal(jsonPayload.Bytes(), &obj) // no err possible unless the xml2json package is buggy
+	return &deserializedPayload{Payload: normalizedPayload{
+		Payload:            jsonPayload.Bytes(),
+		RecognizedEncoding: messageEncodingXML,
+	}, Object: obj, RecognizedEncoding: messageEncodingXML, Size: len(payload)}, nil
+}
+
+func (d *deserializer) decodeAvro(payload []byte) (*deserializedPayload, error) {
+	if d.SchemaService == nil {
+		return nil, fmt.Errorf("schema registry is not configured")
+	}
+	// Check if magic byte is set
+	if !hasSchemaRegistryHeader(payload) {
+		return nil, fmt.Errorf("payload does not start with the schema registry wire format header")
+	}
+
+	schemaID := binary.BigEndian.Uint32(payload[1:5])
+	codec, err := d.SchemaService.GetAvroSchemaByID(schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get avro schema by id '%v': %w", schemaID, err)
+	}
+	native, _, err := codec.NativeFromBinary(payload[5:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode avro: %w", err)
+	}
+	normalized, err := d.avroConverters.get(schemaID, codec).textualFromNative(native)
+	if err != nil {
+		normalized, _ = codec.TextualFromNative(nil, native)
+	}
+	return &deserializedPayload{
+		Payload: normalizedPayload{
+			Payload:            normalized,
+			RecognizedEncoding: messageEncodingAvro,
+		},
+		Object:             native,
+		RecognizedEncoding: messageEncodingAvro,
+		SchemaID:           schemaID,
+		Size:               len(payload),
+	}, nil
+}
+
+func (d *deserializer) decodeProtobuf(payload []byte, topicName string, recordType proto.RecordPropertyType) (*deserializedPayload, error) {
+	if d.ProtoService == nil {
+		return nil, fmt.Errorf("protobuf deserializer is not configured")
+	}
+
+	jsonBytes, schemaID, err := d.ProtoService.UnmarshalPayload(payload, topicName, recordType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode protobuf: %w", err)
+	}
+	var native interface{}
+	err = json.Unmarshal(jsonBytes, &native)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode protobuf json: %w", err)
+	}
+	return &deserializedPayload{
+		Payload: normalizedPayload{
+			Payload:            jsonBytes,
+			RecognizedEncoding: messageEncodingProtobuf,
+		},
+		Object:             native,
+		RecognizedEncoding: messageEncodingProtobuf,
+		SchemaID:           uint32(schemaID),
+		Size:               len(payload),
+	}, nil
+}
+
+func decodeMsgPack(payload []byte) (*deserializedPayload, error) {
+	var obj interface{}
+	err := msgpack.Unmarshal(payload, &obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode msgpack: %w", err)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert msgpack to json: %w", err)
+	}
+
+	return &deserializedPayload{Payload: normalizedPayload{
+		Payload:            data,
+		RecognizedEncoding: messageEncodingMsgP,
+	}, Object: string(payload), RecognizedEncoding: messageEncodingMsgP, Size: len(payload)}, nil
+}
+
+func decodeText(payload []byte) (*deserializedPayload, error) {
+	if !utf8.Valid(payload) {
+		return nil, fmt.Errorf("payload is not valid utf-8")
+	}
+
+	return &deserializedPayload{Payload: normalizedPayload{
+		Payload:            payload,
+		RecognizedEncoding: messageEncodingText,
+	}, Object: string(payload), RecognizedEncoding: messageEncodingText, Size: len(payload)}, nil
+}
+
+func decodeBinary(payload []byte) *deserializedPayload {
 	return &deserializedPayload{Payload: normalizedPayload{
 		Payload:            payload,
 		RecognizedEncoding: messageEncodingBinary,
@@ -324,6 +604,6 @@ func (d *deserializer) deserializeConsumerOffset(record *kgo.Record) (*deseriali
 	return &deserializedRecord{
 		Key:     deserializedKey,
 		Value:   deserializedVal,
-		Headers: nil,
+		Headers: make([]MessageHeader, 0),
 	}, nil
 }
@@ -0,0 +1,175 @@
+package kafka
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/proto"
+	"github.com/cloudhut/kowl/backend/pkg/schema"
+)
+
+func TestDeserializer_DeserializePayloadWithLimit(t *testing.T) {
+	d := deserializer{}
+
+	tests := []struct {
+		name            string
+		payload         []byte
+		maxBytes        int
+		wantTruncated   bool
+		wantEncoding    messageEncoding
+		wantPayload     []byte
+		wantSizeInBytes int
+	}{
+		{name: "no limit", payload: []byte(`{"a":1}`), maxBytes: 0, wantTruncated: false, wantEncoding: messageEncodingJSON, wantPayload: []byte(`{"a":1}`), wantSizeInBytes: 7},
+		{name: "below limit", payload: []byte(`{"a":1}`), maxBytes: 7, wantTruncated: false, wantEncoding: messageEncodingJSON, wantPayload: []byte(`{"a":1}`), wantSizeInBytes: 7},
+		{name: "json above limit", payload: []byte(`{"a":1}`), maxBytes: 4, wantTruncated: true, wantEncoding: messageEncodingText, wantPayload: []byte(`{"a"`), wantSizeInBytes: 7},
+		{name: "cut multi byte rune", payload: []byte("abcä"), maxBytes: 4, wantTruncated: true, wantEncoding: messageEncodingText, wantPayload: []byte("abc"), wantSizeInBytes: 5},
+		{name: "binary above limit", payload: []byte{0xff, 0xfe, 0x00, 0xfd, 0xfc}, maxBytes: 3, wantTruncated: true, wantEncoding: messageEncodingBinary, wantPayload: []byte{0xff, 0xfe, 0x00}, wantSizeInBytes: 5},
+	}
+
+	for _, tc := range tests {
+		payload, isTruncated := d.deserializePayloadWithLimit(tc.payload, "test", proto.RecordValue, tc.maxBytes)
+		assert.Equal(t, tc.wantTruncated, isTruncated, tc.name)
+		assert.Equal(t, tc.wantEncoding, payload.RecognizedEncoding, tc.name)
+		assert.Equal(t, tc.wantPayload, payload.Payload.Payload, tc.name)
+		assert.Equal(t, tc.wantSizeInBytes, payload.Size, tc.name)
+	}
+}
+
+func TestDeserializer_KeysOnly(t *testing.T) {
+	d := deserializer{}
+	record := &kgo.Record{Topic: "orders", Key: []byte(`{"id":1}`), Value: []byte(`{"total":10}`)}
+
+	rec := d.DeserializeRecord(record, deserializeOptions{KeysOnly: true})
+	require.NotNil(t, rec.Key)
+	assert.Equal(t, messageEncodingJSON, rec.Key.RecognizedEncoding)
+	assert.Nil(t, rec.Value)
+	assert.False(t, rec.ValueTruncated)
+
+	// The worker still reports the size of the omitted values and whether they are tombstones
+	svc := &Service{Logger: zap.NewNop(), Deserializer: d}
+	isMessageOK := func(args interpreterArguments) (bool, error) { return true, nil }
+	jobs := make(chan *kgo.Record, 2)
+	jobs <- record
+	jobs <- &kgo.Record{Topic: "orders", Offset: 1, Key: []byte(`{"id":2}`), Value: nil}
+	close(jobs)
+	resultsCh := make(chan *TopicMessage, 2)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	svc.startMessageWorker(context.Background(), &wg, isMessageOK, deserializeOptions{KeysOnly: true}, map[int32]int32{}, nil, jobs, resultsCh)
+	close(resultsCh)
+
+	withValue := <-resultsCh
+	assert.Nil(t, withValue.Value)
+	assert.Equal(t, 12, withValue.ValueSize)
+	assert.False(t, withValue.IsValueNull)
+	assert.Equal(t, int64(20), withValue.MessageSize)
+
+	tombstone := <-resultsCh
+	assert.Nil(t, tombstone.Value)
+	assert.Equal(t, -1, tombstone.ValueSize)
+	assert.True(t, tombstone.IsValueNull)
+}
+
+func TestDeserializer_ForcedEncoding(t *testing.T) {
+	d := deserializer{TopicEncodings: map[string]topicEncodings{
+		"forced": {Key: messageEncodingText, Value: messageEncodingJSON},
+	}}
+
+	tests := []struct {
+		name         string
+		topicName    string
+		recordType   proto.RecordPropertyType
+		payload      []byte
+		wantEncoding messageEncoding
+		wantError    bool
+	}{
+		{name: "detected json", topicName: "detected", recordType: proto.RecordKey, payload: []byte(`{"a":1}`), wantEncoding: messageEncodingJSON},
+		{name: "forced text key", topicName: "forced", recordType: proto.RecordKey, payload: []byte(`{"a":1}`), wantEncoding: messageEncodingText},
+		{name: "forced json value", topicName: "forced", recordType: proto.RecordValue, payload: []byte(` {"a":1}`), wantEncoding: messageEncodingJSON},
+		{name: "invalid forced json value", topicName: "forced", recordType: proto.RecordValue, payload: []byte(`not json`), wantEncoding: messageEncodingBinary, wantError: true},
+		{name: "invalid forced text key", topicName: "forced", recordType: proto.RecordKey, payload: []byte{0xff, 0xfe}, wantEncoding: messageEncodingBinary, wantError: true},
+		{name: "empty forced value", topicName: "forced", recordType: proto.RecordValue, payload: []byte{}, wantEncoding: messageEncodingNone},
+	}
+
+	for _, tc := range tests {
+		payload, _ := d.deserializePayloadWithLimit(tc.payload, tc.topicName, tc.recordType, 0)
+		assert.Equal(t, tc.wantEncoding, payload.RecognizedEncoding, tc.name)
+		assert.Equal(t, tc.wantError, payload.DeserializeError != "", tc.name)
+		assert.Equal(t, len(tc.payload), payload.Size, tc.name)
+	}
+}
+
+func TestDeserializer_SchemaRegistryDecodeError(t *testing.T) {
+	// The schema registry only knows the schema with ID 1
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schemas/ids/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		w.Write([]byte(`{"schema":"{\"type\":\"record\",\"name\":\"Order\",\"fields\":[{\"name\":\"id\",\"type\":\"long\"}]}"}`))
+	}))
+	defer registry.Close()
+	schemaSvc, err := schema.NewService(schema.Config{Enabled: true, URLs: []string{registry.URL}}, zap.NewNop())
+	require.NoError(t, err)
+	d := deserializer{SchemaService: schemaSvc, avroConverters: newAvroConverterCache()}
+
+	tests := []struct {
+		name         string
+		payload      []byte
+		wantEncoding messageEncoding
+		wantError    bool
+	}{
+		{name: "valid avro", payload: []byte{0, 0, 0, 0, 1, 0x02}, wantEncoding: messageEncodingAvro},
+		{name: "corrupt avro", payload: []byte{0, 0, 0, 0, 1, 0xff}, wantEncoding: messageEncodingBinary, wantError: true},
+		{name: "unknown schema", payload: []byte{0, 0, 0, 0, 2, 0x02}, wantEncoding: messageEncodingText, wantError: true},
+		{name: "no wire format header", payload: []byte("plain text"), wantEncoding: messageEncodingText},
+	}
+
+	for _, tc := range tests {
+		payload, _ := d.deserializePayloadWithLimit(tc.payload, "orders", proto.RecordValue, 0)
+		assert.Equal(t, tc.wantEncoding, payload.RecognizedEncoding, tc.name)
+		assert.Equal(t, tc.wantError, payload.DeserializeError != "", tc.name)
+	}
+}
+
+func TestTopicEncodingConfig_Validate(t *testing.T) {
+	valid := TopicEncodingConfig{TopicName: "orders", KeyEncoding: "text", ValueEncoding: "avro"}
+	assert.NoError(t, valid.Validate())
+
+	onlyValue := TopicEncodingConfig{TopicName: "orders", ValueEncoding: "json"}
+	assert.NoError(t, onlyValue.Validate())
+
+	unknown := TopicEncodingConfig{TopicName: "orders", ValueEncoding: "yaml"}
+	assert.Error(t, unknown.Validate())
+
+	noTopic := TopicEncodingConfig{ValueEncoding: "json"}
+	assert.Error(t, noTopic.Validate())
+}
+
+func TestFormatJSON(t *testing.T) {
+	formatted, err := formatJSON([]byte(`{"z":{"b":true,"a":"<x>"},"id":9223372036854775807,"price":12345678901234567.891}`))
+	require.NoError(t, err)
+
+	expected := `{
+  "id": 9223372036854775807,
+  "price": 12345678901234567.891,
+  "z": {
+    "a": "<x>",
+    "b": true
+  }
+}`
+	assert.Equal(t, expected, string(formatted))
+
+	_, err = formatJSON([]byte("not json"))
+	assert.Error(t, err)
+}
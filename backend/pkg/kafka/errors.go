@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The following errors are returned, usually wrapped with further details, if a requested resource does not exist.
+// Use errors.Is() to distinguish them from other errors such as failed requests.
+var (
+	// ErrTopicNotFound is returned if a requested topic does not exist.
+	ErrTopicNotFound = errors.New("topic not found")
+
+	// ErrPartitionNotFound is returned if a requested partition does not exist in a topic.
+	ErrPartitionNotFound = errors.New("partition not found")
+
+	// ErrGroupNotFound is returned if a requested consumer group does not exist.
+	ErrGroupNotFound = errors.New("consumer group not found")
+
+	// ErrBrokerNotFound is returned if a requested broker is not part of the cluster.
+	ErrBrokerNotFound = errors.New("broker not found")
+)
+
+// ErrInvalidOffset is returned if a requested offset is not within the partition's watermarks.
+var ErrInvalidOffset = errors.New("invalid offset")
+
+// GroupError is the error of a single group in a response which may contain multiple groups, e.g. the
+// GroupAuthorizationFailed error if the principal is not allowed to describe the group. Use errors.As() to get the
+// group the error is attributed to and errors.Is() to check for a specific Kafka error.
+type GroupError struct {
+	GroupID string
+	Err     error
+}
+
+func (e *GroupError) Error() string {
+	return fmt.Sprintf("group '%v': %v", e.GroupID, e.Err)
+}
+
+func (e *GroupError) Unwrap() error {
+	return e.Err
+}
+
+// lastErrorIfAllFailed returns the last of the given errors if every single one is set, so that a response whose
+// items have all failed can be reported as failed request. Otherwise, including if there are no errors at all, nil is
+// returned and the errors are up to the individual items.
+func lastErrorIfAllFailed(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errs[len(errs)-1]
+}
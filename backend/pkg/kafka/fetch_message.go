@@ -0,0 +1,121 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// ErrMessageNotFound is returned if there is no record at the requested offset even though the offset is within the
+// partition's watermarks. This is the case for compacted topics or offsets that are occupied by control records.
+var ErrMessageNotFound = errors.New("message not found")
+
+// FetchMessage consumes exactly one record at the given offset and returns it deserialized. An error wrapping
+// ErrMessageNotFound is returned if there is no record at the offset, because it does not exist (anymore), it is a
+// control record or the offset is beyond the partition's high watermark. The partition's current leader epoch is
+// only reported with the message, -1 if unknown.
+func (s *Service) FetchMessage(ctx context.Context, topicName string, partitionID int32, offset int64, partitionLeaderEpoch int32) (*TopicMessage, error) {
+	client, err := s.NewKgoClient(clientIDOpts(ctx)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new kafka client: %w", err)
+	}
+	defer client.Close()
+
+	client.AssignPartitions(kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{
+		topicName: {partitionID: kgo.NewOffset().At(offset)},
+	}))
+
+	record, err := pollRecord(ctx, client, topicName, partitionID, offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFetchedRecord(partitionID, offset, record.Offset, record.Attrs.IsControl()); err != nil {
+		return nil, err
+	}
+
+	return s.decodeRecord(ctx, record, partitionLeaderEpoch)
+}
+
+// checkFetchedRecord returns an error wrapping ErrMessageNotFound if the fetched record is not the requested message
+func checkFetchedRecord(partitionID int32, offset int64, fetchedOffset int64, isControlRecord bool) error {
+	if fetchedOffset != offset {
+		// The consumer continues with the next available offset if the requested one does not exist (anymore)
+		return fmt.Errorf("%w: partition '%v' has no record at offset '%v'", ErrMessageNotFound, partitionID, offset)
+	}
+	if isControlRecord {
+		return fmt.Errorf("%w: offset '%v' is occupied by a control record", ErrMessageNotFound, offset)
+	}
+	return nil
+}
+
+// decodeRecord deserializes a single record. It reuses the message worker so that the message is decoded the very
+// same way as in ListMessages.
+func (s *Service) decodeRecord(ctx context.Context, record *kgo.Record, partitionLeaderEpoch int32) (*TopicMessage, error) {
+	jobs := make(chan *kgo.Record, 1)
+	resultsCh := make(chan *TopicMessage, 1)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	isMessageOK, _ := s.setupInterpreter("")
+	jobs <- record
+	close(jobs)
+	partitionLeaderEpochs := map[int32]int32{record.Partition: partitionLeaderEpoch}
+	s.startMessageWorker(ctx, &wg, isMessageOK, deserializeOptions{}, partitionLeaderEpochs, s.messageTransformers, jobs, resultsCh)
+
+	select {
+	case msg := <-resultsCh:
+		if !msg.IsMessageOk {
+			return nil, errors.New(msg.ErrorMessage)
+		}
+		return msg, nil
+	default:
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return nil, fmt.Errorf("%w: record at offset '%v' could not be decoded", ErrMessageNotFound, record.Offset)
+}
+
+// pollRecord polls until the first record of the given partition at or after the offset has been fetched. If the
+// partition's fetch contains no record, the consumer has reached the high watermark without finding a record at the
+// offset (e.g. because it has been compacted away or is a control record, which the client skips), hence an error
+// wrapping ErrMessageNotFound is returned instead of waiting for new records.
+func pollRecord(ctx context.Context, client *kgo.Client, topicName string, partitionID int32, offset int64) (*kgo.Record, error) {
+	for {
+		fetches := client.PollFetches(ctx)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		record, isFetched, err := findFetchedRecord(fetches, topicName, partitionID, offset)
+		if isFetched {
+			return record, err
+		}
+	}
+}
+
+// findFetchedRecord returns the first fetched record of the given partition. isFetched is false if the fetches do
+// not contain the partition, so that the caller has to poll again.
+func findFetchedRecord(fetches kgo.Fetches, topicName string, partitionID int32, offset int64) (record *kgo.Record, isFetched bool, err error) {
+	for _, fetchErr := range fetches.Errors() {
+		if fetchErr.Topic == topicName && fetchErr.Partition == partitionID {
+			return nil, true, fmt.Errorf("failed to fetch record: %w", fetchErr.Err)
+		}
+	}
+
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		if isFetched || p.Topic != topicName || p.Partition.Partition != partitionID {
+			return
+		}
+		isFetched = true
+		if len(p.Partition.Records) > 0 {
+			record = p.Partition.Records[0]
+			return
+		}
+		err = fmt.Errorf("%w: partition '%v' has no record at offset '%v', the high watermark is '%v'",
+			ErrMessageNotFound, partitionID, offset, p.Partition.HighWatermark)
+	})
+
+	return record, isFetched, err
+}
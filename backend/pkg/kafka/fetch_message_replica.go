@@ -0,0 +1,325 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// minFollowerFetchVersion is the first fetch version (KIP-392) which allows consumers to fetch from follower replicas
+const minFollowerFetchVersion int16 = 11
+
+// recordBatchHeaderSize is the size of a record batch (v2) without its records, including the leading first offset
+// and length fields
+const recordBatchHeaderSize = 61
+
+// maxDecompressedRecordsSize limits the size of the decompressed records of a single batch, so that a malicious or
+// corrupted batch can't exhaust the memory
+const maxDecompressedRecordsSize = 64 * 1024 * 1024
+
+// NotAReplicaError is returned if a message shall be fetched from a broker which does not host a replica of the
+// requested partition.
+type NotAReplicaError struct {
+	TopicName   string
+	PartitionID int32
+	BrokerID    int32
+	Replicas    []int32
+}
+
+func (e *NotAReplicaError) Error() string {
+	return fmt.Sprintf("broker '%v' is not a replica of topic '%v' partition '%v', replicas are: %v",
+		e.BrokerID, e.TopicName, e.PartitionID, e.Replicas)
+}
+
+// FetchMessageFromReplica fetches the record at the given offset directly from the given broker rather than the
+// partition leader. This can be used to check whether a follower is in sync, as it returns the follower's copy of the
+// data. Followers only serve records below the high water mark, a kafka.ErrMessageNotFound is returned if the
+// follower has no record at the offset (yet). An *NotAReplicaError is returned if the broker is not a replica of the
+// partition.
+func (s *Service) FetchMessageFromReplica(ctx context.Context, topicName string, partitionID int32, offset int64, brokerID int32) (*TopicMessage, error) {
+	versions, err := s.getClusterVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req := kmsg.NewPtrFetchRequest()
+	if maxVersion, isSupported := versions.LookupMaxKeyVersion(req.Key()); !isSupported || maxVersion < minFollowerFetchVersion {
+		return nil, &UnsupportedRequestError{RequestName: "Fetch (from follower)"}
+	}
+
+	topicMetadata, restErr := s.GetSingleMetadata(ctx, topicName)
+	if restErr != nil {
+		return nil, restErr.Err
+	}
+	err = checkIsReplica(topicMetadata, partitionID, brokerID)
+	if err != nil {
+		return nil, err
+	}
+
+	partitionReq := kmsg.NewFetchRequestTopicPartition()
+	partitionReq.Partition = partitionID
+	partitionReq.FetchOffset = offset
+	partitionReq.PartitionMaxBytes = 1024 * 1024 // Kafka returns the first batch even if it is larger than this
+	topicReq := kmsg.NewFetchRequestTopic()
+	topicReq.Topic = topicName
+	topicReq.Partitions = []kmsg.FetchRequestTopicPartition{partitionReq}
+	req.ReplicaID = -1
+	req.MaxWaitMillis = 500
+	req.MinBytes = 1
+	req.MaxBytes = 1024 * 1024
+	req.SessionEpoch = -1 // No fetch session
+	req.Topics = []kmsg.FetchRequestTopic{topicReq}
+
+	res, err := req.RequestWith(ctx, s.KafkaClient.ForBroker(brokerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from broker '%v': %w", brokerID, err)
+	}
+	err = kerr.ErrorForCode(res.ErrorCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from broker '%v'. Inner kafka error: %w", brokerID, err)
+	}
+
+	for _, topic := range res.Topics {
+		if topic.Topic != topicName {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			if partition.Partition != partitionID {
+				continue
+			}
+			err := kerr.ErrorForCode(partition.ErrorCode)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch from broker '%v'. Inner kafka error: %w", brokerID, err)
+			}
+
+			record, batchAttrs, err := findRecordInBatches(partition.RecordBatches, topicName, partitionID, offset)
+			if err != nil {
+				return nil, err
+			}
+			msg, err := s.decodeRecord(ctx, record, PartitionLeaderEpoch(topicMetadata, partitionID))
+			if err != nil {
+				return nil, err
+			}
+			// Compression and transaction flags are stored in the batch attributes, which can't be set on a kgo.Record
+			msg.Compression = compressionTypeDisplayname(uint8(batchAttrs & 0x07))
+			msg.IsTransactional = batchAttrs&0x10 != 0
+			return msg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("fetch response from broker '%v' did not contain the requested partition", brokerID)
+}
+
+// checkIsReplica returns a *NotAReplicaError if the broker is not one of the partition's replicas
+func checkIsReplica(topicMetadata kmsg.MetadataResponseTopic, partitionID int32, brokerID int32) error {
+	for _, partition := range topicMetadata.Partitions {
+		if partition.Partition != partitionID {
+			continue
+		}
+		for _, replica := range partition.Replicas {
+			if replica == brokerID {
+				return nil
+			}
+		}
+		return &NotAReplicaError{
+			TopicName:   topicMetadata.Topic,
+			PartitionID: partitionID,
+			BrokerID:    brokerID,
+			Replicas:    partition.Replicas,
+		}
+	}
+
+	err := ValidatePartitionID(topicMetadata.Topic, partitionID, int32(len(topicMetadata.Partitions)))
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("partition '%v' is missing in the metadata of topic '%v'", partitionID, topicMetadata.Topic)
+}
+
+// findRecordInBatches parses the record batches of a fetch response and returns the record at the given offset
+// along with the attributes of its batch.
+func findRecordInBatches(batches []byte, topicName string, partitionID int32, offset int64) (*kgo.Record, int16, error) {
+	for len(batches) > 0 {
+		// Each batch starts with its first offset (int64) and the length (int32) of the remaining batch
+		if len(batches) < 17 {
+			break
+		}
+		batchLength := 12 + int(int32(binary.BigEndian.Uint32(batches[8:12])))
+		if batchLength < recordBatchHeaderSize {
+			return nil, 0, fmt.Errorf("record batch length '%v' is shorter than the batch header", batchLength)
+		}
+		if batchLength > len(batches) {
+			// Brokers may return a partial batch at the end of the response
+			break
+		}
+		if magic := batches[16]; magic != 2 {
+			return nil, 0, fmt.Errorf("message format v%v is not supported, only record batches (v2) can be decoded", magic)
+		}
+
+		batch := kmsg.RecordBatch{}
+		err := batch.ReadFrom(batches[:batchLength])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read record batch: %w", err)
+		}
+		batches = batches[batchLength:]
+
+		if batch.FirstOffset+int64(batch.LastOffsetDelta) < offset {
+			continue
+		}
+		if batch.Attributes&0x20 != 0 {
+			return nil, 0, fmt.Errorf("%w: offset '%v' is occupied by a control record", ErrMessageNotFound, offset)
+		}
+
+		rawRecords, err := decompressRecords(batch.Records, byte(batch.Attributes&0x07))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decompress record batch: %w", err)
+		}
+		for i := int32(0); i < batch.NumRecords && len(rawRecords) > 0; i++ {
+			length, lengthSize := binary.Varint(rawRecords)
+			if lengthSize <= 0 || length < 0 || length > int64(len(rawRecords)-lengthSize) {
+				return nil, 0, fmt.Errorf("record batch contains a malformed record")
+			}
+			record := kmsg.Record{}
+			err := record.ReadFrom(rawRecords[:lengthSize+int(length)])
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to read record: %w", err)
+			}
+			rawRecords = rawRecords[lengthSize+int(length):]
+
+			if batch.FirstOffset+int64(record.OffsetDelta) != offset {
+				continue
+			}
+			return convertFetchedRecord(batch, record, topicName, partitionID), batch.Attributes, nil
+		}
+
+		// The batch covers the offset but it has no record for it, e.g. due to compaction
+		break
+	}
+
+	return nil, 0, fmt.Errorf("%w: replica has no record at offset '%v'", ErrMessageNotFound, offset)
+}
+
+func convertFetchedRecord(batch kmsg.RecordBatch, record kmsg.Record, topicName string, partitionID int32) *kgo.Record {
+	timestamp := batch.FirstTimestamp + int64(record.TimestampDelta)
+	if batch.Attributes&0x08 != 0 {
+		// Log append time is set as max timestamp of the batch
+		timestamp = batch.MaxTimestamp
+	}
+
+	headers := make([]kgo.RecordHeader, len(record.Headers))
+	for i, header := range record.Headers {
+		headers[i] = kgo.RecordHeader{Key: header.Key, Value: header.Value}
+	}
+
+	return &kgo.Record{
+		Key:           record.Key,
+		Value:         record.Value,
+		Headers:       headers,
+		Timestamp:     time.Unix(0, timestamp*int64(time.Millisecond)),
+		Topic:         topicName,
+		Partition:     partitionID,
+		ProducerEpoch: batch.ProducerEpoch,
+		ProducerID:    batch.ProducerID,
+		LeaderEpoch:   batch.PartitionLeaderEpoch,
+		Offset:        batch.FirstOffset + int64(record.OffsetDelta),
+	}
+}
+
+var xerialPrefix = []byte{130, 83, 78, 65, 80, 80, 89, 0}
+
+// decompressRecords decompresses the records of a batch with the codec from the batch attributes. An error is
+// returned if the decompressed records exceed maxDecompressedRecordsSize.
+func decompressRecords(src []byte, codec byte) ([]byte, error) {
+	switch codec {
+	case 0:
+		return src, nil
+	case 1:
+		reader, err := gzip.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		return readAllLimited(reader)
+	case 2:
+		if len(src) > 16 && bytes.HasPrefix(src, xerialPrefix) {
+			return xerialDecode(src)
+		}
+		return snappyDecodeLimited(src)
+	case 3:
+		return readAllLimited(lz4.NewReader(bytes.NewReader(src)))
+	case 4:
+		decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedRecordsSize))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return decoder.DecodeAll(src, nil)
+	default:
+		return nil, fmt.Errorf("unknown compression codec '%v'", codec)
+	}
+}
+
+// readAllLimited reads the reader until EOF, but at most maxDecompressedRecordsSize bytes
+func readAllLimited(reader io.Reader) ([]byte, error) {
+	decompressed, err := ioutil.ReadAll(io.LimitReader(reader, maxDecompressedRecordsSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedRecordsSize {
+		return nil, errDecompressedRecordsTooLarge
+	}
+	return decompressed, nil
+}
+
+// snappyDecodeLimited decodes a snappy block unless its decoded length exceeds maxDecompressedRecordsSize
+func snappyDecodeLimited(src []byte) ([]byte, error) {
+	decodedLength, err := snappy.DecodedLen(src)
+	if err != nil {
+		return nil, err
+	}
+	if decodedLength > maxDecompressedRecordsSize {
+		return nil, errDecompressedRecordsTooLarge
+	}
+	return snappy.Decode(nil, src)
+}
+
+var errDecompressedRecordsTooLarge = fmt.Errorf("decompressed records exceed the limit of %v bytes", maxDecompressedRecordsSize)
+
+// xerialDecode decodes snappy's xerial framing, which is used by some (older) Java producers
+func xerialDecode(src []byte) ([]byte, error) {
+	// 8 bytes header, 8 bytes version, followed by chunks with an uint32 size prefix
+	src = src[16:]
+	var dst []byte
+	for len(src) > 0 {
+		if len(src) < 4 {
+			return nil, errors.New("malformed xerial framing")
+		}
+		size := int(binary.BigEndian.Uint32(src))
+		src = src[4:]
+		if size > len(src) {
+			return nil, errors.New("malformed xerial framing")
+		}
+		chunk, err := snappyDecodeLimited(src[:size])
+		if err != nil {
+			return nil, err
+		}
+		if len(dst)+len(chunk) > maxDecompressedRecordsSize {
+			return nil, errDecompressedRecordsTooLarge
+		}
+		dst = append(dst, chunk...)
+		src = src[size:]
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,168 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// appendTestBatch appends a record batch with one record for each given value, starting at firstOffset
+func appendTestBatch(t *testing.T, dst []byte, firstOffset int64, attributes int16, values ...string) []byte {
+	var records []byte
+	for i, value := range values {
+		record := kmsg.Record{
+			TimestampDelta: int32(i),
+			OffsetDelta:    int32(i),
+			Key:            []byte("key"),
+			Value:          []byte(value),
+			Headers:        []kmsg.Header{{Key: "h", Value: []byte("v")}},
+		}
+		// Length is varint encoded, a zero length occupies exactly one byte
+		record.Length = int32(len(record.AppendTo(nil)) - 1)
+		records = record.AppendTo(records)
+	}
+
+	if attributes&0x07 == 1 {
+		buf := bytes.Buffer{}
+		writer := gzip.NewWriter(&buf)
+		_, err := writer.Write(records)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		records = buf.Bytes()
+	}
+
+	batch := kmsg.RecordBatch{
+		FirstOffset:     firstOffset,
+		Magic:           2,
+		Attributes:      attributes,
+		LastOffsetDelta: int32(len(values) - 1),
+		FirstTimestamp:  1620000000000,
+		NumRecords:      int32(len(values)),
+		Records:         records,
+	}
+	batch.Length = int32(len(batch.AppendTo(nil)) - 12)
+	return batch.AppendTo(dst)
+}
+
+func TestFindRecordInBatches(t *testing.T) {
+	batches := appendTestBatch(t, nil, 10, 0, "a", "b", "c")
+	batches = appendTestBatch(t, batches, 13, 1, "d", "e")
+	// Partial batch at the end of the response
+	partial := appendTestBatch(t, nil, 15, 0, "f")
+	batches = append(batches, partial[:len(partial)-3]...)
+
+	record, attrs, err := findRecordInBatches(batches, "orders", 2, 11)
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(record.Value))
+	assert.Equal(t, "key", string(record.Key))
+	assert.Equal(t, int64(11), record.Offset)
+	assert.Equal(t, int32(2), record.Partition)
+	assert.Equal(t, int64(1620000000001), record.Timestamp.UnixNano()/1e6)
+	assert.Equal(t, "v", string(record.Headers[0].Value))
+	assert.Equal(t, int16(0), attrs)
+
+	record, attrs, err = findRecordInBatches(batches, "orders", 2, 14)
+	require.NoError(t, err)
+	assert.Equal(t, "e", string(record.Value))
+	assert.Equal(t, int16(1), attrs)
+
+	_, _, err = findRecordInBatches(batches, "orders", 2, 15)
+	assert.True(t, errors.Is(err, ErrMessageNotFound))
+
+	control := appendTestBatch(t, nil, 20, 0x20, "")
+	_, _, err = findRecordInBatches(control, "orders", 2, 20)
+	assert.True(t, errors.Is(err, ErrMessageNotFound))
+}
+
+func TestFindRecordInBatches_Malformed(t *testing.T) {
+	batchWithRecords := func(records []byte) []byte {
+		batch := kmsg.RecordBatch{FirstOffset: 10, Magic: 2, NumRecords: 1, Records: records}
+		batch.Length = int32(len(batch.AppendTo(nil)) - 12)
+		return batch.AppendTo(nil)
+	}
+	varint := func(v int64) []byte {
+		buf := make([]byte, binary.MaxVarintLen64)
+		return buf[:binary.PutVarint(buf, v)]
+	}
+	withBatchLength := func(length int32) []byte {
+		batch := appendTestBatch(t, nil, 10, 0, "a")
+		binary.BigEndian.PutUint32(batch[8:12], uint32(length))
+		return batch
+	}
+
+	tests := []struct {
+		name    string
+		batches []byte
+	}{
+		{name: "negative batch length", batches: withBatchLength(-20)},
+		{name: "batch length shorter than header", batches: withBatchLength(10)},
+		{name: "negative record length", batches: batchWithRecords(append(varint(-5), make([]byte, 10)...))},
+		{name: "record length exceeds batch", batches: batchWithRecords(append(varint(100), make([]byte, 10)...))},
+		{name: "overflowing record length", batches: batchWithRecords(append(varint(math.MaxInt64), make([]byte, 10)...))},
+		{name: "truncated record length", batches: batchWithRecords([]byte{0xff})},
+	}
+
+	for _, tc := range tests {
+		_, _, err := findRecordInBatches(tc.batches, "orders", 0, 10)
+		assert.Error(t, err, tc.name)
+	}
+
+	// Truncating a valid response at any position must not panic
+	batches := appendTestBatch(t, nil, 10, 1, "a", "b")
+	for i := 0; i < len(batches); i++ {
+		assert.NotPanics(t, func() { _, _, _ = findRecordInBatches(batches[:i], "orders", 0, 11) }, i)
+	}
+}
+
+func TestDecompressRecords_Limit(t *testing.T) {
+	tooLarge := make([]byte, maxDecompressedRecordsSize+1)
+
+	buf := bytes.Buffer{}
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(tooLarge)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	_, err = decompressRecords(buf.Bytes(), 1)
+	assert.True(t, errors.Is(err, errDecompressedRecordsTooLarge))
+
+	// Snappy blocks start with their decoded length, the content is not needed to reject it
+	snappyHeader := make([]byte, binary.MaxVarintLen64)
+	snappyHeader = snappyHeader[:binary.PutUvarint(snappyHeader, maxDecompressedRecordsSize+1)]
+	_, err = decompressRecords(append(snappyHeader, 0), 2)
+	assert.True(t, errors.Is(err, errDecompressedRecordsTooLarge))
+
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	_, err = decompressRecords(encoder.EncodeAll(tooLarge, nil), 4)
+	assert.Error(t, err)
+
+	decompressed, err := decompressRecords(encoder.EncodeAll([]byte("records"), nil), 4)
+	require.NoError(t, err)
+	assert.Equal(t, "records", string(decompressed))
+}
+
+func TestCheckIsReplica(t *testing.T) {
+	metadata := kmsg.MetadataResponseTopic{
+		Topic: "orders",
+		Partitions: []kmsg.MetadataResponseTopicPartition{
+			{Partition: 0, Replicas: []int32{1, 2, 3}},
+		},
+	}
+
+	assert.NoError(t, checkIsReplica(metadata, 0, 2))
+
+	err := checkIsReplica(metadata, 0, 4)
+	var notAReplicaErr *NotAReplicaError
+	require.True(t, errors.As(err, &notAReplicaErr))
+	assert.Equal(t, []int32{1, 2, 3}, notAReplicaErr.Replicas)
+
+	assert.True(t, errors.Is(checkIsReplica(metadata, 1, 2), ErrPartitionNotFound))
+}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestCheckFetchedRecord(t *testing.T) {
+	tests := []struct {
+		name            string
+		fetchedOffset   int64
+		isControlRecord bool
+		wantErr         string
+	}{
+		{name: "requested record", fetchedOffset: 42},
+		{name: "compacted record", fetchedOffset: 45, wantErr: "partition '0' has no record at offset '42'"},
+		{name: "control record", fetchedOffset: 42, isControlRecord: true, wantErr: "offset '42' is occupied by a control record"},
+	}
+
+	for _, tc := range tests {
+		err := checkFetchedRecord(0, 42, tc.fetchedOffset, tc.isControlRecord)
+		if tc.wantErr == "" {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrMessageNotFound), tc.name)
+		assert.Contains(t, err.Error(), tc.wantErr, tc.name)
+	}
+}
+
+func TestFindFetchedRecord(t *testing.T) {
+	fetches := func(partitions ...kgo.FetchPartition) kgo.Fetches {
+		return kgo.Fetches{{Topics: []kgo.FetchTopic{{Topic: "orders", Partitions: partitions}}}}
+	}
+	record := &kgo.Record{Topic: "orders", Partition: 0, Offset: 45}
+
+	tests := []struct {
+		name          string
+		fetches       kgo.Fetches
+		wantRecord    *kgo.Record
+		wantIsFetched bool
+		wantErr       error
+	}{
+		{name: "no fetch", fetches: kgo.Fetches{}},
+		{name: "other partition", fetches: fetches(kgo.FetchPartition{Partition: 1, Records: []*kgo.Record{record}})},
+		{name: "record", fetches: fetches(kgo.FetchPartition{Partition: 0, Records: []*kgo.Record{record}}), wantRecord: record, wantIsFetched: true},
+		{name: "high watermark reached", fetches: fetches(kgo.FetchPartition{Partition: 0, HighWatermark: 43}), wantIsFetched: true, wantErr: ErrMessageNotFound},
+		{name: "fetch error", fetches: fetches(kgo.FetchPartition{Partition: 0, Err: kerr.OffsetOutOfRange}), wantIsFetched: true, wantErr: kerr.OffsetOutOfRange},
+	}
+
+	for _, tc := range tests {
+		fetchedRecord, isFetched, err := findFetchedRecord(tc.fetches, "orders", 0, 42)
+		assert.Equal(t, tc.wantRecord, fetchedRecord, tc.name)
+		assert.Equal(t, tc.wantIsFetched, isFetched, tc.name)
+		if tc.wantErr == nil {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.True(t, errors.Is(err, tc.wantErr), tc.name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Defaults for the fetch requests of consumers that browse messages. These are the values of the Kafka client, see
+// NewKgoConfig, and apply to all options that are not set explicitly.
+const (
+	DefaultFetchMinBytes int32 = 1
+	DefaultFetchMaxBytes int32 = 5 * 1000 * 1000 // 5MB
+	DefaultFetchMaxWait        = 5 * time.Second
+
+	// Low latency values are used with FetchOptions.LowLatency, so that brokers respond as soon as any data is
+	// available and do not hold back requests for long if there is none (e.g. when tailing a topic).
+	LowLatencyFetchMinBytes int32 = 1
+	LowLatencyFetchMaxBytes int32 = 5 * 1000 * 1000 // 5MB
+	LowLatencyFetchMaxWait        = 500 * time.Millisecond
+
+	// maxFetchMaxBytes keeps fetch responses below the client's max read bytes (100MiB)
+	maxFetchMaxBytes int32 = 50 << 20
+	maxFetchMaxWait        = 30 * time.Second
+)
+
+// ErrInvalidFetchOptions is returned if FetchOptions are invalid. Use errors.Is() to check for it.
+var ErrInvalidFetchOptions = errors.New("invalid fetch options")
+
+// FetchOptions tune the fetch requests which are sent while consuming messages. Zero values use the defaults, or the
+// low latency values if LowLatency is set.
+type FetchOptions struct {
+	// MinBytes is the number of bytes a broker waits for before it responds to a fetch request
+	MinBytes int32
+	// MaxBytes is the maximum number of bytes a broker returns in a single fetch response
+	MaxBytes int32
+	// MaxWait is the maximum time a broker waits for MinBytes before it responds anyways
+	MaxWait time.Duration
+
+	// LowLatency opts into the low latency values for interactive browsing instead of the defaults
+	LowLatency bool
+}
+
+func (o FetchOptions) withDefaults() FetchOptions {
+	minBytes, maxBytes, maxWait := DefaultFetchMinBytes, DefaultFetchMaxBytes, DefaultFetchMaxWait
+	if o.LowLatency {
+		minBytes, maxBytes, maxWait = LowLatencyFetchMinBytes, LowLatencyFetchMaxBytes, LowLatencyFetchMaxWait
+	}
+	if o.MinBytes == 0 {
+		o.MinBytes = minBytes
+	}
+	if o.MaxBytes == 0 {
+		o.MaxBytes = maxBytes
+	}
+	if o.MaxWait == 0 {
+		o.MaxWait = maxWait
+	}
+	return o
+}
+
+// Validate returns an error wrapping ErrInvalidFetchOptions if the options are out of range. maxMessageBytes is the
+// largest record batch the consumed topic accepts (its max.message.bytes config), 0 if unknown. An explicitly
+// configured MaxBytes must be able to hold such a batch, otherwise consuming large messages might stall on brokers
+// that strictly obey the fetch limit.
+func (o FetchOptions) Validate(maxMessageBytes int32) error {
+	if o.MinBytes < 0 || o.MaxBytes < 0 || o.MaxWait < 0 {
+		return fmt.Errorf("%w: fetch min bytes, max bytes and max wait must not be negative", ErrInvalidFetchOptions)
+	}
+	if o.MaxBytes > maxFetchMaxBytes {
+		return fmt.Errorf("%w: fetch max bytes must not exceed '%v' bytes", ErrInvalidFetchOptions, maxFetchMaxBytes)
+	}
+	if o.MaxWait > maxFetchMaxWait {
+		return fmt.Errorf("%w: fetch max wait must not exceed '%v'", ErrInvalidFetchOptions, maxFetchMaxWait)
+	}
+	if o.MaxBytes > 0 && maxMessageBytes > 0 && o.MaxBytes < maxMessageBytes {
+		return fmt.Errorf("%w: fetch max bytes ('%v') must be at least the topic's max message bytes ('%v'), so that it can hold every record",
+			ErrInvalidFetchOptions, o.MaxBytes, maxMessageBytes)
+	}
+
+	withDefaults := o.withDefaults()
+	if withDefaults.MinBytes > withDefaults.MaxBytes {
+		return fmt.Errorf("%w: fetch min bytes ('%v') must not be larger than max bytes ('%v')",
+			ErrInvalidFetchOptions, withDefaults.MinBytes, withDefaults.MaxBytes)
+	}
+
+	return nil
+}
+
+// kgoOpts returns the consumer options that apply the fetch options along with the defaults
+func (o FetchOptions) kgoOpts() []kgo.Opt {
+	o = o.withDefaults()
+	return []kgo.Opt{
+		kgo.FetchMinBytes(o.MinBytes),
+		kgo.FetchMaxBytes(o.MaxBytes),
+		kgo.FetchMaxWait(o.MaxWait),
+	}
+}
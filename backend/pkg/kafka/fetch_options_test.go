@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name            string
+		options         FetchOptions
+		maxMessageBytes int32
+		valid           bool
+	}{
+		{name: "defaults", options: FetchOptions{}, valid: true},
+		{name: "low latency", options: FetchOptions{LowLatency: true}, valid: true},
+		{name: "throughput", options: FetchOptions{MinBytes: 1 << 20, MaxBytes: 20 << 20, MaxWait: 2 * time.Second}, maxMessageBytes: 1048588, valid: true},
+		{name: "negative min bytes", options: FetchOptions{MinBytes: -1}, valid: false},
+		{name: "negative max wait", options: FetchOptions{MaxWait: -time.Second}, valid: false},
+		{name: "max wait too long", options: FetchOptions{MaxWait: time.Minute}, valid: false},
+		{name: "max bytes too large", options: FetchOptions{MaxBytes: 100 << 20}, valid: false},
+		{name: "min bytes larger than default max bytes", options: FetchOptions{MinBytes: 10 << 20}, valid: false},
+		{name: "min bytes larger than low latency max bytes", options: FetchOptions{MinBytes: 10 << 20, LowLatency: true}, valid: false},
+		{name: "max bytes smaller than max message bytes", options: FetchOptions{MaxBytes: 1000}, maxMessageBytes: 1048588, valid: false},
+		{name: "max message bytes unknown", options: FetchOptions{MaxBytes: 1000}, maxMessageBytes: 0, valid: true},
+	}
+
+	for _, tc := range tests {
+		err := tc.options.Validate(tc.maxMessageBytes)
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.True(t, errors.Is(err, ErrInvalidFetchOptions), "%v: expected invalid fetch options error, got: %v", tc.name, err)
+		}
+	}
+}
+
+func TestFetchOptions_withDefaults(t *testing.T) {
+	// Options which are not set keep the client's previous defaults unless the low latency values are opted into
+	assert.Equal(t, FetchOptions{MinBytes: 1, MaxBytes: 5 * 1000 * 1000, MaxWait: 5 * time.Second}, FetchOptions{}.withDefaults())
+	assert.Equal(t, FetchOptions{MinBytes: 1, MaxBytes: 5 * 1000 * 1000, MaxWait: 500 * time.Millisecond, LowLatency: true},
+		FetchOptions{LowLatency: true}.withDefaults())
+	assert.Equal(t, FetchOptions{MinBytes: 1, MaxBytes: 5 * 1000 * 1000, MaxWait: 2 * time.Second, LowLatency: true},
+		FetchOptions{MaxWait: 2 * time.Second, LowLatency: true}.withDefaults())
+}
@@ -2,11 +2,14 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/cloudhut/common/rest"
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
 	"net/http"
+	"time"
 )
 
 // GetMetadata returns some generic information about the brokers in the given cluster
@@ -30,29 +33,12 @@ func (s *Service) GetMetadata(ctx context.Context, topics []string) (*kmsg.Metad
 	return req.RequestWith(ctx, s.KafkaClient)
 }
 
+// GetSingleMetadata returns the metadata of a single topic. The returned error has status 404 and wraps
+// ErrTopicNotFound if the topic does not exist.
 func (s *Service) GetSingleMetadata(ctx context.Context, topic string) (kmsg.MetadataResponseTopic, *rest.Error) {
-	metadata, err := s.GetMetadata(ctx, []string{topic})
+	topicMetadata, err := s.getTopicMetadata(ctx, topic)
 	if err != nil {
-		return kmsg.MetadataResponseTopic{}, &rest.Error{
-			Err:     err,
-			Status:  http.StatusInternalServerError,
-			Message: fmt.Sprintf("Failed to request metadata for topic '%v': %v", topic, err.Error()),
-		}
-	}
-	if len(metadata.Topics) != 1 {
-		customErr := fmt.Errorf("expected just one topic metadata result, but got '%v'", len(metadata.Topics))
-		return kmsg.MetadataResponseTopic{}, &rest.Error{
-			Err:     customErr,
-			Status:  http.StatusInternalServerError,
-			Message: fmt.Sprintf("Failed to request metadata for topic '%v': %v", topic, customErr.Error()),
-		}
-	}
-
-	topicMetadata := metadata.Topics[0]
-	err = kerr.ErrorForCode(topicMetadata.ErrorCode)
-	if err != nil {
-		// If topic does not exist return a 404 error
-		if topicMetadata.ErrorCode == kerr.UnknownTopicOrPartition.Code {
+		if errors.Is(err, ErrTopicNotFound) {
 			return kmsg.MetadataResponseTopic{}, &rest.Error{
 				Err:     err,
 				Status:  http.StatusNotFound,
@@ -69,3 +55,66 @@ func (s *Service) GetSingleMetadata(ctx context.Context, topic string) (kmsg.Met
 
 	return topicMetadata, nil
 }
+
+// topicMetadataRetryBackoff is the time we wait before requesting the metadata of an unknown topic once more
+const topicMetadataRetryBackoff = 200 * time.Millisecond
+
+// getTopicMetadata requests the metadata of a single topic. If the topic is reported as unknown, the metadata is
+// refreshed and requested once more after a short backoff, because a broker's metadata might be stale (e.g. right
+// after the topic has been created). An error wrapping ErrTopicNotFound is returned if the topic does not exist in
+// both responses.
+func (s *Service) getTopicMetadata(ctx context.Context, topic string) (kmsg.MetadataResponseTopic, error) {
+	var topicMetadata kmsg.MetadataResponseTopic
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			if err := s.RefreshMetadata(ctx); err != nil {
+				s.Logger.Debug("failed to refresh metadata before retrying an unknown topic", zap.Error(err))
+			}
+			select {
+			case <-time.After(topicMetadataRetryBackoff):
+			case <-ctx.Done():
+				return kmsg.MetadataResponseTopic{}, ctx.Err()
+			}
+		}
+
+		metadata, err := s.GetMetadata(ctx, []string{topic})
+		if err != nil {
+			return kmsg.MetadataResponseTopic{}, fmt.Errorf("failed to request metadata: %w", err)
+		}
+		if len(metadata.Topics) != 1 {
+			return kmsg.MetadataResponseTopic{}, fmt.Errorf("expected just one topic metadata result, but got '%v'", len(metadata.Topics))
+		}
+
+		topicMetadata = metadata.Topics[0]
+		if topicMetadata.ErrorCode != kerr.UnknownTopicOrPartition.Code {
+			break
+		}
+	}
+
+	err := kerr.ErrorForCode(topicMetadata.ErrorCode)
+	if err != nil {
+		if topicMetadata.ErrorCode == kerr.UnknownTopicOrPartition.Code {
+			return kmsg.MetadataResponseTopic{}, fmt.Errorf("%w: %v", ErrTopicNotFound, topic)
+		}
+		return kmsg.MetadataResponseTopic{}, fmt.Errorf("failed to get topic metadata. Inner kafka error: %w", err)
+	}
+
+	return topicMetadata, nil
+}
+
+// requireBrokerExists returns an error wrapping ErrBrokerNotFound if the given broker is not part of the cluster
+func (s *Service) requireBrokerExists(ctx context.Context, brokerID int32) error {
+	req := kmsg.NewMetadataRequest()
+	req.Topics = []kmsg.MetadataRequestTopic{}
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return fmt.Errorf("failed to request metadata: %w", err)
+	}
+	for _, broker := range res.Brokers {
+		if broker.NodeID == brokerID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: broker '%v' is not part of the cluster", ErrBrokerNotFound, brokerID)
+}
@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestRequireBrokerExists(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.MetadataRequest); !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		return &kmsg.MetadataResponse{Brokers: []kmsg.MetadataResponseBroker{{NodeID: 0}, {NodeID: 1}}}, nil
+	}}
+	svc := &Service{KafkaClient: client}
+
+	assert.NoError(t, svc.requireBrokerExists(context.Background(), 1))
+
+	err := svc.requireBrokerExists(context.Background(), 2)
+	assert.True(t, errors.Is(err, ErrBrokerNotFound), "expected broker not found error, got: %v", err)
+
+	_, err = svc.GetBrokerAPIVersions(context.Background(), 2)
+	assert.True(t, errors.Is(err, ErrBrokerNotFound), "expected broker not found error, got: %v", err)
+}
+
+func TestService_getTopicMetadata_Retry(t *testing.T) {
+	newClient := func(unknownResponses int, requestedTopics *[]string) *mockKafkaClient {
+		return &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+			metadataReq, ok := req.(*kmsg.MetadataRequest)
+			if !ok {
+				return nil, unexpectedRequestError(brokerID, req)
+			}
+			if len(metadataReq.Topics) == 0 {
+				*requestedTopics = append(*requestedTopics, "<refresh>")
+				return &kmsg.MetadataResponse{}, nil
+			}
+			*requestedTopics = append(*requestedTopics, *metadataReq.Topics[0].Topic)
+			topic := kmsg.MetadataResponseTopic{Topic: *metadataReq.Topics[0].Topic}
+			if unknownResponses > 0 {
+				unknownResponses--
+				topic.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			}
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{topic}}, nil
+		}}
+	}
+
+	// A stale broker which doesn't know the topic yet triggers a refresh and a delayed retry
+	var requestedTopics []string
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: newClient(1, &requestedTopics)}
+	startedAt := time.Now()
+	topic, err := svc.getTopicMetadata(context.Background(), "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "orders", topic.Topic)
+	assert.Equal(t, []string{"orders", "<refresh>", "orders"}, requestedTopics)
+	assert.GreaterOrEqual(t, int64(time.Since(startedAt)), int64(topicMetadataRetryBackoff))
+
+	// The topic is only reported as not found if the retry doesn't know it either
+	requestedTopics = nil
+	svc = &Service{Logger: zap.NewNop(), KafkaClient: newClient(2, &requestedTopics)}
+	_, err = svc.getTopicMetadata(context.Background(), "orders")
+	assert.True(t, errors.Is(err, ErrTopicNotFound))
+	assert.Equal(t, []string{"orders", "<refresh>", "orders"}, requestedTopics)
+
+	// Known topics are not retried
+	requestedTopics = nil
+	svc = &Service{Logger: zap.NewNop(), KafkaClient: newClient(0, &requestedTopics)}
+	_, err = svc.getTopicMetadata(context.Background(), "orders")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders"}, requestedTopics)
+
+	// The backoff is cut short if the context is done
+	requestedTopics = nil
+	svc = &Service{Logger: zap.NewNop(), KafkaClient: newClient(1, &requestedTopics)}
+	ctx, cancel := context.WithTimeout(context.Background(), topicMetadataRetryBackoff/4)
+	defer cancel()
+	_, err = svc.getTopicMetadata(ctx, "orders")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
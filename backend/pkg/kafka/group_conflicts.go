@@ -0,0 +1,59 @@
+package kafka
+
+import (
+	"sort"
+)
+
+// GroupConflict is a group ID which has been listed with more than one protocol type, e.g. because a consumer group
+// and a Kafka Connect cluster use the same group ID. Group IDs are unique in a cluster, so either one of them has
+// replaced the other (and the listing was taken while the group changed) or the coordinator moved and two brokers
+// report the group differently. Either way operators are likely confused by what the group shows.
+type GroupConflict struct {
+	GroupID string `json:"groupId"`
+
+	// ProtocolTypes are the distinct, sorted protocol types the group has been listed with. Groups which only
+	// commit offsets have an empty protocol type.
+	ProtocolTypes []string `json:"protocolTypes"`
+
+	// CoordinatorIDs are the distinct, sorted IDs of the brokers which listed the group
+	CoordinatorIDs []int32 `json:"coordinatorIds"`
+}
+
+// findGroupConflicts aggregates the listed groups by ID and returns the IDs with multiple protocol types, sorted by
+// group ID
+func findGroupConflicts(groups []ListedGroup) []GroupConflict {
+	protocolTypesByGroup := make(map[string]map[string]struct{})
+	coordinatorsByGroup := make(map[string]map[int32]struct{})
+	for _, group := range groups {
+		if _, exists := protocolTypesByGroup[group.GroupID]; !exists {
+			protocolTypesByGroup[group.GroupID] = make(map[string]struct{})
+			coordinatorsByGroup[group.GroupID] = make(map[int32]struct{})
+		}
+		protocolTypesByGroup[group.GroupID][group.ProtocolType] = struct{}{}
+		coordinatorsByGroup[group.GroupID][group.CoordinatorID] = struct{}{}
+	}
+
+	conflicts := make([]GroupConflict, 0)
+	for groupID, protocolTypes := range protocolTypesByGroup {
+		if len(protocolTypes) < 2 {
+			continue
+		}
+		conflict := GroupConflict{
+			GroupID:        groupID,
+			ProtocolTypes:  make([]string, 0, len(protocolTypes)),
+			CoordinatorIDs: make([]int32, 0, len(coordinatorsByGroup[groupID])),
+		}
+		for protocolType := range protocolTypes {
+			conflict.ProtocolTypes = append(conflict.ProtocolTypes, protocolType)
+		}
+		for coordinatorID := range coordinatorsByGroup[groupID] {
+			conflict.CoordinatorIDs = append(conflict.CoordinatorIDs, coordinatorID)
+		}
+		sort.Strings(conflict.ProtocolTypes)
+		sort.Slice(conflict.CoordinatorIDs, func(i, j int) bool { return conflict.CoordinatorIDs[i] < conflict.CoordinatorIDs[j] })
+		conflicts = append(conflicts, conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].GroupID < conflicts[j].GroupID })
+
+	return conflicts
+}
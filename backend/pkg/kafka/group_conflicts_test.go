@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestFindGroupConflicts(t *testing.T) {
+	groups := []ListedGroup{
+		{GroupID: "orders", ProtocolType: "consumer", CoordinatorID: 2},
+		{GroupID: "orders", ProtocolType: "connect", CoordinatorID: 1},
+		{GroupID: "orders", ProtocolType: "consumer", CoordinatorID: 1},
+		{GroupID: "payments", ProtocolType: "consumer", CoordinatorID: 1},
+		{GroupID: "payments", ProtocolType: "consumer", CoordinatorID: 3}, // same type on two brokers is no conflict
+		{GroupID: "archiver", ProtocolType: "", CoordinatorID: 3},
+		{GroupID: "archiver", ProtocolType: "consumer", CoordinatorID: 3},
+	}
+
+	assert.Equal(t, []GroupConflict{
+		{GroupID: "archiver", ProtocolTypes: []string{"", "consumer"}, CoordinatorIDs: []int32{3}},
+		{GroupID: "orders", ProtocolTypes: []string{"connect", "consumer"}, CoordinatorIDs: []int32{1, 2}},
+	}, findGroupConflicts(groups))
+	assert.Empty(t, findGroupConflicts(nil))
+}
+
+func TestListConsumerGroups_Conflicts(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		return &kmsg.ListGroupsResponse{Groups: []kmsg.ListGroupsResponseGroup{
+			{Group: "orders", ProtocolType: "consumer"},
+			{Group: "orders", ProtocolType: "connect"},
+			{Group: "payments", ProtocolType: "consumer"},
+		}}, nil
+	}}
+	svc := &Service{KafkaClient: client}
+
+	// Conflicts are reported even if only one of the conflicting types is listed
+	listed, err := svc.ListConsumerGroupsWithOptions(context.Background(), ListConsumerGroupsOptions{Types: []GroupType{GroupTypeClassic}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders", "payments"}, listed.GetGroupIDs())
+	require.Len(t, listed.Conflicts, 1)
+	assert.Equal(t, "orders", listed.Conflicts[0].GroupID)
+	assert.Equal(t, []string{"connect", "consumer"}, listed.Conflicts[0].ProtocolTypes)
+}
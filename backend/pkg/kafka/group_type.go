@@ -0,0 +1,48 @@
+package kafka
+
+// GroupType is the kind of a group, which determines the protocol its members use to coordinate
+type GroupType string
+
+const (
+	// GroupTypeClassic are consumer groups which use the classic rebalance protocol that runs in the clients, as
+	// well as groups without members which only commit offsets (e.g. for manually assigned partitions)
+	GroupTypeClassic GroupType = "classic"
+	// GroupTypeConsumer are consumer groups which use the consumer rebalance protocol of KIP-848, where the group
+	// coordinator computes the assignments
+	GroupTypeConsumer GroupType = "consumer"
+	// GroupTypeConnect are the groups of Kafka Connect workers, which distribute connectors and tasks rather than
+	// partitions
+	GroupTypeConnect GroupType = "connect"
+	// GroupTypeOther are all other groups, e.g. the group of Schema Registry instances which elect a leader
+	GroupTypeOther GroupType = "other"
+)
+
+// Protocol types of the groups which we can classify
+const (
+	protocolTypeConsumer = "consumer"
+	protocolTypeConnect  = "connect"
+)
+
+// ClassifyGroupType returns the type of a group. groupType is the group type reported by ListGroups v5+ (KIP-848),
+// which is empty on older brokers and for client versions which don't request it. The type is inferred from the
+// group's protocol type then, which can't tell KIP-848 consumer groups from classic ones, hence they are classified
+// as classic groups.
+func ClassifyGroupType(groupType string, protocolType string) GroupType {
+	switch groupType {
+	case string(GroupTypeConsumer):
+		return GroupTypeConsumer
+	case string(GroupTypeClassic), "":
+		// Classic groups use the protocol type, e.g. to run Connect
+	default:
+		return GroupTypeOther
+	}
+
+	switch protocolType {
+	case protocolTypeConsumer, "":
+		return GroupTypeClassic
+	case protocolTypeConnect:
+		return GroupTypeConnect
+	default:
+		return GroupTypeOther
+	}
+}
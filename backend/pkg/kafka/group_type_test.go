@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestClassifyGroupType(t *testing.T) {
+	tests := []struct {
+		name         string
+		groupType    string
+		protocolType string
+		expected     GroupType
+	}{
+		{name: "classic consumer", groupType: "classic", protocolType: "consumer", expected: GroupTypeClassic},
+		{name: "kip-848 consumer", groupType: "consumer", protocolType: "consumer", expected: GroupTypeConsumer},
+		{name: "connect", groupType: "classic", protocolType: "connect", expected: GroupTypeConnect},
+		{name: "schema registry", groupType: "classic", protocolType: "sr", expected: GroupTypeOther},
+		{name: "unknown group type", groupType: "share", protocolType: "", expected: GroupTypeOther},
+
+		// Older brokers don't report the group type
+		{name: "inferred consumer", protocolType: "consumer", expected: GroupTypeClassic},
+		{name: "inferred offsets only", protocolType: "", expected: GroupTypeClassic},
+		{name: "inferred connect", protocolType: "connect", expected: GroupTypeConnect},
+		{name: "inferred other", protocolType: "sr", expected: GroupTypeOther},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, ClassifyGroupType(tc.groupType, tc.protocolType), tc.name)
+	}
+}
+
+func TestListConsumerGroupsWithOptions_Types(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.ListGroupsRequest); !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		return &kmsg.ListGroupsResponse{Groups: []kmsg.ListGroupsResponseGroup{
+			{Group: "orders-service", ProtocolType: "consumer"},
+			{Group: "connect-cluster", ProtocolType: "connect"},
+			{Group: "schema-registry", ProtocolType: "sr"},
+		}}, nil
+	}}
+	svc := &Service{KafkaClient: client}
+
+	listed, err := svc.ListConsumerGroupsWithOptions(context.Background(), ListConsumerGroupsOptions{Types: []GroupType{GroupTypeConnect, GroupTypeOther}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"connect-cluster", "schema-registry"}, listed.GetGroupIDs())
+
+	listed, err = svc.ListConsumerGroups(context.Background())
+	require.NoError(t, err)
+	groups := listed.GetGroups()
+	require.Len(t, groups, 3)
+	assert.Equal(t, GroupTypeClassic, groups[0].Type)
+	assert.Equal(t, GroupTypeConnect, groups[1].Type)
+	assert.Equal(t, GroupTypeOther, groups[2].Type)
+}
@@ -0,0 +1,30 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// KafkaClient is the subset of the kgo client's methods that is used by the service for sending requests. Depending
+// on this interface rather than on *kgo.Client allows to test the request handling without a Kafka cluster.
+type KafkaClient interface {
+	// Request sends the request to the appropriate broker, see (*kgo.Client).Request
+	Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error)
+
+	// RequestSharded splits the request to all brokers it must be sent to, see (*kgo.Client).RequestSharded
+	RequestSharded(ctx context.Context, req kmsg.Request) []kgo.ResponseShard
+
+	// ForBroker returns a requestor that sends all requests to the given broker
+	ForBroker(brokerID int32) kmsg.Requestor
+}
+
+// kgoClient adapts *kgo.Client to the KafkaClient interface
+type kgoClient struct {
+	*kgo.Client
+}
+
+func (c kgoClient) ForBroker(brokerID int32) kmsg.Requestor {
+	return c.Client.Broker(int(brokerID))
+}
@@ -0,0 +1,182 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+// Defaults which bound the scan of GetLatestByKey if the options don't set them.
+const (
+	defaultLatestByKeyMaxMessages = 100000
+	defaultLatestByKeyMaxBytes    = 100 * 1024 * 1024
+	defaultLatestByKeyTimeout     = 30 * time.Second
+)
+
+// LatestByKeyOptions bound the scan of GetLatestByKey. Zero values use the defaults of 100k messages, 100 MiB and 30s.
+type LatestByKeyOptions struct {
+	MaxMessages int
+	MaxBytes    int64
+	Timeout     time.Duration
+}
+
+// LatestByKeyResult is the latest message of each key, which is the state a compacted topic is eventually compacted
+// to. If IsTruncated is set, the scan stopped before reaching the end of all partitions and newer messages (or
+// tombstones) of some keys may be missing.
+type LatestByKeyResult struct {
+	Messages        map[string]*TopicMessage `json:"messages"`
+	ScannedMessages int                      `json:"scannedMessages"`
+	ScannedBytes    int64                    `json:"scannedBytes"`
+	IsTruncated     bool                     `json:"isTruncated"`
+	TruncatedReason string                   `json:"truncatedReason,omitempty"`
+}
+
+// GetLatestByKey scans the topic from the oldest offset up to the last stable offsets at the time of the request and
+// keeps the latest message per key. Tombstones remove the key, messages without key are ignored. Keys are compared by
+// their raw bytes. If one of the bounds is reached first, the messages scanned so far are returned as truncated result.
+func (s *Service) GetLatestByKey(ctx context.Context, topic string, opts LatestByKeyOptions) (*LatestByKeyResult, error) {
+	if opts.MaxMessages <= 0 {
+		opts.MaxMessages = defaultLatestByKeyMaxMessages
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultLatestByKeyMaxBytes
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultLatestByKeyTimeout
+	}
+
+	partitionIDs, err := s.ListPartitionIDs(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	// Records of aborted transactions never become part of the topic's state
+	consumer, endOffsets, err := s.newBoundedConsumer(ctx, topic, partitionIDs, IsolationLevelReadCommitted)
+	if err != nil {
+		return nil, err
+	}
+	collector := newLatestByKeyCollector(opts)
+	if len(endOffsets) == 0 {
+		return s.latestByKeyResult(collector), nil
+	}
+	defer consumer.Close()
+
+	scanCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	err = consumeBounded(scanCtx, consumer, endOffsets, func(record *kgo.Record) bool {
+		collector.add(record)
+		return !collector.isTruncated
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		collector.truncate(fmt.Sprintf("timeout of %v reached", opts.Timeout))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.latestByKeyResult(collector), nil
+}
+
+// latestByKeyResult deserializes the latest record of each key. Only the remaining records are deserialized, which is
+// usually a small fraction of the scanned records.
+func (s *Service) latestByKeyResult(collector *latestByKeyCollector) *LatestByKeyResult {
+	messages := make(map[string]*TopicMessage, len(collector.records))
+	for key, record := range collector.records {
+		messages[key] = s.newScannedTopicMessage(record)
+	}
+
+	return &LatestByKeyResult{
+		Messages:        messages,
+		ScannedMessages: collector.scannedMessages,
+		ScannedBytes:    collector.scannedBytes,
+		IsTruncated:     collector.isTruncated,
+		TruncatedReason: collector.truncatedReason,
+	}
+}
+
+// newScannedTopicMessage deserializes a record which has been found by a topic scan. The partition leader epoch is
+// unknown for scans, hence it is -1.
+func (s *Service) newScannedTopicMessage(record *kgo.Record) *TopicMessage {
+	deserializedRec := s.Deserializer.DeserializeRecord(record, deserializeOptions{})
+	msg := &TopicMessage{
+		PartitionID:      record.Partition,
+		Offset:           record.Offset,
+		Timestamp:        record.Timestamp.UnixNano() / int64(time.Millisecond),
+		Compression:      compressionTypeDisplayname(record.Attrs.CompressionType()),
+		IsTransactional:  record.Attrs.IsTransactional(),
+		Headers:          deserializedRec.Headers,
+		Key:              deserializedRec.Key,
+		Value:            deserializedRec.Value,
+		KeySize:          payloadSize(record.Key),
+		ValueSize:        payloadSize(record.Value),
+		LeaderEpoch:      record.LeaderEpoch,
+		DeserializeError: deserializeErrorOf(deserializedRec),
+
+		PartitionLeaderEpoch: -1,
+	}
+
+	if err := applyMessageTransformers(msg, s.messageTransformers); err != nil {
+		s.Logger.Debug("failed to transform message", zap.Error(err))
+		dropTransformedMessage(msg, err)
+	}
+	return msg
+}
+
+// latestByKeyCollector keeps the latest record of each key until the message or byte limit is reached.
+type latestByKeyCollector struct {
+	maxMessages int
+	maxBytes    int64
+
+	records         map[string]*kgo.Record
+	scannedMessages int
+	scannedBytes    int64
+	isTruncated     bool
+	truncatedReason string
+}
+
+func newLatestByKeyCollector(opts LatestByKeyOptions) *latestByKeyCollector {
+	return &latestByKeyCollector{
+		maxMessages: opts.MaxMessages,
+		maxBytes:    opts.MaxBytes,
+		records:     make(map[string]*kgo.Record),
+	}
+}
+
+// add applies the record to the collected state, unless it would exceed one of the limits, in which case the state is
+// truncated instead. Records are expected in offset order per partition, which is
+// sufficient because all records of a key are written to the same partition.
+func (c *latestByKeyCollector) add(record *kgo.Record) {
+	if c.isTruncated {
+		return
+	}
+	size := int64(len(record.Key) + len(record.Value))
+	switch {
+	case c.scannedMessages >= c.maxMessages:
+		c.truncate(fmt.Sprintf("max messages of %v reached", c.maxMessages))
+		return
+	case c.scannedBytes+size > c.maxBytes:
+		c.truncate(fmt.Sprintf("max bytes of %v reached", c.maxBytes))
+		return
+	}
+	c.scannedMessages++
+	c.scannedBytes += size
+
+	if record.Attrs.IsControl() || record.Key == nil {
+		return
+	}
+	if record.Value == nil {
+		delete(c.records, string(record.Key))
+		return
+	}
+	c.records[string(record.Key)] = record
+}
+
+func (c *latestByKeyCollector) truncate(reason string) {
+	c.isTruncated = true
+	c.truncatedReason = reason
+}
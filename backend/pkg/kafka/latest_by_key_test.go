@@ -0,0 +1,39 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestLatestByKeyCollector(t *testing.T) {
+	collector := newLatestByKeyCollector(LatestByKeyOptions{MaxMessages: 10, MaxBytes: 1024})
+	collector.add(&kgo.Record{Key: []byte("a"), Value: []byte("1"), Offset: 0})
+	collector.add(&kgo.Record{Key: []byte("b"), Value: []byte("1"), Offset: 1})
+	collector.add(&kgo.Record{Key: []byte("a"), Value: []byte("2"), Offset: 2})
+	collector.add(&kgo.Record{Key: []byte("b"), Value: nil, Offset: 3}) // tombstone
+	collector.add(&kgo.Record{Key: nil, Value: []byte("ignored"), Offset: 4})
+
+	assert.False(t, collector.isTruncated)
+	assert.Equal(t, 5, collector.scannedMessages)
+	assert.Len(t, collector.records, 1)
+	assert.Equal(t, int64(2), collector.records["a"].Offset)
+
+	// Reaching, but not exceeding a limit does not truncate the result
+	limited := newLatestByKeyCollector(LatestByKeyOptions{MaxMessages: 2, MaxBytes: 1024})
+	limited.add(&kgo.Record{Key: []byte("a"), Value: []byte("1")})
+	limited.add(&kgo.Record{Key: []byte("b"), Value: []byte("1")})
+	assert.False(t, limited.isTruncated)
+	limited.add(&kgo.Record{Key: []byte("c"), Value: []byte("1")})
+	assert.True(t, limited.isTruncated)
+	assert.Equal(t, "max messages of 2 reached", limited.truncatedReason)
+	assert.Len(t, limited.records, 2)
+
+	byteLimited := newLatestByKeyCollector(LatestByKeyOptions{MaxMessages: 10, MaxBytes: 3})
+	byteLimited.add(&kgo.Record{Key: []byte("a"), Value: []byte("1")})
+	byteLimited.add(&kgo.Record{Key: []byte("b"), Value: []byte("1")})
+	assert.True(t, byteLimited.isTruncated)
+	assert.Equal(t, int64(2), byteLimited.scannedBytes)
+	assert.Len(t, byteLimited.records, 1)
+}
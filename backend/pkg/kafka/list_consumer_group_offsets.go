@@ -5,17 +5,35 @@ import (
 	"fmt"
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
 	"sync"
 
 	"golang.org/x/sync/errgroup"
 )
 
-// ListConsumerGroupOffsets returns the committed group offsets for a single group
+// ListConsumerGroupOffsets returns the committed group offsets for a single group across all topics.
+//
+// Brokers which support OffsetFetch v2+ (Kafka 0.10.2+) return all committed topics if no topics are specified.
+// Older brokers require the topics to be set explicitly, hence we derive them from the group members' assignments
+// first. On these brokers committed offsets of topics which are currently not assigned to any member can not be
+// listed.
 func (s *Service) ListConsumerGroupOffsets(ctx context.Context, group string) (*kmsg.OffsetFetchResponse, error) {
-	req := kmsg.OffsetFetchRequest{
-		Group:  group,
-		Topics: nil, // Requests all topics for this consumer group
+	req := kmsg.NewOffsetFetchRequest()
+	req.Group = group
+	req.Topics = nil // Requests all topics for this consumer group
+
+	supportsAllTopics, err := s.supportsFetchingAllGroupOffsets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check whether fetching all group offsets is supported: %w", err)
 	}
+	if !supportsAllTopics {
+		topics, err := s.listAssignedTopics(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list assigned topics for group '%v': %w", group, err)
+		}
+		req.Topics = topics
+	}
+
 	res, err := req.RequestWith(ctx, s.KafkaClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to request group offsets for group '%v': %w", group, err)
@@ -29,6 +47,148 @@ func (s *Service) ListConsumerGroupOffsets(ctx context.Context, group string) (*
 	return res, nil
 }
 
+// TopicPartition identifies a single partition of a topic.
+type TopicPartition struct {
+	Topic     string
+	Partition int32
+}
+
+// UnknownTopicPartitionsError is returned if requested partitions do not exist in the cluster metadata.
+type UnknownTopicPartitionsError struct {
+	TopicPartitions []TopicPartition
+}
+
+func (e *UnknownTopicPartitionsError) Error() string {
+	return fmt.Sprintf("'%v' requested topic partitions do not exist: %v", len(e.TopicPartitions), e.TopicPartitions)
+}
+
+// ListConsumerGroupOffsetsForPartitions is like ListConsumerGroupOffsets, but only fetches the committed offsets of
+// the given partitions. This is cheaper for groups which consume many topics if only some of them are of interest.
+// An *UnknownTopicPartitionsError is returned if at least one of the partitions does not exist.
+func (s *Service) ListConsumerGroupOffsetsForPartitions(ctx context.Context, group string, partitions []TopicPartition) (*kmsg.OffsetFetchResponse, error) {
+	topicNames := make([]string, 0)
+	partitionsByTopic := make(map[string][]int32)
+	for _, tp := range partitions {
+		if _, exists := partitionsByTopic[tp.Topic]; !exists {
+			topicNames = append(topicNames, tp.Topic)
+		}
+		partitionsByTopic[tp.Topic] = append(partitionsByTopic[tp.Topic], tp.Partition)
+	}
+	if len(topicNames) == 0 {
+		return nil, fmt.Errorf("at least one topic partition must be requested")
+	}
+
+	metadata, err := s.GetMetadata(ctx, topicNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata of requested topics: %w", err)
+	}
+	unknownPartitions := findUnknownTopicPartitions(metadata, partitions)
+	if len(unknownPartitions) > 0 {
+		return nil, &UnknownTopicPartitionsError{TopicPartitions: unknownPartitions}
+	}
+
+	req := kmsg.NewOffsetFetchRequest()
+	req.Group = group
+	req.Topics = make([]kmsg.OffsetFetchRequestTopic, 0, len(topicNames))
+	for _, topicName := range topicNames {
+		topic := kmsg.NewOffsetFetchRequestTopic()
+		topic.Topic = topicName
+		topic.Partitions = partitionsByTopic[topicName]
+		req.Topics = append(req.Topics, topic)
+	}
+
+	res, err := req.RequestWith(ctx, s.KafkaClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request group offsets for group '%v': %w", group, err)
+	}
+
+	err = kerr.ErrorForCode(res.ErrorCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request group offsets for group '%v'. Inner error: %w", group, err)
+	}
+
+	return res, nil
+}
+
+// findUnknownTopicPartitions returns all partitions which are not part of the given metadata response. Partitions of
+// topics that returned an error (e.g. UNKNOWN_TOPIC_OR_PARTITION) are considered unknown as well.
+func findUnknownTopicPartitions(metadata *kmsg.MetadataResponse, partitions []TopicPartition) []TopicPartition {
+	knownPartitions := make(map[string]map[int32]struct{})
+	for _, topic := range metadata.Topics {
+		if kerr.ErrorForCode(topic.ErrorCode) != nil {
+			continue
+		}
+		knownPartitions[topic.Topic] = make(map[int32]struct{}, len(topic.Partitions))
+		for _, partition := range topic.Partitions {
+			knownPartitions[topic.Topic][partition.Partition] = struct{}{}
+		}
+	}
+
+	unknown := make([]TopicPartition, 0)
+	for _, tp := range partitions {
+		if _, exists := knownPartitions[tp.Topic][tp.Partition]; !exists {
+			unknown = append(unknown, tp)
+		}
+	}
+
+	return unknown
+}
+
+// supportsFetchingAllGroupOffsets returns true if the cluster supports OffsetFetch requests with null topics.
+func (s *Service) supportsFetchingAllGroupOffsets(ctx context.Context) (bool, error) {
+	versions, err := s.getClusterVersions(ctx)
+	if err != nil {
+		return false, err
+	}
+	maxVersion, isSupported := versions.LookupMaxKeyVersion((&kmsg.OffsetFetchRequest{}).Key())
+
+	return isSupported && maxVersion >= 2, nil
+}
+
+// listAssignedTopics returns an OffsetFetch topic request for each topic that is assigned to at least one member
+// of the given group. Old OffsetFetch versions require explicit partitions too, so only the currently assigned
+// partitions are requested.
+func (s *Service) listAssignedTopics(ctx context.Context, group string) ([]kmsg.OffsetFetchRequestTopic, error) {
+	describedGroup, err := s.DescribeConsumerGroup(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only consumer group members are assigned partitions, other protocols use their own assignment schema
+	if describedGroup.ProtocolType != "consumer" {
+		return []kmsg.OffsetFetchRequestTopic{}, nil
+	}
+
+	partitionsByTopic := make(map[string]map[int32]struct{})
+	for _, member := range describedGroup.Members {
+		assignment, err := DecodeMemberAssignment(member.MemberAssignment)
+		if err != nil {
+			s.Logger.Warn("failed to decode member assignments", zap.String("client_id", member.ClientID), zap.Error(err))
+			continue
+		}
+		for _, topic := range assignment.Topics {
+			if _, exists := partitionsByTopic[topic.Topic]; !exists {
+				partitionsByTopic[topic.Topic] = make(map[int32]struct{})
+			}
+			for _, partition := range topic.Partitions {
+				partitionsByTopic[topic.Topic][partition] = struct{}{}
+			}
+		}
+	}
+
+	topics := make([]kmsg.OffsetFetchRequestTopic, 0, len(partitionsByTopic))
+	for topicName, partitions := range partitionsByTopic {
+		topic := kmsg.NewOffsetFetchRequestTopic()
+		topic.Topic = topicName
+		for partition := range partitions {
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		topics = append(topics, topic)
+	}
+
+	return topics, nil
+}
+
 // ListConsumerGroupOffsetsBulk returns a map which has the Consumer group name as key
 func (s *Service) ListConsumerGroupOffsetsBulk(ctx context.Context, groups []string) (map[string]*kmsg.OffsetFetchResponse, error) {
 	eg, _ := errgroup.WithContext(ctx)
@@ -0,0 +1,35 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestFindUnknownTopicPartitions(t *testing.T) {
+	metadata := &kmsg.MetadataResponse{
+		Topics: []kmsg.MetadataResponseTopic{
+			{
+				Topic:      "orders",
+				Partitions: []kmsg.MetadataResponseTopicPartition{{Partition: 0}, {Partition: 1}},
+			},
+			{
+				Topic:     "missing",
+				ErrorCode: kerr.UnknownTopicOrPartition.Code,
+			},
+		},
+	}
+
+	partitions := []TopicPartition{
+		{Topic: "orders", Partition: 0},
+		{Topic: "orders", Partition: 1},
+		{Topic: "orders", Partition: 2},
+		{Topic: "missing", Partition: 0},
+	}
+	unknown := findUnknownTopicPartitions(metadata, partitions)
+	assert.Equal(t, []TopicPartition{{Topic: "orders", Partition: 2}, {Topic: "missing", Partition: 0}}, unknown)
+
+	assert.Empty(t, findUnknownTopicPartitions(metadata, partitions[:2]))
+}
@@ -12,6 +12,9 @@ type ListConsumerGroupsResponseSharded struct {
 	Groups         []ListConsumerGroupsResponse
 	RequestsSent   int
 	RequestsFailed int
+
+	// Conflicts are the group IDs which have been listed with more than one protocol type, see GroupConflict
+	Conflicts []GroupConflict
 }
 
 func (l *ListConsumerGroupsResponseSharded) GetGroupIDs() []string {
@@ -27,6 +30,35 @@ func (l *ListConsumerGroupsResponseSharded) GetGroupIDs() []string {
 	return groupIDs
 }
 
+// ListedGroup is a group as it has been returned by ListGroups, along with its classified type
+type ListedGroup struct {
+	GroupID       string
+	ProtocolType  string
+	State         string
+	Type          GroupType
+	CoordinatorID int32
+}
+
+// GetGroups returns all listed groups of the successful responses
+func (l *ListConsumerGroupsResponseSharded) GetGroups() []ListedGroup {
+	groups := make([]ListedGroup, 0)
+	for _, groupResp := range l.Groups {
+		if groupResp.Error != nil || groupResp.Groups == nil {
+			continue
+		}
+		for _, group := range groupResp.Groups.Groups {
+			groups = append(groups, ListedGroup{
+				GroupID:       group.Group,
+				ProtocolType:  group.ProtocolType,
+				State:         group.GroupState,
+				Type:          ClassifyGroupType("", group.ProtocolType),
+				CoordinatorID: groupResp.BrokerMetadata.NodeID,
+			})
+		}
+	}
+	return groups
+}
+
 // LogDirResponse can have an error (if the broker failed to return data) or the actual LogDir response
 type ListConsumerGroupsResponse struct {
 	BrokerMetadata kgo.BrokerMetadata
@@ -34,9 +66,21 @@ type ListConsumerGroupsResponse struct {
 	Error          error
 }
 
+// ListConsumerGroupsOptions filter the groups returned by ListConsumerGroupsWithOptions
+type ListConsumerGroupsOptions struct {
+	// Types only returns groups of these types, see ClassifyGroupType. Empty returns all groups.
+	Types []GroupType
+}
+
 // ListConsumerGroups returns an array of Consumer group ids. Failed broker requests will be returned in the response.
 // If all broker requests fail an error will be returned.
 func (s *Service) ListConsumerGroups(ctx context.Context) (*ListConsumerGroupsResponseSharded, error) {
+	return s.ListConsumerGroupsWithOptions(ctx, ListConsumerGroupsOptions{})
+}
+
+// ListConsumerGroupsWithOptions is like ListConsumerGroups, but only returns the groups which match the options. The
+// groups are filtered after they have been listed, because ListGroups can only filter by state.
+func (s *Service) ListConsumerGroupsWithOptions(ctx context.Context, opts ListConsumerGroupsOptions) (*ListConsumerGroupsResponseSharded, error) {
 	req := kmsg.ListGroupsRequest{}
 	shardedResp := s.KafkaClient.RequestSharded(ctx, &req)
 
@@ -68,5 +112,30 @@ func (s *Service) ListConsumerGroups(ctx context.Context) (*ListConsumerGroupsRe
 		return result, fmt.Errorf("all '%v' requests have failed, last error: %w", len(shardedResp), lastErr)
 	}
 
+	// Conflicts are detected across all groups, regardless of the requested types
+	result.Conflicts = findGroupConflicts(result.GetGroups())
+	if len(opts.Types) > 0 {
+		for _, groupResp := range result.Groups {
+			if groupResp.Groups != nil {
+				groupResp.Groups.Groups = filterGroupsByType(groupResp.Groups.Groups, opts.Types)
+			}
+		}
+	}
+
 	return result, nil
 }
+
+// filterGroupsByType returns the groups whose type is any of the given types
+func filterGroupsByType(groups []kmsg.ListGroupsResponseGroup, types []GroupType) []kmsg.ListGroupsResponseGroup {
+	filtered := make([]kmsg.ListGroupsResponseGroup, 0, len(groups))
+	for _, group := range groups {
+		groupType := ClassifyGroupType("", group.ProtocolType)
+		for _, t := range types {
+			if groupType == t {
+				filtered = append(filtered, group)
+				break
+			}
+		}
+	}
+	return filtered
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"sort"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
 )
 
 // ListPartitionIDs returns the partitionIDs for a given topic
@@ -22,3 +25,63 @@ func (s *Service) ListPartitionIDs(ctx context.Context, topicName string) ([]int
 
 	return partitionIDs, nil
 }
+
+// PartitionCount returns the number of partitions of the given topic. An error wrapping ErrTopicNotFound is returned
+// if the topic does not exist.
+func (s *Service) PartitionCount(ctx context.Context, topicName string) (int32, error) {
+	metadata, err := s.GetSingleMetadata(ctx, topicName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get topic metadata: %w", err.Err)
+	}
+
+	return int32(len(metadata.Partitions)), nil
+}
+
+// ValidatePartitionID returns an error wrapping ErrPartitionNotFound if the partition ID is not a valid partition
+// of a topic with the given number of partitions. Partition IDs are always 0 to partitionCount-1.
+func ValidatePartitionID(topicName string, partitionID int32, partitionCount int32) error {
+	if partitionID < 0 || partitionID >= partitionCount {
+		return fmt.Errorf("%w: topic '%v' has %v partitions, requested partition is '%v'",
+			ErrPartitionNotFound, topicName, partitionCount, partitionID)
+	}
+	return nil
+}
+
+// noLeaderID is the broker ID under which PartitionsByLeader returns partitions that currently have no leader
+const noLeaderID int32 = -1
+
+// PartitionsByLeader returns the partition IDs of the given topic grouped by the broker ID of their leader, so
+// that requests which must be sent to the partition leaders can be batched into one request per broker. Partition
+// IDs are sorted. Partitions which currently have no leader are grouped under the broker ID -1. Partitions whose
+// metadata has an error are not grouped at all, their errors are returned by partition ID instead. An error wrapping
+// ErrTopicNotFound is returned if the topic does not exist.
+func (s *Service) PartitionsByLeader(ctx context.Context, topicName string) (map[int32][]int32, map[int32]error, error) {
+	topicMetadata, err := s.getTopicMetadata(ctx, topicName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	partitionIDsByLeader, errByPartitionID := partitionsByLeader(topicMetadata)
+	return partitionIDsByLeader, errByPartitionID, nil
+}
+
+func partitionsByLeader(topicMetadata kmsg.MetadataResponseTopic) (map[int32][]int32, map[int32]error) {
+	partitionIDsByLeader := make(map[int32][]int32)
+	errByPartitionID := make(map[int32]error)
+	for _, partition := range topicMetadata.Partitions {
+		if err := kerr.ErrorForCode(partition.ErrorCode); err != nil {
+			errByPartitionID[partition.Partition] = err
+			continue
+		}
+		leaderID := partition.Leader
+		if leaderID < 0 {
+			leaderID = noLeaderID
+		}
+		partitionIDsByLeader[leaderID] = append(partitionIDsByLeader[leaderID], partition.Partition)
+	}
+	for _, partitionIDs := range partitionIDsByLeader {
+		sort.Slice(partitionIDs, func(i, j int) bool { return partitionIDs[i] < partitionIDs[j] })
+	}
+
+	return partitionIDsByLeader, errByPartitionID
+}
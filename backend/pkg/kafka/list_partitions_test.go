@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestValidatePartitionID(t *testing.T) {
+	assert.NoError(t, ValidatePartitionID("orders", 0, 3))
+	assert.NoError(t, ValidatePartitionID("orders", 2, 3))
+
+	for _, partitionID := range []int32{3, 7, -1} {
+		err := ValidatePartitionID("orders", partitionID, 3)
+		assert.True(t, errors.Is(err, ErrPartitionNotFound), "partition %v", partitionID)
+	}
+	assert.EqualError(t, ValidatePartitionID("orders", 7, 3), "partition not found: topic 'orders' has 3 partitions, requested partition is '7'")
+}
+
+func TestPartitionsByLeader(t *testing.T) {
+	topicMetadata := kmsg.MetadataResponseTopic{
+		Topic: "orders",
+		Partitions: []kmsg.MetadataResponseTopicPartition{
+			{Partition: 3, Leader: 1},
+			{Partition: 0, Leader: 1},
+			{Partition: 1, Leader: 2},
+			{Partition: 2, Leader: -1, ErrorCode: kerr.LeaderNotAvailable.Code},
+			{Partition: 4, Leader: -1},
+			{Partition: 5, Leader: 2, ErrorCode: kerr.ReplicaNotAvailable.Code},
+		},
+	}
+
+	partitionIDsByLeader, errByPartitionID := partitionsByLeader(topicMetadata)
+	assert.Equal(t, map[int32][]int32{
+		1:  {0, 3},
+		2:  {1},
+		-1: {4},
+	}, partitionIDsByLeader)
+	assert.Equal(t, map[int32]error{
+		2: kerr.LeaderNotAvailable,
+		5: kerr.ReplicaNotAvailable,
+	}, errByPartitionID)
+}
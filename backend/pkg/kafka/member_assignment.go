@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// maxDecompressedAssignmentBytes limits the size of decompressed member assignments, so that a small but malicious
+// assignment can not exhaust our memory (decompression bomb). Real assignments are far smaller.
+const maxDecompressedAssignmentBytes = 4 * 1024 * 1024
+
+// DecodeMemberAssignment decodes a consumer group member's assignment, which follows the schema of the consumer
+// protocol. Some (older) clients compress the whole assignment, hence if the assignment can not be decoded, it is
+// decompressed with gzip, zlib or raw deflate and decoded again. The error of the initial decode attempt is returned
+// if none of these succeeds.
+func DecodeMemberAssignment(raw []byte) (kmsg.GroupMemberAssignment, error) {
+	assignment := kmsg.GroupMemberAssignment{}
+	err := assignment.ReadFrom(raw)
+	if err == nil {
+		return assignment, nil
+	}
+
+	decompressors := []func(r io.Reader) (io.Reader, error){
+		func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil },
+	}
+	for _, decompressor := range decompressors {
+		decompressed, decompressErr := decompressAssignment(raw, decompressor)
+		if decompressErr != nil {
+			continue
+		}
+		decompressedAssignment := kmsg.GroupMemberAssignment{}
+		if decodeErr := decompressedAssignment.ReadFrom(decompressed); decodeErr == nil {
+			return decompressedAssignment, nil
+		}
+	}
+
+	return kmsg.GroupMemberAssignment{}, err
+}
+
+// decompressAssignment decompresses the raw assignment with the given decompressor. An error is returned if the
+// decompressed assignment exceeds maxDecompressedAssignmentBytes.
+func decompressAssignment(raw []byte, decompressor func(r io.Reader) (io.Reader, error)) ([]byte, error) {
+	r, err := decompressor(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedAssignmentBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedAssignmentBytes {
+		return nil, fmt.Errorf("decompressed assignment exceeds the limit of '%v' bytes", maxDecompressedAssignmentBytes)
+	}
+	return decompressed, nil
+}
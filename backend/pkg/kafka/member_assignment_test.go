@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestDecodeMemberAssignment(t *testing.T) {
+	assignment := kmsg.GroupMemberAssignment{
+		Topics: []kmsg.GroupMemberAssignmentTopic{{Topic: "orders", Partitions: []int32{0, 2}}},
+	}
+	raw := assignment.AppendTo(nil)
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var zlibbed bytes.Buffer
+	zw := zlib.NewWriter(&zlibbed)
+	_, err = zw.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	for name, blob := range map[string][]byte{"plain": raw, "gzip": gzipped.Bytes(), "zlib": zlibbed.Bytes()} {
+		decoded, err := DecodeMemberAssignment(blob)
+		require.NoError(t, err, name)
+		require.Len(t, decoded.Topics, 1, name)
+		assert.Equal(t, "orders", decoded.Topics[0].Topic, name)
+		assert.Equal(t, []int32{0, 2}, decoded.Topics[0].Partitions, name)
+	}
+
+	_, err = DecodeMemberAssignment([]byte{0x00, 0x01, 0xff})
+	assert.Error(t, err)
+}
+
+func TestDecodeMemberAssignment_DecompressionBomb(t *testing.T) {
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write(make([]byte, maxDecompressedAssignmentBytes+1))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	_, err = decompressAssignment(gzipped.Bytes(), func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) })
+	assert.Error(t, err)
+
+	_, err = DecodeMemberAssignment(gzipped.Bytes())
+	assert.Error(t, err)
+}
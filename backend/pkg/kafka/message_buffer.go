@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+)
+
+// OverflowPolicy decides what happens to consumed messages if the message buffer between the Kafka consumer and a
+// (slow) progress receiver is full.
+type OverflowPolicy string
+
+const (
+	// OverflowPolicyBlock stops consuming until there is space in the buffer again
+	OverflowPolicyBlock OverflowPolicy = "block"
+	// OverflowPolicyDropOldest drops the oldest buffered message in favour of the new one
+	OverflowPolicyDropOldest OverflowPolicy = "dropOldest"
+	// OverflowPolicyDropNewest drops the new message and keeps the buffered ones
+	OverflowPolicyDropNewest OverflowPolicy = "dropNewest"
+)
+
+// Validate returns an error if the policy is not one of the known policies
+func (p OverflowPolicy) Validate() error {
+	switch p {
+	case OverflowPolicyBlock, OverflowPolicyDropOldest, OverflowPolicyDropNewest:
+		return nil
+	default:
+		return fmt.Errorf("overflow policy '%v' is invalid, must be one of '%v', '%v' or '%v'",
+			p, OverflowPolicyBlock, OverflowPolicyDropOldest, OverflowPolicyDropNewest)
+	}
+}
+
+// IMessagesDroppedProgress can be implemented by progress receivers which want to know how many messages have been
+// dropped due to a full message buffer. OnMessagesDropped is called once at the end of the stream, before
+// FetchMessages returns, and only if messages have been dropped.
+type IMessagesDroppedProgress interface {
+	OnMessagesDropped(count int64)
+}
+
+// messageBuffer decouples the delivery of messages to a progress receiver from consuming them, so that a slow
+// receiver (e.g. a websocket to a slow browser) does not block the consumer. Push must not be called concurrently.
+type messageBuffer struct {
+	progress IListMessagesProgress
+	policy   OverflowPolicy
+	messages chan *TopicMessage
+	done     chan struct{}
+	dropped  int64
+}
+
+// newMessageBuffer creates a buffer for up to size messages and starts delivering them to the progress receiver
+func newMessageBuffer(ctx context.Context, progress IListMessagesProgress, size int, policy OverflowPolicy) *messageBuffer {
+	b := &messageBuffer{
+		progress: progress,
+		policy:   policy,
+		messages: make(chan *TopicMessage, size),
+		done:     make(chan struct{}),
+	}
+	go b.deliver(ctx)
+	return b
+}
+
+func (b *messageBuffer) deliver(ctx context.Context) {
+	defer close(b.done)
+	for msg := range b.messages {
+		if ctx.Err() != nil {
+			// Nobody is interested in the remaining messages, but we need to drain the channel
+			continue
+		}
+		b.progress.OnMessage(msg)
+	}
+}
+
+// Push adds a message to the buffer and applies the overflow policy if the buffer is full
+func (b *messageBuffer) Push(ctx context.Context, msg *TopicMessage) {
+	switch b.policy {
+	case OverflowPolicyDropNewest:
+		select {
+		case b.messages <- msg:
+		default:
+			b.dropped++
+		}
+	case OverflowPolicyDropOldest:
+		for {
+			select {
+			case b.messages <- msg:
+				return
+			default:
+			}
+			// Buffer is full, make space by dropping the oldest message. The receiver may have taken it meanwhile.
+			select {
+			case <-b.messages:
+				b.dropped++
+			default:
+			}
+		}
+	default:
+		select {
+		case b.messages <- msg:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// Close waits until all buffered messages have been delivered (or the context is done) and reports the number of
+// dropped messages to the progress receiver.
+func (b *messageBuffer) Close(ctx context.Context) {
+	close(b.messages)
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+	}
+
+	if droppedProgress, ok := b.progress.(IMessagesDroppedProgress); ok && b.dropped > 0 {
+		droppedProgress.OnMessagesDropped(b.dropped)
+	}
+}
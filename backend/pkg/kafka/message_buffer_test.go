@@ -0,0 +1,125 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowProgress blocks in OnMessage until the test releases it, simulating a slow reader
+type slowProgress struct {
+	entered chan struct{}
+	release chan struct{}
+
+	mutex   sync.Mutex
+	offsets []int64
+	dropped int64
+	isFirst bool
+}
+
+func newSlowProgress() *slowProgress {
+	return &slowProgress{entered: make(chan struct{}), release: make(chan struct{}), isFirst: true}
+}
+
+func (p *slowProgress) OnPhase(_ string)           {}
+func (p *slowProgress) OnMessageConsumed(_ int64)  {}
+func (p *slowProgress) OnComplete(_ int64, _ bool) {}
+func (p *slowProgress) OnError(_ string)           {}
+
+func (p *slowProgress) OnMessage(msg *TopicMessage) {
+	if p.isFirst {
+		p.isFirst = false
+		close(p.entered)
+		<-p.release
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.offsets = append(p.offsets, msg.Offset)
+}
+
+func (p *slowProgress) OnMessagesDropped(count int64) {
+	p.dropped = count
+}
+
+func TestMessageBuffer_SlowReader(t *testing.T) {
+	tests := []struct {
+		policy          OverflowPolicy
+		expectedOffsets []int64
+		expectedDropped int64
+	}{
+		{policy: OverflowPolicyDropNewest, expectedOffsets: []int64{0, 1, 2, 3}, expectedDropped: 6},
+		{policy: OverflowPolicyDropOldest, expectedOffsets: []int64{0, 7, 8, 9}, expectedDropped: 6},
+	}
+
+	for _, tc := range tests {
+		ctx := context.Background()
+		progress := newSlowProgress()
+		buffer := newMessageBuffer(ctx, progress, 3, tc.policy)
+
+		// The first message is taken by the reader, which then blocks until released
+		buffer.Push(ctx, &TopicMessage{Offset: 0})
+		<-progress.entered
+		for offset := int64(1); offset < 10; offset++ {
+			buffer.Push(ctx, &TopicMessage{Offset: offset})
+		}
+		close(progress.release)
+		buffer.Close(ctx)
+
+		assert.Equal(t, tc.expectedOffsets, progress.offsets, tc.policy)
+		assert.Equal(t, tc.expectedDropped, progress.dropped, tc.policy)
+	}
+}
+
+func TestMessageBuffer_Block(t *testing.T) {
+	ctx := context.Background()
+	progress := newSlowProgress()
+	buffer := newMessageBuffer(ctx, progress, 3, OverflowPolicyBlock)
+
+	buffer.Push(ctx, &TopicMessage{Offset: 0})
+	<-progress.entered
+
+	pushed := make(chan struct{})
+	go func() {
+		for offset := int64(1); offset < 10; offset++ {
+			buffer.Push(ctx, &TopicMessage{Offset: offset})
+		}
+		close(pushed)
+	}()
+
+	// The producer must not be able to push all messages while the reader is blocked
+	select {
+	case <-pushed:
+		t.Fatal("expected push to block while the buffer is full")
+	default:
+	}
+
+	close(progress.release)
+	<-pushed
+	buffer.Close(ctx)
+
+	assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, progress.offsets)
+	assert.Equal(t, int64(0), progress.dropped)
+}
+
+func TestMessageBuffer_BlockCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := newSlowProgress()
+	buffer := newMessageBuffer(ctx, progress, 1, OverflowPolicyBlock)
+
+	buffer.Push(ctx, &TopicMessage{Offset: 0})
+	<-progress.entered
+	buffer.Push(ctx, &TopicMessage{Offset: 1})
+
+	// A cancelled context must unblock a producer that waits for space in the buffer
+	cancel()
+	buffer.Push(ctx, &TopicMessage{Offset: 2})
+	buffer.Close(ctx)
+	close(progress.release)
+}
+
+func TestOverflowPolicy_Validate(t *testing.T) {
+	assert.NoError(t, OverflowPolicyDropOldest.Validate())
+	assert.Error(t, OverflowPolicy("dropAll").Validate())
+}
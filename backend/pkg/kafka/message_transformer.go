@@ -0,0 +1,262 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MessageTransformer modifies consumed messages before they are returned, e.g. to redact personal data. Transformers
+// run after the message has been deserialized (and truncated) and before it is filtered, so that neither the filter
+// code nor the user can see what has been removed. They may be called concurrently for different messages.
+//
+// If a transformer returns an error, the message is dropped rather than returned untransformed.
+type MessageTransformer interface {
+	Transform(msg *TopicMessage) error
+}
+
+// MessageTransformerFunc allows to use a plain function as MessageTransformer
+type MessageTransformerFunc func(msg *TopicMessage) error
+
+// Transform calls f(msg)
+func (f MessageTransformerFunc) Transform(msg *TopicMessage) error {
+	return f(msg)
+}
+
+// applyMessageTransformers runs all transformers in the given order and stops at the first error
+func applyMessageTransformers(msg *TopicMessage, transformers []MessageTransformer) error {
+	for _, transformer := range transformers {
+		if err := transformer.Transform(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transformersFor returns the service's transformers followed by the given transformers of a request
+func (s *Service) transformersFor(requestTransformers []MessageTransformer) []MessageTransformer {
+	transformers := make([]MessageTransformer, 0, len(s.messageTransformers)+len(requestTransformers))
+	transformers = append(transformers, s.messageTransformers...)
+	return append(transformers, requestTransformers...)
+}
+
+// dropTransformedMessage marks a message which could not be transformed as not ok and removes its payloads, so that
+// a partially transformed message can't be returned by accident.
+func dropTransformedMessage(msg *TopicMessage, err error) {
+	msg.ErrorMessage = fmt.Sprintf("Failed to transform message (partition: '%v', offset: '%v'). Error: %v", msg.PartitionID, msg.Offset, err)
+	msg.IsMessageOk = false
+	msg.Key = nil
+	msg.Value = nil
+	msg.Headers = make([]MessageHeader, 0)
+}
+
+// redactedValue replaces all values which have been redacted by the JSONFieldRedactor
+const redactedValue = "[REDACTED]"
+
+// JSONFieldRedactor is a MessageTransformer which replaces the selected fields of keys and values as well as the
+// values of the selected headers by "[REDACTED]". Payloads are only redacted if they have been converted to JSON (e.g.
+// JSON, Avro, Protobuf), text and binary payloads are left untouched. Truncated payloads can not be parsed, hence they
+// are redacted as a whole.
+type JSONFieldRedactor struct {
+	paths      [][]jsonPathSegment
+	headerKeys map[string]struct{}
+}
+
+// NewJSONFieldRedactor creates a redactor for the given JSONPath expressions and header keys. Only a subset of
+// JSONPath is supported: child names ($.customer.email or $['customer']['e-mail']), array indices ($.items[0]) and
+// wildcards ($.items[*].price or $.customer.*). Paths and headers which don't exist in a message are ignored.
+func NewJSONFieldRedactor(paths []string, headerKeys []string) (*JSONFieldRedactor, error) {
+	redactor := &JSONFieldRedactor{
+		paths:      make([][]jsonPathSegment, len(paths)),
+		headerKeys: make(map[string]struct{}, len(headerKeys)),
+	}
+	for i, path := range paths {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse json path '%v': %w", path, err)
+		}
+		redactor.paths[i] = segments
+	}
+	for _, headerKey := range headerKeys {
+		if headerKey == "" {
+			return nil, fmt.Errorf("header keys must not be empty")
+		}
+		redactor.headerKeys[headerKey] = struct{}{}
+	}
+	return redactor, nil
+}
+
+// Transform redacts the message's key, value and headers
+func (r *JSONFieldRedactor) Transform(msg *TopicMessage) error {
+	if err := r.redactPayload(msg.Key, msg.KeyTruncated); err != nil {
+		return fmt.Errorf("failed to redact key: %w", err)
+	}
+	if err := r.redactPayload(msg.Value, msg.ValueTruncated); err != nil {
+		return fmt.Errorf("failed to redact value: %w", err)
+	}
+	for i, header := range msg.Headers {
+		if _, isRedacted := r.headerKeys[header.Key]; isRedacted && header.Value != nil {
+			msg.Headers[i].Value = redactedPayload(header.Value.Size)
+		}
+	}
+	return nil
+}
+
+// redactedPayload replaces a header value of the given raw size as a whole
+func redactedPayload(size int) *deserializedPayload {
+	return &deserializedPayload{
+		Payload:            normalizedPayload{Payload: []byte(redactedValue), RecognizedEncoding: messageEncodingText},
+		Object:             redactedValue,
+		RecognizedEncoding: messageEncodingText,
+		Size:               size,
+	}
+}
+
+func (r *JSONFieldRedactor) redactPayload(payload *deserializedPayload, isTruncated bool) error {
+	if payload == nil || len(r.paths) == 0 {
+		return nil
+	}
+	if isTruncated {
+		payload.Payload = normalizedPayload{Payload: []byte(redactedValue), RecognizedEncoding: messageEncodingText}
+		payload.Object = redactedValue
+		payload.RecognizedEncoding = messageEncodingText
+		payload.FormattedPayload = ""
+		return nil
+	}
+	switch payload.RecognizedEncoding {
+	case messageEncodingJSON, messageEncodingXML, messageEncodingAvro, messageEncodingProtobuf, messageEncodingMsgP, messageEncodingConsumerOffsets:
+	default:
+		return nil
+	}
+
+	// Numbers are kept in their original representation, see formatJSON
+	decoder := json.NewDecoder(bytes.NewReader(payload.Payload.Payload))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("failed to decode json: %w", err)
+	}
+	for _, segments := range r.paths {
+		doc = redactJSONPath(doc, segments)
+	}
+
+	buf := bytes.Buffer{}
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode json: %w", err)
+	}
+	redacted := bytes.TrimRight(buf.Bytes(), "\n")
+
+	// The object is passed to the filter code, which expects the representation of json.Unmarshal
+	var obj interface{}
+	if err := json.Unmarshal(redacted, &obj); err != nil {
+		return fmt.Errorf("failed to decode redacted json: %w", err)
+	}
+	payload.Payload.Payload = redacted
+	payload.Object = obj
+	if payload.FormattedPayload != "" {
+		setFormattedPayload(payload)
+	}
+	return nil
+}
+
+// jsonPathSegment selects the children of a JSON node. A wildcard selects all children, otherwise either the object
+// member with the given name or (if index >= 0) the array element at the given index.
+type jsonPathSegment struct {
+	name       string
+	index      int
+	isWildcard bool
+}
+
+// parseJSONPath parses the supported subset of JSONPath, see NewJSONFieldRedactor
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with '$'")
+	}
+	rest := path[1:]
+
+	segments := make([]jsonPathSegment, 0)
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return nil, fmt.Errorf("recursive descent is not supported")
+		case rest[0] == '.':
+			end := len(rest)
+			if i := strings.IndexAny(rest[1:], ".["); i >= 0 {
+				end = i + 1
+			}
+			name := rest[1:end]
+			if name == "" {
+				return nil, fmt.Errorf("empty child name")
+			}
+			segments = append(segments, jsonPathSegment{name: name, index: -1, isWildcard: name == "*"})
+			rest = rest[end:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing bracket")
+			}
+			selector := rest[1:end]
+			segment, err := parseJSONPathSelector(selector)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected character '%c'", rest[0])
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("path must select at least one field")
+	}
+
+	return segments, nil
+}
+
+// parseJSONPathSelector parses the content of a bracket selector, which is a wildcard, an index or a quoted name
+func parseJSONPathSelector(selector string) (jsonPathSegment, error) {
+	if selector == "*" {
+		return jsonPathSegment{index: -1, isWildcard: true}, nil
+	}
+	if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+		return jsonPathSegment{name: selector[1 : len(selector)-1], index: -1}, nil
+	}
+	index, err := strconv.Atoi(selector)
+	if err != nil || index < 0 {
+		return jsonPathSegment{}, fmt.Errorf("invalid selector '[%v]', expected a quoted name, an index or '*'", selector)
+	}
+	return jsonPathSegment{index: index}, nil
+}
+
+// redactJSONPath replaces all nodes selected by the segments in the decoded JSON document and returns the document
+func redactJSONPath(node interface{}, segments []jsonPathSegment) interface{} {
+	if len(segments) == 0 {
+		return redactedValue
+	}
+	segment, rest := segments[0], segments[1:]
+
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		if segment.isWildcard {
+			for name, child := range typed {
+				typed[name] = redactJSONPath(child, rest)
+			}
+		} else if child, exists := typed[segment.name]; exists && segment.index < 0 {
+			typed[segment.name] = redactJSONPath(child, rest)
+		}
+	case []interface{}:
+		if segment.isWildcard {
+			for i, child := range typed {
+				typed[i] = redactJSONPath(child, rest)
+			}
+		} else if segment.index >= 0 && segment.index < len(typed) {
+			typed[segment.index] = redactJSONPath(typed[segment.index], rest)
+		}
+	}
+
+	return node
+}
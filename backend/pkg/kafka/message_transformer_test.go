@@ -0,0 +1,172 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []jsonPathSegment
+	}{
+		{path: "$.customer.email", expected: []jsonPathSegment{{name: "customer", index: -1}, {name: "email", index: -1}}},
+		{path: "$['customer'][\"e-mail\"]", expected: []jsonPathSegment{{name: "customer", index: -1}, {name: "e-mail", index: -1}}},
+		{path: "$.items[2].price", expected: []jsonPathSegment{{name: "items", index: -1}, {index: 2}, {name: "price", index: -1}}},
+		{path: "$.items[*].*", expected: []jsonPathSegment{{name: "items", index: -1}, {index: -1, isWildcard: true}, {name: "*", index: -1, isWildcard: true}}},
+	}
+	for _, tc := range tests {
+		segments, err := parseJSONPath(tc.path)
+		require.NoError(t, err, tc.path)
+		assert.Equal(t, tc.expected, segments, tc.path)
+	}
+
+	for _, invalid := range []string{"", "$", "customer.email", "$..email", "$.", "$.items[", "$.items[-1]", "$.items[a]", "$x"} {
+		_, err := parseJSONPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestJSONFieldRedactor(t *testing.T) {
+	redactor, err := NewJSONFieldRedactor([]string{"$.customer.email", "$.items[*].card", "$.missing.field"}, []string{"authorization"})
+	require.NoError(t, err)
+	svc := &Service{
+		Logger:       zap.NewNop(),
+		Deserializer: deserializer{TopicEncodings: map[string]topicEncodings{}},
+	}
+
+	var filteredValue interface{}
+	var filteredHeaders []kgo.RecordHeader
+	isMessageOK := func(args interpreterArguments) (bool, error) {
+		filteredValue = args.Value
+		filteredHeaders = args.Headers
+		return true, nil
+	}
+	failing := MessageTransformerFunc(func(msg *TopicMessage) error {
+		if msg.Offset == 2 {
+			return errors.New("transform failed")
+		}
+		return nil
+	})
+
+	jobs := make(chan *kgo.Record, 2)
+	jobs <- &kgo.Record{Topic: "orders", Offset: 1, Key: []byte("plain text key"),
+		Headers: []kgo.RecordHeader{
+			{Key: "authorization", Value: []byte("Bearer secret")},
+			{Key: "region", Value: []byte("eu-west")},
+			{Key: "region", Value: []byte("us-east")},
+		},
+		Value: []byte(`{"customer":{"email":"jane@example.com","name":"Jane"},"items":[{"card":"4111","amount":12345678901234567890}]}`)}
+	jobs <- &kgo.Record{Topic: "orders", Offset: 2, Value: []byte(`{"customer":{"email":"john@example.com"}}`),
+		Headers: []kgo.RecordHeader{{Key: "authorization", Value: []byte("Bearer secret")}}}
+	close(jobs)
+	resultsCh := make(chan *TopicMessage, 2)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	svc.startMessageWorker(context.Background(), &wg, isMessageOK, deserializeOptions{FormatJSON: true}, map[int32]int32{}, []MessageTransformer{redactor, failing}, jobs, resultsCh)
+	close(resultsCh)
+
+	redacted := <-resultsCh
+	require.True(t, redacted.IsMessageOk)
+	assert.JSONEq(t, `{"customer":{"email":"[REDACTED]","name":"Jane"},"items":[{"card":"[REDACTED]","amount":12345678901234567890}]}`, string(redacted.Value.Payload.Payload))
+	assert.Contains(t, string(redacted.Value.Payload.Payload), "12345678901234567890", "numbers must keep their precision")
+	assert.NotContains(t, redacted.Value.FormattedPayload, "jane@example.com")
+	assert.Equal(t, []byte("plain text key"), redacted.Key.Payload.Payload)
+
+	// Headers are masked and keep their order, including repeated headers
+	require.Len(t, redacted.Headers, 3)
+	assert.Equal(t, "authorization", redacted.Headers[0].Key)
+	assert.Equal(t, []byte(redactedValue), redacted.Headers[0].Value.Payload.Payload)
+	assert.Equal(t, len("Bearer secret"), redacted.Headers[0].Value.Size)
+	assert.Equal(t, []byte("us-east"), redacted.Headers[2].Value.Payload.Payload)
+
+	// The filter code sees the redacted value and headers only
+	assert.Equal(t, "[REDACTED]", filteredValue.(map[string]interface{})["customer"].(map[string]interface{})["email"])
+	assert.Equal(t, []kgo.RecordHeader{
+		{Key: "authorization", Value: []byte(redactedValue)},
+		{Key: "region", Value: []byte("eu-west")},
+		{Key: "region", Value: []byte("us-east")},
+	}, filteredHeaders)
+	assert.False(t, matchesHeaderFilters(filteredHeaders, map[string]string{"authorization": "Bearer secret"}))
+	assert.True(t, matchesHeaderFilters(filteredHeaders, map[string]string{"region": "eu-west"}))
+
+	// Messages which could not be transformed are not returned, not even partially transformed
+	failed := <-resultsCh
+	assert.False(t, failed.IsMessageOk)
+	assert.Contains(t, failed.ErrorMessage, "transform failed")
+	assert.Nil(t, failed.Value)
+	assert.Empty(t, failed.Headers)
+}
+
+func TestService_MessageTransformers(t *testing.T) {
+	cfg := RedactionConfig{JSONPaths: []string{"$.email"}, Headers: []string{"authorization"}}
+	require.NoError(t, cfg.Validate())
+	assert.Error(t, (&RedactionConfig{JSONPaths: []string{"email"}}).Validate())
+	assert.Error(t, (&RedactionConfig{Headers: []string{""}}).Validate())
+
+	redactor, err := NewJSONFieldRedactor(cfg.JSONPaths, cfg.Headers)
+	require.NoError(t, err)
+	var requestTransformerCalls int
+	requestTransformer := MessageTransformerFunc(func(msg *TopicMessage) error {
+		assert.Equal(t, "[REDACTED]", msg.Value.Object.(map[string]interface{})["email"])
+		requestTransformerCalls++
+		return nil
+	})
+	svc := &Service{
+		Logger:              zap.NewNop(),
+		Deserializer:        deserializer{TopicEncodings: map[string]topicEncodings{}},
+		messageTransformers: []MessageTransformer{redactor},
+	}
+
+	record := &kgo.Record{
+		Topic:   "orders",
+		Offset:  3,
+		Value:   []byte(`{"email":"jane@example.com"}`),
+		Headers: []kgo.RecordHeader{{Key: "authorization", Value: []byte("Bearer secret")}},
+	}
+
+	// Single messages are redacted as well
+	msg, err := svc.decodeRecord(context.Background(), record, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"email":"[REDACTED]"}`, string(msg.Value.Payload.Payload))
+	assert.Equal(t, []byte(redactedValue), msg.Headers[0].Value.Payload.Payload)
+
+	// Scanned messages too
+	msg = svc.newScannedTopicMessage(record)
+	assert.JSONEq(t, `{"email":"[REDACTED]"}`, string(msg.Value.Payload.Payload))
+	assert.Equal(t, []byte(redactedValue), msg.Headers[0].Value.Payload.Payload)
+
+	// The transformers of a request run after the service's transformers
+	unredacted := &TopicMessage{Value: svc.Deserializer.DeserializeRecord(record, deserializeOptions{}).Value}
+	require.NoError(t, applyMessageTransformers(unredacted, svc.transformersFor([]MessageTransformer{requestTransformer})))
+	assert.Equal(t, 1, requestTransformerCalls)
+
+	// Messages which can't be transformed are not returned
+	svc.messageTransformers = []MessageTransformer{MessageTransformerFunc(func(msg *TopicMessage) error {
+		return errors.New("transform failed")
+	})}
+	_, err = svc.decodeRecord(context.Background(), record, 1)
+	assert.Error(t, err)
+}
+
+func TestJSONFieldRedactor_Truncated(t *testing.T) {
+	redactor, err := NewJSONFieldRedactor([]string{"$.email"}, nil)
+	require.NoError(t, err)
+
+	d := deserializer{TopicEncodings: map[string]topicEncodings{}}
+	rec := d.DeserializeRecord(&kgo.Record{Topic: "orders", Value: []byte(`{"email":"jane@example.com","name":"Jane"}`)}, deserializeOptions{MaxPayloadBytes: 16})
+	require.True(t, rec.ValueTruncated)
+
+	msg := &TopicMessage{Key: rec.Key, Value: rec.Value, ValueTruncated: true}
+	require.NoError(t, redactor.Transform(msg))
+	assert.Equal(t, []byte(redactedValue), msg.Value.Payload.Payload)
+	assert.Equal(t, messageEncodingText, msg.Value.RecognizedEncoding)
+}
@@ -0,0 +1,161 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Defaults which bound the scan of GetMessagesByProducerID if the options don't set them.
+const (
+	defaultProducerMessagesMaxMessages        = 100
+	defaultProducerMessagesMaxScannedMessages = 100000
+	defaultProducerMessagesTimeout            = 30 * time.Second
+)
+
+// ProducerMessagesOptions bound the scan of GetMessagesByProducerID. MaxMessages limits the number of returned
+// messages, MaxScannedMessages the number of consumed records. Zero values use the defaults of 100 messages, 100k
+// scanned messages and 30s.
+type ProducerMessagesOptions struct {
+	MaxMessages        int
+	MaxScannedMessages int
+	Timeout            time.Duration
+}
+
+// ProducerMessagesResult are the messages of a single producer, sorted by partition and offset. If IsTruncated is
+// set, the scan stopped before reaching the end of all partitions and more messages of the producer may exist.
+type ProducerMessagesResult struct {
+	Messages        []*TopicMessage `json:"messages"`
+	ScannedMessages int             `json:"scannedMessages"`
+	IsTruncated     bool            `json:"isTruncated"`
+	TruncatedReason string          `json:"truncatedReason,omitempty"`
+}
+
+// GetMessagesByProducerID scans the topic from the oldest offset up to the high water marks at the time of the request
+// and returns the messages written by the given producer ID, e.g. to isolate the output of a misbehaving transactional
+// producer. Records of aborted and open transactions are returned as well, control records are skipped.
+//
+// The producer ID is stored per record batch, hence the filter operates at batch granularity. Records in the message
+// format of Kafka versions before 0.11 have no producer ID and never match, neither do records of producers which are
+// neither idempotent nor transactional.
+func (s *Service) GetMessagesByProducerID(ctx context.Context, topic string, producerID int64, opts ProducerMessagesOptions) (*ProducerMessagesResult, error) {
+	if producerID < 0 {
+		return nil, fmt.Errorf("producer id must not be negative")
+	}
+	if opts.MaxMessages <= 0 {
+		opts.MaxMessages = defaultProducerMessagesMaxMessages
+	}
+	if opts.MaxScannedMessages <= 0 {
+		opts.MaxScannedMessages = defaultProducerMessagesMaxScannedMessages
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultProducerMessagesTimeout
+	}
+
+	partitionIDs, err := s.ListPartitionIDs(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	// Records of aborted and open transactions are returned as well, hence the scan is bounded by the high water marks
+	consumer, endOffsets, err := s.newBoundedConsumer(ctx, topic, partitionIDs, IsolationLevelReadUncommitted)
+	if err != nil {
+		return nil, err
+	}
+	collector := newProducerMessagesCollector(producerID, opts)
+	if len(endOffsets) == 0 {
+		return s.producerMessagesResult(collector), nil
+	}
+	defer consumer.Close()
+
+	scanCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	err = consumeBounded(scanCtx, consumer, endOffsets, func(record *kgo.Record) bool {
+		collector.add(record)
+		return !collector.isTruncated
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		collector.truncate(fmt.Sprintf("timeout of %v reached", opts.Timeout))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return s.producerMessagesResult(collector), nil
+}
+
+// producerMessagesResult deserializes the collected records
+func (s *Service) producerMessagesResult(collector *producerMessagesCollector) *ProducerMessagesResult {
+	records := collector.records
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Partition != records[j].Partition {
+			return records[i].Partition < records[j].Partition
+		}
+		return records[i].Offset < records[j].Offset
+	})
+	messages := make([]*TopicMessage, len(records))
+	for i, record := range records {
+		messages[i] = s.newScannedTopicMessage(record)
+	}
+
+	return &ProducerMessagesResult{
+		Messages:        messages,
+		ScannedMessages: collector.scannedMessages,
+		IsTruncated:     collector.isTruncated,
+		TruncatedReason: collector.truncatedReason,
+	}
+}
+
+// producerMessagesCollector keeps the records of a single producer until the message or scan limit is reached.
+type producerMessagesCollector struct {
+	producerID         int64
+	maxMessages        int
+	maxScannedMessages int
+
+	records         []*kgo.Record
+	scannedMessages int
+	isTruncated     bool
+	truncatedReason string
+}
+
+func newProducerMessagesCollector(producerID int64, opts ProducerMessagesOptions) *producerMessagesCollector {
+	return &producerMessagesCollector{
+		producerID:         producerID,
+		maxMessages:        opts.MaxMessages,
+		maxScannedMessages: opts.MaxScannedMessages,
+		records:            make([]*kgo.Record, 0),
+	}
+}
+
+// add keeps the record if it has been written by the producer, unless it would exceed one of the limits, in which
+// case the result is truncated instead. Control records count as scanned, because the consumer keeps them in order
+// to finish partitions which end with a transaction marker.
+func (c *producerMessagesCollector) add(record *kgo.Record) {
+	if c.isTruncated {
+		return
+	}
+	if c.scannedMessages >= c.maxScannedMessages {
+		c.truncate(fmt.Sprintf("max scanned messages of %v reached", c.maxScannedMessages))
+		return
+	}
+	c.scannedMessages++
+
+	if record.Attrs.IsControl() || record.ProducerID != c.producerID {
+		return
+	}
+	if len(c.records) >= c.maxMessages {
+		c.truncate(fmt.Sprintf("max messages of %v reached", c.maxMessages))
+		return
+	}
+	c.records = append(c.records, record)
+}
+
+func (c *producerMessagesCollector) truncate(reason string) {
+	c.isTruncated = true
+	c.truncatedReason = reason
+}
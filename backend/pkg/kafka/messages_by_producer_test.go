@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestProducerMessagesCollector(t *testing.T) {
+	collector := newProducerMessagesCollector(1000, ProducerMessagesOptions{MaxMessages: 10, MaxScannedMessages: 10})
+	collector.add(&kgo.Record{Partition: 0, Offset: 0, ProducerID: 1000, Value: []byte("a")})
+	collector.add(&kgo.Record{Partition: 0, Offset: 1, ProducerID: 2000, Value: []byte("b")})
+	collector.add(&kgo.Record{Partition: 1, Offset: 0, ProducerID: 1000, Value: []byte("c")})
+	collector.add(&kgo.Record{Partition: 1, Offset: 1, ProducerID: -1, Value: []byte("legacy")})
+	collector.add(&kgo.Record{Partition: 0, Offset: 2, ProducerID: 2000, Value: []byte("d")})
+
+	assert.False(t, collector.isTruncated)
+	assert.Equal(t, 5, collector.scannedMessages)
+	values := make([]string, 0)
+	for _, record := range collector.records {
+		values = append(values, string(record.Value))
+	}
+	assert.Equal(t, []string{"a", "c"}, values)
+
+	// Reaching, but not exceeding a limit does not truncate the result
+	limited := newProducerMessagesCollector(1000, ProducerMessagesOptions{MaxMessages: 1, MaxScannedMessages: 10})
+	limited.add(&kgo.Record{ProducerID: 1000})
+	limited.add(&kgo.Record{ProducerID: 2000})
+	assert.False(t, limited.isTruncated)
+	limited.add(&kgo.Record{ProducerID: 1000})
+	assert.True(t, limited.isTruncated)
+	assert.Equal(t, "max messages of 1 reached", limited.truncatedReason)
+	assert.Len(t, limited.records, 1)
+
+	scanLimited := newProducerMessagesCollector(1000, ProducerMessagesOptions{MaxMessages: 10, MaxScannedMessages: 2})
+	scanLimited.add(&kgo.Record{ProducerID: 2000})
+	scanLimited.add(&kgo.Record{ProducerID: 2000})
+	scanLimited.add(&kgo.Record{ProducerID: 1000})
+	assert.True(t, scanLimited.isTruncated)
+	assert.Equal(t, "max scanned messages of 2 reached", scanLimited.truncatedReason)
+	assert.Equal(t, 2, scanLimited.scannedMessages)
+	assert.Empty(t, scanLimited.records)
+}
+
+func TestService_GetMessagesByProducerID_NegativeProducerID(t *testing.T) {
+	svc := &Service{}
+	_, err := svc.GetMessagesByProducerID(context.Background(), "orders", -1, ProducerMessagesOptions{})
+	assert.Error(t, err)
+}
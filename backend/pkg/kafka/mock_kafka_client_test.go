@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// mockKafkaClient is a KafkaClient which passes all requests to the handler function. The broker ID is -1 for
+// requests which are not sent to a specific broker.
+type mockKafkaClient struct {
+	handle func(ctx context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error)
+}
+
+func (m *mockKafkaClient) Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	return m.handle(ctx, -1, req)
+}
+
+func (m *mockKafkaClient) RequestSharded(ctx context.Context, req kmsg.Request) []kgo.ResponseShard {
+	res, err := m.handle(ctx, -1, req)
+	return []kgo.ResponseShard{{Meta: kgo.BrokerMetadata{NodeID: -1}, Req: req, Resp: res, Err: err}}
+}
+
+func (m *mockKafkaClient) ForBroker(brokerID int32) kmsg.Requestor {
+	return &mockBrokerRequestor{client: m, brokerID: brokerID}
+}
+
+type mockBrokerRequestor struct {
+	client   *mockKafkaClient
+	brokerID int32
+}
+
+func (m *mockBrokerRequestor) Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	return m.client.handle(ctx, m.brokerID, req)
+}
+
+// unexpectedRequestError is returned by mock handlers for requests the test did not expect
+func unexpectedRequestError(brokerID int32, req kmsg.Request) error {
+	return fmt.Errorf("unexpected %v request to broker '%v'", kmsg.NameForKey(req.Key()), brokerID)
+}
+
+// apiVersionsResponse returns an ApiVersions response which announces support for the given requests' max versions
+func apiVersionsResponse(supported ...kmsg.Request) *kmsg.ApiVersionsResponse {
+	res := &kmsg.ApiVersionsResponse{}
+	for _, req := range supported {
+		res.ApiKeys = append(res.ApiKeys, kmsg.ApiVersionsResponseApiKey{ApiKey: req.Key(), MaxVersion: req.MaxVersion()})
+	}
+	return res
+}
@@ -0,0 +1,183 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Partitioner decides to which partition a produced record is written.
+type Partitioner string
+
+const (
+	// PartitionerHash writes records with the same key into the same partition, using the same murmur2 hash as the
+	// default partitioner of the Java client. Records without key are distributed round-robin. This is the default.
+	PartitionerHash Partitioner = "hash"
+	// PartitionerExplicit writes the record into the partition that is set in the ProduceRecord.
+	PartitionerExplicit Partitioner = "explicit"
+	// PartitionerRoundRobin distributes records evenly across all partitions.
+	PartitionerRoundRobin Partitioner = "round-robin"
+	// PartitionerRandom writes each record into a random partition.
+	PartitionerRandom Partitioner = "random"
+)
+
+// ProduceRecord is a record that shall be produced. PartitionID is only considered by the explicit partitioner.
+type ProduceRecord struct {
+	Key         []byte
+	Value       []byte
+	Headers     []kgo.RecordHeader
+	Partitioner Partitioner
+	PartitionID int32
+}
+
+// ProduceResult is the partition and offset a record has been written to. Error is set if the record could not be
+// produced, in which case partition and offset are -1.
+type ProduceResult struct {
+	PartitionID int32 `json:"partitionId"`
+	Offset      int64 `json:"offset"`
+	Error       error `json:"-"`
+}
+
+// produceMaxBufferedRecords limits the number of records which are waiting to be produced, so that producing a large
+// batch does not buffer the whole batch in the client.
+const produceMaxBufferedRecords = 1000
+
+// ProduceMessage produces a single record into the given topic and waits until it has been acknowledged. The
+// partition is chosen by us rather than by the client, so that the result does not depend on the client's partitioner
+// state. An error wrapping ErrPartitionNotFound is returned if an explicit partition does not exist.
+func (s *Service) ProduceMessage(ctx context.Context, topic string, record ProduceRecord) (ProduceResult, error) {
+	results, err := s.ProduceMessages(ctx, topic, []ProduceRecord{record})
+	if err != nil {
+		return ProduceResult{}, err
+	}
+	if results[0].Error != nil {
+		return ProduceResult{}, results[0].Error
+	}
+
+	return results[0], nil
+}
+
+// ProduceMessages produces all records into the given topic with a single producer and waits until all of them have
+// been acknowledged or failed. The results are in the order of the records and carry the error of each record, an
+// error is only returned if the topic's partitions can not be determined or the producer can not be created. Records
+// are produced as the client's buffer drains, so that large batches don't need to be buffered at once. See
+// ProduceMessage for how partitions are chosen.
+func (s *Service) ProduceMessages(ctx context.Context, topic string, records []ProduceRecord) ([]ProduceResult, error) {
+	partitionCount, err := s.PartitionCount(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	producer, err := s.NewKgoClient(append(clientIDOpts(ctx),
+		kgo.RecordPartitioner(recordPartitioner{}),
+		kgo.MaxBufferedRecords(produceMaxBufferedRecords),
+	)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+	defer producer.Close()
+
+	return s.produceRecords(ctx, producer, topic, records, partitionCount), nil
+}
+
+// produceRecords produces the records into the given topic and returns the result of each record once all of them
+// have been acknowledged or failed.
+func (s *Service) produceRecords(ctx context.Context, producer recordProducer, topic string, records []ProduceRecord, partitionCount int32) []ProduceResult {
+	results := make([]ProduceResult, len(records))
+	wg := sync.WaitGroup{}
+	for i, record := range records {
+		i := i
+		results[i] = ProduceResult{PartitionID: -1, Offset: -1}
+		partitionID, err := s.choosePartition(topic, record, partitionCount)
+		if err != nil {
+			results[i].Error = err
+			continue
+		}
+
+		// Each promise writes its own result only, hence no locking is required
+		promise := func(produced *kgo.Record, err error) {
+			defer wg.Done()
+			if err != nil {
+				results[i].Error = fmt.Errorf("failed to produce record: %w", err)
+				return
+			}
+			results[i].PartitionID = produced.Partition
+			results[i].Offset = produced.Offset
+		}
+		wg.Add(1)
+		err = producer.Produce(ctx, &kgo.Record{
+			Topic:     topic,
+			Partition: partitionID,
+			Key:       record.Key,
+			Value:     record.Value,
+			Headers:   record.Headers,
+		}, promise)
+		if err != nil {
+			wg.Done()
+			results[i].Error = fmt.Errorf("failed to produce record: %w", err)
+		}
+	}
+	wg.Wait()
+
+	return results
+}
+
+// choosePartition returns the partition the record shall be written to, according to the record's partitioner.
+func (s *Service) choosePartition(topic string, record ProduceRecord, partitionCount int32) (int32, error) {
+	if partitionCount <= 0 {
+		return 0, fmt.Errorf("topic '%v' has no partitions", topic)
+	}
+
+	switch record.Partitioner {
+	case PartitionerHash, "":
+		if record.Key == nil {
+			return s.nextRoundRobinPartition(partitionCount), nil
+		}
+		return hashPartition(topic, record.Key, partitionCount), nil
+	case PartitionerExplicit:
+		if err := ValidatePartitionID(topic, record.PartitionID, partitionCount); err != nil {
+			return 0, err
+		}
+		return record.PartitionID, nil
+	case PartitionerRoundRobin:
+		return s.nextRoundRobinPartition(partitionCount), nil
+	case PartitionerRandom:
+		return rand.Int31n(partitionCount), nil
+	default:
+		return 0, fmt.Errorf("unknown partitioner '%v'", record.Partitioner)
+	}
+}
+
+// PartitionForKey returns the partition a record with the given key would be produced to by the hash partitioner, which
+// is the same partition the default partitioner of the Java client chooses. The result is only valid as long as the
+// topic's partition count doesn't change. Records without key don't have a fixed partition, hence the key must
+// not be nil. An error wrapping ErrTopicNotFound is returned if the topic does not exist.
+func (s *Service) PartitionForKey(ctx context.Context, topic string, key []byte) (int32, error) {
+	if key == nil {
+		return 0, fmt.Errorf("records without key are not assigned to a fixed partition")
+	}
+	partitionCount, err := s.PartitionCount(ctx, topic)
+	if err != nil {
+		return 0, err
+	}
+	if partitionCount <= 0 {
+		return 0, fmt.Errorf("topic '%v' has no partitions", topic)
+	}
+
+	return hashPartition(topic, key, partitionCount), nil
+}
+
+// hashPartition returns the partition of a keyed record as chosen by the default partitioner of the Java client.
+func hashPartition(topic string, key []byte, partitionCount int32) int32 {
+	partitioner := kgo.StickyKeyPartitioner(nil).ForTopic(topic)
+	return int32(partitioner.Partition(&kgo.Record{Key: key}, int(partitionCount)))
+}
+
+func (s *Service) nextRoundRobinPartition(partitionCount int32) int32 {
+	next := atomic.AddUint32(&s.roundRobinCounter, 1) - 1
+	return int32(next % uint32(partitionCount))
+}
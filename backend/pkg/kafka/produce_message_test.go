@@ -0,0 +1,128 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestService_ChoosePartition(t *testing.T) {
+	svc := &Service{}
+
+	// The same key always lands in the same partition
+	keyed := ProduceRecord{Key: []byte("customer-42")}
+	partitionID, err := svc.choosePartition("orders", keyed, 6)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		again, err := svc.choosePartition("orders", keyed, 6)
+		require.NoError(t, err)
+		assert.Equal(t, partitionID, again)
+	}
+
+	explicit, err := svc.choosePartition("orders", ProduceRecord{Partitioner: PartitionerExplicit, PartitionID: 5}, 6)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), explicit)
+	_, err = svc.choosePartition("orders", ProduceRecord{Partitioner: PartitionerExplicit, PartitionID: 6}, 6)
+	assert.True(t, errors.Is(err, ErrPartitionNotFound))
+
+	roundRobin := make([]int32, 0)
+	for i := 0; i < 4; i++ {
+		partitionID, err := svc.choosePartition("orders", ProduceRecord{Partitioner: PartitionerRoundRobin}, 3)
+		require.NoError(t, err)
+		roundRobin = append(roundRobin, partitionID)
+	}
+	assert.Equal(t, []int32{0, 1, 2, 0}, roundRobin)
+
+	random, err := svc.choosePartition("orders", ProduceRecord{Partitioner: PartitionerRandom}, 3)
+	require.NoError(t, err)
+	assert.True(t, random >= 0 && random < 3)
+
+	_, err = svc.choosePartition("orders", ProduceRecord{Partitioner: "sticky"}, 3)
+	assert.Error(t, err)
+	_, err = svc.choosePartition("orders", ProduceRecord{}, 0)
+	assert.Error(t, err)
+}
+
+func TestService_PartitionForKey(t *testing.T) {
+	const partitionCount = 12
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		metadataReq, ok := req.(*kmsg.MetadataRequest)
+		if !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		if len(metadataReq.Topics) == 0 {
+			return &kmsg.MetadataResponse{}, nil // metadata refresh before retrying unknown topics
+		}
+		topic := kmsg.MetadataResponseTopic{Topic: *metadataReq.Topics[0].Topic}
+		switch topic.Topic {
+		case "orders":
+			for i := int32(0); i < partitionCount; i++ {
+				topic.Partitions = append(topic.Partitions, kmsg.MetadataResponseTopicPartition{Partition: i})
+			}
+		case "unknown":
+			topic.ErrorCode = kerr.UnknownTopicOrPartition.Code
+		}
+		return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{topic}}, nil
+	}}
+	svc := &Service{KafkaClient: client}
+
+	// murmur2 vectors of the Java client's UtilsTest, the partition is the positive hash modulo the partition count
+	vectors := []struct {
+		key  string
+		hash int32
+	}{
+		{key: "21", hash: -973932308},
+		{key: "foobar", hash: -790332482},
+		{key: "a-little-bit-long-string", hash: -985981536},
+		{key: "a-little-bit-longer-string", hash: -1486304829},
+		{key: "lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8", hash: -58897971},
+		{key: "abc", hash: 479470107},
+	}
+	for _, vector := range vectors {
+		partitionID, err := svc.PartitionForKey(context.Background(), "orders", []byte(vector.key))
+		require.NoError(t, err, vector.key)
+		assert.Equal(t, (vector.hash&0x7fffffff)%partitionCount, partitionID, vector.key)
+	}
+
+	_, err := svc.PartitionForKey(context.Background(), "orders", nil)
+	assert.Error(t, err)
+	_, err = svc.PartitionForKey(context.Background(), "empty", []byte("abc"))
+	assert.Error(t, err)
+	_, err = svc.PartitionForKey(context.Background(), "unknown", []byte("abc"))
+	assert.True(t, errors.Is(err, ErrTopicNotFound))
+}
+
+func TestService_ProduceRecords_PartialFailure(t *testing.T) {
+	svc := &Service{}
+	producer := &mockRecordProducer{failValue: "too large"}
+
+	// A failing record must neither fail nor stop producing the other records
+	results := svc.produceRecords(context.Background(), producer, "orders", []ProduceRecord{
+		{Value: []byte("a"), Partitioner: PartitionerExplicit, PartitionID: 0},
+		{Value: []byte("too large"), Partitioner: PartitionerExplicit, PartitionID: 1},
+		{Value: []byte("b"), Partitioner: PartitionerExplicit, PartitionID: 5},
+		{Value: []byte("c"), Partitioner: PartitionerExplicit, PartitionID: 2},
+	}, 3)
+	require.Len(t, results, 4)
+
+	assert.NoError(t, results[0].Error)
+	assert.Equal(t, int32(0), results[0].PartitionID)
+
+	assert.True(t, errors.Is(results[1].Error, kerr.MessageTooLarge))
+	assert.Equal(t, ProduceResult{PartitionID: -1, Offset: -1, Error: results[1].Error}, results[1])
+
+	assert.True(t, errors.Is(results[2].Error, ErrPartitionNotFound))
+	assert.Equal(t, int32(-1), results[2].PartitionID)
+
+	assert.NoError(t, results[3].Error)
+	assert.Equal(t, int32(2), results[3].PartitionID)
+
+	require.Len(t, producer.produced, 2)
+	assert.Equal(t, []byte("a"), producer.produced[0].Value)
+	assert.Equal(t, []byte("c"), producer.produced[1].Value)
+}
@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"golang.org/x/sync/singleflight"
+)
+
+// minMetadataRefreshInterval is the minimum time between two metadata refreshes
+const minMetadataRefreshInterval = 5 * time.Second
+
+// metadataRefreshTimeout bounds a shared metadata refresh, which does not use the context of any of its callers
+const metadataRefreshTimeout = 10 * time.Second
+
+// metadataRefresher deduplicates metadata refreshes. Concurrent callers share a single in-flight refresh and callers
+// within the minimum interval after a refresh get the result of that refresh, so that many requests failing at the
+// same time (e.g. because a broker went away) do not all send their own metadata request.
+type metadataRefresher struct {
+	minInterval  time.Duration
+	timeout      time.Duration
+	now          func() time.Time
+	requestGroup singleflight.Group
+
+	mutex       sync.Mutex
+	lastRefresh time.Time
+	lastErr     error
+}
+
+func newMetadataRefresher(minInterval time.Duration) *metadataRefresher {
+	return &metadataRefresher{
+		minInterval:  minInterval,
+		timeout:      metadataRefreshTimeout,
+		now:          time.Now,
+		requestGroup: singleflight.Group{},
+	}
+}
+
+// Refresh calls the refresh function unless a refresh is already in flight or the last refresh has completed less
+// than the minimum interval ago. In both cases the result of that refresh is returned. A nil refresher calls the
+// refresh function every time.
+//
+// The refresh is shared by all concurrent callers, hence it runs with its own timeout rather than the context of the
+// caller which started it. A caller whose context is done stops waiting, but the refresh continues for the others.
+// Refreshes which failed due to the timeout are not remembered, so that the next caller tries again.
+func (r *metadataRefresher) Refresh(ctx context.Context, refresh func(ctx context.Context) error) error {
+	if r == nil {
+		return refresh(ctx)
+	}
+
+	r.mutex.Lock()
+	if !r.lastRefresh.IsZero() && r.now().Sub(r.lastRefresh) < r.minInterval {
+		err := r.lastErr
+		r.mutex.Unlock()
+		return err
+	}
+	r.mutex.Unlock()
+
+	resCh := r.requestGroup.DoChan("refresh", func() (interface{}, error) {
+		refreshCtx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		defer cancel()
+		err := refresh(refreshCtx)
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+
+		r.mutex.Lock()
+		r.lastRefresh = r.now()
+		r.lastErr = err
+		r.mutex.Unlock()
+
+		return nil, err
+	})
+
+	select {
+	case res := <-resCh:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RefreshMetadata requests the broker list of the cluster. The Kafka client intercepts metadata requests and updates
+// its known brokers and controller from the response, so that requests sent to a specific broker (e.g. a group
+// coordinator) pick up added, removed or moved brokers. The client's topic and partition metadata, which is used for
+// producing and consuming, is not updated by this request. The client version we use has no way to trigger that
+// refresh, it happens in the background every MetadataRefreshInterval and whenever a produce or fetch fails.
+//
+// Concurrent calls share a single metadata request and the metadata is not requested more often than every
+// minMetadataRefreshInterval.
+func (s *Service) RefreshMetadata(ctx context.Context) error {
+	return s.metadataRefresher.Refresh(ctx, func(ctx context.Context) error {
+		req := kmsg.NewMetadataRequest()
+		req.Topics = []kmsg.MetadataRequestTopic{}
+		_, err := req.RequestWith(ctx, s.KafkaClient)
+		return err
+	})
+}
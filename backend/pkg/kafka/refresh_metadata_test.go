@@ -0,0 +1,135 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestMetadataRefresher_SharesConcurrentRefreshes(t *testing.T) {
+	refresher := newMetadataRefresher(time.Minute)
+
+	var calls int32
+	release := make(chan struct{})
+	refresh := func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return errors.New("broker not reachable")
+	}
+
+	wg := sync.WaitGroup{}
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = refresher.Refresh(context.Background(), refresh)
+		}(i)
+	}
+	// Wait until the first refresh is in flight before letting it complete
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, err := range errs {
+		assert.EqualError(t, err, "broker not reachable")
+	}
+}
+
+func TestMetadataRefresher_MinInterval(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	refresher := newMetadataRefresher(5 * time.Second)
+	refresher.now = func() time.Time { return now }
+
+	calls := 0
+	refresh := func(ctx context.Context) error {
+		calls++
+		return nil
+	}
+
+	assert.NoError(t, refresher.Refresh(context.Background(), refresh))
+	now = now.Add(4 * time.Second)
+	assert.NoError(t, refresher.Refresh(context.Background(), refresh))
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(time.Second)
+	assert.NoError(t, refresher.Refresh(context.Background(), refresh))
+	assert.Equal(t, 2, calls)
+}
+
+func TestMetadataRefresher_CancelledFirstCaller(t *testing.T) {
+	refresher := newMetadataRefresher(time.Minute)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	refresh := func(ctx context.Context) error {
+		close(started)
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// The first caller gives up while its refresh is in flight
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() { firstErr <- refresher.Refresh(firstCtx, refresh) }()
+	<-started
+	secondErr := make(chan error, 1)
+	go func() { secondErr <- refresher.Refresh(context.Background(), refresh) }()
+	cancel()
+	assert.True(t, errors.Is(<-firstErr, context.Canceled))
+
+	// The shared refresh is not affected by the cancellation and completes for the second caller
+	close(release)
+	assert.NoError(t, <-secondErr)
+}
+
+func TestMetadataRefresher_TimeoutIsNotRemembered(t *testing.T) {
+	refresher := newMetadataRefresher(time.Minute)
+	refresher.timeout = time.Millisecond
+
+	calls := 0
+	refresh := func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	assert.True(t, errors.Is(refresher.Refresh(context.Background(), refresh), context.DeadlineExceeded))
+	assert.NoError(t, refresher.Refresh(context.Background(), refresh))
+	assert.Equal(t, 2, calls)
+}
+
+func TestRefreshMetadata(t *testing.T) {
+	var metadataRequests int32
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		metadataReq, ok := req.(*kmsg.MetadataRequest)
+		if !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		assert.Empty(t, metadataReq.Topics)
+		atomic.AddInt32(&metadataRequests, 1)
+		return &kmsg.MetadataResponse{}, nil
+	}}
+	svc := &Service{KafkaClient: client, metadataRefresher: newMetadataRefresher(time.Minute)}
+
+	assert.NoError(t, svc.RefreshMetadata(context.Background()))
+	assert.NoError(t, svc.RefreshMetadata(context.Background()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&metadataRequests))
+}
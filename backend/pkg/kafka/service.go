@@ -3,6 +3,7 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kerr"
@@ -15,6 +16,7 @@ import (
 	"github.com/twmb/franz-go/pkg/kmsg"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 // Service acts as interface to interact with the Kafka Cluster
@@ -23,11 +25,34 @@ type Service struct {
 	Logger *zap.Logger
 
 	KafkaClientHooks kgo.Hook
-	KafkaClient      *kgo.Client
+	KafkaClient      KafkaClient
 	SchemaService    *schema.Service
 	ProtoService     *proto.Service
 	Deserializer     deserializer
 	MetricsNamespace string
+
+	circuitBreaker    *brokerCircuitBreaker
+	metadataRefresher *metadataRefresher
+
+	clusterVersionsMutex        sync.Mutex
+	clusterVersions             *kversion.Versions
+	clusterVersionsFetchedAt    time.Time
+	clusterVersionsRequestGroup singleflight.Group
+
+	// roundRobinCounter is the number of records produced with the round-robin partitioner
+	roundRobinCounter uint32
+
+	// coordinatorCache caches the coordinators of groups and transactions, nil disables caching
+	coordinatorCache *coordinatorCache
+
+	// messageTransformers are applied to all consumed messages before the transformers of the request, e.g. the
+	// configured redaction
+	messageTransformers []MessageTransformer
+
+	// clientIDPool sends requests with request scoped client IDs, ownedClient is the client created by NewService. Both
+	// are closed by Close, nil if there is nothing to close.
+	clientIDPool *clientIDPool
+	ownedClient  *kgo.Client
 }
 
 // NewService creates a new Kafka service and immediately checks connectivity to all components. If any of these external
@@ -51,6 +76,25 @@ func NewService(cfg Config, logger *zap.Logger, metricsNamespace string) (*Servi
 		return nil, fmt.Errorf("failed to test kafka connection: %w", err)
 	}
 
+	svc, err := NewServiceFromClient(cfg, kafkaClient, logger)
+	if err != nil {
+		return nil, err
+	}
+	svc.KafkaClientHooks = clientHooks
+	svc.MetricsNamespace = metricsNamespace
+	svc.ownedClient = kafkaClient
+
+	return svc, nil
+}
+
+// NewServiceFromClient creates a new Kafka service that sends all requests with the given client, which allows to
+// share a client or to inject a client for a test cluster. Unlike NewService it does not test the connectivity to
+// Kafka. The config is still required, because consuming messages creates dedicated clients based on it and because
+// it configures the deserializers. The returned service has no client hooks attached, so that no metrics are
+// registered.
+func NewServiceFromClient(cfg Config, kafkaClient *kgo.Client, logger *zap.Logger) (*Service, error) {
+	var err error
+
 	// Schema Registry
 	var schemaSvc *schema.Service
 	if cfg.Schema.Enabled {
@@ -86,20 +130,40 @@ func NewService(cfg Config, logger *zap.Logger, metricsNamespace string) (*Servi
 		}
 	}
 
-	return &Service{
+	svc := &Service{
 		Config:           cfg,
 		Logger:           logger,
-		KafkaClientHooks: clientHooks,
-		KafkaClient:      kafkaClient,
+		KafkaClientHooks: nil,
 		SchemaService:    schemaSvc,
 		ProtoService:     protoSvc,
 		Deserializer: deserializer{
 			SchemaService:  schemaSvc,
 			ProtoService:   protoSvc,
 			MsgPackService: msgPackSvc,
+			TopicEncodings: topicEncodingsByName(cfg.TopicEncodings),
+			avroConverters: newAvroConverterCache(),
 		},
-		MetricsNamespace: metricsNamespace,
-	}, nil
+		circuitBreaker:    newBrokerCircuitBreaker(cfg.CircuitBreaker),
+		metadataRefresher: newMetadataRefresher(minMetadataRefreshInterval),
+		coordinatorCache:  newCoordinatorCache(defaultCoordinatorCacheTTL),
+	}
+	if cfg.Redaction.isEnabled() {
+		redactor, err := NewJSONFieldRedactor(cfg.Redaction.JSONPaths, cfg.Redaction.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redactor: %w", err)
+		}
+		svc.messageTransformers = []MessageTransformer{redactor}
+	}
+	svc.clientIDPool = newClientIDPool(kgoClient{Client: kafkaClient}, func(clientID string) (closableKafkaClient, error) {
+		client, err := svc.NewKgoClient(kgo.ClientID(clientID))
+		if err != nil {
+			return nil, err
+		}
+		return kgoClient{Client: client}, nil
+	}, maxClientIDPoolSize, logger)
+	svc.KafkaClient = svc.clientIDPool
+
+	return svc, nil
 }
 
 // Start starts all the (background) tasks which are required for this service to work properly. If any of these
@@ -111,12 +175,26 @@ func (s *Service) Start() error {
 	return s.ProtoService.Start()
 }
 
-func (s *Service) NewKgoClient() (*kgo.Client, error) {
+// Close closes all Kafka clients which have been created by the service, so that their broker connections are released
+// on shutdown. Clients which have been passed to NewServiceFromClient are owned by the caller and not closed.
+func (s *Service) Close() {
+	if s.clientIDPool != nil {
+		s.clientIDPool.Close()
+	}
+	if s.ownedClient != nil {
+		s.ownedClient.Close()
+	}
+}
+
+// NewKgoClient creates a new Kafka client based on the service's config. Additional options can be passed which will
+// be applied on top of the default config (e.g. consumer specific options).
+func (s *Service) NewKgoClient(additionalOpts ...kgo.Opt) (*kgo.Client, error) {
 	// Kafka client
 	kgoOpts, err := NewKgoConfig(&s.Config, s.Logger, s.KafkaClientHooks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create a valid kafka client config: %w", err)
 	}
+	kgoOpts = append(kgoOpts, additionalOpts...)
 
 	kafkaClient, err := kgo.NewClient(kgoOpts...)
 	if err != nil {
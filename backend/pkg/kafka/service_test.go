@@ -0,0 +1,27 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+func TestNewServiceFromClient(t *testing.T) {
+	// The client does not connect before the first request, hence no broker needs to be reachable here
+	client, err := kgo.NewClient(kgo.SeedBrokers("127.0.0.1:1"))
+	require.NoError(t, err)
+	defer client.Close()
+
+	cfg := Config{}
+	cfg.SetDefaults()
+	svc, err := NewServiceFromClient(cfg, client, zap.NewNop())
+	require.NoError(t, err)
+	// Requests without a request scoped client ID are sent with the given client
+	pool, ok := svc.KafkaClient.(*clientIDPool)
+	require.True(t, ok)
+	assert.Equal(t, kgoClient{Client: client}, pool.defaultClient)
+	assert.NotNil(t, svc.circuitBreaker)
+}
@@ -0,0 +1,119 @@
+package kafka
+
+import (
+	"container/heap"
+	"math"
+	"time"
+)
+
+// defaultTimestampMergeMaxBuffered limits the number of messages the timestamp merger holds back, so that a
+// partition without progress (or a huge reorder window) can not make us buffer the whole topic.
+const defaultTimestampMergeMaxBuffered = 10000
+
+// timestampMerger reorders the messages of multiple partitions, so that they are emitted in ascending timestamp
+// order. Ties are broken by partition ID and offset.
+//
+// Messages are emitted once no partition can return an older message anymore. We assume that a partition never
+// returns a message which is more than the reorder window older than the newest message seen in that partition
+// (Kafka does not enforce increasing timestamps within a partition, e.g. for producer assigned timestamps). So the
+// output is strictly ordered, as long as timestamps within each partition are out of order by at most the window and
+// no more than maxBuffered messages need to be held back. If the buffer is full, the oldest message is emitted early.
+//
+// Partitions which have not returned any message yet hold back all messages, until they are finished. The merger
+// is not safe for concurrent use.
+type timestampMerger struct {
+	window      int64
+	maxBuffered int
+	emit        func(*TopicMessage)
+
+	// newestByPartition is the newest timestamp seen in each unfinished partition, math.MinInt64 if the partition
+	// has not returned any message yet
+	newestByPartition map[int32]int64
+	messages          timestampHeap
+}
+
+func newTimestampMerger(partitionIDs []int32, window time.Duration, maxBuffered int, emit func(*TopicMessage)) *timestampMerger {
+	newestByPartition := make(map[int32]int64, len(partitionIDs))
+	for _, partitionID := range partitionIDs {
+		newestByPartition[partitionID] = math.MinInt64
+	}
+	return &timestampMerger{
+		window:            window.Milliseconds(),
+		maxBuffered:       maxBuffered,
+		emit:              emit,
+		newestByPartition: newestByPartition,
+		messages:          make(timestampHeap, 0),
+	}
+}
+
+// Advance records that a message with the timestamp (unix ms) has been consumed from the partition. This must be
+// called for all consumed messages, including those which are not emitted (e.g. filtered), so that partitions
+// without matching messages don't hold back the messages of other partitions.
+func (m *timestampMerger) Advance(partitionID int32, timestamp int64) {
+	newest, isActive := m.newestByPartition[partitionID]
+	if isActive && timestamp > newest {
+		m.newestByPartition[partitionID] = timestamp
+	}
+	m.emitReady()
+}
+
+// Push adds a message that shall be emitted once all older messages have been emitted
+func (m *timestampMerger) Push(msg *TopicMessage) {
+	heap.Push(&m.messages, msg)
+	for len(m.messages) > m.maxBuffered {
+		m.emit(heap.Pop(&m.messages).(*TopicMessage))
+	}
+	m.emitReady()
+}
+
+// FinishPartition records that the partition won't return any more messages
+func (m *timestampMerger) FinishPartition(partitionID int32) {
+	delete(m.newestByPartition, partitionID)
+	m.emitReady()
+}
+
+// Flush emits all held back messages, e.g. because all partitions are done
+func (m *timestampMerger) Flush() {
+	for len(m.messages) > 0 {
+		m.emit(heap.Pop(&m.messages).(*TopicMessage))
+	}
+}
+
+// emitReady emits all messages which are older than any message that unfinished partitions may still return
+func (m *timestampMerger) emitReady() {
+	threshold := int64(math.MaxInt64)
+	for _, newest := range m.newestByPartition {
+		if newest == math.MinInt64 {
+			return
+		}
+		if newest-m.window < threshold {
+			threshold = newest - m.window
+		}
+	}
+	for len(m.messages) > 0 && m.messages[0].Timestamp < threshold {
+		m.emit(heap.Pop(&m.messages).(*TopicMessage))
+	}
+}
+
+// timestampHeap is a min-heap of messages ordered by timestamp, partition ID and offset
+type timestampHeap []*TopicMessage
+
+func (h timestampHeap) Len() int { return len(h) }
+func (h timestampHeap) Less(i, j int) bool {
+	if h[i].Timestamp != h[j].Timestamp {
+		return h[i].Timestamp < h[j].Timestamp
+	}
+	if h[i].PartitionID != h[j].PartitionID {
+		return h[i].PartitionID < h[j].PartitionID
+	}
+	return h[i].Offset < h[j].Offset
+}
+func (h timestampHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *timestampHeap) Push(x interface{}) { *h = append(*h, x.(*TopicMessage)) }
+func (h *timestampHeap) Pop() interface{} {
+	old := *h
+	msg := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return msg
+}
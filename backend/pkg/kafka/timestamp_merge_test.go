@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mergedMessages []*TopicMessage
+
+func (m *mergedMessages) emit(msg *TopicMessage) { *m = append(*m, msg) }
+
+func (m mergedMessages) timestamps() []int64 {
+	timestamps := make([]int64, len(m))
+	for i, msg := range m {
+		timestamps[i] = msg.Timestamp
+	}
+	return timestamps
+}
+
+// consume advances the merger and pushes the message, like FetchMessages does for each consumed message
+func consume(merger *timestampMerger, partitionID int32, offset int64, timestamp int64) {
+	merger.Advance(partitionID, timestamp)
+	merger.Push(&TopicMessage{PartitionID: partitionID, Offset: offset, Timestamp: timestamp})
+}
+
+func TestTimestampMerger(t *testing.T) {
+	emitted := mergedMessages{}
+	merger := newTimestampMerger([]int32{0, 1}, 0, 100, emitted.emit)
+
+	consume(merger, 0, 0, 10)
+	consume(merger, 0, 1, 30)
+	// Partition 1 has not returned anything yet, so partition 0 might not be the oldest
+	assert.Empty(t, emitted)
+
+	consume(merger, 1, 0, 20)
+	assert.Equal(t, []int64{10}, emitted.timestamps())
+
+	consume(merger, 1, 1, 40)
+	assert.Equal(t, []int64{10, 20}, emitted.timestamps())
+
+	merger.FinishPartition(0)
+	assert.Equal(t, []int64{10, 20, 30}, emitted.timestamps())
+
+	merger.Flush()
+	assert.Equal(t, []int64{10, 20, 30, 40}, emitted.timestamps())
+}
+
+func TestTimestampMerger_ReorderWindow(t *testing.T) {
+	emitted := mergedMessages{}
+	merger := newTimestampMerger([]int32{0, 1}, 50*time.Millisecond, 100, emitted.emit)
+
+	// Partition 0 has timestamps out of order within the window
+	consume(merger, 1, 0, 90)
+	consume(merger, 0, 0, 100)
+	consume(merger, 0, 1, 60)
+	consume(merger, 1, 1, 200)
+	consume(merger, 0, 2, 180)
+	consume(merger, 0, 3, 150)
+	merger.Flush()
+
+	assert.Equal(t, []int64{60, 90, 100, 150, 180, 200}, emitted.timestamps())
+}
+
+func TestTimestampMerger_Ties(t *testing.T) {
+	emitted := mergedMessages{}
+	merger := newTimestampMerger([]int32{0, 1}, 0, 100, emitted.emit)
+
+	consume(merger, 1, 5, 10)
+	consume(merger, 0, 7, 10)
+	consume(merger, 0, 6, 10)
+	merger.Flush()
+
+	assert.Equal(t, []int32{0, 0, 1}, []int32{emitted[0].PartitionID, emitted[1].PartitionID, emitted[2].PartitionID})
+	assert.Equal(t, []int64{6, 7, 5}, []int64{emitted[0].Offset, emitted[1].Offset, emitted[2].Offset})
+}
+
+func TestTimestampMerger_MaxBuffered(t *testing.T) {
+	emitted := mergedMessages{}
+	merger := newTimestampMerger([]int32{0, 1}, 0, 2, emitted.emit)
+
+	// Partition 1 never returns a message, hence only the buffer limit makes the merger emit messages
+	consume(merger, 0, 0, 30)
+	consume(merger, 0, 1, 10)
+	consume(merger, 0, 2, 20)
+	assert.Equal(t, []int64{10}, emitted.timestamps())
+
+	// Filtered messages are not pushed, but still advance the partition
+	merger.Advance(1, 25)
+	assert.Equal(t, []int64{10, 20}, emitted.timestamps())
+}
@@ -0,0 +1,21 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+)
+
+// TopicExists returns whether the given topic exists. The metadata is requested a second time before false is
+// returned, so that a stale broker metadata does not lead to false negatives. An error is only returned if the
+// metadata could not be requested.
+func (s *Service) TopicExists(ctx context.Context, topic string) (bool, error) {
+	_, err := s.getTopicMetadata(ctx, topic)
+	if err != nil {
+		if errors.Is(err, ErrTopicNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestTopicExists(t *testing.T) {
+	tests := []struct {
+		name          string
+		errorCodes    []int16 // error code of the topic in each consecutive metadata response
+		expected      bool
+		expectedCalls int
+	}{
+		{name: "exists", errorCodes: []int16{0}, expected: true, expectedCalls: 1},
+		{name: "stale negative", errorCodes: []int16{kerr.UnknownTopicOrPartition.Code, 0}, expected: true, expectedCalls: 2},
+		{name: "missing", errorCodes: []int16{kerr.UnknownTopicOrPartition.Code, kerr.UnknownTopicOrPartition.Code}, expected: false, expectedCalls: 2},
+	}
+
+	for _, tc := range tests {
+		calls := 0
+		client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+			metadataReq, ok := req.(*kmsg.MetadataRequest)
+			if !ok {
+				return nil, unexpectedRequestError(brokerID, req)
+			}
+			if len(metadataReq.Topics) == 0 {
+				return &kmsg.MetadataResponse{}, nil // metadata refresh before retrying unknown topics
+			}
+			errorCode := tc.errorCodes[calls]
+			calls++
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{
+				{Topic: *metadataReq.Topics[0].Topic, ErrorCode: errorCode},
+			}}, nil
+		}}
+		svc := &Service{KafkaClient: client}
+
+		exists, err := svc.TopicExists(context.Background(), "orders")
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, exists, tc.name)
+		assert.Equal(t, tc.expectedCalls, calls, tc.name)
+	}
+}
+
+func TestGetSingleMetadata_TopicNotFound(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, _ int32, _ kmsg.Request) (kmsg.Response, error) {
+		return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{
+			{Topic: "orders", ErrorCode: kerr.UnknownTopicOrPartition.Code},
+		}}, nil
+	}}
+	svc := &Service{KafkaClient: client}
+
+	_, restErr := svc.GetSingleMetadata(context.Background(), "orders")
+	require.NotNil(t, restErr)
+	assert.Equal(t, 404, restErr.Status)
+	assert.True(t, errors.Is(restErr.Err, ErrTopicNotFound))
+
+	_, err := svc.ListPartitionIDs(context.Background(), "orders")
+	assert.True(t, errors.Is(err, ErrTopicNotFound))
+}
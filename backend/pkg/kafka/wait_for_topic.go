@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+const (
+	waitForTopicInitialBackoff = 50 * time.Millisecond
+	waitForTopicMaxBackoff     = time.Second
+)
+
+// WaitForTopic polls the topic metadata until at least minPartitionsWithLeader partitions have an elected leader, or
+// all partitions if minPartitionsWithLeader is 0 or negative. This is helpful right after creating a topic, because
+// producing or consuming fails with LEADER_NOT_AVAILABLE until the metadata has been propagated. The topic not being
+// known yet is not an error, polling only stops once the context is done. The poll interval starts short and doubles
+// up to one second.
+func (s *Service) WaitForTopic(ctx context.Context, topicName string, minPartitionsWithLeader int32) error {
+	return s.waitForTopic(ctx, topicName, minPartitionsWithLeader, waitForTopicInitialBackoff, waitForTopicMaxBackoff)
+}
+
+func (s *Service) waitForTopic(ctx context.Context, topicName string, minPartitionsWithLeader int32, backoff time.Duration, maxBackoff time.Duration) error {
+	var lastErr error
+	for {
+		available, partitionCount, err := s.partitionsWithLeader(ctx, topicName)
+		if err == nil {
+			required := partitionCount
+			if minPartitionsWithLeader > 0 && minPartitionsWithLeader < partitionCount {
+				required = minPartitionsWithLeader
+			}
+			if partitionCount > 0 && available >= required {
+				return nil
+			}
+			lastErr = fmt.Errorf("'%v' of '%v' partitions have a leader, but '%v' are required", available, partitionCount, required)
+		} else {
+			lastErr = err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("topic '%v' did not become available: %v: %w", topicName, lastErr, ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// partitionsWithLeader returns the number of the topic's partitions which have a leader and the total partition count
+func (s *Service) partitionsWithLeader(ctx context.Context, topicName string) (int32, int32, error) {
+	metadata, err := s.GetMetadata(ctx, []string{topicName})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to request metadata: %w", err)
+	}
+	if len(metadata.Topics) != 1 {
+		return 0, 0, fmt.Errorf("expected just one topic metadata result, but got '%v'", len(metadata.Topics))
+	}
+
+	topic := metadata.Topics[0]
+	if err := kerr.ErrorForCode(topic.ErrorCode); err != nil {
+		if topic.ErrorCode == kerr.UnknownTopicOrPartition.Code {
+			return 0, 0, fmt.Errorf("%w: %v", ErrTopicNotFound, topicName)
+		}
+		return 0, 0, fmt.Errorf("failed to get topic metadata. Inner kafka error: %w", err)
+	}
+
+	available := int32(0)
+	for _, partition := range topic.Partitions {
+		if partition.Leader >= 0 && kerr.ErrorForCode(partition.ErrorCode) != kerr.LeaderNotAvailable {
+			available++
+		}
+	}
+	return available, int32(len(topic.Partitions)), nil
+}
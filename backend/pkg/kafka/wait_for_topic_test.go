@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// metadataSequenceClient returns the given topic metadata responses one after another, repeating the last one
+func metadataSequenceClient(topics ...kmsg.MetadataResponseTopic) (*mockKafkaClient, *int) {
+	requests := 0
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.MetadataRequest); !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		topic := topics[len(topics)-1]
+		if requests < len(topics) {
+			topic = topics[requests]
+		}
+		requests++
+		return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{topic}}, nil
+	}}
+	return client, &requests
+}
+
+func TestWaitForTopic(t *testing.T) {
+	client, requests := metadataSequenceClient(
+		kmsg.MetadataResponseTopic{Topic: "orders", ErrorCode: kerr.UnknownTopicOrPartition.Code},
+		kmsg.MetadataResponseTopic{Topic: "orders", Partitions: []kmsg.MetadataResponseTopicPartition{
+			{Partition: 0, Leader: 1},
+			{Partition: 1, Leader: -1, ErrorCode: kerr.LeaderNotAvailable.Code},
+		}},
+		kmsg.MetadataResponseTopic{Topic: "orders", Partitions: []kmsg.MetadataResponseTopicPartition{
+			{Partition: 0, Leader: 1},
+			{Partition: 1, Leader: 2},
+		}},
+	)
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	err := svc.waitForTopic(context.Background(), "orders", 0, time.Millisecond, 2*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, *requests)
+}
+
+func TestWaitForTopic_MinPartitions(t *testing.T) {
+	client, requests := metadataSequenceClient(
+		kmsg.MetadataResponseTopic{Topic: "orders", Partitions: []kmsg.MetadataResponseTopicPartition{
+			{Partition: 0, Leader: 1},
+			{Partition: 1, Leader: -1, ErrorCode: kerr.LeaderNotAvailable.Code},
+		}},
+	)
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	err := svc.waitForTopic(context.Background(), "orders", 1, time.Millisecond, 2*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestWaitForTopic_Timeout(t *testing.T) {
+	client, _ := metadataSequenceClient(
+		kmsg.MetadataResponseTopic{Topic: "orders", ErrorCode: kerr.UnknownTopicOrPartition.Code},
+	)
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := svc.waitForTopic(ctx, "orders", 0, time.Millisecond, 2*time.Millisecond)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
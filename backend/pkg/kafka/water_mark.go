@@ -111,8 +111,45 @@ func (s *Service) GetPartitionMarks(ctx context.Context, topic string, partition
 	return partitionMarksByTopic[topic], nil
 }
 
+// GetLastStablePartitionMarks is like GetPartitionMarks, but the high marks are the last stable offsets (LSO). Read
+// committed consumers don't receive records at or beyond the LSO while a transaction is open, so they must consume up
+// to the LSO rather than the high water mark, which they would wait for until the transaction completes.
+func (s *Service) GetLastStablePartitionMarks(ctx context.Context, topic string, partitionIDs []int32) (map[int32]*PartitionMarks, error) {
+	marks, err := s.GetPartitionMarks(ctx, topic, partitionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.ListOffsetsWithIsolationLevel(ctx, map[string][]int32{topic: partitionIDs}, TimestampLatest, IsolationLevelReadCommitted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request last stable offsets: %w", err)
+	}
+	for _, resTopic := range res.Topics {
+		for _, partition := range resTopic.Partitions {
+			mark, exists := marks[partition.Partition]
+			if !exists || mark.Error != "" {
+				continue
+			}
+			err := kerr.TypedErrorForCode(partition.ErrorCode)
+			if err != nil {
+				mark.Error = err.Error()
+				continue
+			}
+			mark.High = partition.Offset
+		}
+	}
+
+	return marks, nil
+}
+
 // ListOffsets returns a nested map of: topic -> partitionID -> high water mark offset of all available partitions
 func (s *Service) ListOffsets(ctx context.Context, topicPartitions map[string][]int32, timestamp int64) (*kmsg.ListOffsetsResponse, error) {
+	return s.ListOffsetsWithIsolationLevel(ctx, topicPartitions, timestamp, IsolationLevelReadUncommitted)
+}
+
+// ListOffsetsWithIsolationLevel is like ListOffsets, but allows to specify the isolation level. For the latest offset
+// (-1) with IsolationLevelReadCommitted Kafka returns the last stable offset (LSO) instead of the high water mark.
+func (s *Service) ListOffsetsWithIsolationLevel(ctx context.Context, topicPartitions map[string][]int32, timestamp int64, isolationLevel IsolationLevel) (*kmsg.ListOffsetsResponse, error) {
 	topicRequests := make([]kmsg.ListOffsetsRequestTopic, 0, len(topicPartitions))
 
 	for topic, partitionIDs := range topicPartitions {
@@ -134,7 +171,8 @@ func (s *Service) ListOffsets(ctx context.Context, topicPartitions map[string][]
 	}
 
 	req := kmsg.ListOffsetsRequest{
-		Topics: topicRequests,
+		Topics:         topicRequests,
+		IsolationLevel: int8(isolationLevel),
 	}
 	res, err := req.RequestWith(ctx, s.KafkaClient)
 	if err != nil {
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+func TestGetLastStablePartitionMarks(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, brokerID int32, req kmsg.Request) (kmsg.Response, error) {
+		listReq, ok := req.(*kmsg.ListOffsetsRequest)
+		if !ok {
+			return nil, unexpectedRequestError(brokerID, req)
+		}
+		res := &kmsg.ListOffsetsResponse{}
+		for _, topic := range listReq.Topics {
+			resTopic := kmsg.ListOffsetsResponseTopic{Topic: topic.Topic}
+			for _, partition := range topic.Partitions {
+				resPartition := kmsg.ListOffsetsResponseTopicPartition{Partition: partition.Partition}
+				switch {
+				case partition.Timestamp == TimestampEarliest:
+					resPartition.Offset = 10
+				case listReq.IsolationLevel == int8(IsolationLevelReadCommitted) && partition.Partition == 1:
+					resPartition.ErrorCode = kerr.NotLeaderForPartition.Code
+				case listReq.IsolationLevel == int8(IsolationLevelReadCommitted):
+					// A transaction which started at offset 80 is still open
+					resPartition.Offset = 80
+				default:
+					resPartition.Offset = 100
+				}
+				resTopic.Partitions = append(resTopic.Partitions, resPartition)
+			}
+			res.Topics = append(res.Topics, resTopic)
+		}
+		return res, nil
+	}}
+	svc := &Service{Logger: zap.NewNop(), KafkaClient: client}
+
+	marks, err := svc.GetLastStablePartitionMarks(context.Background(), "orders", []int32{0, 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), marks[0].Low)
+	assert.Equal(t, int64(80), marks[0].High)
+	assert.NotEmpty(t, marks[1].Error)
+}
@@ -0,0 +1,218 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+const (
+	// aclWildcardPrincipal is the principal of ACLs which apply to all users
+	aclWildcardPrincipal = "User:*"
+	// aclWildcardHost is the host of ACLs which apply to connections from all hosts
+	aclWildcardHost = "*"
+)
+
+// EffectivePermissions is the result of Kafka's authorization for a principal on a single resource, per operation
+// that exists for the resource type.
+type EffectivePermissions struct {
+	Principal    string `json:"principal"`
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourceName"`
+
+	// IsAuthorizerEnabled is false if the cluster has no authorizer, in which case every operation is allowed and
+	// Operations is empty
+	IsAuthorizerEnabled bool                  `json:"isAuthorizerEnabled"`
+	Operations          []EffectivePermission `json:"operations"`
+}
+
+// EffectivePermission is whether the principal may perform the operation when connecting from any host.
+type EffectivePermission struct {
+	Operation string `json:"operation"`
+	Allowed   bool   `json:"allowed"`
+
+	// DecidingACL is the ACL that denied or allowed the operation. It's nil if no ACL applies, in which case the
+	// operation is denied, unless the brokers allow everyone if no ACL is found or the principal is a super user.
+	DecidingACL *EffectiveACL `json:"decidingAcl"`
+
+	// HostDependent is true if ACLs restricted to specific hosts apply to the operation, so that the result differs
+	// for connections from these hosts
+	HostDependent bool `json:"hostDependent"`
+}
+
+// EffectiveACL is a single ACL along with the resource pattern it has been defined for
+type EffectiveACL struct {
+	ResourceName        string `json:"resourceName"`
+	ResourcePatternType string `json:"resourcePatternType"`
+	Principal           string `json:"principal"`
+	Host                string `json:"host"`
+	Operation           string `json:"operation"`
+	PermissionType      string `json:"permissionType"`
+}
+
+// aclOperationsByResourceType are the operations Kafka authorizes for each resource type
+var aclOperationsByResourceType = map[kmsg.ACLResourceType][]kmsg.ACLOperation{
+	kmsg.ACLResourceTypeTopic: {
+		kmsg.ACLOperationRead, kmsg.ACLOperationWrite, kmsg.ACLOperationCreate, kmsg.ACLOperationDelete,
+		kmsg.ACLOperationAlter, kmsg.ACLOperationDescribe, kmsg.ACLOperationDescribeConfigs, kmsg.ACLOperationAlterConfigs,
+	},
+	kmsg.ACLResourceTypeGroup: {kmsg.ACLOperationRead, kmsg.ACLOperationDelete, kmsg.ACLOperationDescribe},
+	kmsg.ACLResourceTypeCluster: {
+		kmsg.ACLOperationCreate, kmsg.ACLOperationAlter, kmsg.ACLOperationDescribe, kmsg.ACLOperationClusterAction,
+		kmsg.ACLOperationDescribeConfigs, kmsg.ACLOperationAlterConfigs, kmsg.ACLOperationIdempotentWrite,
+	},
+	kmsg.ACLResourceTypeTransactionalId: {kmsg.ACLOperationWrite, kmsg.ACLOperationDescribe},
+	kmsg.ACLResourceTypeDelegationToken: {kmsg.ACLOperationDescribe},
+}
+
+// impliedByOperations are the operations whose ALLOW ACLs implicitly allow another operation too. Denying these
+// operations does not deny the implied operation.
+var impliedByOperations = map[kmsg.ACLOperation][]kmsg.ACLOperation{
+	kmsg.ACLOperationDescribe: {
+		kmsg.ACLOperationRead, kmsg.ACLOperationWrite, kmsg.ACLOperationDelete, kmsg.ACLOperationAlter,
+	},
+	kmsg.ACLOperationDescribeConfigs: {kmsg.ACLOperationAlterConfigs},
+}
+
+// ResolveEffectivePermissions returns whether the principal (e.g. "User:alice") is allowed to perform each of the
+// resource type's operations on the resource. All ACLs that apply are considered: literal ACLs of the resource,
+// wildcard ACLs, prefixed ACLs matching the resource name and ACLs of the wildcard principal "User:*". Like Kafka's
+// authorizer, a DENY always overrides an ALLOW. Super users and the brokers' allow.everyone.if.no.acl.found setting
+// are not visible via the Kafka API and therefore ignored.
+func (s *Service) ResolveEffectivePermissions(ctx context.Context, principal string, resourceType string, resourceName string) (EffectivePermissions, error) {
+	if !strings.Contains(principal, ":") {
+		return EffectivePermissions{}, fmt.Errorf("principal '%v' must be prefixed with its type, e.g. 'User:'", principal)
+	}
+	aclResourceType, err := parseAclResourceType(resourceType)
+	if err != nil {
+		return EffectivePermissions{}, err
+	}
+
+	req := kmsg.NewDescribeACLsRequest()
+	req.ResourceType = aclResourceType
+	req.ResourcePatternType = AclPatternTypeAny
+	req.Operation = kmsg.ACLOperationAny
+	req.PermissionType = kmsg.ACLPermissionTypeAny
+	res, err := s.kafkaSvc.ListACLs(ctx, req)
+	if err != nil {
+		return EffectivePermissions{}, fmt.Errorf("failed to get ACLs from Kafka: %w", err)
+	}
+
+	permissions := EffectivePermissions{
+		Principal:           principal,
+		ResourceType:        aclResourceType.String(),
+		ResourceName:        resourceName,
+		IsAuthorizerEnabled: true,
+	}
+	if err := kerr.ErrorForCode(res.ErrorCode); err != nil {
+		if err == kerr.SecurityDisabled {
+			permissions.IsAuthorizerEnabled = false
+			permissions.Operations = []EffectivePermission{}
+			return permissions, nil
+		}
+		return EffectivePermissions{}, fmt.Errorf("failed to get ACLs from Kafka: %w", err)
+	}
+	permissions.Operations = resolveEffectivePermissions(aclResourceType, resourceName, principal, res.Resources)
+
+	return permissions, nil
+}
+
+// parseAclResourceType returns the resource type for the case insensitive name, e.g. "topic" or "TRANSACTIONAL_ID"
+func parseAclResourceType(name string) (kmsg.ACLResourceType, error) {
+	for resourceType := range aclOperationsByResourceType {
+		if strings.EqualFold(resourceType.String(), name) {
+			return resourceType, nil
+		}
+	}
+	return kmsg.ACLResourceTypeUnknown, fmt.Errorf("unknown resource type '%v'", name)
+}
+
+// resolveEffectivePermissions resolves the permissions of each operation of the resource type from the given
+// described ACLs, which may include ACLs of other resources and principals.
+func resolveEffectivePermissions(resourceType kmsg.ACLResourceType, resourceName string, principal string, resources []kmsg.DescribeACLsResponseResource) []EffectivePermission {
+	type applicableACL struct {
+		acl            EffectiveACL
+		operation      kmsg.ACLOperation
+		permissionType kmsg.ACLPermissionType
+	}
+	applicable := make([]applicableACL, 0)
+	for _, resource := range resources {
+		if resource.ResourceType != resourceType || !aclPatternMatches(resource.ResourcePatternType, resource.ResourceName, resourceName) {
+			continue
+		}
+		for _, acl := range resource.ACLs {
+			if acl.Principal != principal && acl.Principal != aclWildcardPrincipal {
+				continue
+			}
+			applicable = append(applicable, applicableACL{
+				acl: EffectiveACL{
+					ResourceName:        resource.ResourceName,
+					ResourcePatternType: aclPatternTypeName(resource.ResourcePatternType),
+					Principal:           acl.Principal,
+					Host:                acl.Host,
+					Operation:           acl.Operation.String(),
+					PermissionType:      acl.PermissionType.String(),
+				},
+				operation:      acl.Operation,
+				permissionType: acl.PermissionType,
+			})
+		}
+	}
+
+	operations := aclOperationsByResourceType[resourceType]
+	permissions := make([]EffectivePermission, len(operations))
+	for i, op := range operations {
+		permission := EffectivePermission{Operation: op.String()}
+		var deny, allow *EffectiveACL
+		for j := range applicable {
+			acl := &applicable[j].acl
+			var isRelevant bool
+			switch applicable[j].permissionType {
+			case kmsg.ACLPermissionTypeDeny:
+				isRelevant = aclOperationMatches(applicable[j].operation, op, false)
+				if isRelevant && acl.Host == aclWildcardHost && deny == nil {
+					deny = acl
+				}
+			case kmsg.ACLPermissionTypeAllow:
+				isRelevant = aclOperationMatches(applicable[j].operation, op, true)
+				if isRelevant && acl.Host == aclWildcardHost && allow == nil {
+					allow = acl
+				}
+			}
+			if isRelevant && acl.Host != aclWildcardHost {
+				permission.HostDependent = true
+			}
+		}
+
+		switch {
+		case deny != nil:
+			permission.DecidingACL = deny
+		case allow != nil:
+			permission.Allowed = true
+			permission.DecidingACL = allow
+		}
+		permissions[i] = permission
+	}
+
+	return permissions
+}
+
+// aclOperationMatches returns true if an ACL for the ACL operation applies to the requested operation. ALL applies
+// to every operation, implied operations (e.g. DESCRIBE by READ) only apply to ALLOW ACLs.
+func aclOperationMatches(aclOp kmsg.ACLOperation, op kmsg.ACLOperation, isAllow bool) bool {
+	if aclOp == op || aclOp == kmsg.ACLOperationAll {
+		return true
+	}
+	if !isAllow {
+		return false
+	}
+	for _, implying := range impliedByOperations[op] {
+		if aclOp == implying {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func aclResource(patternType kmsg.ACLResourcePatternType, name string, acls ...kmsg.DescribeACLsResponseResourceACL) kmsg.DescribeACLsResponseResource {
+	return kmsg.DescribeACLsResponseResource{
+		ResourceType:        kmsg.ACLResourceTypeTopic,
+		ResourceName:        name,
+		ResourcePatternType: patternType,
+		ACLs:                acls,
+	}
+}
+
+func aclEntry(principal string, host string, op kmsg.ACLOperation, permissionType kmsg.ACLPermissionType) kmsg.DescribeACLsResponseResourceACL {
+	return kmsg.DescribeACLsResponseResourceACL{Principal: principal, Host: host, Operation: op, PermissionType: permissionType}
+}
+
+func permissionByOperation(permissions []EffectivePermission) map[string]EffectivePermission {
+	byOperation := make(map[string]EffectivePermission)
+	for _, permission := range permissions {
+		byOperation[permission.Operation] = permission
+	}
+	return byOperation
+}
+
+func TestResolveEffectivePermissions(t *testing.T) {
+	allow, deny := kmsg.ACLPermissionTypeAllow, kmsg.ACLPermissionTypeDeny
+	resources := []kmsg.DescribeACLsResponseResource{
+		aclResource(AclPatternTypeLiteral, "orders-eu",
+			aclEntry("User:alice", "*", kmsg.ACLOperationRead, allow),
+			aclEntry("User:bob", "*", kmsg.ACLOperationAll, allow)),
+		aclResource(AclPatternTypePrefixed, "orders-",
+			aclEntry("User:alice", "*", kmsg.ACLOperationWrite, allow),
+			aclEntry("User:alice", "10.0.0.1", kmsg.ACLOperationDelete, allow)),
+		aclResource(AclPatternTypeLiteral, "*",
+			aclEntry("User:*", "*", kmsg.ACLOperationWrite, deny),
+			aclEntry("User:*", "*", kmsg.ACLOperationDescribeConfigs, allow)),
+		// Neither the resource nor the other prefix match
+		aclResource(AclPatternTypeLiteral, "orders", aclEntry("User:alice", "*", kmsg.ACLOperationAll, allow)),
+		aclResource(AclPatternTypePrefixed, "payments-", aclEntry("User:alice", "*", kmsg.ACLOperationAll, allow)),
+	}
+
+	alice := permissionByOperation(resolveEffectivePermissions(kmsg.ACLResourceTypeTopic, "orders-eu", "User:alice", resources))
+	require.Len(t, alice, 8)
+
+	assert.True(t, alice["READ"].Allowed)
+	assert.Equal(t, "orders-eu", alice["READ"].DecidingACL.ResourceName)
+
+	// The wildcard deny for all users overrides the prefixed allow
+	assert.False(t, alice["WRITE"].Allowed)
+	assert.Equal(t, "DENY", alice["WRITE"].DecidingACL.PermissionType)
+	assert.Equal(t, "User:*", alice["WRITE"].DecidingACL.Principal)
+
+	// Allowing read implies describe, the denied write does not deny describe
+	assert.True(t, alice["DESCRIBE"].Allowed)
+	assert.True(t, alice["DESCRIBE_CONFIGS"].Allowed)
+
+	// Delete is only allowed from a specific host
+	assert.False(t, alice["DELETE"].Allowed)
+	assert.Nil(t, alice["DELETE"].DecidingACL)
+	assert.True(t, alice["DELETE"].HostDependent)
+	assert.False(t, alice["READ"].HostDependent)
+
+	assert.False(t, alice["CREATE"].Allowed)
+	assert.Nil(t, alice["CREATE"].DecidingACL)
+
+	// ALL allows everything, but does not override a deny
+	bob := permissionByOperation(resolveEffectivePermissions(kmsg.ACLResourceTypeTopic, "orders-eu", "User:bob", resources))
+	assert.True(t, bob["ALTER_CONFIGS"].Allowed)
+	assert.True(t, bob["CREATE"].Allowed)
+	assert.False(t, bob["WRITE"].Allowed)
+
+	// ACLs of other resource types never apply
+	groups := resolveEffectivePermissions(kmsg.ACLResourceTypeGroup, "orders-eu", "User:bob", resources)
+	require.Len(t, groups, 3)
+	for _, permission := range groups {
+		assert.False(t, permission.Allowed, permission.Operation)
+	}
+}
+
+func TestAclOperationMatches(t *testing.T) {
+	assert.True(t, aclOperationMatches(kmsg.ACLOperationAll, kmsg.ACLOperationRead, false))
+	assert.True(t, aclOperationMatches(kmsg.ACLOperationRead, kmsg.ACLOperationDescribe, true))
+	assert.False(t, aclOperationMatches(kmsg.ACLOperationRead, kmsg.ACLOperationDescribe, false))
+	assert.True(t, aclOperationMatches(kmsg.ACLOperationAlterConfigs, kmsg.ACLOperationDescribeConfigs, true))
+	assert.False(t, aclOperationMatches(kmsg.ACLOperationDescribe, kmsg.ACLOperationRead, true))
+}
+
+func TestParseAclResourceType(t *testing.T) {
+	resourceType, err := parseAclResourceType("topic")
+	require.NoError(t, err)
+	assert.Equal(t, kmsg.ACLResourceTypeTopic, resourceType)
+
+	resourceType, err = parseAclResourceType("TRANSACTIONAL_ID")
+	require.NoError(t, err)
+	assert.Equal(t, kmsg.ACLResourceTypeTransactionalId, resourceType)
+
+	_, err = parseAclResourceType("any")
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// ValidationError is returned if an admin request has been rejected before sending it to Kafka, because it does not
+// match the current cluster metadata. Field is the name of the invalid request property.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %v: %v", e.Field, e.Message)
+}
+
+// adminRequest contains all properties of an admin request that can be validated against the cluster metadata.
+type adminRequest struct {
+	// PartitionReassignments are the requested replica assignments. Partitions without replicas cancel an ongoing
+	// reassignment and are therefore not validated against the broker list.
+	PartitionReassignments []kmsg.AlterPartitionAssignmentsRequestTopic
+
+	// CreateTopics are topics which shall be created. They must not exist yet.
+	CreateTopics []topicCreation
+
+	// CreatePartitions are partition count increases of existing topics
+	CreatePartitions []partitionIncrease
+}
+
+// topicCreation is a topic which shall be created. A partition count or replication factor of -1 uses the brokers'
+// defaults and can't be validated.
+type topicCreation struct {
+	TopicName         string
+	PartitionCount    int32
+	ReplicationFactor int16
+}
+
+// partitionIncrease is the new total partition count of a topic
+type partitionIncrease struct {
+	TopicName      string
+	PartitionCount int32
+}
+
+// topicNames returns the names of all topics the request refers to
+func (r adminRequest) topicNames() []string {
+	topicNames := make([]string, 0, len(r.PartitionReassignments)+len(r.CreateTopics)+len(r.CreatePartitions))
+	for _, topic := range r.PartitionReassignments {
+		topicNames = append(topicNames, topic.Topic)
+	}
+	for _, topic := range r.CreateTopics {
+		topicNames = append(topicNames, topic.TopicName)
+	}
+	for _, topic := range r.CreatePartitions {
+		topicNames = append(topicNames, topic.TopicName)
+	}
+	return topicNames
+}
+
+// validateAdminRequest checks the given request against the cluster metadata, so that we can return actionable error
+// messages rather than the raw error codes brokers would respond with. A *ValidationError is returned for the first
+// invalid property.
+func validateAdminRequest(metadata *kmsg.MetadataResponse, req adminRequest) error {
+	brokerIDs := make(map[int32]struct{}, len(metadata.Brokers))
+	for _, broker := range metadata.Brokers {
+		brokerIDs[broker.NodeID] = struct{}{}
+	}
+	partitionsByTopic := make(map[string]map[int32]struct{}, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		if kerr.ErrorForCode(topic.ErrorCode) != nil {
+			continue
+		}
+		partitionsByTopic[topic.Topic] = make(map[int32]struct{}, len(topic.Partitions))
+		for _, partition := range topic.Partitions {
+			partitionsByTopic[topic.Topic][partition.Partition] = struct{}{}
+		}
+	}
+
+	for _, topic := range req.CreateTopics {
+		if _, exists := partitionsByTopic[topic.TopicName]; exists {
+			return &ValidationError{Field: "topicName", Message: fmt.Sprintf("topic '%v' already exists", topic.TopicName)}
+		}
+		if topic.PartitionCount == 0 || topic.PartitionCount < -1 {
+			return &ValidationError{
+				Field:   "partitionCount",
+				Message: fmt.Sprintf("partition count '%v' of topic '%v' must be at least 1", topic.PartitionCount, topic.TopicName),
+			}
+		}
+		if topic.ReplicationFactor == 0 || topic.ReplicationFactor < -1 {
+			return &ValidationError{
+				Field:   "replicationFactor",
+				Message: fmt.Sprintf("replication factor '%v' of topic '%v' must be at least 1", topic.ReplicationFactor, topic.TopicName),
+			}
+		}
+		if int(topic.ReplicationFactor) > len(brokerIDs) {
+			return &ValidationError{
+				Field: "replicationFactor",
+				Message: fmt.Sprintf("replication factor '%v' of topic '%v' exceeds the number of brokers '%v'",
+					topic.ReplicationFactor, topic.TopicName, len(brokerIDs)),
+			}
+		}
+	}
+
+	for _, topic := range req.CreatePartitions {
+		partitions, exists := partitionsByTopic[topic.TopicName]
+		if !exists {
+			return &ValidationError{Field: "topicName", Message: fmt.Sprintf("topic '%v' does not exist", topic.TopicName)}
+		}
+		if int(topic.PartitionCount) <= len(partitions) {
+			return &ValidationError{
+				Field: "partitionCount",
+				Message: fmt.Sprintf("partition count of topic '%v' can only be increased, it has '%v' partitions but '%v' were requested",
+					topic.TopicName, len(partitions), topic.PartitionCount),
+			}
+		}
+	}
+
+	for _, topic := range req.PartitionReassignments {
+		partitions, exists := partitionsByTopic[topic.Topic]
+		if !exists {
+			return &ValidationError{Field: "topicName", Message: fmt.Sprintf("topic '%v' does not exist", topic.Topic)}
+		}
+
+		for _, partition := range topic.Partitions {
+			if _, exists := partitions[partition.Partition]; !exists {
+				return &ValidationError{
+					Field:   "partitionId",
+					Message: fmt.Sprintf("partition '%v' does not exist in topic '%v'", partition.Partition, topic.Topic),
+				}
+			}
+			if len(partition.Replicas) > len(brokerIDs) {
+				return &ValidationError{
+					Field: "replicas",
+					Message: fmt.Sprintf("replication factor '%v' of topic '%v' partition '%v' exceeds the number of brokers '%v'",
+						len(partition.Replicas), topic.Topic, partition.Partition, len(brokerIDs)),
+				}
+			}
+
+			seenReplicas := make(map[int32]struct{}, len(partition.Replicas))
+			for _, replica := range partition.Replicas {
+				if _, exists := brokerIDs[replica]; !exists {
+					return &ValidationError{
+						Field:   "replicas",
+						Message: fmt.Sprintf("broker '%v' of topic '%v' partition '%v' does not exist", replica, topic.Topic, partition.Partition),
+					}
+				}
+				if _, isDuplicate := seenReplicas[replica]; isDuplicate {
+					return &ValidationError{
+						Field:   "replicas",
+						Message: fmt.Sprintf("broker '%v' is assigned more than once to topic '%v' partition '%v'", replica, topic.Topic, partition.Partition),
+					}
+				}
+				seenReplicas[replica] = struct{}{}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAdminRequestWithMetadata fetches the metadata that is required to validate the given request and runs
+// validateAdminRequest.
+func (s *Service) validateAdminRequestWithMetadata(ctx context.Context, req adminRequest) error {
+	topicNames := req.topicNames()
+	if len(topicNames) == 0 {
+		return nil
+	}
+
+	metadata, err := s.kafkaSvc.GetMetadata(ctx, topicNames)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata for validating the request: %w", err)
+	}
+
+	return validateAdminRequest(metadata, req)
+}
@@ -0,0 +1,104 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestValidateAdminRequest(t *testing.T) {
+	metadata := &kmsg.MetadataResponse{
+		Brokers: []kmsg.MetadataResponseBroker{{NodeID: 0}, {NodeID: 1}, {NodeID: 2}},
+		Topics: []kmsg.MetadataResponseTopic{
+			{Topic: "orders", Partitions: []kmsg.MetadataResponseTopicPartition{{Partition: 0}, {Partition: 1}}},
+		},
+	}
+	reassignment := func(topic string, partition int32, replicas ...int32) adminRequest {
+		return adminRequest{PartitionReassignments: []kmsg.AlterPartitionAssignmentsRequestTopic{
+			{Topic: topic, Partitions: []kmsg.AlterPartitionAssignmentsRequestTopicPartition{{Partition: partition, Replicas: replicas}}},
+		}}
+	}
+
+	creation := func(topic string, partitionCount int32, replicationFactor int16) adminRequest {
+		return adminRequest{CreateTopics: []topicCreation{
+			{TopicName: topic, PartitionCount: partitionCount, ReplicationFactor: replicationFactor},
+		}}
+	}
+	increase := func(topic string, partitionCount int32) adminRequest {
+		return adminRequest{CreatePartitions: []partitionIncrease{{TopicName: topic, PartitionCount: partitionCount}}}
+	}
+
+	tests := []struct {
+		name          string
+		req           adminRequest
+		expectedField string
+	}{
+		{name: "valid reassignment", req: reassignment("orders", 1, 2, 0, 1), expectedField: ""},
+		{name: "cancel reassignment", req: reassignment("orders", 1), expectedField: ""},
+		{name: "unknown topic", req: reassignment("payments", 0, 1), expectedField: "topicName"},
+		{name: "unknown partition", req: reassignment("orders", 2, 1), expectedField: "partitionId"},
+		{name: "unknown broker", req: reassignment("orders", 0, 0, 5), expectedField: "replicas"},
+		{name: "duplicate broker", req: reassignment("orders", 0, 1, 1), expectedField: "replicas"},
+		{name: "replication factor exceeds brokers", req: reassignment("orders", 0, 0, 1, 2, 3), expectedField: "replicas"},
+		{name: "valid topic creation", req: creation("payments", 6, 3), expectedField: ""},
+		{name: "topic creation with defaults", req: creation("payments", -1, -1), expectedField: ""},
+		{name: "create existing topic", req: creation("orders", 6, 3), expectedField: "topicName"},
+		{name: "create topic without partitions", req: creation("payments", 0, 3), expectedField: "partitionCount"},
+		{name: "create topic without replicas", req: creation("payments", 6, 0), expectedField: "replicationFactor"},
+		{name: "create topic exceeding brokers", req: creation("payments", 6, 4), expectedField: "replicationFactor"},
+		{name: "valid partition increase", req: increase("orders", 3), expectedField: ""},
+		{name: "partition count unchanged", req: increase("orders", 2), expectedField: "partitionCount"},
+		{name: "partition count decrease", req: increase("orders", 1), expectedField: "partitionCount"},
+		{name: "increase partitions of unknown topic", req: increase("payments", 3), expectedField: "topicName"},
+	}
+
+	for _, tc := range tests {
+		err := validateAdminRequest(metadata, tc.req)
+		if tc.expectedField == "" {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+
+		var validationErr *ValidationError
+		if assert.True(t, errors.As(err, &validationErr), tc.name) {
+			assert.Equal(t, tc.expectedField, validationErr.Field, tc.name)
+		}
+	}
+}
+
+func TestService_CreateTopic(t *testing.T) {
+	createTopicsRequests := 0
+	client := &mockKafkaClient{handle: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.MetadataRequest:
+			res := &kmsg.MetadataResponse{Brokers: []kmsg.MetadataResponseBroker{{NodeID: 0}, {NodeID: 1}}}
+			for _, topic := range typedReq.Topics {
+				res.Topics = append(res.Topics, kmsg.MetadataResponseTopic{Topic: *topic.Topic, ErrorCode: kerr.UnknownTopicOrPartition.Code})
+			}
+			return res, nil
+		case *kmsg.CreateTopicsRequest:
+			createTopicsRequests++
+			return &kmsg.CreateTopicsResponse{Topics: []kmsg.CreateTopicsResponseTopic{{Topic: typedReq.Topics[0].Topic}}}, nil
+		}
+		return nil, fmt.Errorf("unexpected %v request", kmsg.NameForKey(req.Key()))
+	}}
+	svc := &Service{logger: zap.NewNop(), kafkaSvc: &kafka.Service{Logger: zap.NewNop(), KafkaClient: client}}
+
+	err := svc.CreateTopic(context.Background(), "payments", 6, 3, nil)
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		assert.Equal(t, "replicationFactor", validationErr.Field)
+	}
+	assert.Equal(t, 0, createTopicsRequests)
+
+	assert.NoError(t, svc.CreateTopic(context.Background(), "payments", 6, 2, nil))
+	assert.Equal(t, 1, createTopicsRequests)
+}
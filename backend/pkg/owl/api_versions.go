@@ -14,14 +14,28 @@ type APIVersion struct {
 	MinVersion int16  `json:"minVersion"`
 }
 
-// GetTopicDetails returns the partition in the topic along with their watermarks
+// GetAPIVersions returns the supported Kafka API versions
 func (s *Service) GetAPIVersions(ctx context.Context) ([]APIVersion, error) {
 	versionsRes, err := s.kafkaSvc.GetAPIVersions(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get kafka api version: %w", err)
 	}
 
-	err = kerr.ErrorForCode(versionsRes.ErrorCode)
+	return convertAPIVersions(versionsRes)
+}
+
+// GetBrokerAPIVersions returns the Kafka API versions which are supported by the given broker
+func (s *Service) GetBrokerAPIVersions(ctx context.Context, brokerID int32) ([]APIVersion, error) {
+	versionsRes, err := s.kafkaSvc.GetBrokerAPIVersions(ctx, brokerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kafka api version of broker '%v': %w", brokerID, err)
+	}
+
+	return convertAPIVersions(versionsRes)
+}
+
+func convertAPIVersions(versionsRes *kmsg.ApiVersionsResponse) ([]APIVersion, error) {
+	err := kerr.ErrorForCode(versionsRes.ErrorCode)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get kafka api version. Inner error: %w", err)
 	}
@@ -0,0 +1,29 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestConvertAPIVersions(t *testing.T) {
+	res := &kmsg.ApiVersionsResponse{ApiKeys: []kmsg.ApiVersionsResponseApiKey{
+		{ApiKey: kmsg.NewPtrProduceRequest().Key(), MinVersion: 0, MaxVersion: 8},
+		{ApiKey: kmsg.NewPtrMetadataRequest().Key(), MinVersion: 0, MaxVersion: 9},
+		{ApiKey: 999, MinVersion: 0, MaxVersion: 1}, // unknown to the client library
+	}}
+
+	versions, err := convertAPIVersions(res)
+	require.NoError(t, err)
+	assert.Equal(t, []APIVersion{
+		{KeyID: 0, KeyName: "Produce", MinVersion: 0, MaxVersion: 8},
+		{KeyID: 3, KeyName: "Metadata", MinVersion: 0, MaxVersion: 9},
+		{KeyID: 999, KeyName: "Unknown", MinVersion: 0, MaxVersion: 1},
+	}, versions)
+
+	_, err = convertAPIVersions(&kmsg.ApiVersionsResponse{ErrorCode: kerr.UnsupportedVersion.Code})
+	assert.Error(t, err)
+}
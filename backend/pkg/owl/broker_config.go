@@ -2,11 +2,13 @@ package owl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"net/http"
 
 	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
 )
@@ -78,6 +80,14 @@ func (s *Service) GetAllBrokerConfigs(ctx context.Context) (map[int32]BrokerConf
 
 func (s *Service) GetBrokerConfig(ctx context.Context, brokerID int32) ([]BrokerConfigEntry, *rest.Error) {
 	res, err := s.kafkaSvc.DescribeBrokerConfig(ctx, brokerID, nil)
+	if errors.Is(err, kafka.ErrBrokerNotFound) {
+		return nil, &rest.Error{
+			Err:      err,
+			Status:   http.StatusNotFound,
+			Message:  fmt.Sprintf("Broker with id '%v' does not exist", brokerID),
+			IsSilent: true,
+		}
+	}
 	if err != nil {
 		return nil, &rest.Error{
 			Err:      fmt.Errorf("failed to request broker config: %w", err),
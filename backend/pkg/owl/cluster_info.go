@@ -19,6 +19,9 @@ type ClusterInfo struct {
 	ControllerID int32     `json:"controllerId"`
 	Brokers      []*Broker `json:"brokers"`
 	KafkaVersion string    `json:"kafkaVersion"`
+
+	// RackDistribution groups the brokers by rack and lists topics whose replicas don't span racks
+	RackDistribution RackDistribution `json:"rackDistribution"`
 }
 
 // Broker described by some basic broker properties
@@ -111,6 +114,8 @@ func (s *Service) GetClusterInfo(ctx context.Context) (*ClusterInfo, error) {
 		ControllerID: metadata.ControllerID,
 		Brokers:      brokers,
 		KafkaVersion: kafkaVersion,
+
+		RackDistribution: calculateRackDistribution(metadata),
 	}, nil
 }
 
@@ -7,10 +7,13 @@ import (
 
 type Config struct {
 	TopicDocumentation ConfigTopicDocumentation `yaml:"topicDocumentation"`
+	Browse             ConfigBrowse             `yaml:"browse"`
+	ConsumerGroups     ConfigConsumerGroups     `yaml:"consumerGroups"`
 }
 
 func (c *Config) SetDefaults() {
 	c.TopicDocumentation.SetDefaults()
+	c.Browse.SetDefaults()
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
@@ -23,5 +26,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("failed to validate topic documentation config: %w", err)
 	}
 
+	err = c.Browse.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate browse config: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,65 @@
+package owl
+
+import (
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+const (
+	// BrowseStartLatest starts at the high water mark and only returns messages which arrive after the request
+	BrowseStartLatest = "latest"
+	// BrowseStartEarliest starts at the low water mark
+	BrowseStartEarliest = "earliest"
+	// BrowseStartLatestMinusN starts so that the most recent messages (as many as requested) are returned
+	BrowseStartLatestMinusN = "latestMinusN"
+)
+
+// ConfigBrowse configures the defaults for browsing the messages of a topic
+type ConfigBrowse struct {
+	// DefaultStart is the start position for list message requests which opt into the default start offset. Other
+	// requests always use the start offset they have set.
+	DefaultStart string `yaml:"defaultStart"`
+
+	// LiveTailBufferSize is the number of messages that are buffered for live tail requests (start at newest offset)
+	// if the client receives messages slower than they are consumed. 0 disables the buffer.
+	LiveTailBufferSize int `yaml:"liveTailBufferSize"`
+
+	// LiveTailOverflowPolicy decides what happens if the live tail buffer is full: "block" stops consuming until
+	// the client caught up, "dropOldest" and "dropNewest" drop messages so that a slow client does not slow down
+	// the consumer. The number of dropped messages is reported to the client at the end of the stream.
+	LiveTailOverflowPolicy kafka.OverflowPolicy `yaml:"liveTailOverflowPolicy"`
+}
+
+func (c *ConfigBrowse) SetDefaults() {
+	c.DefaultStart = BrowseStartLatestMinusN
+	c.LiveTailBufferSize = 500
+	c.LiveTailOverflowPolicy = kafka.OverflowPolicyBlock
+}
+
+func (c *ConfigBrowse) Validate() error {
+	switch c.DefaultStart {
+	case BrowseStartLatest, BrowseStartEarliest, BrowseStartLatestMinusN:
+	default:
+		return fmt.Errorf("default start '%v' is invalid, must be one of '%v', '%v' or '%v'",
+			c.DefaultStart, BrowseStartLatest, BrowseStartEarliest, BrowseStartLatestMinusN)
+	}
+
+	if c.LiveTailBufferSize < 0 {
+		return fmt.Errorf("live tail buffer size must not be negative")
+	}
+
+	return c.LiveTailOverflowPolicy.Validate()
+}
+
+// StartOffset returns the special start offset (e.g. StartOffsetOldest) for the configured default start
+func (c *ConfigBrowse) StartOffset() int64 {
+	switch c.DefaultStart {
+	case BrowseStartLatest:
+		return StartOffsetNewest
+	case BrowseStartEarliest:
+		return StartOffsetOldest
+	default:
+		return StartOffsetRecent
+	}
+}
@@ -0,0 +1,9 @@
+package owl
+
+// ConfigConsumerGroups configures how consumer groups are returned
+type ConfigConsumerGroups struct {
+	// OmitEmptyAssignments omits the assignments of group members which have no partitions assigned from the JSON
+	// response, which makes the response of large groups that are rebalancing or idle smaller. By default, members
+	// without assignments are returned with an empty (never null) assignments array.
+	OmitEmptyAssignments bool `yaml:"omitEmptyAssignments"`
+}
@@ -0,0 +1,144 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+// ConsumeFromGroupOffsetsOptions controls where ConsumeFromGroupOffsets starts consuming partitions which have no
+// usable committed offset.
+type ConsumeFromGroupOffsetsOptions struct {
+	// StartOffsetWithoutCommit is either StartOffsetOldest (default) or StartOffsetNewest. It's used for partitions
+	// without committed offset and for committed offsets which are behind the retention. Nothing will be returned
+	// for these partitions when StartOffsetNewest is used.
+	StartOffsetWithoutCommit int64
+}
+
+// ConsumeFromGroupOffsets returns the messages the given group will consume next from the given topic. Each partition
+// is consumed starting at the group's committed offset until maxMessages messages have been consumed in total or all
+// partitions have been consumed up to their high water mark.
+func (s *Service) ConsumeFromGroupOffsets(ctx context.Context, group string, topicName string, maxMessages int64, opts ConsumeFromGroupOffsetsOptions) ([]*kafka.TopicMessage, error) {
+	if opts.StartOffsetWithoutCommit == 0 {
+		opts.StartOffsetWithoutCommit = StartOffsetOldest
+	}
+	if opts.StartOffsetWithoutCommit != StartOffsetOldest && opts.StartOffsetWithoutCommit != StartOffsetNewest {
+		return nil, fmt.Errorf("start offset for partitions without commit must be either oldest or newest")
+	}
+	if maxMessages <= 0 {
+		return nil, fmt.Errorf("max messages must be greater than zero")
+	}
+
+	partitionIDs, err := s.kafkaSvc.ListPartitionIDs(ctx, topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	committedOffsets, err := s.getCommittedOffsets(ctx, group, topicName, partitionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	marks, err := s.kafkaSvc.GetPartitionMarks(ctx, topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	consumeRequests := groupOffsetConsumeRequests(marks, committedOffsets, maxMessages, opts.StartOffsetWithoutCommit)
+	if len(consumeRequests) == 0 {
+		return []*kafka.TopicMessage{}, nil
+	}
+
+	collector := &messageCollector{messages: make([]*kafka.TopicMessage, 0)}
+	err = s.kafkaSvc.FetchMessages(ctx, collector, kafka.TopicConsumeRequest{
+		TopicName:       topicName,
+		MaxMessageCount: int(maxMessages),
+		Partitions:      consumeRequests,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume messages: %w", err)
+	}
+	if collector.err != "" {
+		return nil, fmt.Errorf("failed to consume messages: %v", collector.err)
+	}
+
+	return collector.messages, nil
+}
+
+// getCommittedOffsets returns the group's committed offsets of the given partitions by partition ID. Partitions the
+// group has not committed an offset for are not part of the result.
+func (s *Service) getCommittedOffsets(ctx context.Context, group string, topicName string, partitionIDs []int32) (map[int32]int64, error) {
+	topicPartitions := make([]kafka.TopicPartition, len(partitionIDs))
+	for i, partitionID := range partitionIDs {
+		topicPartitions[i] = kafka.TopicPartition{Topic: topicName, Partition: partitionID}
+	}
+
+	offsetsRes, err := s.kafkaSvc.ListConsumerGroupOffsetsForPartitions(ctx, group, topicPartitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+	committedOffsets := make(map[int32]int64)
+	for _, topic := range offsetsRes.Topics {
+		for _, partition := range topic.Partitions {
+			if kerr.ErrorForCode(partition.ErrorCode) != nil || partition.Offset < 0 {
+				continue
+			}
+			committedOffsets[partition.Partition] = partition.Offset
+		}
+	}
+
+	return committedOffsets, nil
+}
+
+// groupOffsetConsumeRequests returns a consume request for each partition that has messages at or after the start
+// offset, which is the committed offset if there is one.
+func groupOffsetConsumeRequests(marks map[int32]*kafka.PartitionMarks, committedOffsets map[int32]int64, maxMessages int64, startOffsetWithoutCommit int64) map[int32]*kafka.PartitionConsumeRequest {
+	requests := make(map[int32]*kafka.PartitionConsumeRequest)
+	for partitionID, mark := range marks {
+		if mark.Error != "" {
+			continue
+		}
+
+		startOffset, hasCommit := committedOffsets[partitionID]
+		if !hasCommit || startOffset < mark.Low {
+			startOffset = mark.Low
+			if startOffsetWithoutCommit == StartOffsetNewest {
+				startOffset = mark.High
+			}
+		}
+		if startOffset >= mark.High {
+			// Nothing to consume for this partition
+			continue
+		}
+
+		requests[partitionID] = &kafka.PartitionConsumeRequest{
+			PartitionID:     partitionID,
+			LowWaterMark:    mark.Low,
+			HighWaterMark:   mark.High,
+			StartOffset:     startOffset,
+			EndOffset:       mark.High - 1,
+			MaxMessageCount: maxMessages,
+		}
+	}
+
+	return requests
+}
+
+// messageCollector is an IListMessagesProgress that collects all consumed messages
+type messageCollector struct {
+	messages []*kafka.TopicMessage
+	err      string
+}
+
+func (m *messageCollector) OnPhase(_ string)           {}
+func (m *messageCollector) OnMessageConsumed(_ int64)  {}
+func (m *messageCollector) OnComplete(_ int64, _ bool) {}
+
+func (m *messageCollector) OnMessage(message *kafka.TopicMessage) {
+	m.messages = append(m.messages, message)
+}
+
+func (m *messageCollector) OnError(msg string) {
+	m.err = msg
+}
@@ -0,0 +1,36 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupOffsetConsumeRequests(t *testing.T) {
+	marks := map[int32]*kafka.PartitionMarks{
+		0: {PartitionID: 0, Low: 0, High: 100},
+		1: {PartitionID: 1, Low: 50, High: 100},
+		2: {PartitionID: 2, Low: 0, High: 30},
+		3: {PartitionID: 3, Low: 10, High: 20},
+		4: {PartitionID: 4, Error: "NOT_LEADER_FOR_PARTITION", Low: -1, High: -1},
+	}
+	committedOffsets := map[int32]int64{
+		0: 40,  // regular commit
+		1: 10,  // behind retention
+		2: 30,  // fully caught up
+		4: 123, // watermarks unknown
+	}
+
+	requests := groupOffsetConsumeRequests(marks, committedOffsets, 5, StartOffsetOldest)
+	assert.Equal(t, map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, LowWaterMark: 0, HighWaterMark: 100, StartOffset: 40, EndOffset: 99, MaxMessageCount: 5},
+		1: {PartitionID: 1, LowWaterMark: 50, HighWaterMark: 100, StartOffset: 50, EndOffset: 99, MaxMessageCount: 5},
+		3: {PartitionID: 3, LowWaterMark: 10, HighWaterMark: 20, StartOffset: 10, EndOffset: 19, MaxMessageCount: 5},
+	}, requests)
+
+	requests = groupOffsetConsumeRequests(marks, committedOffsets, 5, StartOffsetNewest)
+	assert.Equal(t, map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, LowWaterMark: 0, HighWaterMark: 100, StartOffset: 40, EndOffset: 99, MaxMessageCount: 5},
+	}, requests)
+}
@@ -0,0 +1,55 @@
+package owl
+
+import (
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// Actions a user can perform on a consumer group. They are derived from the group's authorized operations, so that
+// the frontend can disable actions which would be rejected anyways.
+const (
+	GroupActionView          = "view"
+	GroupActionResetOffsets  = "reset-offsets"
+	GroupActionDeleteOffsets = "delete-offsets"
+	GroupActionDelete        = "delete"
+)
+
+var allGroupActions = []string{GroupActionDelete, GroupActionDeleteOffsets, GroupActionResetOffsets, GroupActionView}
+
+// operationsByGroupAction are the ACL operations on the group resource which are required for each action. Committing
+// offsets requires READ while deleting offsets or the whole group requires DELETE.
+var operationsByGroupAction = map[string]kmsg.ACLOperation{
+	GroupActionView:          kmsg.ACLOperationDescribe,
+	GroupActionResetOffsets:  kmsg.ACLOperationRead,
+	GroupActionDeleteOffsets: kmsg.ACLOperationDelete,
+	GroupActionDelete:        kmsg.ACLOperationDelete,
+}
+
+// GroupActionsForOperations returns the sorted group actions which are allowed by the given authorized operations.
+// If the authorized operations are not known (nil), e.g. because the broker does not report them (Kafka < v2.3), all
+// actions are returned. Either way the broker still enforces its ACLs when the action is performed, and some actions
+// require further permissions (e.g. READ on the topics whose offsets are reset), which are not checked here.
+func GroupActionsForOperations(authorizedOperations []string) []string {
+	if authorizedOperations == nil {
+		actions := make([]string, len(allGroupActions))
+		copy(actions, allGroupActions)
+		return actions
+	}
+
+	authorized := make(map[string]struct{}, len(authorizedOperations))
+	for _, op := range authorizedOperations {
+		authorized[op] = struct{}{}
+	}
+	_, isAllAuthorized := authorized[kmsg.ACLOperationAll.String()]
+
+	actions := make([]string, 0, len(allGroupActions))
+	for action, op := range operationsByGroupAction {
+		if _, isAuthorized := authorized[op.String()]; isAuthorized || isAllAuthorized {
+			actions = append(actions, action)
+		}
+	}
+	sort.Strings(actions)
+
+	return actions
+}
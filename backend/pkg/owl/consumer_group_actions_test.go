@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupActionsForOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []string
+		want       []string
+	}{
+		{name: "unknown operations", operations: nil, want: []string{GroupActionDelete, GroupActionDeleteOffsets, GroupActionResetOffsets, GroupActionView}},
+		{name: "no operations", operations: []string{}, want: []string{}},
+		{name: "describe only", operations: []string{"DESCRIBE"}, want: []string{GroupActionView}},
+		{name: "read and describe", operations: []string{"DESCRIBE", "READ"}, want: []string{GroupActionResetOffsets, GroupActionView}},
+		{name: "delete", operations: []string{"DELETE", "DESCRIBE"}, want: []string{GroupActionDelete, GroupActionDeleteOffsets, GroupActionView}},
+		{name: "all", operations: []string{"ALL"}, want: []string{GroupActionDelete, GroupActionDeleteOffsets, GroupActionResetOffsets, GroupActionView}},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, GroupActionsForOperations(tc.operations), tc.name)
+	}
+}
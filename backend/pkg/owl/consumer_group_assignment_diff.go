@@ -0,0 +1,182 @@
+package owl
+
+import (
+	"sort"
+)
+
+// AssignmentDiff describes how the partition assignments of a consumer group have changed between two generations,
+// e.g. before and after a rebalance.
+type AssignmentDiff struct {
+	// Members contains one entry for each member whose assignments have changed, sorted by member ID
+	Members []MemberAssignmentDiff `json:"members"`
+
+	// Moved contains all partitions which were assigned to a different member in both generations
+	Moved []PartitionMove `json:"moved"`
+}
+
+// MemberAssignmentDiff contains the partitions a single member has gained and lost. Static members (with a group
+// instance ID) are considered the same member across generations, even if they reconnected with a new member ID.
+type MemberAssignmentDiff struct {
+	MemberID        string                  `json:"memberId"` // Member ID in the new generation, old one if the member left
+	GroupInstanceID string                  `json:"groupInstanceId"`
+	Joined          bool                    `json:"joined"` // Member did not exist in the old generation
+	Left            bool                    `json:"left"`   // Member does not exist in the new generation anymore
+	Gained          []GroupMemberAssignment `json:"gained"`
+	Lost            []GroupMemberAssignment `json:"lost"`
+
+	// PreviousMemberID is set if a static member has reconnected with a new member ID
+	PreviousMemberID string `json:"previousMemberId,omitempty"`
+}
+
+// PartitionMove is a partition which has been reassigned from one member to another.
+type PartitionMove struct {
+	TopicName    string `json:"topicName"`
+	PartitionID  int32  `json:"partitionId"`
+	FromMemberID string `json:"fromMemberId"`
+	ToMemberID   string `json:"toMemberId"`
+}
+
+type memberTopicPartition struct {
+	TopicName   string
+	PartitionID int32
+}
+
+// logicalMember is a group member identified by its group instance ID if it's a static member, otherwise by its
+// (ephemeral) member ID.
+type logicalMember struct {
+	MemberID        string
+	GroupInstanceID string
+}
+
+func logicalMemberKey(member GroupMemberDescription) string {
+	if member.GroupInstanceID != "" {
+		return "instance:" + member.GroupInstanceID
+	}
+	return "member:" + member.ID
+}
+
+// DiffAssignments compares the member assignments of two descriptions of the same consumer group. Nil descriptions
+// are treated as groups without any members. Static members which merely reconnected with a new member ID are not
+// reported as left and joined members.
+func DiffAssignments(oldGroup, newGroup *ConsumerGroupOverview) AssignmentDiff {
+	oldOwners, oldMembers := partitionOwners(oldGroup)
+	newOwners, newMembers := partitionOwners(newGroup)
+
+	gained := make(map[string][]memberTopicPartition)
+	lost := make(map[string][]memberTopicPartition)
+	moved := make([]PartitionMove, 0)
+	for tp, newOwner := range newOwners {
+		oldOwner, wasAssigned := oldOwners[tp]
+		if wasAssigned && oldOwner == newOwner {
+			continue
+		}
+		gained[newOwner] = append(gained[newOwner], tp)
+		if wasAssigned {
+			moved = append(moved, PartitionMove{
+				TopicName:    tp.TopicName,
+				PartitionID:  tp.PartitionID,
+				FromMemberID: oldMembers[oldOwner].MemberID,
+				ToMemberID:   newMembers[newOwner].MemberID,
+			})
+		}
+	}
+	for tp, oldOwner := range oldOwners {
+		if newOwner, isAssigned := newOwners[tp]; isAssigned && newOwner == oldOwner {
+			continue
+		}
+		lost[oldOwner] = append(lost[oldOwner], tp)
+	}
+
+	// Collect all members which have changed, that is members that joined, left or whose assignments differ
+	changedMembers := make(map[string]struct{})
+	for key := range gained {
+		changedMembers[key] = struct{}{}
+	}
+	for key := range lost {
+		changedMembers[key] = struct{}{}
+	}
+	for key := range oldMembers {
+		if _, exists := newMembers[key]; !exists {
+			changedMembers[key] = struct{}{}
+		}
+	}
+	for key := range newMembers {
+		if _, exists := oldMembers[key]; !exists {
+			changedMembers[key] = struct{}{}
+		}
+	}
+
+	memberDiffs := make([]MemberAssignmentDiff, 0, len(changedMembers))
+	for key := range changedMembers {
+		oldMember, existedBefore := oldMembers[key]
+		newMember, existsNow := newMembers[key]
+		member := newMember
+		if !existsNow {
+			member = oldMember
+		}
+		var previousMemberID string
+		if existedBefore && existsNow && oldMember.MemberID != newMember.MemberID {
+			previousMemberID = oldMember.MemberID
+		}
+
+		memberDiffs = append(memberDiffs, MemberAssignmentDiff{
+			MemberID:         member.MemberID,
+			GroupInstanceID:  member.GroupInstanceID,
+			Joined:           !existedBefore,
+			Left:             !existsNow,
+			Gained:           groupByTopic(gained[key]),
+			Lost:             groupByTopic(lost[key]),
+			PreviousMemberID: previousMemberID,
+		})
+	}
+	sort.Slice(memberDiffs, func(i, j int) bool { return memberDiffs[i].MemberID < memberDiffs[j].MemberID })
+	sort.Slice(moved, func(i, j int) bool {
+		if moved[i].TopicName != moved[j].TopicName {
+			return moved[i].TopicName < moved[j].TopicName
+		}
+		return moved[i].PartitionID < moved[j].PartitionID
+	})
+
+	return AssignmentDiff{
+		Members: memberDiffs,
+		Moved:   moved,
+	}
+}
+
+// partitionOwners returns the logical member key for each assigned partition along with all members by their key
+func partitionOwners(group *ConsumerGroupOverview) (map[memberTopicPartition]string, map[string]logicalMember) {
+	owners := make(map[memberTopicPartition]string)
+	members := make(map[string]logicalMember)
+	if group == nil {
+		return owners, members
+	}
+
+	for _, member := range group.Members {
+		key := logicalMemberKey(member)
+		members[key] = logicalMember{MemberID: member.ID, GroupInstanceID: member.GroupInstanceID}
+		for _, assignment := range member.Assignments {
+			for _, partitionID := range assignment.PartitionIDs {
+				owners[memberTopicPartition{TopicName: assignment.TopicName, PartitionID: partitionID}] = key
+			}
+		}
+	}
+
+	return owners, members
+}
+
+// groupByTopic converts a list of topic partitions into assignments sorted by topic name and partition ID
+func groupByTopic(topicPartitions []memberTopicPartition) []GroupMemberAssignment {
+	partitionsByTopic := make(map[string][]int32)
+	for _, tp := range topicPartitions {
+		partitionsByTopic[tp.TopicName] = append(partitionsByTopic[tp.TopicName], tp.PartitionID)
+	}
+
+	assignments := make([]GroupMemberAssignment, 0, len(partitionsByTopic))
+	for topicName, partitionIDs := range partitionsByTopic {
+		sort.Slice(partitionIDs, func(i, j int) bool { return partitionIDs[i] < partitionIDs[j] })
+		assignments = append(assignments, GroupMemberAssignment{TopicName: topicName, PartitionIDs: partitionIDs})
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].TopicName < assignments[j].TopicName })
+
+	return assignments
+}
@@ -0,0 +1,214 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testGroup(members ...GroupMemberDescription) *ConsumerGroupOverview {
+	return &ConsumerGroupOverview{GroupID: "test", Members: members}
+}
+
+func testMember(id string, assignments ...GroupMemberAssignment) GroupMemberDescription {
+	return GroupMemberDescription{ID: id, Assignments: assignments}
+}
+
+func TestDiffAssignments(t *testing.T) {
+	noAssignments := make([]GroupMemberAssignment, 0)
+
+	tests := []struct {
+		name     string
+		old      *ConsumerGroupOverview
+		new      *ConsumerGroupOverview
+		expected AssignmentDiff
+	}{
+		{
+			name: "unchanged",
+			old:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0, 1}})),
+			new:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{1, 0}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{},
+				Moved:   []PartitionMove{},
+			},
+		},
+		{
+			name: "partition added to existing member",
+			old:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			new: testGroup(testMember("a",
+				GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0, 1}},
+				GroupMemberAssignment{TopicName: "payments", PartitionIDs: []int32{3}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{
+						MemberID: "a",
+						Gained: []GroupMemberAssignment{
+							{TopicName: "orders", PartitionIDs: []int32{1}},
+							{TopicName: "payments", PartitionIDs: []int32{3}},
+						},
+						Lost: noAssignments,
+					},
+				},
+				Moved: []PartitionMove{},
+			},
+		},
+		{
+			name: "partition removed from existing member",
+			old:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0, 1}})),
+			new:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "a", Gained: noAssignments, Lost: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1}}}},
+				},
+				Moved: []PartitionMove{},
+			},
+		},
+		{
+			name: "member joined and partitions moved to it",
+			old:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0, 1, 2}})),
+			new: testGroup(
+				testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}}),
+				testMember("b", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{1, 2}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "a", Gained: noAssignments, Lost: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1, 2}}}},
+					{MemberID: "b", Joined: true, Gained: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1, 2}}}, Lost: noAssignments},
+				},
+				Moved: []PartitionMove{
+					{TopicName: "orders", PartitionID: 1, FromMemberID: "a", ToMemberID: "b"},
+					{TopicName: "orders", PartitionID: 2, FromMemberID: "a", ToMemberID: "b"},
+				},
+			},
+		},
+		{
+			name: "member left and its partitions moved to remaining member",
+			old: testGroup(
+				testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}}),
+				testMember("b", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{1}})),
+			new: testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0, 1}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "a", Gained: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1}}}, Lost: noAssignments},
+					{MemberID: "b", Left: true, Gained: noAssignments, Lost: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1}}}},
+				},
+				Moved: []PartitionMove{
+					{TopicName: "orders", PartitionID: 1, FromMemberID: "b", ToMemberID: "a"},
+				},
+			},
+		},
+		{
+			name: "member without assignments joined",
+			old:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			new: testGroup(
+				testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}}),
+				testMember("b")),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "b", Joined: true, Gained: noAssignments, Lost: noAssignments},
+				},
+				Moved: []PartitionMove{},
+			},
+		},
+		{
+			name: "group became empty",
+			old:  testGroup(testMember("a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			new:  nil,
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "a", Left: true, Gained: noAssignments, Lost: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}},
+				},
+				Moved: []PartitionMove{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		actual := DiffAssignments(tc.old, tc.new)
+		assert.Equal(t, tc.expected, actual, tc.name)
+	}
+}
+
+func TestDiffAssignments_StaticMembers(t *testing.T) {
+	noAssignments := make([]GroupMemberAssignment, 0)
+	staticMember := func(id string, instanceID string, assignments ...GroupMemberAssignment) GroupMemberDescription {
+		member := testMember(id, assignments...)
+		member.GroupInstanceID = instanceID
+		return member
+	}
+
+	tests := []struct {
+		name     string
+		old      *ConsumerGroupOverview
+		new      *ConsumerGroupOverview
+		expected AssignmentDiff
+	}{
+		{
+			name: "static member reconnected with same assignments",
+			old: testGroup(
+				staticMember("a-1", "instance-a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}}),
+				staticMember("b-1", "instance-b", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{1}})),
+			new: testGroup(
+				staticMember("a-2", "instance-a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}}),
+				staticMember("b-1", "instance-b", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{1}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{},
+				Moved:   []PartitionMove{},
+			},
+		},
+		{
+			name: "static member reconnected and gained a partition",
+			old: testGroup(
+				staticMember("a-1", "instance-a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}}),
+				staticMember("b-1", "instance-b", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{1}})),
+			new: testGroup(
+				staticMember("a-2", "instance-a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0, 1}}),
+				staticMember("b-1", "instance-b")),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "a-2", GroupInstanceID: "instance-a", PreviousMemberID: "a-1",
+						Gained: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1}}}, Lost: noAssignments},
+					{MemberID: "b-1", GroupInstanceID: "instance-b",
+						Gained: noAssignments, Lost: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{1}}}},
+				},
+				Moved: []PartitionMove{
+					{TopicName: "orders", PartitionID: 1, FromMemberID: "b-1", ToMemberID: "a-2"},
+				},
+			},
+		},
+		{
+			name: "static member replaced by a different instance",
+			old:  testGroup(staticMember("a-1", "instance-a", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			new:  testGroup(staticMember("c-1", "instance-c", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "a-1", GroupInstanceID: "instance-a", Left: true,
+						Gained: noAssignments, Lost: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}},
+					{MemberID: "c-1", GroupInstanceID: "instance-c", Joined: true,
+						Gained: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}, Lost: noAssignments},
+				},
+				Moved: []PartitionMove{
+					{TopicName: "orders", PartitionID: 0, FromMemberID: "a-1", ToMemberID: "c-1"},
+				},
+			},
+		},
+		{
+			name: "dynamic member reconnected with a new member id",
+			old:  testGroup(testMember("a-1", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			new:  testGroup(testMember("a-2", GroupMemberAssignment{TopicName: "orders", PartitionIDs: []int32{0}})),
+			expected: AssignmentDiff{
+				Members: []MemberAssignmentDiff{
+					{MemberID: "a-1", Left: true, Gained: noAssignments, Lost: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}},
+					{MemberID: "a-2", Joined: true, Gained: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}, Lost: noAssignments},
+				},
+				Moved: []PartitionMove{
+					{TopicName: "orders", PartitionID: 0, FromMemberID: "a-1", ToMemberID: "a-2"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		actual := DiffAssignments(tc.old, tc.new)
+		assert.Equal(t, tc.expected, actual, tc.name)
+	}
+}
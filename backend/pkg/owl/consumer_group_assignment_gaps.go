@@ -0,0 +1,54 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// FindAssignmentGaps returns the sorted IDs of the topic's partitions which are not assigned to any member of the
+// given consumer group. Nobody in the group reads these partitions, e.g. because a member subscribes to a different
+// set of topics or the group is empty. An empty group returns all partitions of the topic. The returned warnings
+// list the members whose assignment could not be decoded, the partitions assigned to them are reported as gaps.
+func (s *Service) FindAssignmentGaps(ctx context.Context, groupID string, topicName string) ([]int32, []Warning, error) {
+	partitionIDs, err := s.kafkaSvc.ListPartitionIDs(ctx, topicName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list partitions: %w", err)
+	}
+
+	describedGroup, err := s.kafkaSvc.DescribeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+	members, warnings, err := s.convertGroupMembers(describedGroup.ProtocolType, describedGroup.Members, []string{topicName})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+
+	return unassignedPartitions(members, topicName, partitionIDs), warnings, nil
+}
+
+// unassignedPartitions returns the given partitions of the topic which are assigned to none of the members
+func unassignedPartitions(members []GroupMemberDescription, topicName string, partitionIDs []int32) []int32 {
+	assigned := make(map[int32]struct{})
+	for _, member := range members {
+		for _, assignment := range member.Assignments {
+			if assignment.TopicName != topicName {
+				continue
+			}
+			for _, partitionID := range assignment.PartitionIDs {
+				assigned[partitionID] = struct{}{}
+			}
+		}
+	}
+
+	gaps := make([]int32, 0)
+	for _, partitionID := range partitionIDs {
+		if _, isAssigned := assigned[partitionID]; !isAssigned {
+			gaps = append(gaps, partitionID)
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+
+	return gaps
+}
@@ -0,0 +1,63 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestUnassignedPartitions(t *testing.T) {
+	members := []GroupMemberDescription{
+		{ID: "a", Assignments: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 3}}}},
+		{ID: "b", Assignments: []GroupMemberAssignment{
+			{TopicName: "orders", PartitionIDs: []int32{1}},
+			{TopicName: "payments", PartitionIDs: []int32{2}},
+		}},
+	}
+
+	assert.Equal(t, []int32{2, 4}, unassignedPartitions(members, "orders", []int32{4, 3, 2, 1, 0}))
+	assert.Equal(t, []int32{0, 1}, unassignedPartitions(members, "payments", []int32{0, 1, 2}))
+	assert.Equal(t, []int32{0, 1}, unassignedPartitions(nil, "orders", []int32{0, 1}))
+	assert.Equal(t, []int32{}, unassignedPartitions(members, "orders", []int32{0, 1, 3}))
+}
+
+func TestFindAssignmentGaps_Warnings(t *testing.T) {
+	assignment := kmsg.GroupMemberAssignment{Topics: []kmsg.GroupMemberAssignmentTopic{{Topic: "orders", Partitions: []int32{0, 1}}}}
+	// Member b's assignment can not be decoded
+	client := &mockKafkaClient{handle: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+		switch req.(type) {
+		case *kmsg.MetadataRequest:
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{{
+				Topic:      "orders",
+				Partitions: []kmsg.MetadataResponseTopicPartition{{Partition: 0}, {Partition: 1}, {Partition: 2}},
+			}}}, nil
+		case *kmsg.DescribeGroupsRequest:
+			return &kmsg.DescribeGroupsResponse{Groups: []kmsg.DescribeGroupsResponseGroup{{
+				Group:        "billing",
+				State:        "Stable",
+				ProtocolType: "consumer",
+				Members: []kmsg.DescribeGroupsResponseGroupMember{
+					{MemberID: "a", MemberAssignment: assignment.AppendTo(nil)},
+					{MemberID: "b", MemberAssignment: []byte{0xff}},
+				},
+			}}}, nil
+		}
+		return nil, fmt.Errorf("unexpected %v request", kmsg.NameForKey(req.Key()))
+	}}
+	svc := &Service{logger: zap.NewNop(), kafkaSvc: &kafka.Service{Logger: zap.NewNop(), KafkaClient: client}}
+
+	// Partition 2 may be assigned to member b, hence the warning must be returned along with the gaps
+	gaps, warnings, err := svc.FindAssignmentGaps(context.Background(), "billing", "orders")
+	require.NoError(t, err)
+	assert.Equal(t, []int32{2}, gaps)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, WarningCodeMemberAssignmentDecode, warnings[0].Code)
+	assert.Equal(t, "b", warnings[0].Subject)
+}
@@ -0,0 +1,41 @@
+package owl
+
+// GroupAssignmentSummary is a compact health number of a consumer group: how many partitions in how many topics are
+// consumed by its members
+type GroupAssignmentSummary struct {
+	PartitionCount int `json:"partitionCount"`
+	TopicCount     int `json:"topicCount"`
+}
+
+// AssignmentSummary counts the distinct partitions and topics which are assigned to any member of the group. A
+// partition that is (wrongly) assigned to multiple members is counted once. Groups without members or assignments,
+// e.g. while rebalancing, have a summary of zero.
+func (g ConsumerGroupOverview) AssignmentSummary() GroupAssignmentSummary {
+	return summarizeAssignments(g.Members)
+}
+
+func summarizeAssignments(members []GroupMemberDescription) GroupAssignmentSummary {
+	partitionsByTopic := make(map[string]map[int32]struct{})
+	for _, member := range members {
+		for _, assignment := range member.Assignments {
+			if len(assignment.PartitionIDs) == 0 {
+				continue
+			}
+			partitions, exists := partitionsByTopic[assignment.TopicName]
+			if !exists {
+				partitions = make(map[int32]struct{})
+				partitionsByTopic[assignment.TopicName] = partitions
+			}
+			for _, partitionID := range assignment.PartitionIDs {
+				partitions[partitionID] = struct{}{}
+			}
+		}
+	}
+
+	summary := GroupAssignmentSummary{TopicCount: len(partitionsByTopic)}
+	for _, partitions := range partitionsByTopic {
+		summary.PartitionCount += len(partitions)
+	}
+
+	return summary
+}
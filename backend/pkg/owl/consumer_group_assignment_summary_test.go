@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerGroupOverview_AssignmentSummary(t *testing.T) {
+	group := ConsumerGroupOverview{Members: []GroupMemberDescription{
+		{ID: "a", Assignments: []GroupMemberAssignment{
+			{TopicName: "orders", PartitionIDs: []int32{0, 1}},
+			{TopicName: "payments", PartitionIDs: []int32{0}},
+		}},
+		{ID: "b", Assignments: []GroupMemberAssignment{
+			{TopicName: "orders", PartitionIDs: []int32{2, 1}}, // partition 1 is assigned twice
+			{TopicName: "shipments", PartitionIDs: []int32{}},
+		}},
+		{ID: "c", Assignments: []GroupMemberAssignment{}},
+	}}
+	assert.Equal(t, GroupAssignmentSummary{PartitionCount: 4, TopicCount: 2}, group.AssignmentSummary())
+
+	// Empty groups
+	assert.Equal(t, GroupAssignmentSummary{}, ConsumerGroupOverview{}.AssignmentSummary())
+	assert.Equal(t, GroupAssignmentSummary{}, ConsumerGroupOverview{Members: make([]GroupMemberDescription, 0)}.AssignmentSummary())
+}
@@ -0,0 +1,193 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// AssignmentRow is a flat representation of a single partition assignment of a consumer group member along with
+// the group's committed offset and the partition's log end offset. Partitions which the group has committed offsets
+// for, but which are not assigned to any member, are represented by rows without member details.
+type AssignmentRow struct {
+	MemberID    string `json:"memberId"`
+	ClientID    string `json:"clientId"`
+	ClientHost  string `json:"clientHost"`
+	TopicName   string `json:"topicName"`
+	PartitionID int32  `json:"partitionId"`
+
+	// Assigned is false if the group has committed offsets for this partition, but no member is assigned to it
+	// (e.g. because the member left or the group no longer subscribes to the topic)
+	Assigned bool `json:"assigned"`
+
+	// GroupOffset is the committed offset of the group. It's nil if the group has never committed an offset
+	// for this partition, which should be displayed as "none".
+	GroupOffset *int64 `json:"groupOffset"`
+
+	// HighWaterMark is the log end offset of the partition
+	HighWaterMark int64 `json:"highWaterMark"`
+
+	// Lag is nil if either the group offset or the high water mark is unknown
+	Lag *int64 `json:"lag"`
+
+	// BehindRetention is true if the group offset is below the partition's log start offset
+	BehindRetention bool `json:"behindRetention"`
+
+	// Error will be set when the high water mark could not be fetched
+	Error string `json:"error,omitempty"`
+}
+
+// GetConsumerGroupAssignmentTable returns one row for each partition that is assigned to a member of the given
+// consumer group or that the group has committed an offset for. Each row contains the member's details, the committed
+// group offset, the log end offset and the resulting lag. Rows of assigned partitions are sorted by member ID, topic
+// name and partition ID, followed by the rows of unassigned partitions sorted by topic name and partition ID.
+func (s *Service) GetConsumerGroupAssignmentTable(ctx context.Context, groupID string) ([]AssignmentRow, error) {
+	// 1. Describe group so that we know all members and their assignments
+	describedGroup, err := s.kafkaSvc.DescribeConsumerGroup(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer group: %w", err)
+	}
+	members, _, err := s.convertGroupMembers(describedGroup.ProtocolType, describedGroup.Members, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+
+	// 2. Fetch committed group offsets
+	offsetsRes, err := s.kafkaSvc.ListConsumerGroupOffsets(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+	offsetsByTopic := convertOffsets(offsetsRes)
+
+	// 3. Fetch watermarks for all assigned partitions and all partitions with committed offsets
+	waterMarks, err := s.kafkaSvc.GetPartitionMarksBulk(ctx, assignmentTablePartitions(members, offsetsByTopic))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition watermarks: %w", err)
+	}
+
+	// 4. Join all three responses into flat rows
+	return s.joinAssignmentRows(groupID, members, offsetsByTopic, waterMarks), nil
+}
+
+// assignmentTablePartitions returns the union of all assigned partitions and all partitions with committed offsets
+func assignmentTablePartitions(members []GroupMemberDescription, offsetsByTopic map[string]partitionOffsets) map[string][]int32 {
+	partitionSet := make(map[string]map[int32]struct{})
+	add := func(topicName string, partitionID int32) {
+		if _, exists := partitionSet[topicName]; !exists {
+			partitionSet[topicName] = make(map[int32]struct{})
+		}
+		partitionSet[topicName][partitionID] = struct{}{}
+	}
+	for _, member := range members {
+		for _, assignment := range member.Assignments {
+			for _, partitionID := range assignment.PartitionIDs {
+				add(assignment.TopicName, partitionID)
+			}
+		}
+	}
+	for topicName, offsets := range offsetsByTopic {
+		for partitionID, offset := range offsets {
+			if offset >= 0 {
+				add(topicName, partitionID)
+			}
+		}
+	}
+
+	topicPartitions := make(map[string][]int32, len(partitionSet))
+	for topicName, partitionIDs := range partitionSet {
+		for partitionID := range partitionIDs {
+			topicPartitions[topicName] = append(topicPartitions[topicName], partitionID)
+		}
+	}
+
+	return topicPartitions
+}
+
+// joinAssignmentRows creates one row for each assigned partition and one row for each partition that has a committed
+// offset but is not assigned to any member.
+func (s *Service) joinAssignmentRows(groupID string, members []GroupMemberDescription, offsetsByTopic map[string]partitionOffsets, waterMarks map[string]map[int32]*kafka.PartitionMarks) []AssignmentRow {
+	fillOffsets := func(row *AssignmentRow) {
+		if offset, exists := offsetsByTopic[row.TopicName][row.PartitionID]; exists && offset >= 0 {
+			groupOffset := offset
+			row.GroupOffset = &groupOffset
+		}
+
+		mark, exists := waterMarks[row.TopicName][row.PartitionID]
+		if !exists {
+			s.logger.Warn("no partition watermark for a consumer group partition available",
+				zap.String("group", groupID),
+				zap.String("topic", row.TopicName),
+				zap.Int32("partition_id", row.PartitionID))
+			row.Error = "no partition watermark available"
+		} else if mark.Error != "" {
+			row.Error = mark.Error
+		} else {
+			row.HighWaterMark = mark.High
+		}
+
+		if row.GroupOffset != nil && row.Error == "" {
+			lag, behindRetention := calculateLag(*row.GroupOffset, mark.Low, mark.High)
+			row.Lag = &lag
+			row.BehindRetention = behindRetention
+		}
+	}
+
+	rows := make([]AssignmentRow, 0)
+	assigned := make(map[string]map[int32]struct{})
+	for _, member := range members {
+		for _, assignment := range member.Assignments {
+			if _, exists := assigned[assignment.TopicName]; !exists {
+				assigned[assignment.TopicName] = make(map[int32]struct{})
+			}
+			for _, partitionID := range assignment.PartitionIDs {
+				assigned[assignment.TopicName][partitionID] = struct{}{}
+				row := AssignmentRow{
+					MemberID:      member.ID,
+					ClientID:      member.ClientID,
+					ClientHost:    member.ClientHost,
+					TopicName:     assignment.TopicName,
+					PartitionID:   partitionID,
+					Assigned:      true,
+					HighWaterMark: -1,
+				}
+				fillOffsets(&row)
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	for topicName, offsets := range offsetsByTopic {
+		for partitionID, offset := range offsets {
+			if _, isAssigned := assigned[topicName][partitionID]; isAssigned || offset < 0 {
+				continue
+			}
+			row := AssignmentRow{
+				TopicName:     topicName,
+				PartitionID:   partitionID,
+				Assigned:      false,
+				HighWaterMark: -1,
+			}
+			fillOffsets(&row)
+			rows = append(rows, row)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Assigned != rows[j].Assigned {
+			return rows[i].Assigned
+		}
+		if rows[i].MemberID != rows[j].MemberID {
+			return rows[i].MemberID < rows[j].MemberID
+		}
+		if rows[i].TopicName != rows[j].TopicName {
+			return rows[i].TopicName < rows[j].TopicName
+		}
+		return rows[i].PartitionID < rows[j].PartitionID
+	})
+
+	return rows
+}
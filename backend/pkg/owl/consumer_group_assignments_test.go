@@ -0,0 +1,139 @@
+package owl
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestJoinAssignmentRows_UnassignedCommittedPartitions(t *testing.T) {
+	members := []GroupMemberDescription{
+		{ID: "member-a", ClientID: "client-a", ClientHost: "/10.0.0.1", Assignments: []GroupMemberAssignment{
+			{TopicName: "orders", PartitionIDs: []int32{0}},
+		}},
+	}
+	offsetsByTopic := map[string]partitionOffsets{
+		"orders":   {0: 40, 1: 90, 2: -1}, // partition 1 was assigned to a member that left, 2 has never been committed
+		"payments": {0: 5},                // group no longer subscribes to this topic
+	}
+	waterMarks := map[string]map[int32]*kafka.PartitionMarks{
+		"orders": {
+			0: {PartitionID: 0, Low: 0, High: 100},
+			1: {PartitionID: 1, Low: 0, High: 100},
+		},
+		"payments": {
+			0: {PartitionID: 0, Low: 10, High: 20},
+		},
+	}
+
+	assert.Equal(t, map[string][]int32{"orders": {0, 1}, "payments": {0}}, sortedTopicPartitions(assignmentTablePartitions(members, offsetsByTopic)))
+
+	svc := &Service{logger: zap.NewNop()}
+	rows := svc.joinAssignmentRows("test", members, offsetsByTopic, waterMarks)
+	require.Len(t, rows, 3)
+	assert.Equal(t, AssignmentRow{
+		MemberID:      "member-a",
+		ClientID:      "client-a",
+		ClientHost:    "/10.0.0.1",
+		TopicName:     "orders",
+		PartitionID:   0,
+		Assigned:      true,
+		GroupOffset:   int64Ptr(40),
+		HighWaterMark: 100,
+		Lag:           int64Ptr(60),
+	}, rows[0])
+	assert.Equal(t, AssignmentRow{
+		TopicName:     "orders",
+		PartitionID:   1,
+		Assigned:      false,
+		GroupOffset:   int64Ptr(90),
+		HighWaterMark: 100,
+		Lag:           int64Ptr(10),
+	}, rows[1])
+	assert.Equal(t, AssignmentRow{
+		TopicName:       "payments",
+		PartitionID:     0,
+		Assigned:        false,
+		GroupOffset:     int64Ptr(5),
+		HighWaterMark:   20,
+		Lag:             int64Ptr(10),
+		BehindRetention: true,
+	}, rows[2])
+}
+
+func TestJoinAssignmentRows(t *testing.T) {
+	members := []GroupMemberDescription{
+		{ID: "member-b", ClientID: "client-b", ClientHost: "/10.0.0.2", Assignments: []GroupMemberAssignment{
+			{TopicName: "orders", PartitionIDs: []int32{2}},
+		}},
+		{ID: "member-a", ClientID: "client-a", ClientHost: "/10.0.0.1", Assignments: []GroupMemberAssignment{
+			{TopicName: "orders", PartitionIDs: []int32{1, 0}},
+			{TopicName: "payments", PartitionIDs: []int32{0}},
+		}},
+	}
+	offsetsByTopic := map[string]partitionOffsets{
+		"orders":   {0: 40, 1: -1, 2: 5},
+		"payments": {0: 7},
+	}
+	waterMarks := map[string]map[int32]*kafka.PartitionMarks{
+		"orders": {
+			0: {PartitionID: 0, Low: 0, High: 100},
+			1: {PartitionID: 1, Low: 0, High: 50},
+			2: {PartitionID: 2, Low: 10, High: 30},
+		},
+		"payments": {
+			0: {PartitionID: 0, Error: "NOT_LEADER_FOR_PARTITION"},
+		},
+	}
+
+	svc := &Service{logger: zap.NewNop()}
+	rows := svc.joinAssignmentRows("test", members, offsetsByTopic, waterMarks)
+
+	tests := []struct {
+		name string
+		want AssignmentRow
+	}{
+		{
+			name: "assigned and committed",
+			want: AssignmentRow{MemberID: "member-a", ClientID: "client-a", ClientHost: "/10.0.0.1", TopicName: "orders", PartitionID: 0,
+				Assigned: true, GroupOffset: int64Ptr(40), HighWaterMark: 100, Lag: int64Ptr(60)},
+		},
+		{
+			// Displayed as "none": neither group offset nor lag are known
+			name: "assigned but never committed",
+			want: AssignmentRow{MemberID: "member-a", ClientID: "client-a", ClientHost: "/10.0.0.1", TopicName: "orders", PartitionID: 1,
+				Assigned: true, GroupOffset: nil, HighWaterMark: 50, Lag: nil},
+		},
+		{
+			name: "watermark error",
+			want: AssignmentRow{MemberID: "member-a", ClientID: "client-a", ClientHost: "/10.0.0.1", TopicName: "payments", PartitionID: 0,
+				Assigned: true, GroupOffset: int64Ptr(7), HighWaterMark: -1, Lag: nil, Error: "NOT_LEADER_FOR_PARTITION"},
+		},
+		{
+			name: "behind retention",
+			want: AssignmentRow{MemberID: "member-b", ClientID: "client-b", ClientHost: "/10.0.0.2", TopicName: "orders", PartitionID: 2,
+				Assigned: true, GroupOffset: int64Ptr(5), HighWaterMark: 30, Lag: int64Ptr(20), BehindRetention: true},
+		},
+	}
+
+	require.Len(t, rows, len(tests))
+	for i, tc := range tests {
+		assert.Equal(t, tc.want, rows[i], tc.name)
+	}
+}
+
+func sortedTopicPartitions(topicPartitions map[string][]int32) map[string][]int32 {
+	for _, partitionIDs := range topicPartitions {
+		sort.Slice(partitionIDs, func(i, j int) bool { return partitionIDs[i] < partitionIDs[j] })
+	}
+	return topicPartitions
+}
@@ -0,0 +1,119 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// PartitionLag is the committed offset of a consumer group for a single partition along with the partition's log
+// start and end offset.
+type PartitionLag struct {
+	Topic           string `json:"topic"`
+	PartitionID     int32  `json:"partitionId"`
+	CommittedOffset int64  `json:"committedOffset"`
+	LogStartOffset  int64  `json:"logStartOffset"`
+	LogEndOffset    int64  `json:"logEndOffset"`
+
+	// Lag is the number of records the group still has to consume, see BehindRetention
+	Lag int64 `json:"lag"`
+
+	// BehindRetention is true if the committed offset is below the log start offset, so that records have been
+	// deleted before the group consumed them. The lag is capped at the number of records which are still available.
+	BehindRetention bool `json:"behindRetention"`
+
+	// RetentionPosition is the committed offset's position within the available log, from 0.0 at the log start
+	// offset to 1.0 at the log end offset. Groups close to 0.0 are about to lose records due to retention. It is nil
+	// if the partition is empty or its offsets could not be fetched.
+	RetentionPosition *float64 `json:"retentionPosition"`
+
+	// Error is set if the committed offset or the partition's log offsets could not be fetched. The lag and the
+	// offsets which could not be fetched are zero then.
+	Error string `json:"error,omitempty"`
+}
+
+// GetGroupLagDetailed returns the lag of the group for each partition it has committed an offset for, sorted by
+// topic name and partition ID. The committed offsets are fetched first, then the log start and end offsets of these
+// partitions are listed. Both ListOffsets requests are sent concurrently and the client splits them into one request
+// per partition leader, which are sent in parallel as well.
+func (s *Service) GetGroupLagDetailed(ctx context.Context, group string) ([]PartitionLag, error) {
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(ctx, group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+
+	topicPartitions := committedTopicPartitions(offsets)
+	if len(topicPartitions) == 0 {
+		return make([]PartitionLag, 0), nil
+	}
+
+	marks, err := s.kafkaSvc.GetPartitionMarksBulk(ctx, topicPartitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partition offsets: %w", err)
+	}
+
+	return mergePartitionLags(offsets, marks), nil
+}
+
+// mergePartitionLags calculates the lag of each committed offset from the partition's watermarks. Partitions
+// without a committed offset (offset -1) are not part of the result.
+func mergePartitionLags(offsets *kmsg.OffsetFetchResponse, marks map[string]map[int32]*kafka.PartitionMarks) []PartitionLag {
+	lags := make([]PartitionLag, 0)
+	for _, topic := range offsets.Topics {
+		for _, partition := range topic.Partitions {
+			lag := PartitionLag{Topic: topic.Topic, PartitionID: partition.Partition}
+			if err := kerr.ErrorForCode(partition.ErrorCode); err != nil {
+				lag.Error = fmt.Sprintf("failed to fetch committed offset: %v", err.Error())
+				lags = append(lags, lag)
+				continue
+			}
+			if partition.Offset < 0 {
+				continue
+			}
+			lag.CommittedOffset = partition.Offset
+
+			mark, exists := marks[topic.Topic][partition.Partition]
+			switch {
+			case !exists:
+				lag.Error = "partition offsets are missing in the response"
+			case mark.Error != "":
+				lag.Error = mark.Error
+			default:
+				lag.LogStartOffset = mark.Low
+				lag.LogEndOffset = mark.High
+				lag.Lag, lag.BehindRetention = calculateLag(partition.Offset, mark.Low, mark.High)
+				lag.RetentionPosition = retentionPosition(partition.Offset, mark.Low, mark.High)
+			}
+			lags = append(lags, lag)
+		}
+	}
+
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].PartitionID < lags[j].PartitionID
+	})
+
+	return lags
+}
+
+// retentionPosition returns the position of the group offset relative to the partition's low..high range, clamped
+// to 0.0 and 1.0. Nil is returned for empty partitions, where the position is undefined.
+func retentionPosition(groupOffset, lowWaterMark, highWaterMark int64) *float64 {
+	if highWaterMark <= lowWaterMark {
+		return nil
+	}
+	position := float64(groupOffset-lowWaterMark) / float64(highWaterMark-lowWaterMark)
+	if position < 0 {
+		position = 0
+	} else if position > 1 {
+		position = 1
+	}
+	return &position
+}
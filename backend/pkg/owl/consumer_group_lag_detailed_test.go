@@ -0,0 +1,67 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestMergePartitionLags(t *testing.T) {
+	offsets := &kmsg.OffsetFetchResponse{Topics: []kmsg.OffsetFetchResponseTopic{
+		{Topic: "payments", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+			{Partition: 0, Offset: 5},
+		}},
+		{Topic: "orders", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+			{Partition: 2, Offset: -1},
+			{Partition: 1, Offset: 10},
+			{Partition: 0, Offset: 90},
+			{Partition: 3, ErrorCode: kerr.UnstableOffsetCommit.Code},
+			{Partition: 4, Offset: 3},
+		}},
+	}}
+	marks := map[string]map[int32]*kafka.PartitionMarks{
+		"orders": {
+			0: {PartitionID: 0, Low: 0, High: 100},
+			1: {PartitionID: 1, Low: 50, High: 80},
+			4: {PartitionID: 4, Error: kerr.NotLeaderForPartition.Message},
+		},
+	}
+
+	lags := mergePartitionLags(offsets, marks)
+	assert.Equal(t, []PartitionLag{
+		{Topic: "orders", PartitionID: 0, CommittedOffset: 90, LogStartOffset: 0, LogEndOffset: 100, Lag: 10, RetentionPosition: float64Ptr(0.9)},
+		{Topic: "orders", PartitionID: 1, CommittedOffset: 10, LogStartOffset: 50, LogEndOffset: 80, Lag: 30, BehindRetention: true, RetentionPosition: float64Ptr(0)},
+		{Topic: "orders", PartitionID: 3, Error: "failed to fetch committed offset: " + kerr.UnstableOffsetCommit.Error()},
+		{Topic: "orders", PartitionID: 4, CommittedOffset: 3, Error: kerr.NotLeaderForPartition.Message},
+		{Topic: "payments", PartitionID: 0, CommittedOffset: 5, Error: "partition offsets are missing in the response"},
+	}, lags)
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestRetentionPosition(t *testing.T) {
+	tests := []struct {
+		name         string
+		groupOffset  int64
+		low          int64
+		high         int64
+		wantPosition *float64
+	}{
+		{name: "at log start", groupOffset: 100, low: 100, high: 200, wantPosition: float64Ptr(0)},
+		{name: "in between", groupOffset: 125, low: 100, high: 200, wantPosition: float64Ptr(0.25)},
+		{name: "caught up", groupOffset: 200, low: 100, high: 200, wantPosition: float64Ptr(1)},
+		{name: "behind retention", groupOffset: 20, low: 100, high: 200, wantPosition: float64Ptr(0)},
+		{name: "ahead of watermark", groupOffset: 210, low: 100, high: 200, wantPosition: float64Ptr(1)},
+		{name: "empty partition", groupOffset: 100, low: 100, high: 100, wantPosition: nil},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.wantPosition, retentionPosition(tc.groupOffset, tc.low, tc.high), tc.name)
+	}
+}
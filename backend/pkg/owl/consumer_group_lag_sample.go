@@ -0,0 +1,69 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LagSample is the summed lag of a consumer group across all its topics at a point in time.
+type LagSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	TotalLag  int64     `json:"totalLag"`
+}
+
+// SampleGroupLag requests the lag of the given group `samples` times, waiting `interval` between two requests, and
+// returns the lags as time series. If the context is cancelled before all samples are taken, the samples taken so far
+// are returned along with the context's error.
+func (s *Service) SampleGroupLag(ctx context.Context, group string, interval time.Duration, samples int) ([]LagSample, error) {
+	return sampleLag(ctx, interval, samples, time.Now, func(ctx context.Context) (int64, error) {
+		return s.getGroupTotalLag(ctx, group)
+	})
+}
+
+// getGroupTotalLag returns the summed lag of all partitions the group has committed offsets for
+func (s *Service) getGroupTotalLag(ctx context.Context, group string) (int64, error) {
+	offsetsByGroup, err := s.getConsumerGroupOffsets(ctx, []string{group})
+	if err != nil {
+		return 0, err
+	}
+
+	var totalLag int64
+	for _, topicOffsets := range offsetsByGroup[group] {
+		totalLag += topicOffsets.SummedLag
+	}
+	return totalLag, nil
+}
+
+// sampleLag calls getLag `samples` times with `interval` in between. The timestamp of a sample is taken before its
+// lag is requested.
+func sampleLag(ctx context.Context, interval time.Duration, samples int, now func() time.Time, getLag func(ctx context.Context) (int64, error)) ([]LagSample, error) {
+	if samples <= 0 {
+		return nil, fmt.Errorf("number of samples must be greater than 0")
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("sample interval must not be negative")
+	}
+
+	lagSamples := make([]LagSample, 0, samples)
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return lagSamples, fmt.Errorf("sampling group lag was cancelled after '%v' samples: %w", len(lagSamples), ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		timestamp := now()
+		lag, err := getLag(ctx)
+		if err != nil {
+			return lagSamples, fmt.Errorf("failed to get lag for sample '%v': %w", i+1, err)
+		}
+		lagSamples = append(lagSamples, LagSample{Timestamp: timestamp, TotalLag: lag})
+	}
+
+	return lagSamples, nil
+}
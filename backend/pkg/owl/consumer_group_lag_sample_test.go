@@ -0,0 +1,60 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleLag(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	calls := 0
+	now := func() time.Time { return start.Add(time.Duration(calls) * time.Second) }
+	getLag := func(_ context.Context) (int64, error) {
+		calls++
+		return int64(calls * 10), nil
+	}
+
+	samples, err := sampleLag(context.Background(), time.Millisecond, 3, now, getLag)
+	require.NoError(t, err)
+	assert.Equal(t, []LagSample{
+		{Timestamp: start, TotalLag: 10},
+		{Timestamp: start.Add(time.Second), TotalLag: 20},
+		{Timestamp: start.Add(2 * time.Second), TotalLag: 30},
+	}, samples)
+
+	_, err = sampleLag(context.Background(), time.Millisecond, 0, now, getLag)
+	assert.Error(t, err)
+}
+
+func TestSampleLag_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	getLag := func(_ context.Context) (int64, error) {
+		cancel()
+		return 5, nil
+	}
+
+	samples, err := sampleLag(ctx, time.Hour, 3, time.Now, getLag)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Len(t, samples, 1)
+}
+
+func TestSampleLag_Error(t *testing.T) {
+	calls := 0
+	getLag := func(_ context.Context) (int64, error) {
+		calls++
+		if calls == 2 {
+			return 0, errors.New("broker down")
+		}
+		return 5, nil
+	}
+
+	samples, err := sampleLag(context.Background(), 0, 3, time.Now, getLag)
+	assert.Error(t, err)
+	assert.Len(t, samples, 1)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,103 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// GroupLagSnapshot is emitted by WatchGroupLag for every sample of the group's lag.
+type GroupLagSnapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// TotalLag is the sum of the lags of all partitions which could be fetched without error
+	TotalLag   int64          `json:"totalLag"`
+	Partitions []PartitionLag `json:"partitions"`
+
+	// ConsumptionRate is the number of records per second the group has committed since the previous snapshot. It can
+	// be compared with the change of TotalLag to see whether the group is catching up or falling behind. Partitions
+	// whose committed offset has been reset or could not be fetched in either sample are not considered. The rate of
+	// the first snapshot is 0.
+	ConsumptionRate float64 `json:"consumptionRate"`
+}
+
+// WatchGroupLag fetches the group's lag every interval (see GetGroupLagDetailed) and emits a snapshot for each
+// sample. The first snapshot describes the group's current lag. An error is returned if the lag can not be fetched
+// initially, later failures are logged and the sample is skipped. The returned channel is closed once the context is
+// done.
+func (s *Service) WatchGroupLag(ctx context.Context, group string, interval time.Duration) (<-chan GroupLagSnapshot, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watch interval must be greater than 0")
+	}
+
+	initial, err := s.GetGroupLagDetailed(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make(chan GroupLagSnapshot, 1)
+	go func() {
+		defer close(snapshots)
+		watcher := groupLagWatcher{}
+		watcher.observe(ctx, time.Now(), initial, snapshots)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			lags, err := s.GetGroupLagDetailed(ctx, group)
+			if err != nil {
+				s.logger.Debug("failed to get group lag while watching it", zap.String("group_id", group), zap.Error(err))
+				continue
+			}
+			watcher.observe(ctx, time.Now(), lags, snapshots)
+		}
+	}()
+
+	return snapshots, nil
+}
+
+// groupLagWatcher remembers the committed offsets of the last sample in order to calculate the consumption rate.
+type groupLagWatcher struct {
+	lastTimestamp        time.Time
+	lastCommittedOffsets map[string]map[int32]int64
+}
+
+// observe sends a snapshot of the given lags and remembers their committed offsets for the next observation.
+func (w *groupLagWatcher) observe(ctx context.Context, timestamp time.Time, lags []PartitionLag, snapshots chan<- GroupLagSnapshot) {
+	snapshot := GroupLagSnapshot{Timestamp: timestamp, Partitions: lags}
+
+	committedOffsets := make(map[string]map[int32]int64)
+	consumedRecords := int64(0)
+	for _, lag := range lags {
+		if lag.Error != "" {
+			continue
+		}
+		snapshot.TotalLag += lag.Lag
+		if _, exists := committedOffsets[lag.Topic]; !exists {
+			committedOffsets[lag.Topic] = make(map[int32]int64)
+		}
+		committedOffsets[lag.Topic][lag.PartitionID] = lag.CommittedOffset
+
+		lastOffset, exists := w.lastCommittedOffsets[lag.Topic][lag.PartitionID]
+		if exists && lag.CommittedOffset >= lastOffset {
+			consumedRecords += lag.CommittedOffset - lastOffset
+		}
+	}
+	if elapsed := timestamp.Sub(w.lastTimestamp); w.lastCommittedOffsets != nil && elapsed > 0 {
+		snapshot.ConsumptionRate = float64(consumedRecords) / elapsed.Seconds()
+	}
+	w.lastTimestamp = timestamp
+	w.lastCommittedOffsets = committedOffsets
+
+	select {
+	case <-ctx.Done():
+	case snapshots <- snapshot:
+	}
+}
@@ -0,0 +1,103 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestGroupLagWatcher(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	samples := [][]PartitionLag{
+		{
+			{Topic: "orders", PartitionID: 0, CommittedOffset: 100, Lag: 50},
+			{Topic: "orders", PartitionID: 1, CommittedOffset: 200, Lag: 10},
+		},
+		{
+			{Topic: "orders", PartitionID: 0, CommittedOffset: 120, Lag: 40},
+			{Topic: "orders", PartitionID: 1, CommittedOffset: 210, Lag: 5},
+		},
+		{
+			// Partition 0 could not be fetched, partition 1 has been reset to an earlier offset
+			{Topic: "orders", PartitionID: 0, Error: "partition offsets are missing in the response"},
+			{Topic: "orders", PartitionID: 1, CommittedOffset: 0, Lag: 215},
+		},
+		{
+			{Topic: "orders", PartitionID: 0, CommittedOffset: 140, Lag: 30},
+			{Topic: "orders", PartitionID: 1, CommittedOffset: 20, Lag: 200},
+		},
+	}
+
+	watcher := groupLagWatcher{}
+	snapshots := make(chan GroupLagSnapshot, len(samples))
+	for i, sample := range samples {
+		watcher.observe(context.Background(), start.Add(time.Duration(i)*10*time.Second), sample, snapshots)
+	}
+	close(snapshots)
+
+	type result struct {
+		totalLag        int64
+		consumptionRate float64
+	}
+	results := make([]result, 0)
+	for snapshot := range snapshots {
+		results = append(results, result{snapshot.TotalLag, snapshot.ConsumptionRate})
+	}
+	assert.Equal(t, []result{
+		{60, 0},
+		{45, 3},
+		{215, 0},
+		{230, 2},
+	}, results)
+}
+
+func TestWatchGroupLag_Cancel(t *testing.T) {
+	// The group has no committed offsets, hence each sample only lists the group's offsets
+	client := &mockKafkaClient{handle: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+		switch req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			offsetFetchReq := kmsg.NewPtrOffsetFetchRequest()
+			return &kmsg.ApiVersionsResponse{ApiKeys: []kmsg.ApiVersionsResponseApiKey{
+				{ApiKey: offsetFetchReq.Key(), MaxVersion: offsetFetchReq.MaxVersion()},
+			}}, nil
+		case *kmsg.OffsetFetchRequest:
+			return &kmsg.OffsetFetchResponse{}, nil
+		}
+		return nil, fmt.Errorf("unexpected %v request", kmsg.NameForKey(req.Key()))
+	}}
+	svc := &Service{logger: zap.NewNop(), kafkaSvc: &kafka.Service{Logger: zap.NewNop(), KafkaClient: client}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snapshots, err := svc.WatchGroupLag(ctx, "billing", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	select {
+	case snapshot := <-snapshots:
+		assert.Empty(t, snapshot.Partitions)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no snapshot has been emitted")
+	}
+
+	// Cancelling the context closes the channel, snapshots which have been sent before may still be received
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, isOpen := <-snapshots:
+			if !isOpen {
+				return
+			}
+		case <-timeout:
+			t.Fatal("channel has not been closed after the context has been cancelled")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package owl
+
+import (
+	"sync"
+	"time"
+)
+
+// memberConnectionTracker remembers when each group member has been seen first, because Kafka does not report when
+// a member joined its group. The first-seen times only live as long as the process, nothing is persisted.
+type memberConnectionTracker struct {
+	mutex sync.Mutex
+	// firstSeenByGroup is the first-seen time by member key (see memberConnectionKey) by group ID
+	firstSeenByGroup map[string]map[string]time.Time
+}
+
+func newMemberConnectionTracker() *memberConnectionTracker {
+	return &memberConnectionTracker{firstSeenByGroup: make(map[string]map[string]time.Time)}
+}
+
+// memberConnectionKey identifies a member across describes. Static members get a new member ID whenever they
+// restart, hence they are identified by their group instance ID.
+func memberConnectionKey(member GroupMemberDescription) string {
+	if member.GroupInstanceID != "" {
+		return "instance:" + member.GroupInstanceID
+	}
+	return "member:" + member.ID
+}
+
+// Track sets ConnectedSince of the given members, which must be all current members of the group. Members that are
+// seen for the first time are connected since now. Members which are no longer part of the group are forgotten, so
+// that a member which leaves and joins again between two describes is reported as connected since its rejoin.
+// Track is a no-op on a nil tracker.
+func (t *memberConnectionTracker) Track(groupID string, members []GroupMemberDescription, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previous := t.firstSeenByGroup[groupID]
+	current := make(map[string]time.Time, len(members))
+	for i := range members {
+		key := memberConnectionKey(members[i])
+		firstSeen, exists := previous[key]
+		if !exists {
+			firstSeen = now
+		}
+		current[key] = firstSeen
+		members[i].ConnectedSince = &firstSeen
+	}
+
+	if len(current) == 0 {
+		delete(t.firstSeenByGroup, groupID)
+		return
+	}
+	t.firstSeenByGroup[groupID] = current
+}
@@ -0,0 +1,52 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberConnectionTracker(t *testing.T) {
+	tracker := newMemberConnectionTracker()
+	t0 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	t2 := t1.Add(time.Minute)
+
+	members := []GroupMemberDescription{
+		{ID: "consumer-1-aaa"},
+		{ID: "static-1-bbb", GroupInstanceID: "static-1"},
+	}
+	tracker.Track("orders", members, t0)
+	require.NotNil(t, members[0].ConnectedSince)
+	assert.Equal(t, t0, *members[0].ConnectedSince)
+	assert.Equal(t, t0, *members[1].ConnectedSince)
+
+	// The static member restarted with a new member ID, the dynamic member left and a new one joined
+	members = []GroupMemberDescription{
+		{ID: "static-1-ccc", GroupInstanceID: "static-1"},
+		{ID: "consumer-2-ddd"},
+	}
+	tracker.Track("orders", members, t1)
+	assert.Equal(t, t0, *members[0].ConnectedSince)
+	assert.Equal(t, t1, *members[1].ConnectedSince)
+
+	// A member rejoining after it has been gone is connected since its rejoin
+	members = []GroupMemberDescription{{ID: "consumer-1-aaa"}}
+	tracker.Track("orders", members, t2)
+	assert.Equal(t, t2, *members[0].ConnectedSince)
+
+	// Groups are tracked independently and empty groups are forgotten
+	members = []GroupMemberDescription{{ID: "consumer-1-aaa"}}
+	tracker.Track("payments", members, t0)
+	assert.Equal(t, t0, *members[0].ConnectedSince)
+	tracker.Track("orders", nil, t2)
+	assert.NotContains(t, tracker.firstSeenByGroup, "orders")
+
+	// A nil tracker does not track anything
+	var nilTracker *memberConnectionTracker
+	members = []GroupMemberDescription{{ID: "consumer-1-aaa"}}
+	nilTracker.Track("orders", members, t0)
+	assert.Nil(t, members[0].ConnectedSince)
+}
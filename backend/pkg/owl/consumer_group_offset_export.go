@@ -0,0 +1,191 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// OffsetExport is a serializable snapshot of a consumer group's committed offsets, which can be imported into the
+// same or another group, e.g. when migrating consumers between groups or restoring a backup.
+type OffsetExport struct {
+	GroupID    string              `json:"groupId"`
+	ExportedAt time.Time           `json:"exportedAt"`
+	Topics     []OffsetExportTopic `json:"topics"`
+}
+
+// OffsetExportTopic contains the committed offsets of all partitions of a topic, sorted by partition ID.
+type OffsetExportTopic struct {
+	TopicName  string                  `json:"topicName"`
+	Partitions []OffsetExportPartition `json:"partitions"`
+}
+
+// OffsetExportPartition is the committed offset of a single partition along with the metadata the consumer committed
+type OffsetExportPartition struct {
+	PartitionID int32   `json:"partitionId"`
+	Offset      int64   `json:"offset"`
+	Metadata    *string `json:"metadata,omitempty"`
+}
+
+// ExportConsumerGroupOffsets returns the committed offsets of all partitions of the given group, sorted by topic name.
+// Partitions without a committed offset or with an error are not exported.
+func (s *Service) ExportConsumerGroupOffsets(ctx context.Context, group string) (OffsetExport, error) {
+	offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(ctx, group)
+	if err != nil {
+		return OffsetExport{}, fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+
+	export := OffsetExport{
+		GroupID:    group,
+		ExportedAt: time.Now(),
+		Topics:     exportTopicOffsets(offsets),
+	}
+	return export, nil
+}
+
+// exportTopicOffsets converts an offset fetch response into the exported topics
+func exportTopicOffsets(offsets *kmsg.OffsetFetchResponse) []OffsetExportTopic {
+	topics := make([]OffsetExportTopic, 0, len(offsets.Topics))
+	for _, topic := range offsets.Topics {
+		partitions := make([]OffsetExportPartition, 0, len(topic.Partitions))
+		for _, partition := range topic.Partitions {
+			if kerr.ErrorForCode(partition.ErrorCode) != nil || partition.Offset < 0 {
+				continue
+			}
+			partitions = append(partitions, OffsetExportPartition{
+				PartitionID: partition.Partition,
+				Offset:      partition.Offset,
+				Metadata:    partition.Metadata,
+			})
+		}
+		if len(partitions) == 0 {
+			continue
+		}
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i].PartitionID < partitions[j].PartitionID })
+		topics = append(topics, OffsetExportTopic{TopicName: topic.Topic, Partitions: partitions})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].TopicName < topics[j].TopicName })
+
+	return topics
+}
+
+// ImportConsumerGroupOffsets commits the exported offsets for the given group. The group must not have any active
+// members, it may not exist yet though. Without overwrite the import is rejected if the group already has a committed
+// offset for one of the partitions or if one of the exported partitions does not exist anymore, in which case an
+// *kafka.UnknownTopicPartitionsError is returned. With overwrite, existing offsets are replaced and partitions which
+// do not exist anymore are skipped.
+func (s *Service) ImportConsumerGroupOffsets(ctx context.Context, group string, export OffsetExport, overwrite bool) error {
+	describedGroup, err := s.kafkaSvc.DescribeConsumerGroup(ctx, group)
+	if err != nil && !errors.Is(err, kafka.ErrGroupNotFound) {
+		return fmt.Errorf("failed to check group state: %w", err)
+	}
+	if err == nil && ParseGroupState(describedGroup.State) != GroupStateEmpty {
+		return fmt.Errorf("%w: group '%v' is in state '%v'", ErrGroupNotEmpty, group, describedGroup.State)
+	}
+
+	topicNames := make([]string, len(export.Topics))
+	for i, topic := range export.Topics {
+		topicNames[i] = topic.TopicName
+	}
+	if len(topicNames) == 0 {
+		return nil
+	}
+	metadata, err := s.kafkaSvc.GetMetadata(ctx, topicNames)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata of exported topics: %w", err)
+	}
+	committed, err := s.kafkaSvc.ListConsumerGroupOffsets(ctx, group)
+	if err != nil {
+		return fmt.Errorf("failed to list consumer group offsets: %w", err)
+	}
+
+	topics, skipped, err := planOffsetImport(export, existingTopicPartitions(metadata), convertOffsets(committed), overwrite)
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		s.logger.Info("skipped importing offsets of partitions which do not exist anymore",
+			zap.String("group", group),
+			zap.Int("skipped_partitions", len(skipped)))
+	}
+	if len(topics) == 0 {
+		return nil
+	}
+
+	res, err := s.kafkaSvc.EditConsumerGroupOffsets(ctx, group, topics)
+	if err != nil {
+		return err
+	}
+	for _, topic := range res.Topics {
+		for _, partition := range topic.Partitions {
+			if err := kerr.ErrorForCode(partition.ErrorCode); err != nil {
+				return fmt.Errorf("failed to commit offset of topic '%v' partition '%v': %w", topic.Topic, partition.Partition, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// existingTopicPartitions returns the partition IDs of all topics in the metadata response which have no error
+func existingTopicPartitions(metadata *kmsg.MetadataResponse) map[string]map[int32]struct{} {
+	existing := make(map[string]map[int32]struct{}, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		if kerr.ErrorForCode(topic.ErrorCode) != nil {
+			continue
+		}
+		partitions := make(map[int32]struct{}, len(topic.Partitions))
+		for _, partition := range topic.Partitions {
+			partitions[partition.Partition] = struct{}{}
+		}
+		existing[topic.Topic] = partitions
+	}
+	return existing
+}
+
+// planOffsetImport returns the offset commit request topics for the exported offsets and the partitions which are
+// skipped, because they do not exist anymore. Committed offsets below 0 are treated as not committed.
+func planOffsetImport(export OffsetExport, existing map[string]map[int32]struct{}, committed map[string]partitionOffsets, overwrite bool) ([]kmsg.OffsetCommitRequestTopic, []kafka.TopicPartition, error) {
+	topics := make([]kmsg.OffsetCommitRequestTopic, 0, len(export.Topics))
+	unknown := make([]kafka.TopicPartition, 0)
+	alreadyCommitted := make([]kafka.TopicPartition, 0)
+	for _, exportedTopic := range export.Topics {
+		topic := kmsg.NewOffsetCommitRequestTopic()
+		topic.Topic = exportedTopic.TopicName
+		for _, exportedPartition := range exportedTopic.Partitions {
+			tp := kafka.TopicPartition{Topic: exportedTopic.TopicName, Partition: exportedPartition.PartitionID}
+			if _, exists := existing[tp.Topic][tp.Partition]; !exists {
+				unknown = append(unknown, tp)
+				continue
+			}
+			if offset, exists := committed[tp.Topic][tp.Partition]; exists && offset >= 0 {
+				alreadyCommitted = append(alreadyCommitted, tp)
+			}
+
+			partition := kmsg.NewOffsetCommitRequestTopicPartition()
+			partition.Partition = exportedPartition.PartitionID
+			partition.Offset = exportedPartition.Offset
+			partition.Metadata = exportedPartition.Metadata
+			topic.Partitions = append(topic.Partitions, partition)
+		}
+		if len(topic.Partitions) > 0 {
+			topics = append(topics, topic)
+		}
+	}
+
+	if !overwrite && len(unknown) > 0 {
+		return nil, nil, &kafka.UnknownTopicPartitionsError{TopicPartitions: unknown}
+	}
+	if !overwrite && len(alreadyCommitted) > 0 {
+		return nil, nil, fmt.Errorf("%w: '%v' partitions, e.g. %v", ErrOffsetsAlreadyCommitted, len(alreadyCommitted), alreadyCommitted[0])
+	}
+
+	return topics, unknown, nil
+}
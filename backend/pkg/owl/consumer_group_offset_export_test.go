@@ -0,0 +1,81 @@
+package owl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestExportTopicOffsets(t *testing.T) {
+	metadata := "consumer-1"
+	offsets := &kmsg.OffsetFetchResponse{Topics: []kmsg.OffsetFetchResponseTopic{
+		{Topic: "payments", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+			{Partition: 1, Offset: 20},
+			{Partition: 0, Offset: 10, Metadata: &metadata},
+		}},
+		{Topic: "orders", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+			{Partition: 0, Offset: -1},
+			{Partition: 1, Offset: 5, ErrorCode: kerr.UnknownTopicOrPartition.Code},
+		}},
+		{Topic: "audit", Partitions: []kmsg.OffsetFetchResponseTopicPartition{{Partition: 0, Offset: 3}}},
+	}}
+
+	assert.Equal(t, []OffsetExportTopic{
+		{TopicName: "audit", Partitions: []OffsetExportPartition{{PartitionID: 0, Offset: 3}}},
+		{TopicName: "payments", Partitions: []OffsetExportPartition{
+			{PartitionID: 0, Offset: 10, Metadata: &metadata},
+			{PartitionID: 1, Offset: 20},
+		}},
+	}, exportTopicOffsets(offsets))
+}
+
+func TestPlanOffsetImport(t *testing.T) {
+	export := OffsetExport{GroupID: "old-group", Topics: []OffsetExportTopic{
+		{TopicName: "orders", Partitions: []OffsetExportPartition{{PartitionID: 0, Offset: 10}, {PartitionID: 1, Offset: 11}}},
+		{TopicName: "deleted", Partitions: []OffsetExportPartition{{PartitionID: 0, Offset: 3}}},
+	}}
+	existing := map[string]map[int32]struct{}{"orders": {0: {}, 1: {}}}
+
+	// Without overwrite missing partitions are rejected
+	_, _, err := planOffsetImport(export, existing, nil, false)
+	var unknownErr *kafka.UnknownTopicPartitionsError
+	require.True(t, errors.As(err, &unknownErr), "expected unknown topic partitions error, got: %v", err)
+	assert.Equal(t, []kafka.TopicPartition{{Topic: "deleted", Partition: 0}}, unknownErr.TopicPartitions)
+
+	// Without overwrite existing commits are rejected
+	export.Topics = export.Topics[:1]
+	committed := map[string]partitionOffsets{"orders": {0: -1, 1: 4}}
+	_, _, err = planOffsetImport(export, existing, committed, false)
+	assert.True(t, errors.Is(err, ErrOffsetsAlreadyCommitted))
+
+	// A commit offset of -1 means there is no committed offset
+	committed = map[string]partitionOffsets{"orders": {0: -1}}
+	topics, skipped, err := planOffsetImport(export, existing, committed, false)
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	require.Len(t, topics, 1)
+	assert.Len(t, topics[0].Partitions, 2)
+}
+
+func TestPlanOffsetImport_Overwrite(t *testing.T) {
+	export := OffsetExport{GroupID: "old-group", Topics: []OffsetExportTopic{
+		{TopicName: "orders", Partitions: []OffsetExportPartition{{PartitionID: 0, Offset: 10}, {PartitionID: 2, Offset: 12}}},
+		{TopicName: "deleted", Partitions: []OffsetExportPartition{{PartitionID: 0, Offset: 3}}},
+	}}
+	existing := map[string]map[int32]struct{}{"orders": {0: {}, 1: {}}}
+	committed := map[string]partitionOffsets{"orders": {0: 4}}
+
+	topics, skipped, err := planOffsetImport(export, existing, committed, true)
+	require.NoError(t, err)
+	assert.Equal(t, []kafka.TopicPartition{{Topic: "orders", Partition: 2}, {Topic: "deleted", Partition: 0}}, skipped)
+	require.Len(t, topics, 1)
+	assert.Equal(t, "orders", topics[0].Topic)
+	require.Len(t, topics[0].Partitions, 1)
+	assert.Equal(t, int32(0), topics[0].Partitions[0].Partition)
+	assert.Equal(t, int64(10), topics[0].Partitions[0].Offset)
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/cloudhut/kowl/backend/pkg/kafka"
-	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
 
 	"go.uber.org/zap"
@@ -30,6 +28,26 @@ type PartitionOffsets struct {
 	GroupOffset   int64  `json:"groupOffset"`
 	HighWaterMark int64  `json:"highWaterMark"`
 	Lag           int64  `json:"lag"`
+
+	// BehindRetention is true if the group offset is below the partition's log start offset, which happens if the
+	// records have been deleted due to retention before the group consumed them. The lag is capped at the number of
+	// records which are still available in this case.
+	BehindRetention bool `json:"behindRetention"`
+}
+
+// calculateLag returns the number of records the group still has to consume. Group offsets below the low water mark
+// can not be consumed anymore, hence the lag is limited to high - low and behindRetention is set. Group offsets above
+// the high water mark (e.g. if the watermark has been fetched before the group offset) result in a lag of 0.
+func calculateLag(groupOffset, lowWaterMark, highWaterMark int64) (lag int64, behindRetention bool) {
+	if lowWaterMark >= 0 && groupOffset < lowWaterMark {
+		return highWaterMark - lowWaterMark, true
+	}
+
+	lag = highWaterMark - groupOffset
+	if lag < 0 {
+		lag = 0
+	}
+	return lag, false
 }
 
 // convertOffsets returns a map where the key is the topic name
@@ -87,36 +105,13 @@ func (s *Service) getConsumerGroupOffsets(ctx context.Context, groups []string)
 		topicPartitions[topic.Topic] = partitionIDs
 	}
 
-	highMarkRes, err := s.kafkaSvc.ListOffsets(ctx, topicPartitions, kafka.TimestampLatest)
+	// 3. Fetch low and high water marks. The low water marks are required to detect group offsets which are
+	// behind the retention.
+	waterMarks, err := s.kafkaSvc.GetPartitionMarksBulk(ctx, topicPartitions)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. Format high water marks
-	type highMark struct {
-		PartitionID int32
-		Error       string
-		Offset      int64
-	}
-	highWaterMarks := make(map[string]map[int32]highMark)
-	for _, topic := range highMarkRes.Topics {
-		highWaterMarks[topic.Topic] = make(map[int32]highMark)
-		for _, partition := range topic.Partitions {
-			err := kerr.ErrorForCode(partition.ErrorCode)
-			if err != nil {
-				highWaterMarks[topic.Topic][partition.Partition] = highMark{
-					PartitionID: partition.Partition,
-					Error:       err.Error(),
-					Offset:      -1,
-				}
-			}
-			highWaterMarks[topic.Topic][partition.Partition] = highMark{
-				PartitionID: partition.Partition,
-				Offset:      partition.Offset,
-			}
-		}
-	}
-
 	// 4. Now that we've got all partition high water marks as well as the consumer group offsets we can calculate the lags
 	res := make(map[string][]GroupTopicOffsets, len(groups))
 	for _, group := range groups {
@@ -125,7 +120,7 @@ func (s *Service) getConsumerGroupOffsets(ctx context.Context, groups []string)
 			// In this scope we iterate on a single group's, single topic's offset
 			childLogger := s.logger.With(zap.String("group", group), zap.String("topic", topic))
 
-			highWaterMarks, ok := highWaterMarks[topic]
+			topicWaterMarks, ok := waterMarks[topic]
 			if !ok {
 				childLogger.Error("no partition watermark for the group's topic available")
 				return nil, fmt.Errorf("no partition watermark for the group's topic available")
@@ -136,11 +131,11 @@ func (s *Service) getConsumerGroupOffsets(ctx context.Context, groups []string)
 			t := GroupTopicOffsets{
 				Topic:                topic,
 				SummedLag:            0,
-				PartitionCount:       len(highWaterMarks),
+				PartitionCount:       len(topicWaterMarks),
 				PartitionsWithOffset: 0,
 				PartitionOffsets:     make([]PartitionOffsets, 0),
 			}
-			for pID, watermark := range highWaterMarks {
+			for pID, watermark := range topicWaterMarks {
 				if watermark.Error != "" {
 					t.PartitionOffsets = append(t.PartitionOffsets, PartitionOffsets{Error: watermark.Error, PartitionID: pID})
 					continue
@@ -152,17 +147,14 @@ func (s *Service) getConsumerGroupOffsets(ctx context.Context, groups []string)
 				}
 				t.PartitionsWithOffset++
 
-				lag := watermark.Offset - groupOffset
-				if lag < 0 {
-					// If Watermark has been updated after we got the group offset lag could be negative, which ofc doesn't make sense
-					lag = 0
-				}
+				lag, behindRetention := calculateLag(groupOffset, watermark.Low, watermark.High)
 				t.SummedLag += lag
 				t.PartitionOffsets = append(t.PartitionOffsets, PartitionOffsets{
-					PartitionID:   pID,
-					GroupOffset:   groupOffset,
-					HighWaterMark: watermark.Offset,
-					Lag:           lag})
+					PartitionID:     pID,
+					GroupOffset:     groupOffset,
+					HighWaterMark:   watermark.High,
+					Lag:             lag,
+					BehindRetention: behindRetention})
 			}
 			topicLags = append(topicLags, t)
 		}
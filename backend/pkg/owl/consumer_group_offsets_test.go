@@ -0,0 +1,32 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateLag(t *testing.T) {
+	tests := []struct {
+		name                    string
+		groupOffset             int64
+		low                     int64
+		high                    int64
+		expectedLag             int64
+		expectedBehindRetention bool
+	}{
+		{name: "regular lag", groupOffset: 80, low: 50, high: 100, expectedLag: 20},
+		{name: "fully caught up", groupOffset: 100, low: 50, high: 100, expectedLag: 0},
+		{name: "committed at earliest", groupOffset: 50, low: 50, high: 100, expectedLag: 50},
+		{name: "committed below earliest", groupOffset: 10, low: 50, high: 100, expectedLag: 50, expectedBehindRetention: true},
+		{name: "committed below earliest of empty partition", groupOffset: 10, low: 100, high: 100, expectedLag: 0, expectedBehindRetention: true},
+		{name: "committed above latest", groupOffset: 120, low: 50, high: 100, expectedLag: 0},
+		{name: "unknown low water mark", groupOffset: 10, low: -1, high: 100, expectedLag: 90},
+	}
+
+	for _, tc := range tests {
+		lag, behindRetention := calculateLag(tc.groupOffset, tc.low, tc.high)
+		assert.Equal(t, tc.expectedLag, lag, tc.name)
+		assert.Equal(t, tc.expectedBehindRetention, behindRetention, tc.name)
+	}
+}
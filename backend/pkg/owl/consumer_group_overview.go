@@ -2,9 +2,11 @@ package owl
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"sort"
+	"time"
 
 	"github.com/cloudhut/common/rest"
 	"github.com/cloudhut/kowl/backend/pkg/kafka"
@@ -17,7 +19,7 @@ import (
 // ConsumerGroupOverview for a Kafka Consumer Group
 type ConsumerGroupOverview struct {
 	GroupID       string                   `json:"groupId"`
-	State         string                   `json:"state"`
+	State         GroupState               `json:"state"`
 	ProtocolType  string                   `json:"protocolType"`
 	Protocol      string                   `json:"protocol"`
 	Members       []GroupMemberDescription `json:"members"`
@@ -26,14 +28,58 @@ type ConsumerGroupOverview struct {
 
 	// AllowedActions define the Kowl Business permissions on this specific group
 	AllowedActions []string `json:"allowedActions"`
+
+	// AuthorizedOperations are the ACL operations Kowl is allowed to perform on the group, nil if the broker does not
+	// report them. AvailableActions are the group actions (see GroupActionsForOperations) these operations allow.
+	AuthorizedOperations []string `json:"authorizedOperations"`
+	AvailableActions     []string `json:"availableActions"`
+
+	// Error is set if the group could not be described, e.g. because of missing permissions. The State is
+	// GroupStateUnauthorized then and all other details are empty.
+	Error *KafkaError `json:"error,omitempty"`
+
+	// Warnings are problems that made the description incomplete, e.g. member assignments which could not be decoded
+	Warnings []Warning `json:"warnings"`
+
+	// Type separates classic consumer groups from KIP-848 consumer groups, Kafka Connect and other groups
+	Type kafka.GroupType `json:"type"`
 }
 
 // GroupMemberDescription is a member (e. g. connected host) of a Consumer Group
 type GroupMemberDescription struct {
-	ID          string                  `json:"id"`
-	ClientID    string                  `json:"clientId"`
-	ClientHost  string                  `json:"clientHost"`
+	ID         string `json:"id"`
+	ClientID   string `json:"clientId"`
+	ClientHost string `json:"clientHost"`
+
+	// GroupInstanceID is the instance ID of static members (KIP-345), which stays the same across restarts while
+	// the member ID changes. It's empty for dynamic members and for brokers which don't report it (< v2.4).
+	GroupInstanceID string `json:"groupInstanceId"`
+
+	// Assignments are sorted by topic name. They are never nil, members without assignments (e.g. while the group is
+	// rebalancing or if the assignment can not be decoded) have an empty slice, which is serialized as empty array.
+	// If omitting empty assignments is configured, empty assignments are omitted from the JSON instead.
 	Assignments []GroupMemberAssignment `json:"assignments"`
+
+	// ConnectedSince is when this Kowl process has seen the member in the group for the first time. Kafka does not
+	// report when members joined, so members which joined before Kowl started are connected since Kowl first
+	// described the group. The time is not persisted and resets when Kowl restarts. Nil if not tracked.
+	ConnectedSince *time.Time `json:"connectedSince,omitempty"`
+
+	omitEmptyAssignments bool
+}
+
+// MarshalJSON serializes the member according to the Assignments policy
+func (g GroupMemberDescription) MarshalJSON() ([]byte, error) {
+	type member GroupMemberDescription
+	if !g.omitEmptyAssignments || len(g.Assignments) > 0 {
+		return json.Marshal(member(g))
+	}
+
+	// The outer field shadows the embedded member's Assignments
+	return json.Marshal(struct {
+		member
+		Assignments []GroupMemberAssignment `json:"assignments,omitempty"`
+	}{member: member(g)})
 }
 
 // GroupMemberAssignment represents a partition assignment for a group member
@@ -42,9 +88,22 @@ type GroupMemberAssignment struct {
 	PartitionIDs []int32 `json:"partitionIds"`
 }
 
+// ConsumerGroupsOverviewOptions customize the returned consumer group overviews
+type ConsumerGroupsOverviewOptions struct {
+	// FilterTopics restricts the members' assignments to these topics, assignments of all other topics are dropped.
+	// This reduces the payload for topic-scoped views of groups which consume many topics. Empty includes all topics.
+	FilterTopics []string
+}
+
 // GetConsumerGroupsOverview returns a ConsumerGroupOverview for all available consumer groups
 // Pass nil for groupIDs if you want to fetch all available groups.
 func (s *Service) GetConsumerGroupsOverview(ctx context.Context, groupIDs []string) ([]ConsumerGroupOverview, *rest.Error) {
+	return s.GetConsumerGroupsOverviewWithOptions(ctx, groupIDs, ConsumerGroupsOverviewOptions{})
+}
+
+// GetConsumerGroupsOverviewWithOptions is like GetConsumerGroupsOverview, but the overviews can be customized via
+// the options.
+func (s *Service) GetConsumerGroupsOverviewWithOptions(ctx context.Context, groupIDs []string, opts ConsumerGroupsOverviewOptions) ([]ConsumerGroupOverview, *rest.Error) {
 	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
 	if err != nil {
 		return nil, &rest.Error{
@@ -93,13 +152,13 @@ func (s *Service) GetConsumerGroupsOverview(ctx context.Context, groupIDs []stri
 		}
 	}
 
-	res := s.convertKgoGroupDescriptions(describedGroupsSharded, groupLags)
+	res := s.convertKgoGroupDescriptions(describedGroupsSharded, groupLags, opts.FilterTopics)
 	sort.Slice(res, func(i, j int) bool { return res[i].GroupID < res[j].GroupID })
 
 	return res, nil
 }
 
-func (s *Service) convertKgoGroupDescriptions(describedGroups *kafka.DescribeConsumerGroupsResponseSharded, offsets map[string][]GroupTopicOffsets) []ConsumerGroupOverview {
+func (s *Service) convertKgoGroupDescriptions(describedGroups *kafka.DescribeConsumerGroupsResponseSharded, offsets map[string][]GroupTopicOffsets, filterTopics []string) []ConsumerGroupOverview {
 	result := make([]ConsumerGroupOverview, 0)
 	for _, response := range describedGroups.Groups {
 		if response.Error != nil {
@@ -113,6 +172,23 @@ func (s *Service) convertKgoGroupDescriptions(describedGroups *kafka.DescribeCon
 
 		for _, d := range response.Groups.Groups {
 			err := kerr.ErrorForCode(d.ErrorCode)
+			if err == kerr.GroupAuthorizationFailed {
+				// The group exists, so hiding it would be confusing. Instead it's returned with an error marker
+				s.logger.Debug("not authorized to describe consumer group",
+					zap.Error(&kafka.GroupError{GroupID: d.Group, Err: err}),
+					zap.Int32("coordinator_id", coordinatorID),
+				)
+				result = append(result, ConsumerGroupOverview{
+					GroupID:       d.Group,
+					State:         GroupStateUnauthorized,
+					Members:       make([]GroupMemberDescription, 0),
+					CoordinatorID: coordinatorID,
+					TopicOffsets:  make([]GroupTopicOffsets, 0),
+					Error:         newKafkaError(d.ErrorCode),
+					Warnings:      make([]Warning, 0),
+				})
+				continue
+			}
 			if err != nil {
 				s.logger.Warn("failed to describe consumer group, inner kafka error",
 					zap.Error(err),
@@ -121,7 +197,7 @@ func (s *Service) convertKgoGroupDescriptions(describedGroups *kafka.DescribeCon
 				continue
 			}
 
-			members, err := s.convertGroupMembers(d.Members)
+			members, warnings, err := s.convertGroupMembers(d.ProtocolType, d.Members, filterTopics)
 			if err != nil {
 				s.logger.Warn("failed to convert group members from described groups to kowl result type",
 					zap.Error(err),
@@ -129,14 +205,21 @@ func (s *Service) convertKgoGroupDescriptions(describedGroups *kafka.DescribeCon
 				)
 				continue
 			}
+			s.memberConnections.Track(d.Group, members, time.Now())
+			authorizedOperations := kafka.AuthorizedOperations(d.AuthorizedOperations)
 			result = append(result, ConsumerGroupOverview{
 				GroupID:       d.Group,
-				State:         d.State,
+				State:         ParseGroupState(d.State),
 				ProtocolType:  d.ProtocolType,
 				Protocol:      d.Protocol,
 				Members:       members,
 				CoordinatorID: coordinatorID,
 				TopicOffsets:  offsets[d.Group],
+
+				AuthorizedOperations: authorizedOperations,
+				AvailableActions:     GroupActionsForOperations(authorizedOperations),
+				Warnings:             warnings,
+				Type:                 kafka.ClassifyGroupType("", d.ProtocolType),
 			})
 		}
 	}
@@ -144,8 +227,18 @@ func (s *Service) convertKgoGroupDescriptions(describedGroups *kafka.DescribeCon
 	return result
 }
 
-func (s *Service) convertGroupMembers(members []kmsg.DescribeGroupsResponseGroupMember) ([]GroupMemberDescription, error) {
+// convertGroupMembers converts the described members of a group with the given protocol type. Assignments are only
+// decoded if the group uses the consumer protocol, members of other groups are returned without assignments. Members
+// whose assignment can not be decoded are returned without assignments and a warning, which is logged as well. If
+// filterTopics is not empty, only the assignments of these topics are returned.
+func (s *Service) convertGroupMembers(protocolType string, members []kmsg.DescribeGroupsResponseGroupMember, filterTopics []string) ([]GroupMemberDescription, []Warning, error) {
 	response := make([]GroupMemberDescription, 0)
+	warnings := make([]Warning, 0)
+
+	includedTopics := make(map[string]struct{}, len(filterTopics))
+	for _, topic := range filterTopics {
+		includedTopics[topic] = struct{}{}
+	}
 
 	for _, m := range members {
 		// MemberAssignments is a byte array which will be set by kafka clients. All clients which use protocol
@@ -158,12 +251,26 @@ func (s *Service) convertGroupMembers(members []kmsg.DescribeGroupsResponseGroup
 
 		// Try to decode Group member assignments
 		convertedAssignments := make([]GroupMemberAssignment, 0)
-		memberAssignments := kmsg.GroupMemberAssignment{}
-		err := memberAssignments.ReadFrom(m.MemberAssignment)
-		if err != nil {
-			s.logger.Debug("failed to decode member assignments", zap.String("client_id", m.ClientID), zap.Error(err))
-		} else {
+		switch {
+		case protocolType != "consumer":
+			// The assignments of other protocols (e.g. Connect) have their own schema, they are not partitions
+		case len(m.MemberAssignment) == 0:
+			// Members have no assignment until the rebalance they joined has completed, that's not a decode failure
+		default:
+			memberAssignments, err := kafka.DecodeMemberAssignment(m.MemberAssignment)
+			if err != nil {
+				s.logger.Warn("failed to decode member assignments", zap.String("client_id", m.ClientID), zap.Error(err))
+				warnings = append(warnings, Warning{
+					Code:    WarningCodeMemberAssignmentDecode,
+					Subject: m.MemberID,
+					Message: fmt.Sprintf("assignment of member '%v' (client id '%v') could not be decoded: %v", m.MemberID, m.ClientID, err),
+				})
+				break
+			}
 			for _, topic := range memberAssignments.Topics {
+				if _, isIncluded := includedTopics[topic.Topic]; len(includedTopics) > 0 && !isIncluded {
+					continue
+				}
 				partitionIDs := topic.Partitions
 				sort.Slice(partitionIDs, func(i, j int) bool { return partitionIDs[i] < partitionIDs[j] })
 				a := GroupMemberAssignment{
@@ -179,13 +286,21 @@ func (s *Service) convertGroupMembers(members []kmsg.DescribeGroupsResponseGroup
 			return convertedAssignments[i].TopicName < convertedAssignments[j].TopicName
 		})
 
+		var instanceID string
+		if m.InstanceID != nil {
+			instanceID = *m.InstanceID
+		}
+
 		response = append(response, GroupMemberDescription{
-			ID:          m.MemberID,
-			ClientID:    m.ClientID,
-			ClientHost:  m.ClientHost,
-			Assignments: convertedAssignments,
+			ID:              m.MemberID,
+			ClientID:        m.ClientID,
+			ClientHost:      m.ClientHost,
+			GroupInstanceID: instanceID,
+			Assignments:     convertedAssignments,
+
+			omitEmptyAssignments: s.omitEmptyAssignments,
 		})
 	}
 
-	return response, nil
+	return response, warnings, nil
 }
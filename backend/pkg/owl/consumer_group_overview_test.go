@@ -0,0 +1,141 @@
+package owl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestConvertGroupMembers_GroupInstanceID(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+	instanceID := "consumer-instance-1"
+
+	members, _, err := svc.convertGroupMembers("consumer", []kmsg.DescribeGroupsResponseGroupMember{
+		{MemberID: "consumer-instance-1-0c5d0a1b", InstanceID: &instanceID, ClientID: "consumer"},
+		{MemberID: "consumer-7a2e9f10", ClientID: "consumer"},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	assert.Equal(t, "consumer-instance-1", members[0].GroupInstanceID)
+	assert.Equal(t, "", members[1].GroupInstanceID)
+}
+
+func TestGroupMemberDescription_MarshalJSON(t *testing.T) {
+	empty := GroupMemberDescription{ID: "member-1", Assignments: []GroupMemberAssignment{}}
+	assigned := GroupMemberDescription{ID: "member-2", Assignments: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}}
+
+	// Empty assignments are an empty array by default
+	payload, err := json.Marshal(empty)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"member-1","clientId":"","clientHost":"","groupInstanceId":"","assignments":[]}`, string(payload))
+
+	empty.omitEmptyAssignments = true
+	payload, err = json.Marshal(empty)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"member-1","clientId":"","clientHost":"","groupInstanceId":""}`, string(payload))
+
+	assigned.omitEmptyAssignments = true
+	payload, err = json.Marshal(assigned)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"member-2","clientId":"","clientHost":"","groupInstanceId":"","assignments":[{"topicName":"orders","partitionIds":[0]}]}`, string(payload))
+
+	// The policy is kept when members are serialized as part of a group
+	payload, err = json.Marshal([]GroupMemberDescription{empty})
+	require.NoError(t, err)
+	assert.NotContains(t, string(payload), "assignments")
+}
+
+func TestConvertKgoGroupDescriptions_Unauthorized(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+	described := &kafka.DescribeConsumerGroupsResponseSharded{
+		Groups: []kafka.DescribeConsumerGroupsResponse{{
+			BrokerMetadata: kgo.BrokerMetadata{NodeID: 1},
+			Groups: &kmsg.DescribeGroupsResponse{Groups: []kmsg.DescribeGroupsResponseGroup{
+				{Group: "authorized", State: "Stable", ProtocolType: "consumer"},
+				{Group: "unauthorized", ErrorCode: kerr.GroupAuthorizationFailed.Code},
+				{Group: "failed", ErrorCode: kerr.CoordinatorLoadInProgress.Code},
+			}},
+		}},
+	}
+
+	groups := svc.convertKgoGroupDescriptions(described, nil, nil)
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, "authorized", groups[0].GroupID)
+	assert.Equal(t, GroupStateStable, groups[0].State)
+	assert.Nil(t, groups[0].Error)
+
+	// Unauthorized groups are part of the result with an error marker, other errors still skip the group
+	assert.Equal(t, "unauthorized", groups[1].GroupID)
+	assert.Equal(t, GroupStateUnauthorized, groups[1].State)
+	assert.Equal(t, int32(1), groups[1].CoordinatorID)
+	require.NotNil(t, groups[1].Error)
+	assert.Equal(t, kerr.GroupAuthorizationFailed.Code, groups[1].Error.Code)
+	assert.Empty(t, groups[1].Members)
+}
+
+func TestConvertGroupMembers_Warnings(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+	valid := kmsg.GroupMemberAssignment{Topics: []kmsg.GroupMemberAssignmentTopic{{Topic: "orders", Partitions: []int32{1, 0}}}}
+
+	members, warnings, err := svc.convertGroupMembers("consumer", []kmsg.DescribeGroupsResponseGroupMember{
+		{MemberID: "consumer-1", ClientID: "consumer", MemberAssignment: valid.AppendTo(nil)},
+		{MemberID: "custom-1", ClientID: "custom-client", MemberAssignment: []byte{0xff, 0x01}},
+		{MemberID: "joining-1", ClientID: "consumer"},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	assert.Equal(t, []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0, 1}}}, members[0].Assignments)
+	assert.Empty(t, members[1].Assignments)
+
+	// Members without assignment (e.g. while rebalancing) don't cause a warning
+	require.Len(t, warnings, 1)
+	assert.Equal(t, WarningCodeMemberAssignmentDecode, warnings[0].Code)
+	assert.Equal(t, "custom-1", warnings[0].Subject)
+	assert.Contains(t, warnings[0].Message, "custom-client")
+
+	// Assignments of other protocol types are not decoded at all, hence they don't cause warnings either
+	members, warnings, err = svc.convertGroupMembers("connect", []kmsg.DescribeGroupsResponseGroupMember{
+		{MemberID: "worker-1", ClientID: "connect", MemberAssignment: valid.AppendTo(nil)},
+		{MemberID: "worker-2", ClientID: "connect", MemberAssignment: []byte{0xff, 0x01}},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Empty(t, members[0].Assignments)
+	assert.Empty(t, members[1].Assignments)
+	assert.Empty(t, warnings)
+}
+
+func TestConvertGroupMembers_FilterTopics(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+	assignment := kmsg.GroupMemberAssignment{Topics: []kmsg.GroupMemberAssignmentTopic{
+		{Topic: "orders", Partitions: []int32{0}},
+		{Topic: "payments", Partitions: []int32{1}},
+		{Topic: "shipments", Partitions: []int32{2}},
+	}}
+	members := []kmsg.DescribeGroupsResponseGroupMember{
+		{MemberID: "consumer-1", ClientID: "consumer", MemberAssignment: assignment.AppendTo(nil)},
+	}
+
+	converted, _, err := svc.convertGroupMembers("consumer", members, []string{"shipments", "orders", "unknown"})
+	require.NoError(t, err)
+	require.Len(t, converted, 1)
+	assert.Equal(t, []GroupMemberAssignment{
+		{TopicName: "orders", PartitionIDs: []int32{0}},
+		{TopicName: "shipments", PartitionIDs: []int32{2}},
+	}, converted[0].Assignments)
+
+	// Without filter all topics are included
+	converted, _, err = svc.convertGroupMembers("consumer", members, nil)
+	require.NoError(t, err)
+	assert.Len(t, converted[0].Assignments, 3)
+}
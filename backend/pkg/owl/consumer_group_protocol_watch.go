@@ -0,0 +1,109 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// GroupProtocolEvent is emitted by WatchGroupProtocol whenever a new generation of the group has been observed.
+type GroupProtocolEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// ObservedGeneration counts the generations seen by the watcher, starting at 1 for the initial description.
+	// DescribeGroups does not return the group's actual generation ID, hence a new generation is assumed whenever
+	// the group is seen rebalancing or its protocol has changed. Rebalances which happen entirely between two samples
+	// are only noticed if they changed the protocol.
+	ObservedGeneration int `json:"observedGeneration"`
+
+	State            GroupState `json:"state"`
+	ProtocolType     string     `json:"protocolType"`
+	Protocol         string     `json:"protocol"` // Name of the assignor, e.g. "range"; empty if the group has no members
+	PreviousProtocol string     `json:"previousProtocol"`
+	MemberCount      int        `json:"memberCount"`
+}
+
+// WatchGroupProtocol describes the given group every interval and emits an event whenever a new generation is
+// observed, so that changes of the used assignor can be traced. The first event describes the group's current state.
+// An error is returned if the group can not be described initially, later failures are logged and the sample is
+// skipped. The returned channel is closed once the context is done.
+func (s *Service) WatchGroupProtocol(ctx context.Context, groupID string, interval time.Duration) (<-chan GroupProtocolEvent, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watch interval must be greater than 0")
+	}
+
+	describe := func(ctx context.Context) (kmsg.DescribeGroupsResponseGroup, error) {
+		return s.kafkaSvc.DescribeConsumerGroup(ctx, groupID)
+	}
+	initial, err := describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan GroupProtocolEvent, 1)
+	go func() {
+		defer close(events)
+		watcher := groupProtocolWatcher{}
+		watcher.observe(ctx, time.Now(), initial, events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			described, err := describe(ctx)
+			if err != nil {
+				s.logger.Debug("failed to describe group while watching its protocol", zap.String("group_id", groupID), zap.Error(err))
+				continue
+			}
+			watcher.observe(ctx, time.Now(), described, events)
+		}
+	}()
+
+	return events, nil
+}
+
+// groupProtocolWatcher remembers the last observed description of a group in order to detect new generations.
+type groupProtocolWatcher struct {
+	generation   int
+	lastState    GroupState
+	lastProtocol string
+}
+
+// observe sends an event if the described group is in a new generation compared to the last observation.
+func (w *groupProtocolWatcher) observe(ctx context.Context, timestamp time.Time, described kmsg.DescribeGroupsResponseGroup, events chan<- GroupProtocolEvent) {
+	state := ParseGroupState(described.State)
+	isRebalancing := state == GroupStatePreparingRebalance || state == GroupStateCompletingRebalance
+	wasRebalancing := w.lastState == GroupStatePreparingRebalance || w.lastState == GroupStateCompletingRebalance
+
+	isNewGeneration := w.generation == 0 ||
+		described.Protocol != w.lastProtocol ||
+		(isRebalancing && !wasRebalancing)
+	previousProtocol := w.lastProtocol
+	w.lastState = state
+	w.lastProtocol = described.Protocol
+	if !isNewGeneration {
+		return
+	}
+	w.generation++
+
+	event := GroupProtocolEvent{
+		Timestamp:          timestamp,
+		ObservedGeneration: w.generation,
+		State:              state,
+		ProtocolType:       described.ProtocolType,
+		Protocol:           described.Protocol,
+		PreviousProtocol:   previousProtocol,
+		MemberCount:        len(described.Members),
+	}
+	select {
+	case <-ctx.Done():
+	case events <- event:
+	}
+}
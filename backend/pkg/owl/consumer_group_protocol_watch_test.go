@@ -0,0 +1,45 @@
+package owl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestGroupProtocolWatcher(t *testing.T) {
+	samples := []kmsg.DescribeGroupsResponseGroup{
+		{State: "Stable", ProtocolType: "consumer", Protocol: "range"},
+		{State: "Stable", ProtocolType: "consumer", Protocol: "range"},
+		{State: "PreparingRebalance", ProtocolType: "consumer", Protocol: "range"},
+		{State: "CompletingRebalance", ProtocolType: "consumer", Protocol: "range"},
+		{State: "Stable", ProtocolType: "consumer", Protocol: "cooperative-sticky"},
+		{State: "Empty", ProtocolType: "consumer", Protocol: ""},
+	}
+
+	watcher := groupProtocolWatcher{}
+	events := make(chan GroupProtocolEvent, len(samples))
+	for _, sample := range samples {
+		watcher.observe(context.Background(), time.Unix(0, 0), sample, events)
+	}
+	close(events)
+
+	type transition struct {
+		generation       int
+		state            GroupState
+		protocol         string
+		previousProtocol string
+	}
+	transitions := make([]transition, 0)
+	for event := range events {
+		transitions = append(transitions, transition{event.ObservedGeneration, event.State, event.Protocol, event.PreviousProtocol})
+	}
+	assert.Equal(t, []transition{
+		{1, GroupStateStable, "range", ""},
+		{2, GroupStatePreparingRebalance, "range", "range"},
+		{3, GroupStateStable, "cooperative-sticky", "range"},
+		{4, GroupStateEmpty, "", "cooperative-sticky"},
+	}, transitions)
+}
@@ -0,0 +1,88 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GroupStateSample is the state of a consumer group at a point in time.
+type GroupStateSample struct {
+	Timestamp time.Time  `json:"timestamp"`
+	State     GroupState `json:"state"`
+}
+
+// RebalanceCheck is the result of checking whether a group's rebalance is stuck. IsStuck is true if the group has
+// been rebalancing in all observed states.
+type RebalanceCheck struct {
+	GroupID        string             `json:"groupId"`
+	IsStuck        bool               `json:"isStuck"`
+	ObservedStates []GroupStateSample `json:"observedStates"`
+}
+
+// IsRebalanceStuck returns true if the group is rebalancing (PreparingRebalance or CompletingRebalance) now and still
+// is after the threshold. See CheckRebalance for the observed states.
+func (s *Service) IsRebalanceStuck(ctx context.Context, group string, threshold time.Duration) (bool, error) {
+	check, err := s.CheckRebalance(ctx, group, threshold)
+	if err != nil {
+		return false, err
+	}
+	return check.IsStuck, nil
+}
+
+// CheckRebalance describes the group twice, `threshold` apart, and reports the rebalance as stuck if the group was
+// rebalancing both times. The group is not described in between, so a group that completes a rebalance and starts
+// the next one within the threshold is reported as stuck as well. The second describe is skipped if the group is not
+// rebalancing at first.
+func (s *Service) CheckRebalance(ctx context.Context, group string, threshold time.Duration) (RebalanceCheck, error) {
+	states, err := sampleRebalanceStates(ctx, threshold, time.Now, func(ctx context.Context) (GroupState, error) {
+		describedGroup, err := s.kafkaSvc.DescribeConsumerGroup(ctx, group)
+		if err != nil {
+			return GroupStateUnknown, err
+		}
+		return ParseGroupState(describedGroup.State), nil
+	})
+	if err != nil {
+		return RebalanceCheck{}, err
+	}
+
+	isStuck := true
+	for _, sample := range states {
+		if !sample.State.IsRebalancing() {
+			isStuck = false
+		}
+	}
+	return RebalanceCheck{GroupID: group, IsStuck: isStuck, ObservedStates: states}, nil
+}
+
+// sampleRebalanceStates gets the group state, and if the group is rebalancing, gets it once more after the threshold
+func sampleRebalanceStates(ctx context.Context, threshold time.Duration, now func() time.Time, getState func(ctx context.Context) (GroupState, error)) ([]GroupStateSample, error) {
+	if threshold < 0 {
+		return nil, fmt.Errorf("rebalance threshold must not be negative")
+	}
+
+	states := make([]GroupStateSample, 0, 2)
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			if !states[0].State.IsRebalancing() {
+				break
+			}
+			timer := time.NewTimer(threshold)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return states, fmt.Errorf("checking the rebalance was cancelled: %w", ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		timestamp := now()
+		state, err := getState(ctx)
+		if err != nil {
+			return states, fmt.Errorf("failed to get group state: %w", err)
+		}
+		states = append(states, GroupStateSample{Timestamp: timestamp, State: state})
+	}
+
+	return states, nil
+}
@@ -0,0 +1,52 @@
+package owl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleRebalanceStates(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	tests := []struct {
+		name     string
+		states   []GroupState
+		expected []GroupState
+	}{
+		{name: "stuck", states: []GroupState{GroupStatePreparingRebalance, GroupStateCompletingRebalance}, expected: []GroupState{GroupStatePreparingRebalance, GroupStateCompletingRebalance}},
+		{name: "completed", states: []GroupState{GroupStatePreparingRebalance, GroupStateStable}, expected: []GroupState{GroupStatePreparingRebalance, GroupStateStable}},
+		{name: "stable", states: []GroupState{GroupStateStable, GroupStatePreparingRebalance}, expected: []GroupState{GroupStateStable}},
+	}
+
+	for _, tc := range tests {
+		calls := 0
+		now := func() time.Time { return start.Add(time.Duration(calls) * time.Minute) }
+		getState := func(_ context.Context) (GroupState, error) {
+			calls++
+			return tc.states[calls-1], nil
+		}
+
+		samples, err := sampleRebalanceStates(context.Background(), time.Millisecond, now, getState)
+		require.NoError(t, err, tc.name)
+		require.Len(t, samples, len(tc.expected), tc.name)
+		for i, sample := range samples {
+			assert.Equal(t, tc.expected[i], sample.State, tc.name)
+			assert.Equal(t, start.Add(time.Duration(i)*time.Minute), sample.Timestamp, tc.name)
+		}
+	}
+}
+
+func TestSampleRebalanceStates_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	getState := func(_ context.Context) (GroupState, error) {
+		cancel()
+		return GroupStatePreparingRebalance, nil
+	}
+
+	samples, err := sampleRebalanceStates(ctx, time.Hour, time.Now, getState)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, samples, 1)
+}
@@ -0,0 +1,94 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// GroupSnapshot is a serializable point in time view of a consumer group for offline analysis. It bundles the
+// group's description, its committed offsets and the lag including the partitions' log offsets.
+type GroupSnapshot struct {
+	GroupID    string    `json:"groupId"`
+	CapturedAt time.Time `json:"capturedAt"`
+
+	State        GroupState               `json:"state"`
+	ProtocolType string                   `json:"protocolType"`
+	Protocol     string                   `json:"protocol"`
+	Members      []GroupMemberDescription `json:"members"`
+
+	// Offsets are the committed offsets in the format of ExportConsumerGroupOffsets, so that they can be imported
+	Offsets []OffsetExportTopic `json:"offsets"`
+	Lags    []PartitionLag      `json:"lags"`
+
+	// Warnings are problems that made the snapshot incomplete, e.g. member assignments which could not be decoded
+	Warnings []Warning `json:"warnings"`
+}
+
+// SnapshotConsumerGroup describes the group and fetches its committed offsets concurrently, then the log offsets of
+// all committed partitions are listed. All requests are sent with the same client, so that the group requests
+// share its coordinator lookup and the log offset requests share its partition leader lookups. CapturedAt is the
+// time the snapshot has been started. An error wrapping kafka.ErrGroupNotFound is returned if the group does not
+// exist.
+func (s *Service) SnapshotConsumerGroup(ctx context.Context, group string) (*GroupSnapshot, error) {
+	capturedAt := time.Now()
+
+	var described kmsg.DescribeGroupsResponseGroup
+	var offsets *kmsg.OffsetFetchResponse
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		var err error
+		described, err = s.kafkaSvc.DescribeConsumerGroup(egCtx, group)
+		if err != nil {
+			return fmt.Errorf("failed to describe consumer group: %w", err)
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		var err error
+		offsets, err = s.kafkaSvc.ListConsumerGroupOffsets(egCtx, group)
+		if err != nil {
+			return fmt.Errorf("failed to list consumer group offsets: %w", err)
+		}
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	marks := make(map[string]map[int32]*kafka.PartitionMarks)
+	if topicPartitions := committedTopicPartitions(offsets); len(topicPartitions) > 0 {
+		var err error
+		marks, err = s.kafkaSvc.GetPartitionMarksBulk(ctx, topicPartitions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partition offsets: %w", err)
+		}
+	}
+
+	members, warnings, err := s.convertGroupMembers(described.ProtocolType, described.Members, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert group members: %w", err)
+	}
+
+	return newGroupSnapshot(capturedAt, described, members, warnings, offsets, marks), nil
+}
+
+// newGroupSnapshot assembles the snapshot from the fetched group data
+func newGroupSnapshot(capturedAt time.Time, described kmsg.DescribeGroupsResponseGroup, members []GroupMemberDescription, warnings []Warning, offsets *kmsg.OffsetFetchResponse, marks map[string]map[int32]*kafka.PartitionMarks) *GroupSnapshot {
+	return &GroupSnapshot{
+		GroupID:      described.Group,
+		CapturedAt:   capturedAt,
+		State:        ParseGroupState(described.State),
+		ProtocolType: described.ProtocolType,
+		Protocol:     described.Protocol,
+		Members:      members,
+		Offsets:      exportTopicOffsets(offsets),
+		Lags:         mergePartitionLags(offsets, marks),
+		Warnings:     warnings,
+	}
+}
@@ -0,0 +1,52 @@
+package owl
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestNewGroupSnapshot(t *testing.T) {
+	capturedAt := time.Date(2021, 5, 3, 12, 0, 0, 0, time.UTC)
+	described := kmsg.DescribeGroupsResponseGroup{Group: "billing", State: "Stable", ProtocolType: "consumer", Protocol: "range"}
+	members := []GroupMemberDescription{
+		{ID: "consumer-1", Assignments: []GroupMemberAssignment{{TopicName: "orders", PartitionIDs: []int32{0}}}},
+	}
+	offsets := &kmsg.OffsetFetchResponse{Topics: []kmsg.OffsetFetchResponseTopic{
+		{Topic: "orders", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+			{Partition: 1, Offset: -1},
+			{Partition: 0, Offset: 90},
+		}},
+	}}
+	marks := map[string]map[int32]*kafka.PartitionMarks{
+		"orders": {0: {PartitionID: 0, Low: 0, High: 100}},
+	}
+
+	snapshot := newGroupSnapshot(capturedAt, described, members, make([]Warning, 0), offsets, marks)
+	assert.Equal(t, "billing", snapshot.GroupID)
+	assert.Equal(t, capturedAt, snapshot.CapturedAt)
+	assert.Equal(t, GroupStateStable, snapshot.State)
+	assert.Equal(t, members, snapshot.Members)
+	assert.Equal(t, []OffsetExportTopic{
+		{TopicName: "orders", Partitions: []OffsetExportPartition{{PartitionID: 0, Offset: 90}}},
+	}, snapshot.Offsets)
+	assert.Equal(t, []PartitionLag{
+		{Topic: "orders", PartitionID: 0, CommittedOffset: 90, LogStartOffset: 0, LogEndOffset: 100, Lag: 10, RetentionPosition: float64Ptr(0.9)},
+	}, snapshot.Lags)
+
+	// The snapshot must survive a round trip, so that it can be analyzed offline
+	payload, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var decoded GroupSnapshot
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, snapshot.CapturedAt, decoded.CapturedAt)
+	assert.Equal(t, snapshot.Offsets, decoded.Offsets)
+	assert.Equal(t, snapshot.Lags, decoded.Lags)
+	assert.Equal(t, snapshot.Members[0].Assignments, decoded.Members[0].Assignments)
+}
@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"strings"
+)
+
+// GroupState is the state of a consumer group as reported by the group coordinator. It's serialized as the state's
+// name, e.g. "Stable".
+type GroupState string
+
+const (
+	GroupStateUnknown             GroupState = "Unknown"
+	GroupStatePreparingRebalance  GroupState = "PreparingRebalance"
+	GroupStateCompletingRebalance GroupState = "CompletingRebalance"
+	GroupStateStable              GroupState = "Stable"
+	GroupStateDead                GroupState = "Dead"
+	GroupStateEmpty               GroupState = "Empty"
+)
+
+// GroupStateUnauthorized is reported for groups which Kowl is not allowed to describe. It's not a state reported by
+// Kafka and hence not a known state.
+const GroupStateUnauthorized GroupState = "Unauthorized"
+
+var knownGroupStates = []GroupState{
+	GroupStateUnknown,
+	GroupStatePreparingRebalance,
+	GroupStateCompletingRebalance,
+	GroupStateStable,
+	GroupStateDead,
+	GroupStateEmpty,
+}
+
+// ParseGroupState returns the GroupState for the given state name. Names are matched case insensitive. Kafka versions
+// before 2.0 call the CompletingRebalance state "AwaitingSync", which is mapped accordingly. States we don't know
+// are returned unchanged, so that they are still displayed rather than being swallowed.
+func ParseGroupState(state string) GroupState {
+	if strings.EqualFold(state, "AwaitingSync") {
+		return GroupStateCompletingRebalance
+	}
+	for _, knownState := range knownGroupStates {
+		if strings.EqualFold(state, string(knownState)) {
+			return knownState
+		}
+	}
+
+	return GroupState(state)
+}
+
+// IsKnown returns true if the state is one of the GroupState constants of the states reported by Kafka.
+func (g GroupState) IsKnown() bool {
+	for _, knownState := range knownGroupStates {
+		if g == knownState {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns true if the group has members, that is it is either stable or rebalancing.
+func (g GroupState) IsActive() bool {
+	switch g {
+	case GroupStateStable, GroupStatePreparingRebalance, GroupStateCompletingRebalance:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRebalancing returns true if the group is in the middle of a rebalance, that is either waiting for members to
+// (re)join or waiting for the leader's assignment.
+func (g GroupState) IsRebalancing() bool {
+	return g == GroupStatePreparingRebalance || g == GroupStateCompletingRebalance
+}
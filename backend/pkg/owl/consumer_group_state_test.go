@@ -0,0 +1,54 @@
+package owl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroupState(t *testing.T) {
+	tests := []struct {
+		input         string
+		expected      GroupState
+		isKnown       bool
+		isActive      bool
+		isRebalancing bool
+	}{
+		{input: "Stable", expected: GroupStateStable, isKnown: true, isActive: true},
+		{input: "stable", expected: GroupStateStable, isKnown: true, isActive: true},
+		{input: "PreparingRebalance", expected: GroupStatePreparingRebalance, isKnown: true, isActive: true, isRebalancing: true},
+		{input: "CompletingRebalance", expected: GroupStateCompletingRebalance, isKnown: true, isActive: true, isRebalancing: true},
+		{input: "AwaitingSync", expected: GroupStateCompletingRebalance, isKnown: true, isActive: true, isRebalancing: true},
+		{input: "Empty", expected: GroupStateEmpty, isKnown: true, isActive: false},
+		{input: "Dead", expected: GroupStateDead, isKnown: true, isActive: false},
+		{input: "Unknown", expected: GroupStateUnknown, isKnown: true, isActive: false},
+		{input: "Assigning", expected: GroupState("Assigning"), isKnown: false, isActive: false},
+		{input: "", expected: GroupState(""), isKnown: false, isActive: false},
+	}
+
+	for _, tc := range tests {
+		state := ParseGroupState(tc.input)
+		assert.Equal(t, tc.expected, state, tc.input)
+		assert.Equal(t, tc.isKnown, state.IsKnown(), tc.input)
+		assert.Equal(t, tc.isActive, state.IsActive(), tc.input)
+		assert.Equal(t, tc.isRebalancing, state.IsRebalancing(), tc.input)
+	}
+}
+
+func TestGroupState_JSONRoundTrip(t *testing.T) {
+	for _, state := range []GroupState{GroupStateStable, ParseGroupState("Assigning")} {
+		overview := ConsumerGroupOverview{GroupID: "test", State: state}
+		serialized, err := json.Marshal(overview)
+		require.NoError(t, err)
+
+		var deserialized ConsumerGroupOverview
+		require.NoError(t, json.Unmarshal(serialized, &deserialized))
+		assert.Equal(t, state, deserialized.State)
+	}
+
+	serialized, err := json.Marshal(ConsumerGroupOverview{State: GroupStateStable})
+	require.NoError(t, err)
+	assert.Contains(t, string(serialized), `"state":"Stable"`)
+}
@@ -0,0 +1,135 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// GroupsLagError is returned by GetGroupsLag if the lag of some groups could not be fetched. The lags of all other
+// groups are returned nonetheless.
+type GroupsLagError struct {
+	// Errors contains the error of each group whose lag is missing in the result
+	Errors map[string]error
+}
+
+func (e *GroupsLagError) Error() string {
+	groupIDs := make([]string, 0, len(e.Errors))
+	for groupID := range e.Errors {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+
+	messages := make([]string, len(groupIDs))
+	for i, groupID := range groupIDs {
+		messages[i] = fmt.Sprintf("group '%v': %v", groupID, e.Errors[groupID])
+	}
+	return fmt.Sprintf("failed to get the lag of %v groups: %v", len(groupIDs), strings.Join(messages, "; "))
+}
+
+// GetGroupsLag returns the lag of each of the given groups, see GetGroupLagDetailed. OffsetFetch requests can only
+// fetch the offsets of a single group, hence they are sent concurrently for all groups (at most
+// maxConcurrentGroupOffsetRequests at the same time). The log offsets of all partitions involved are then listed at
+// once, so that each partition leader receives a single ListOffsets request per offset type regardless of the number
+// of groups.
+//
+// If the offsets of some groups could not be fetched, the lags of the other groups are returned along with a
+// *GroupsLagError. An error is returned without result if the log offsets could not be listed.
+func (s *Service) GetGroupsLag(ctx context.Context, groups []string) (map[string][]PartitionLag, error) {
+	offsetsByGroup := make(map[string]*kmsg.OffsetFetchResponse, len(groups))
+	errorsByGroup := make(map[string]error)
+	mutex := sync.Mutex{}
+
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, maxConcurrentGroupOffsetRequests)
+	for _, group := range groups {
+		group := group
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var offsets *kmsg.OffsetFetchResponse
+			var err error
+			select {
+			case sem <- struct{}{}:
+				offsets, err = s.kafkaSvc.ListConsumerGroupOffsets(ctx, group)
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errorsByGroup[group] = fmt.Errorf("failed to list consumer group offsets: %w", err)
+				return
+			}
+			offsetsByGroup[group] = offsets
+		}()
+	}
+	wg.Wait()
+
+	lags := make(map[string][]PartitionLag, len(offsetsByGroup))
+	topicPartitions := mergeTopicPartitions(offsetsByGroup)
+	if len(topicPartitions) > 0 {
+		marks, err := s.kafkaSvc.GetPartitionMarksBulk(ctx, topicPartitions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get partition offsets: %w", err)
+		}
+		for group, offsets := range offsetsByGroup {
+			lags[group] = mergePartitionLags(offsets, marks)
+		}
+	} else {
+		for group := range offsetsByGroup {
+			lags[group] = make([]PartitionLag, 0)
+		}
+	}
+
+	if len(errorsByGroup) > 0 {
+		return lags, &GroupsLagError{Errors: errorsByGroup}
+	}
+	return lags, nil
+}
+
+// committedTopicPartitions returns the partitions which have a committed offset by topic name
+func committedTopicPartitions(offsets *kmsg.OffsetFetchResponse) map[string][]int32 {
+	topicPartitions := make(map[string][]int32, len(offsets.Topics))
+	for _, topic := range offsets.Topics {
+		for _, partition := range topic.Partitions {
+			if partition.ErrorCode != 0 || partition.Offset < 0 {
+				continue
+			}
+			topicPartitions[topic.Topic] = append(topicPartitions[topic.Topic], partition.Partition)
+		}
+	}
+	return topicPartitions
+}
+
+// mergeTopicPartitions returns the sorted, distinct partitions which any of the groups has committed an offset for
+func mergeTopicPartitions(offsetsByGroup map[string]*kmsg.OffsetFetchResponse) map[string][]int32 {
+	distinct := make(map[string]map[int32]struct{})
+	for _, offsets := range offsetsByGroup {
+		for topic, partitionIDs := range committedTopicPartitions(offsets) {
+			if _, exists := distinct[topic]; !exists {
+				distinct[topic] = make(map[int32]struct{})
+			}
+			for _, partitionID := range partitionIDs {
+				distinct[topic][partitionID] = struct{}{}
+			}
+		}
+	}
+
+	topicPartitions := make(map[string][]int32, len(distinct))
+	for topic, partitionIDs := range distinct {
+		sorted := make([]int32, 0, len(partitionIDs))
+		for partitionID := range partitionIDs {
+			sorted = append(sorted, partitionID)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		topicPartitions[topic] = sorted
+	}
+	return topicPartitions
+}
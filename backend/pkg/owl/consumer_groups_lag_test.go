@@ -0,0 +1,46 @@
+package owl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestMergeTopicPartitions(t *testing.T) {
+	offsetsByGroup := map[string]*kmsg.OffsetFetchResponse{
+		"billing": {Topics: []kmsg.OffsetFetchResponseTopic{
+			{Topic: "orders", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+				{Partition: 2, Offset: 10},
+				{Partition: 0, Offset: 5},
+				{Partition: 1, Offset: -1}, // not committed
+			}},
+		}},
+		"shipping": {Topics: []kmsg.OffsetFetchResponseTopic{
+			{Topic: "orders", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+				{Partition: 0, Offset: 7},
+				{Partition: 3, ErrorCode: kerr.UnstableOffsetCommit.Code},
+			}},
+			{Topic: "shipments", Partitions: []kmsg.OffsetFetchResponseTopicPartition{
+				{Partition: 0, Offset: 1},
+			}},
+		}},
+		"idle": {},
+	}
+
+	assert.Equal(t, map[string][]int32{
+		"orders":    {0, 2},
+		"shipments": {0},
+	}, mergeTopicPartitions(offsetsByGroup))
+	assert.Empty(t, mergeTopicPartitions(nil))
+}
+
+func TestGroupsLagError(t *testing.T) {
+	err := &GroupsLagError{Errors: map[string]error{
+		"shipping": errors.New("coordinator not available"),
+		"billing":  errors.New("not authorized"),
+	}}
+	assert.Equal(t, "failed to get the lag of 2 groups: group 'billing': not authorized; group 'shipping': coordinator not available", err.Error())
+}
@@ -0,0 +1,50 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// CountMessages returns the number of messages for each partition of the given topic, calculated as the difference
+// between the high and low water mark. This is an upper bound rather than an exact count: compacted topics may have
+// gaps between the watermarks and offsets occupied by transaction control records are counted as well.
+func (s *Service) CountMessages(ctx context.Context, topicName string) (map[int32]int64, error) {
+	partitionIDs, err := s.kafkaSvc.ListPartitionIDs(ctx, topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+
+	marks, err := s.kafkaSvc.GetPartitionMarks(ctx, topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	return messageCountsByPartition(marks)
+}
+
+// SumMessageCounts returns the total message count of a topic for the per partition counts returned by CountMessages.
+func SumMessageCounts(countsByPartition map[int32]int64) int64 {
+	var total int64
+	for _, count := range countsByPartition {
+		total += count
+	}
+	return total
+}
+
+func messageCountsByPartition(marks map[int32]*kafka.PartitionMarks) (map[int32]int64, error) {
+	counts := make(map[int32]int64, len(marks))
+	for partitionID, mark := range marks {
+		if mark.Error != "" {
+			return nil, fmt.Errorf("failed to get watermarks for partition '%v': %v", partitionID, mark.Error)
+		}
+		count := mark.High - mark.Low
+		if count < 0 {
+			count = 0
+		}
+		counts[partitionID] = count
+	}
+
+	return counts, nil
+}
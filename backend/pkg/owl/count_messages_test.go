@@ -0,0 +1,25 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageCountsByPartition(t *testing.T) {
+	marks := map[int32]*kafka.PartitionMarks{
+		0: {PartitionID: 0, Low: 0, High: 100},
+		1: {PartitionID: 1, Low: 40, High: 50},
+		2: {PartitionID: 2, Low: 7, High: 7},
+	}
+	counts, err := messageCountsByPartition(marks)
+	require.NoError(t, err)
+	assert.Equal(t, map[int32]int64{0: 100, 1: 10, 2: 0}, counts)
+	assert.Equal(t, int64(110), SumMessageCounts(counts))
+
+	marks[3] = &kafka.PartitionMarks{PartitionID: 3, Error: "NOT_LEADER_FOR_PARTITION", Low: -1, High: -1}
+	_, err = messageCountsByPartition(marks)
+	assert.Error(t, err)
+}
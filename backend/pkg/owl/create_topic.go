@@ -0,0 +1,56 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+)
+
+// CreateTopic validates the requested partition count and replication factor against the cluster metadata and
+// creates the topic. A *ValidationError is returned if the topic exists already or the replication factor exceeds
+// the number of brokers. -1 uses the brokers' default partition count or replication factor.
+func (s *Service) CreateTopic(ctx context.Context, topicName string, partitionCount int32, replicationFactor int16, configs map[string]*string) error {
+	err := s.validateAdminRequestWithMetadata(ctx, adminRequest{CreateTopics: []topicCreation{
+		{TopicName: topicName, PartitionCount: partitionCount, ReplicationFactor: replicationFactor},
+	}})
+	if err != nil {
+		return err
+	}
+
+	return s.kafkaSvc.CreateTopic(ctx, topicName, partitionCount, replicationFactor, configs)
+}
+
+// CreatePartitions increases the partition count of the topic to the given total. A *ValidationError is returned if
+// the topic does not exist or the partition count would not increase.
+func (s *Service) CreatePartitions(ctx context.Context, topicName string, partitionCount int32) error {
+	err := s.validateAdminRequestWithMetadata(ctx, adminRequest{CreatePartitions: []partitionIncrease{
+		{TopicName: topicName, PartitionCount: partitionCount},
+	}})
+	if err != nil {
+		return err
+	}
+
+	return s.kafkaSvc.CreatePartitions(ctx, topicName, partitionCount)
+}
+
+// CopyTopic validates that the destination topic can be created with the source topic's partition count and
+// replication factor and copies the topic, see kafka.Service.CopyTopic.
+func (s *Service) CopyTopic(ctx context.Context, src string, dst string, includeData bool) error {
+	srcMetadata, restErr := s.kafkaSvc.GetSingleMetadata(ctx, src)
+	if restErr != nil {
+		return fmt.Errorf("failed to get metadata of source topic: %w", restErr.Err)
+	}
+	if len(srcMetadata.Partitions) == 0 {
+		return fmt.Errorf("source topic '%v' has no partitions", src)
+	}
+
+	err := s.validateAdminRequestWithMetadata(ctx, adminRequest{CreateTopics: []topicCreation{{
+		TopicName:         dst,
+		PartitionCount:    int32(len(srcMetadata.Partitions)),
+		ReplicationFactor: int16(len(srcMetadata.Partitions[0].Replicas)),
+	}}})
+	if err != nil {
+		return err
+	}
+
+	return s.kafkaSvc.CopyTopic(ctx, src, dst, includeData)
+}
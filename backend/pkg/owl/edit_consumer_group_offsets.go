@@ -2,9 +2,9 @@ package owl
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/cloudhut/common/rest"
 	"github.com/cloudhut/kowl/backend/pkg/kafka"
@@ -32,6 +32,14 @@ func (s *Service) EditConsumerGroupOffsets(ctx context.Context, groupID string,
 	// 0. Check if consumer group is empty, otherwise we can't edit the group offsets and want to provide a proper
 	// error message for the frontend.
 	describedGroup, err := s.kafkaSvc.DescribeConsumerGroup(ctx, groupID)
+	if errors.Is(err, kafka.ErrGroupNotFound) {
+		return nil, &rest.Error{
+			Err:      err,
+			Status:   http.StatusNotFound,
+			Message:  fmt.Sprintf("Consumer group '%v' does not exist", groupID),
+			IsSilent: true,
+		}
+	}
 	if err != nil {
 		return nil, &rest.Error{
 			Err:     fmt.Errorf("failed to check group state: %w", err),
@@ -39,7 +47,7 @@ func (s *Service) EditConsumerGroupOffsets(ctx context.Context, groupID string,
 			Message: fmt.Sprintf("Failed to check consumer group state before proceeding: %v", err.Error()),
 		}
 	}
-	if !strings.EqualFold(describedGroup.State, "empty") {
+	if ParseGroupState(describedGroup.State) != GroupStateEmpty {
 		return &EditConsumerGroupOffsetsResponse{
 			Error:  fmt.Sprintf("Consumer group is still active and therefore can't be edited. Current Group State is: %v", describedGroup.State),
 			Topics: nil,
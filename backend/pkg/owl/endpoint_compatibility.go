@@ -38,6 +38,11 @@ func (s *Service) GetEndpointCompatibility(ctx context.Context) (EndpointCompati
 			Method:   "GET",
 			Requests: []kmsg.Request{&kmsg.DescribeConfigsRequest{}},
 		},
+		{
+			URL:      "/api/cluster/quorum",
+			Method:   "GET",
+			Requests: []kmsg.Request{&kmsg.DescribeQuorumRequest{}},
+		},
 		{
 			URL:      "/api/consumer-groups",
 			Method:   "GET",
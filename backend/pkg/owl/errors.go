@@ -4,4 +4,6 @@ import "errors"
 
 var (
 	ErrSchemaRegistryNotConfigured = errors.New("no schema registry configured")
+	ErrGroupNotEmpty               = errors.New("consumer group is not empty")
+	ErrOffsetsAlreadyCommitted     = errors.New("consumer group has committed offsets already")
 )
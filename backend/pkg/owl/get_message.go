@@ -0,0 +1,88 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+// OffsetOutOfRangeError is returned by GetMessage if the requested offset is below the partition's low water mark or
+// at/above the high water mark.
+type OffsetOutOfRangeError struct {
+	TopicName     string
+	PartitionID   int32
+	Offset        int64
+	LowWaterMark  int64
+	HighWaterMark int64
+}
+
+func (e *OffsetOutOfRangeError) Error() string {
+	return fmt.Sprintf("offset '%v' is out of range for topic '%v' partition '%v', consumable offsets are [%v, %v)",
+		e.Offset, e.TopicName, e.PartitionID, e.LowWaterMark, e.HighWaterMark)
+}
+
+// GetMessage returns the message at the given topic, partition and offset. An *OffsetOutOfRangeError is returned if
+// the offset is not within the partition's watermarks, kafka.ErrMessageNotFound if there is no record at an offset
+// within the watermarks (e.g. due to compaction) and kafka.ErrTopicNotFound or kafka.ErrPartitionNotFound if the
+// topic or partition does not exist.
+func (s *Service) GetMessage(ctx context.Context, topicName string, partitionID int32, offset int64) (*kafka.TopicMessage, error) {
+	topicMetadata, err := s.checkOffsetInRange(ctx, topicName, partitionID, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.kafkaSvc.FetchMessage(ctx, topicName, partitionID, offset, kafka.PartitionLeaderEpoch(topicMetadata, partitionID))
+}
+
+// GetMessageFromReplica is like GetMessage, but the message is fetched from the given replica broker rather than the
+// partition leader, so that the data of a follower can be verified. A *kafka.NotAReplicaError is returned if the
+// broker is not a replica of the partition.
+func (s *Service) GetMessageFromReplica(ctx context.Context, topicName string, partitionID int32, offset int64, brokerID int32) (*kafka.TopicMessage, error) {
+	_, err := s.checkOffsetInRange(ctx, topicName, partitionID, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.kafkaSvc.FetchMessageFromReplica(ctx, topicName, partitionID, offset, brokerID)
+}
+
+// checkOffsetInRange returns an *OffsetOutOfRangeError if the offset is not within the partition's watermarks and an
+// error wrapping kafka.ErrTopicNotFound or kafka.ErrPartitionNotFound if the topic or partition does not exist. The
+// fetched topic metadata is returned, so that it can be reused.
+func (s *Service) checkOffsetInRange(ctx context.Context, topicName string, partitionID int32, offset int64) (kmsg.MetadataResponseTopic, error) {
+	topicMetadata, restErr := s.kafkaSvc.GetSingleMetadata(ctx, topicName)
+	if restErr != nil {
+		return kmsg.MetadataResponseTopic{}, fmt.Errorf("failed to get topic metadata: %w", restErr.Err)
+	}
+	err := kafka.ValidatePartitionID(topicName, partitionID, int32(len(topicMetadata.Partitions)))
+	if err != nil {
+		return kmsg.MetadataResponseTopic{}, err
+	}
+
+	marks, err := s.kafkaSvc.GetPartitionMarks(ctx, topicName, []int32{partitionID})
+	if err != nil {
+		return kmsg.MetadataResponseTopic{}, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+	mark, exists := marks[partitionID]
+	if !exists {
+		return kmsg.MetadataResponseTopic{}, fmt.Errorf("no watermarks returned for partition '%v'", partitionID)
+	}
+	if mark.Error != "" {
+		return kmsg.MetadataResponseTopic{}, fmt.Errorf("failed to get watermarks for partition '%v': %v", partitionID, mark.Error)
+	}
+
+	if offset < mark.Low || offset >= mark.High {
+		return kmsg.MetadataResponseTopic{}, &OffsetOutOfRangeError{
+			TopicName:     topicName,
+			PartitionID:   partitionID,
+			Offset:        offset,
+			LowWaterMark:  mark.Low,
+			HighWaterMark: mark.High,
+		}
+	}
+
+	return topicMetadata, nil
+}
@@ -0,0 +1,63 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestService_GetMessage(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+		switch typedReq := req.(type) {
+		case *kmsg.MetadataRequest:
+			if len(typedReq.Topics) == 0 {
+				return &kmsg.MetadataResponse{}, nil // metadata refresh before retrying unknown topics
+			}
+			topic := kmsg.MetadataResponseTopic{Topic: *typedReq.Topics[0].Topic}
+			if topic.Topic == "orders" {
+				topic.Partitions = []kmsg.MetadataResponseTopicPartition{{Partition: 0}}
+			} else {
+				topic.ErrorCode = kerr.UnknownTopicOrPartition.Code
+			}
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{topic}}, nil
+		case *kmsg.ListOffsetsRequest:
+			res := &kmsg.ListOffsetsResponse{}
+			for _, topic := range typedReq.Topics {
+				resTopic := kmsg.ListOffsetsResponseTopic{Topic: topic.Topic}
+				for _, partition := range topic.Partitions {
+					offset := int64(100) // log end offset
+					if partition.Timestamp == kafka.TimestampEarliest {
+						offset = 10
+					}
+					resTopic.Partitions = append(resTopic.Partitions, kmsg.ListOffsetsResponseTopicPartition{Partition: partition.Partition, Offset: offset})
+				}
+				res.Topics = append(res.Topics, resTopic)
+			}
+			return res, nil
+		}
+		return nil, fmt.Errorf("unexpected %v request", kmsg.NameForKey(req.Key()))
+	}}
+	svc := &Service{logger: zap.NewNop(), kafkaSvc: &kafka.Service{Logger: zap.NewNop(), KafkaClient: client}}
+
+	for _, offset := range []int64{5, 100, 150} {
+		_, err := svc.GetMessage(context.Background(), "orders", 0, offset)
+		var outOfRangeErr *OffsetOutOfRangeError
+		require.True(t, errors.As(err, &outOfRangeErr), offset)
+		assert.Equal(t, int64(10), outOfRangeErr.LowWaterMark)
+		assert.Equal(t, int64(100), outOfRangeErr.HighWaterMark)
+	}
+
+	_, err := svc.GetMessage(context.Background(), "orders", 1, 50)
+	assert.True(t, errors.Is(err, kafka.ErrPartitionNotFound))
+	_, err = svc.GetMessage(context.Background(), "unknown", 0, 50)
+	assert.True(t, errors.Is(err, kafka.ErrTopicNotFound))
+}
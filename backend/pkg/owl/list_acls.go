@@ -3,11 +3,24 @@ package owl
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
 )
 
+// Resource pattern types as sent on the wire (KIP-290). The kmsg.ACLResourcePatternType constants of our Kafka client
+// are off by one (they lack ANY), so that e.g. PREFIXED ACLs would be reported as UNKNOWN. Hence we use our own.
+const (
+	AclPatternTypeAny      kmsg.ACLResourcePatternType = 1
+	AclPatternTypeMatch    kmsg.ACLResourcePatternType = 2
+	AclPatternTypeLiteral  kmsg.ACLResourcePatternType = 3
+	AclPatternTypePrefixed kmsg.ACLResourcePatternType = 4
+)
+
+// aclWildcardResourceName is the name of literal ACLs which apply to all resources of their type
+const aclWildcardResourceName = "*"
+
 type AclOverview struct {
 	AclResources        []*AclResource `json:"aclResources"`
 	IsAuthorizerEnabled bool           `json:"isAuthorizerEnabled"`
@@ -28,8 +41,26 @@ type AclRule struct {
 	PermissionType string `json:"permissionType"`
 }
 
-// ListAllACLs returns a list of all stored ACLs.
+// ListAllACLs returns a list of all stored ACLs. If the request filters by resource name with the MATCH pattern type,
+// all ACLs which apply to that resource are returned: literal ACLs for that name, wildcard ACLs and prefixed ACLs whose
+// prefix matches the name (e.g. the prefix "orders-" matches the topic "orders-eu").
 func (s *Service) ListAllACLs(ctx context.Context, req kmsg.DescribeACLsRequest) (*AclOverview, error) {
+	if req.ResourcePatternType != AclPatternTypeMatch || req.ResourceName == nil {
+		return s.listACLs(ctx, req, func(kmsg.DescribeACLsResponseResource) bool { return true })
+	}
+
+	// We resolve the matching patterns ourselves rather than by the broker, so that the same matching is used
+	// whenever we decide which ACLs apply to a resource.
+	resourceName := *req.ResourceName
+	req.ResourceName = nil
+	req.ResourcePatternType = AclPatternTypeAny
+	return s.listACLs(ctx, req, func(resource kmsg.DescribeACLsResponseResource) bool {
+		return aclPatternMatches(resource.ResourcePatternType, resource.ResourceName, resourceName)
+	})
+}
+
+// listACLs returns all ACLs matching the request whose resource is accepted by the keep function
+func (s *Service) listACLs(ctx context.Context, req kmsg.DescribeACLsRequest, keep func(kmsg.DescribeACLsResponseResource) bool) (*AclOverview, error) {
 	aclResponses, err := s.kafkaSvc.ListACLs(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ACLs from Kafka: %w", err)
@@ -46,12 +77,15 @@ func (s *Service) ListAllACLs(ctx context.Context, req kmsg.DescribeACLsRequest)
 		return nil, fmt.Errorf("failed to get ACLs from Kafka: %v", kafkaErr.Error())
 	}
 
-	resources := make([]*AclResource, len(aclResponses.Resources))
-	for i, aclResponse := range aclResponses.Resources {
+	resources := make([]*AclResource, 0, len(aclResponses.Resources))
+	for _, aclResponse := range aclResponses.Resources {
+		if !keep(aclResponse) {
+			continue
+		}
 		overview := &AclResource{
 			ResourceType:        aclResponse.ResourceType.String(),
 			ResourceName:        aclResponse.ResourceName,
-			ResourcePatternType: aclResponse.ResourcePatternType.String(),
+			ResourcePatternType: aclPatternTypeName(aclResponse.ResourcePatternType),
 			ACLs:                nil,
 		}
 
@@ -65,7 +99,7 @@ func (s *Service) ListAllACLs(ctx context.Context, req kmsg.DescribeACLsRequest)
 			}
 		}
 		overview.ACLs = acls
-		resources[i] = overview
+		resources = append(resources, overview)
 	}
 
 	return &AclOverview{
@@ -73,3 +107,33 @@ func (s *Service) ListAllACLs(ctx context.Context, req kmsg.DescribeACLsRequest)
 		IsAuthorizerEnabled: true,
 	}, nil
 }
+
+// aclPatternTypeName returns Kafka's name of the resource pattern type
+func aclPatternTypeName(patternType kmsg.ACLResourcePatternType) string {
+	switch patternType {
+	case AclPatternTypeAny:
+		return "ANY"
+	case AclPatternTypeMatch:
+		return "MATCH"
+	case AclPatternTypeLiteral:
+		return "LITERAL"
+	case AclPatternTypePrefixed:
+		return "PREFIXED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// aclPatternMatches returns true if an ACL with the given pattern type and pattern applies to the resource name.
+// Literal patterns match the same name or any name if they are the wildcard "*". Prefixed patterns match all names
+// starting with the prefix, the wildcard has no special meaning for them. Names are case sensitive.
+func aclPatternMatches(patternType kmsg.ACLResourcePatternType, pattern string, resourceName string) bool {
+	switch patternType {
+	case AclPatternTypeLiteral:
+		return pattern == aclWildcardResourceName || pattern == resourceName
+	case AclPatternTypePrefixed:
+		return strings.HasPrefix(resourceName, pattern)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,38 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestAclPatternMatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		patternType  kmsg.ACLResourcePatternType
+		pattern      string
+		resourceName string
+		want         bool
+	}{
+		{name: "literal exact", patternType: AclPatternTypeLiteral, pattern: "orders", resourceName: "orders", want: true},
+		{name: "literal other", patternType: AclPatternTypeLiteral, pattern: "orders", resourceName: "orders-eu", want: false},
+		{name: "literal wildcard", patternType: AclPatternTypeLiteral, pattern: "*", resourceName: "orders-eu", want: true},
+		{name: "literal is case sensitive", patternType: AclPatternTypeLiteral, pattern: "Orders", resourceName: "orders", want: false},
+		{name: "prefix matches", patternType: AclPatternTypePrefixed, pattern: "orders-", resourceName: "orders-eu", want: true},
+		{name: "prefix equals name", patternType: AclPatternTypePrefixed, pattern: "orders-", resourceName: "orders-", want: true},
+		{name: "prefix longer than name", patternType: AclPatternTypePrefixed, pattern: "orders-", resourceName: "orders", want: false},
+		{name: "prefix wildcard is no wildcard", patternType: AclPatternTypePrefixed, pattern: "*", resourceName: "orders", want: false},
+		{name: "filter pattern types never match", patternType: AclPatternTypeMatch, pattern: "orders", resourceName: "orders", want: false},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, aclPatternMatches(tc.patternType, tc.pattern, tc.resourceName), tc.name)
+	}
+}
+
+func TestAclPatternTypeName(t *testing.T) {
+	assert.Equal(t, "LITERAL", aclPatternTypeName(AclPatternTypeLiteral))
+	assert.Equal(t, "PREFIXED", aclPatternTypeName(AclPatternTypePrefixed))
+	assert.Equal(t, "UNKNOWN", aclPatternTypeName(0))
+}
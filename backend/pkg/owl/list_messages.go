@@ -8,6 +8,8 @@ import (
 	"github.com/twmb/franz-go/pkg/kmsg"
 	"go.uber.org/zap"
 	"math"
+	"sort"
+	"strconv"
 	"time"
 )
 
@@ -24,16 +26,93 @@ const (
 	StartOffsetNewest int64 = -3
 	// Timestamp = Start offset is specified as unix timestamp in ms
 	StartOffsetTimestamp int64 = -4
+	// SinceDuration = Start offset is resolved from the timestamp now - SinceDuration, e.g. the last 5 minutes
+	StartOffsetSinceDuration int64 = -5
+	// GroupCommitted = Start offset is the committed offset of a consumer group plus GroupOffsetDelta
+	StartOffsetGroupCommitted int64 = -6
 )
 
 // ListMessageRequest carries all filter, sort and cancellation options for fetching messages from Kafka
 type ListMessageRequest struct {
 	TopicName             string
 	PartitionID           int32 // -1 for all partitions
-	StartOffset           int64 // -1 for recent (high - n), -2 for oldest offset, -3 for newest offset, -4 for timestamp
+	StartOffset           int64 // -1 for recent (high - n), -2 for oldest offset, -3 for newest offset, -4 for timestamp, -5 for since duration, -6 for group committed
 	StartTimestamp        int64 // Start offset by unix timestamp in ms
 	MessageCount          int
 	FilterInterpreterCode string
+
+	// HeaderFilters only returns messages whose headers match all given key value pairs. An empty value only requires
+	// the header to exist. Like the interpreter code, the filters are evaluated while scanning the topic, so that
+	// MessageCount bounds the number of matching messages rather than the number of scanned messages.
+	HeaderFilters map[string]string
+
+	// IsolationLevel defaults to read uncommitted. With read committed, records of aborted transactions are skipped and
+	// the last stable offset (LSO) is used as end of each partition instead of the high water mark, so that we never
+	// wait for records of transactions which are still open.
+	IsolationLevel kafka.IsolationLevel
+
+	// MaxPayloadBytes truncates keys and values which are larger than the given number of bytes. 0 means no limit.
+	MaxPayloadBytes int
+
+	// KeysOnly skips the deserialization of values and returns messages without value, which is useful to enumerate
+	// the keys of compacted topics. Value size and tombstone information are still reported. Messages are counted
+	// towards MessageCount as usual and the consumed bytes still include the values, because Kafka always sends
+	// whole records to consumers.
+	KeysOnly bool
+
+	// FormatJSON adds an indented JSON representation with sorted object keys to all keys and values which can be
+	// converted to JSON (e.g. JSON, Avro or Protobuf). The original payload is returned as well.
+	FormatJSON bool
+
+	// Follow keeps streaming once the requested messages have been returned. New messages of all requested
+	// partitions are returned as they arrive until the context is cancelled, similar to 'tail -f'.
+	Follow bool
+
+	// Fetch tunes the consumer's fetch requests. Set Fetch.LowLatency to favor a low latency for interactive browsing.
+	Fetch kafka.FetchOptions
+
+	// SinceDuration is only considered with StartOffsetSinceDuration. Each partition is consumed from the first offset
+	// whose timestamp is at or after now - SinceDuration. Partitions without newer messages are skipped.
+	SinceDuration time.Duration
+
+	// GroupID and GroupOffsetDelta are only considered with StartOffsetGroupCommitted. Each partition is consumed
+	// from the group's committed offset plus the delta, which may be negative to look at the messages before the
+	// commit. The start offset is clamped to the partition's consumable offsets, partitions without a committed
+	// offset are skipped.
+	GroupID          string
+	GroupOffsetDelta int64
+
+	// ContinueOnDecodeError returns messages which could not be deserialized as raw bytes along with their
+	// DeserializeError, so that a single corrupt record doesn't fail the whole request. If false, listing stops
+	// with an error at the first message that could not be deserialized. Defaults to true if nil.
+	ContinueOnDecodeError *bool
+
+	// SortByTimestamp returns the messages of all partitions in ascending timestamp order rather than as they arrive.
+	// Timestamps within a partition may be out of order by up to ReorderWindow. Not supported together with Follow.
+	SortByTimestamp bool
+	ReorderWindow   time.Duration
+
+	// Transformers modify the messages before they are filtered and returned, e.g. to redact personal data
+	Transformers []kafka.MessageTransformer
+
+	// EndOffsets bounds the consumed range of the given partitions, each stops before its end offset (exclusive).
+	// End offsets beyond the high water mark are clamped, partitions which are not in the map are consumed as usual.
+	// Together with a custom start offset this allows to browse a precise range. MessageCount still limits the total
+	// number of returned messages. Not supported with recent or newest start offsets and Follow.
+	EndOffsets map[int32]int64
+
+	// CursorOffsets continues a previous page, see kafka.Cursor. Each partition in the map is consumed from its next
+	// offset instead of the start offset, offsets below the low water mark are clamped and partitions which have no
+	// messages after their next offset are skipped. Partitions which are not in the map start at the start offset as
+	// usual, because the previous pages have not returned any of their messages. Not supported with recent or newest
+	// start offsets and Follow, as these don't page forward through the topic.
+	CursorOffsets map[int32]int64
+}
+
+// HasFilters returns true if messages are filtered by interpreter code or headers, in which case the number of
+// messages each partition will return is unknown upfront.
+func (l *ListMessageRequest) HasFilters() bool {
+	return l.FilterInterpreterCode != "" || len(l.HeaderFilters) > 0
 }
 
 // ListMessageResponse returns the requested kafka messages along with some metadata about the operation
@@ -50,16 +129,29 @@ type ListMessageResponse struct {
 func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest, progress kafka.IListMessagesProgress) error {
 	start := time.Now()
 
+	err := s.validateFetchOptions(ctx, listReq.TopicName, listReq.Fetch)
+	if err != nil {
+		return err
+	}
+
 	progress.OnPhase("Get Partitions")
 	// Create array of partitionIDs which shall be consumed (always do that to ensure the requested topic exists at all)
-	partitions, err := s.kafkaSvc.ListPartitionIDs(ctx, listReq.TopicName)
-	if err != nil {
-		return fmt.Errorf("failed to get partitions: %w", err)
+	topicMetadata, restErr := s.kafkaSvc.GetSingleMetadata(ctx, listReq.TopicName)
+	if restErr != nil {
+		return fmt.Errorf("failed to get partitions: %w", restErr.Err)
 	}
+	partitions := make([]int32, len(topicMetadata.Partitions))
+	for i, partition := range topicMetadata.Partitions {
+		partitions[i] = partition.Partition
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
 
 	// Check if requested partitionID exists
-	if listReq.PartitionID > int32(len(partitions)) {
-		return fmt.Errorf("requested partitionID (%v) is greater than number of partitions (%v)", listReq.PartitionID, len(partitions))
+	if listReq.PartitionID != partitionsAll {
+		err := kafka.ValidatePartitionID(listReq.TopicName, listReq.PartitionID, int32(len(partitions)))
+		if err != nil {
+			return err
+		}
 	}
 
 	partitionIDs := make([]int32, 0, len(partitions))
@@ -74,9 +166,23 @@ func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest,
 	if err != nil {
 		return fmt.Errorf("failed to get watermarks: %w", err)
 	}
+	if listReq.IsolationLevel == kafka.IsolationLevelReadCommitted {
+		lsoRes, err := s.kafkaSvc.ListOffsetsWithIsolationLevel(ctx, map[string][]int32{listReq.TopicName: partitionIDs},
+			kafka.TimestampLatest, kafka.IsolationLevelReadCommitted)
+		if err != nil {
+			return fmt.Errorf("failed to get last stable offsets: %w", err)
+		}
+		setLastStableOffsets(marks, lsoRes)
+	}
 
 	// Get partition consume request by calculating start and end offsets for each partition
 	consumeRequests, err := s.calculateConsumeRequests(ctx, &listReq, marks)
+	if err != nil {
+		return fmt.Errorf("failed to calculate consume requests: %w", err)
+	}
+	if listReq.Follow {
+		addFollowRequests(consumeRequests, marks)
+	}
 	if len(consumeRequests) == 0 {
 		// No partitions/messages to consume, we can quit early.
 		progress.OnComplete(time.Since(start).Milliseconds(), false)
@@ -87,6 +193,24 @@ func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest,
 		MaxMessageCount:       listReq.MessageCount,
 		Partitions:            consumeRequests,
 		FilterInterpreterCode: listReq.FilterInterpreterCode,
+		HeaderFilters:         listReq.HeaderFilters,
+		IsolationLevel:        listReq.IsolationLevel,
+		MaxPayloadBytes:       listReq.MaxPayloadBytes,
+		KeysOnly:              listReq.KeysOnly,
+		FormatJSON:            listReq.FormatJSON,
+		Follow:                listReq.Follow,
+		Fetch:                 listReq.Fetch,
+		PartitionLeaderEpochs: kafka.PartitionLeaderEpochs(topicMetadata),
+
+		ContinueOnDecodeError: listReq.ContinueOnDecodeError,
+		SortByTimestamp:       listReq.SortByTimestamp,
+		ReorderWindow:         listReq.ReorderWindow,
+		Transformers:          listReq.Transformers,
+	}
+	if listReq.StartOffset == StartOffsetNewest || listReq.Follow {
+		// Live tail requests stream messages as they arrive, a slow client shall not slow down the consumer
+		topicConsumeRequest.MessageBufferSize = s.browseConfig.LiveTailBufferSize
+		topicConsumeRequest.OverflowPolicy = s.browseConfig.LiveTailOverflowPolicy
 	}
 
 	progress.OnPhase("Consuming messages")
@@ -105,27 +229,91 @@ func (s *Service) ListMessages(ctx context.Context, listReq ListMessageRequest,
 	return nil
 }
 
+// validateFetchOptions validates the fetch options. If the fetch max bytes have been set explicitly, they are checked
+// against the topic's max.message.bytes, so that every record of the topic fits into a fetch response.
+func (s *Service) validateFetchOptions(ctx context.Context, topicName string, fetch kafka.FetchOptions) error {
+	if fetch.MaxBytes <= 0 {
+		return fetch.Validate(0)
+	}
+
+	topicConfig, restErr := s.GetTopicConfigs(ctx, topicName, []string{"max.message.bytes"})
+	if restErr != nil {
+		return fmt.Errorf("failed to get max message bytes of topic: %w", restErr.Err)
+	}
+	maxMessageBytes := int32(0)
+	if entry := topicConfig.GetConfigEntryByName("max.message.bytes"); entry != nil && entry.Value != nil {
+		parsed, err := strconv.ParseInt(*entry.Value, 10, 32)
+		if err != nil {
+			s.logger.Warn("failed to parse max message bytes of topic", zap.String("topic", topicName), zap.Error(err))
+		}
+		maxMessageBytes = int32(parsed)
+	}
+
+	return fetch.Validate(maxMessageBytes)
+}
+
+// setLastStableOffsets replaces the high water marks with the last stable offsets returned by a read committed
+// ListOffsets request. Records at or after the LSO belong to open transactions and would not be returned to a
+// read committed consumer, hence we must not wait for them. Partitions with an error keep their high water mark.
+func setLastStableOffsets(marks map[int32]*kafka.PartitionMarks, lsoRes *kmsg.ListOffsetsResponse) {
+	for _, topic := range lsoRes.Topics {
+		for _, partition := range topic.Partitions {
+			if kerr.ErrorForCode(partition.ErrorCode) != nil {
+				continue
+			}
+			mark, exists := marks[partition.Partition]
+			if !exists {
+				continue
+			}
+			mark.High = partition.Offset
+		}
+	}
+}
+
 // calculateConsumeRequests is supposed to calculate the start and end offsets for each partition consumer, so that
 // we'll end up with ${messageCount} messages in total. To do so we'll take the known low and high watermarks into
 // account. Gaps between low and high watermarks (caused by compactions) will be neglected for now.
 func (s *Service) calculateConsumeRequests(ctx context.Context, listReq *ListMessageRequest, marks map[int32]*kafka.PartitionMarks) (map[int32]*kafka.PartitionConsumeRequest, error) {
 	requests := make(map[int32]*kafka.PartitionConsumeRequest, len(marks))
 
-	predictableResults := listReq.StartOffset != StartOffsetNewest && listReq.FilterInterpreterCode == ""
+	predictableResults := listReq.StartOffset != StartOffsetNewest && !listReq.HasFilters()
+
+	if err := validateEndOffsets(listReq); err != nil {
+		return nil, err
+	}
+	if err := validateCursorOffsets(listReq); err != nil {
+		return nil, err
+	}
 
 	// Resolve offsets by partitionID if the user sent a timestamp as start offset
 	var startOffsetByPartitionID map[int32]int64
-	if listReq.StartOffset == StartOffsetTimestamp {
+	if listReq.StartOffset == StartOffsetTimestamp || listReq.StartOffset == StartOffsetSinceDuration {
 		partitionIDs := make([]int32, 0)
 		for _, mark := range marks {
 			partitionIDs = append(partitionIDs, mark.PartitionID)
 		}
-		offsets, err := s.requestOffsetsByTimestamp(ctx, listReq.TopicName, partitionIDs, listReq.StartTimestamp)
+		startTimestamp := listReq.StartTimestamp
+		if listReq.StartOffset == StartOffsetSinceDuration {
+			startTimestamp = sinceTimestamp(time.Now(), listReq.SinceDuration)
+		}
+		offsets, err := s.requestOffsetsByTimestamp(ctx, listReq.TopicName, partitionIDs, startTimestamp)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get start offset by timestamp: %w", err)
 		}
 		startOffsetByPartitionID = offsets
 	}
+	var committedOffsets map[int32]int64
+	if listReq.StartOffset == StartOffsetGroupCommitted {
+		partitionIDs := make([]int32, 0, len(marks))
+		for _, mark := range marks {
+			partitionIDs = append(partitionIDs, mark.PartitionID)
+		}
+		offsets, err := s.getCommittedOffsets(ctx, listReq.GroupID, listReq.TopicName, partitionIDs)
+		if err != nil {
+			return nil, err
+		}
+		committedOffsets = offsets
+	}
 
 	// Init result map
 	notInitialized := int64(-100)
@@ -143,7 +331,16 @@ func (s *Service) calculateConsumeRequests(ctx context.Context, listReq *ListMes
 			MaxMessageCount: 0,
 		}
 
-		if listReq.StartOffset == StartOffsetRecent {
+		if cursorOffset, hasCursor := listReq.CursorOffsets[mark.PartitionID]; hasCursor {
+			if cursorOffset >= mark.High {
+				// All messages of this partition have been returned with the previous pages
+				continue
+			}
+			p.StartOffset = cursorOffset
+			if p.StartOffset < mark.Low {
+				p.StartOffset = mark.Low
+			}
+		} else if listReq.StartOffset == StartOffsetRecent {
 			p.StartOffset = mark.High // StartOffset will be recalculated later
 		} else if listReq.StartOffset == StartOffsetOldest {
 			p.StartOffset = mark.Low
@@ -165,6 +362,23 @@ func (s *Service) calculateConsumeRequests(ctx context.Context, listReq *ListMes
 				offset = marks[mark.PartitionID].High - 1
 			}
 			p.StartOffset = offset
+		} else if listReq.StartOffset == StartOffsetSinceDuration {
+			offset, hasNewerMessages := sinceStartOffset(startOffsetByPartitionID[mark.PartitionID], mark)
+			if !hasNewerMessages {
+				// Nothing has been written to this partition since then, there is nothing to consume
+				continue
+			}
+			p.StartOffset = offset
+		} else if listReq.StartOffset == StartOffsetGroupCommitted {
+			committedOffset, hasCommit := committedOffsets[mark.PartitionID]
+			if !hasCommit {
+				continue
+			}
+			offset, hasMessages := groupCommittedStartOffset(committedOffset, listReq.GroupOffsetDelta, mark)
+			if !hasMessages {
+				continue
+			}
+			p.StartOffset = offset
 		} else {
 			// Either custom offset or resolved offset by timestamp is given
 			p.StartOffset = listReq.StartOffset
@@ -175,6 +389,14 @@ func (s *Service) calculateConsumeRequests(ctx context.Context, listReq *ListMes
 			}
 		}
 
+		if endOffset, hasEndOffset := listReq.EndOffsets[mark.PartitionID]; hasEndOffset {
+			lastOffset, hasMessages := rangeLastOffset(p.StartOffset, endOffset, mark)
+			if !hasMessages {
+				continue
+			}
+			p.EndOffset = lastOffset
+		}
+
 		// Special handling for live tail and requests with enabled filter code as we don't know how many results on each
 		// partition we'll get (which is required for the "roundrobin" approach).
 		if !predictableResults {
@@ -261,6 +483,111 @@ func (s *Service) calculateConsumeRequests(ctx context.Context, listReq *ListMes
 	return filteredRequests, nil
 }
 
+// sinceTimestamp returns the unix timestamp in ms of the given duration before now.
+func sinceTimestamp(now time.Time, duration time.Duration) int64 {
+	return now.Add(-duration).UnixNano() / int64(time.Millisecond)
+}
+
+// sinceStartOffset returns the start offset for the offset resolved by timestamp. Kafka resolves timestamps before
+// the partition's earliest message to the earliest offset, which may have been deleted by retention since then, hence
+// the offset is never lower than the low water mark. The second return value is false if the partition has no message
+// at or after the timestamp, in which case Kafka returns -1.
+func sinceStartOffset(resolvedOffset int64, mark *kafka.PartitionMarks) (int64, bool) {
+	if resolvedOffset < 0 || resolvedOffset >= mark.High {
+		return 0, false
+	}
+	if resolvedOffset < mark.Low {
+		return mark.Low, true
+	}
+	return resolvedOffset, true
+}
+
+// groupCommittedStartOffset returns the committed offset plus delta, clamped to the partition's first and last
+// consumable offset. The second return value is false if the partition is empty.
+func groupCommittedStartOffset(committedOffset int64, delta int64, mark *kafka.PartitionMarks) (int64, bool) {
+	if mark.High <= mark.Low {
+		return 0, false
+	}
+	offset := committedOffset + delta
+	if offset < mark.Low {
+		return mark.Low, true
+	}
+	if offset >= mark.High {
+		return mark.High - 1, true
+	}
+	return offset, true
+}
+
+// validateEndOffsets checks that the request's end offsets can be applied. End offsets must not be lower than
+// a custom start offset, the start offsets which are resolved per partition are checked by rangeLastOffset.
+func validateEndOffsets(listReq *ListMessageRequest) error {
+	if len(listReq.EndOffsets) == 0 {
+		return nil
+	}
+	if listReq.StartOffset == StartOffsetRecent || listReq.StartOffset == StartOffsetNewest || listReq.Follow {
+		return fmt.Errorf("end offsets are not supported with recent or newest start offsets and follow")
+	}
+	for partitionID, endOffset := range listReq.EndOffsets {
+		if endOffset < 0 {
+			return fmt.Errorf("end offset %d of partition %d must not be negative", endOffset, partitionID)
+		}
+		if listReq.StartOffset >= 0 && endOffset < listReq.StartOffset {
+			return fmt.Errorf("end offset %d of partition %d is lower than the start offset %d",
+				endOffset, partitionID, listReq.StartOffset)
+		}
+	}
+	return nil
+}
+
+// validateCursorOffsets checks that the request's cursor offsets can be applied
+func validateCursorOffsets(listReq *ListMessageRequest) error {
+	if len(listReq.CursorOffsets) == 0 {
+		return nil
+	}
+	if listReq.StartOffset == StartOffsetRecent || listReq.StartOffset == StartOffsetNewest || listReq.Follow {
+		return fmt.Errorf("cursors are not supported with recent or newest start offsets and follow")
+	}
+	for partitionID, offset := range listReq.CursorOffsets {
+		if offset < 0 {
+			return fmt.Errorf("cursor offset %d of partition %d must not be negative", offset, partitionID)
+		}
+	}
+	return nil
+}
+
+// rangeLastOffset returns the last offset which shall be consumed if the partition is consumed up to the given
+// (exclusive) end offset. The end offset is clamped to the high water mark. The second return value is false if
+// there is no message between the start and end offset, e.g. because they have been removed by retention.
+func rangeLastOffset(startOffset int64, endOffset int64, mark *kafka.PartitionMarks) (int64, bool) {
+	if endOffset > mark.High {
+		endOffset = mark.High
+	}
+	if endOffset <= startOffset {
+		return 0, false
+	}
+	return endOffset - 1, true
+}
+
+// addFollowRequests adds a consume request starting at the high water mark for all partitions which have no consume
+// request yet, so that new messages of these partitions are returned as well when following the topic. Partitions
+// which already have a consume request continue to follow after their end offset, see TopicConsumeRequest.Follow.
+func addFollowRequests(requests map[int32]*kafka.PartitionConsumeRequest, marks map[int32]*kafka.PartitionMarks) {
+	for partitionID, mark := range marks {
+		if _, exists := requests[partitionID]; exists {
+			continue
+		}
+		requests[partitionID] = &kafka.PartitionConsumeRequest{
+			PartitionID:     partitionID,
+			IsDrained:       true,
+			LowWaterMark:    mark.Low,
+			HighWaterMark:   mark.High,
+			StartOffset:     mark.High,
+			EndOffset:       mark.High - 1,
+			MaxMessageCount: 0,
+		}
+	}
+}
+
 // requestOffsetsByTimestamp returns the offset that has been resolved for the given timestamp in a map which is indexed
 // by partitionID.
 func (s *Service) requestOffsetsByTimestamp(ctx context.Context, topicName string, partitionIDs []int32, timestamp int64) (map[int32]int64, error) {
@@ -289,7 +616,7 @@ func (s *Service) requestOffsetsByTimestamp(ctx context.Context, topicName strin
 		for _, partition := range topic.Partitions {
 			typedErr := kerr.TypedErrorForCode(partition.ErrorCode)
 			if typedErr != nil {
-				return nil, fmt.Errorf("failed to get timestamp for at least one partition. Inner Kafka error: %w", typedErr.Error())
+				return nil, fmt.Errorf("failed to get timestamp for at least one partition. Inner Kafka error: %w", typedErr)
 			}
 			offsetByPartition[partition.Partition] = partition.Offset
 		}
@@ -1,16 +1,23 @@
 package owl
 
 import (
+	"context"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
 )
 
 func TestCalculateConsumeRequests_AllPartitions_FewNewestMessages(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+
 	// Request less messages than we have partitions
-	marks := map[int32]kafka.PartitionMarks{
+	marks := map[int32]*kafka.PartitionMarks{
 		0: {PartitionID: 0, Low: 0, High: 300},
 		1: {PartitionID: 1, Low: 0, High: 10},
 		2: {PartitionID: 2, Low: 10, High: 30},
@@ -29,14 +36,17 @@ func TestCalculateConsumeRequests_AllPartitions_FewNewestMessages(t *testing.T)
 		1: {PartitionID: 1, IsDrained: false, StartOffset: marks[1].High - 1, EndOffset: marks[1].High - 1, MaxMessageCount: 1, LowWaterMark: marks[1].Low, HighWaterMark: marks[1].High},
 		2: {PartitionID: 2, IsDrained: false, StartOffset: marks[2].High - 1, EndOffset: marks[2].High - 1, MaxMessageCount: 1, LowWaterMark: marks[2].Low, HighWaterMark: marks[2].High},
 	}
-	actual := calculateConsumeRequests(req, marks)
+	actual, err := svc.calculateConsumeRequests(context.Background(), req, marks)
+	assert.NoError(t, err)
 
 	assert.Equal(t, expected, actual, "expected other result for unbalanced message distribution - all partition IDs")
 }
 
 func TestCalculateConsumeRequests_AllPartitions_Unbalanced(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+
 	// Unbalanced message distribution across 3 partitions
-	marks := map[int32]kafka.PartitionMarks{
+	marks := map[int32]*kafka.PartitionMarks{
 		0: {PartitionID: 0, Low: 0, High: 300},
 		1: {PartitionID: 1, Low: 0, High: 11},
 		2: {PartitionID: 2, Low: 10, High: 31},
@@ -51,17 +61,20 @@ func TestCalculateConsumeRequests_AllPartitions_Unbalanced(t *testing.T) {
 
 	// Expected result should be able to return all 100 requested messages as evenly distributed as possible
 	expected := map[int32]*kafka.PartitionConsumeRequest{
-		0: {PartitionID: 0, IsDrained: false, LowWaterMark: marks[0].Low, HighWaterMark: marks[0].High, StartOffset: 0, EndOffset: marks[0].High - 1, MaxMessageCount: 70},
-		1: {PartitionID: 1, IsDrained: true, LowWaterMark: marks[1].Low, HighWaterMark: marks[1].High, StartOffset: 0, EndOffset: marks[1].High - 1, MaxMessageCount: 10},
-		2: {PartitionID: 2, IsDrained: true, LowWaterMark: marks[2].Low, HighWaterMark: marks[2].High, StartOffset: 10, EndOffset: marks[2].High - 1, MaxMessageCount: 20},
+		0: {PartitionID: 0, IsDrained: false, LowWaterMark: marks[0].Low, HighWaterMark: marks[0].High, StartOffset: 0, EndOffset: marks[0].High - 1, MaxMessageCount: 68},
+		1: {PartitionID: 1, IsDrained: true, LowWaterMark: marks[1].Low, HighWaterMark: marks[1].High, StartOffset: 0, EndOffset: marks[1].High - 1, MaxMessageCount: 11},
+		2: {PartitionID: 2, IsDrained: true, LowWaterMark: marks[2].Low, HighWaterMark: marks[2].High, StartOffset: 10, EndOffset: marks[2].High - 1, MaxMessageCount: 21},
 	}
-	actual := calculateConsumeRequests(req, marks)
+	actual, err := svc.calculateConsumeRequests(context.Background(), req, marks)
+	assert.NoError(t, err)
 
 	assert.Equal(t, expected, actual, "expected other result for unbalanced message distribution - all partition IDs")
 }
 
 func TestCalculateConsumeRequests_SinglePartition(t *testing.T) {
-	marks := map[int32]kafka.PartitionMarks{
+	svc := Service{logger: zap.NewNop()}
+
+	marks := map[int32]*kafka.PartitionMarks{
 		14: {PartitionID: 14, Low: 100, High: 301},
 	}
 	lowMark := marks[14].Low
@@ -87,11 +100,11 @@ func TestCalculateConsumeRequests_SinglePartition(t *testing.T) {
 			},
 		},
 
-		// Custom start offset with drained - 50 messages
+		// Custom start offset with drained - 51 messages
 		{
 			&ListMessageRequest{TopicName: "test", PartitionID: 14, StartOffset: 250, MessageCount: 200},
 			map[int32]*kafka.PartitionConsumeRequest{
-				14: {PartitionID: 14, IsDrained: true, StartOffset: 250, EndOffset: highMark - 1, MaxMessageCount: 50, LowWaterMark: lowMark, HighWaterMark: highMark},
+				14: {PartitionID: 14, IsDrained: true, StartOffset: 250, EndOffset: highMark - 1, MaxMessageCount: 51, LowWaterMark: lowMark, HighWaterMark: highMark},
 			},
 		},
 
@@ -115,7 +128,7 @@ func TestCalculateConsumeRequests_SinglePartition(t *testing.T) {
 		{
 			&ListMessageRequest{TopicName: "test", PartitionID: 14, StartOffset: StartOffsetOldest, MessageCount: 500},
 			map[int32]*kafka.PartitionConsumeRequest{
-				14: {PartitionID: 14, IsDrained: true, StartOffset: lowMark, EndOffset: highMark - 1, MaxMessageCount: 200, LowWaterMark: lowMark, HighWaterMark: highMark},
+				14: {PartitionID: 14, IsDrained: true, StartOffset: lowMark, EndOffset: highMark - 1, MaxMessageCount: 201, LowWaterMark: lowMark, HighWaterMark: highMark},
 			},
 		},
 
@@ -129,15 +142,18 @@ func TestCalculateConsumeRequests_SinglePartition(t *testing.T) {
 	}
 
 	for i, table := range tt {
-		actual := calculateConsumeRequests(table.req, marks)
+		actual, err := svc.calculateConsumeRequests(context.Background(), table.req, marks)
+		assert.NoError(t, err)
 		assert.Equal(t, table.expected, actual, "expected other result for single partition test. Case: ", i)
 	}
 }
 
 func TestCalculateConsumeRequests_AllPartitions_WithFilter(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+
 	// Request less messages than we have partitions, if filter code is set we handle consume requests different than
 	// usual - as we don't care about the distribution between partitions.
-	marks := map[int32]kafka.PartitionMarks{
+	marks := map[int32]*kafka.PartitionMarks{
 		0: {PartitionID: 0, Low: 0, High: 300},
 		1: {PartitionID: 1, Low: 0, High: 300},
 		2: {PartitionID: 2, Low: 0, High: 300},
@@ -176,10 +192,246 @@ func TestCalculateConsumeRequests_AllPartitions_WithFilter(t *testing.T) {
 				2: {PartitionID: 2, IsDrained: false, StartOffset: 249, EndOffset: 299, MaxMessageCount: 50, LowWaterMark: 0, HighWaterMark: 300},
 			},
 		},
+		{
+			&ListMessageRequest{
+				TopicName:     "test",
+				PartitionID:   partitionsAll, // All partitions
+				StartOffset:   StartOffsetOldest,
+				MessageCount:  2,
+				HeaderFilters: map[string]string{"traceId": "abc"},
+			},
+			map[int32]*kafka.PartitionConsumeRequest{
+				0: {PartitionID: 0, IsDrained: false, StartOffset: 0, EndOffset: 299, MaxMessageCount: 2, LowWaterMark: 0, HighWaterMark: 300},
+				1: {PartitionID: 1, IsDrained: false, StartOffset: 0, EndOffset: 299, MaxMessageCount: 2, LowWaterMark: 0, HighWaterMark: 300},
+				2: {PartitionID: 2, IsDrained: false, StartOffset: 0, EndOffset: 299, MaxMessageCount: 2, LowWaterMark: 0, HighWaterMark: 300},
+			},
+		},
 	}
 
 	for i, table := range tt {
-		actual := calculateConsumeRequests(table.req, marks)
+		actual, err := svc.calculateConsumeRequests(context.Background(), table.req, marks)
+		assert.NoError(t, err)
 		assert.Equal(t, table.expected, actual, "expected other result for all partitions with filter enable. Case: ", i)
 	}
 }
+
+func TestCalculateConsumeRequests_EndOffsets(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+
+	marks := map[int32]*kafka.PartitionMarks{
+		0: {PartitionID: 0, Low: 0, High: 300},
+		1: {PartitionID: 1, Low: 0, High: 50},
+		2: {PartitionID: 2, Low: 100, High: 200},
+		3: {PartitionID: 3, Low: 0, High: 300},
+	}
+
+	req := &ListMessageRequest{
+		TopicName:    "test",
+		PartitionID:  partitionsAll,
+		StartOffset:  20,
+		MessageCount: 100,
+		EndOffsets: map[int32]int64{
+			0: 30,  // range 20-29
+			1: 500, // clamped to the high water mark
+			2: 80,  // range has been removed by retention
+		},
+	}
+
+	// Partition 3 has no end offset and takes the remaining messages
+	expected := map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, IsDrained: true, LowWaterMark: 0, HighWaterMark: 300, StartOffset: 20, EndOffset: 29, MaxMessageCount: 10},
+		1: {PartitionID: 1, IsDrained: true, LowWaterMark: 0, HighWaterMark: 50, StartOffset: 20, EndOffset: 49, MaxMessageCount: 30},
+		3: {PartitionID: 3, IsDrained: false, LowWaterMark: 0, HighWaterMark: 300, StartOffset: 20, EndOffset: 299, MaxMessageCount: 60},
+	}
+	actual, err := svc.calculateConsumeRequests(context.Background(), req, marks)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// With filters the number of results is unknown, but the bounded partitions still stop at their end offset
+	req.FilterInterpreterCode = "return true"
+	actual, err = svc.calculateConsumeRequests(context.Background(), req, marks)
+	assert.NoError(t, err)
+	assert.Len(t, actual, 3)
+	assert.Equal(t, int64(29), actual[0].EndOffset)
+	assert.Equal(t, int64(49), actual[1].EndOffset)
+	assert.Equal(t, int64(299), actual[3].EndOffset)
+
+	invalidRequests := []*ListMessageRequest{
+		{StartOffset: 20, MessageCount: 100, EndOffsets: map[int32]int64{0: 10}},
+		{StartOffset: StartOffsetOldest, MessageCount: 100, EndOffsets: map[int32]int64{0: -1}},
+		{StartOffset: StartOffsetRecent, MessageCount: 100, EndOffsets: map[int32]int64{0: 10}},
+		{StartOffset: StartOffsetOldest, MessageCount: 100, Follow: true, EndOffsets: map[int32]int64{0: 10}},
+	}
+	for _, invalidReq := range invalidRequests {
+		_, err := svc.calculateConsumeRequests(context.Background(), invalidReq, marks)
+		assert.Error(t, err)
+	}
+}
+
+func TestCalculateConsumeRequests_CursorOffsets(t *testing.T) {
+	svc := Service{logger: zap.NewNop()}
+
+	marks := map[int32]*kafka.PartitionMarks{
+		0: {PartitionID: 0, Low: 0, High: 300},
+		1: {PartitionID: 1, Low: 0, High: 50},
+		2: {PartitionID: 2, Low: 100, High: 200},
+		3: {PartitionID: 3, Low: 0, High: 300},
+	}
+
+	req := &ListMessageRequest{
+		TopicName:    "test",
+		PartitionID:  partitionsAll,
+		StartOffset:  StartOffsetOldest,
+		MessageCount: 30,
+		CursorOffsets: map[int32]int64{
+			0: 280, // continues after the previous page
+			1: 50,  // all messages have been returned already
+			2: 20,  // clamped to the low water mark
+		},
+	}
+
+	// Partition 3 has not returned any message yet and starts at the oldest offset
+	expected := map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, IsDrained: false, LowWaterMark: 0, HighWaterMark: 300, StartOffset: 280, EndOffset: 299, MaxMessageCount: 10},
+		2: {PartitionID: 2, IsDrained: false, LowWaterMark: 100, HighWaterMark: 200, StartOffset: 100, EndOffset: 199, MaxMessageCount: 10},
+		3: {PartitionID: 3, IsDrained: false, LowWaterMark: 0, HighWaterMark: 300, StartOffset: 0, EndOffset: 299, MaxMessageCount: 10},
+	}
+	actual, err := svc.calculateConsumeRequests(context.Background(), req, marks)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	invalidRequests := []*ListMessageRequest{
+		{StartOffset: StartOffsetOldest, MessageCount: 100, CursorOffsets: map[int32]int64{0: -1}},
+		{StartOffset: StartOffsetRecent, MessageCount: 100, CursorOffsets: map[int32]int64{0: 10}},
+		{StartOffset: StartOffsetNewest, MessageCount: 100, CursorOffsets: map[int32]int64{0: 10}},
+		{StartOffset: StartOffsetOldest, MessageCount: 100, Follow: true, CursorOffsets: map[int32]int64{0: 10}},
+	}
+	for _, invalidReq := range invalidRequests {
+		_, err := svc.calculateConsumeRequests(context.Background(), invalidReq, marks)
+		assert.Error(t, err)
+	}
+}
+
+func TestRangeLastOffset(t *testing.T) {
+	mark := &kafka.PartitionMarks{PartitionID: 0, Low: 100, High: 200}
+
+	lastOffset, hasMessages := rangeLastOffset(120, 150, mark)
+	assert.True(t, hasMessages)
+	assert.Equal(t, int64(149), lastOffset)
+
+	lastOffset, hasMessages = rangeLastOffset(120, 1000, mark)
+	assert.True(t, hasMessages)
+	assert.Equal(t, int64(199), lastOffset)
+
+	_, hasMessages = rangeLastOffset(120, 120, mark)
+	assert.False(t, hasMessages)
+	_, hasMessages = rangeLastOffset(100, 50, mark)
+	assert.False(t, hasMessages)
+}
+
+func TestSetLastStableOffsets(t *testing.T) {
+	marks := map[int32]*kafka.PartitionMarks{
+		0: {PartitionID: 0, Low: 0, High: 300},
+		1: {PartitionID: 1, Low: 0, High: 100},
+		2: {PartitionID: 2, Low: 0, High: 50},
+	}
+	lsoRes := &kmsg.ListOffsetsResponse{
+		Topics: []kmsg.ListOffsetsResponseTopic{
+			{
+				Topic: "test",
+				Partitions: []kmsg.ListOffsetsResponseTopicPartition{
+					{Partition: 0, Offset: 280},
+					{Partition: 1, Offset: 90, ErrorCode: kerr.NotLeaderForPartition.Code},
+					{Partition: 2, Offset: 50},
+				},
+			},
+		},
+	}
+
+	setLastStableOffsets(marks, lsoRes)
+	assert.Equal(t, int64(280), marks[0].High, "expected high water mark to be replaced by the LSO")
+	assert.Equal(t, int64(100), marks[1].High, "expected high water mark to be kept on partition errors")
+	assert.Equal(t, int64(50), marks[2].High, "expected high water mark to equal LSO without open transactions")
+}
+
+func TestAddFollowRequests(t *testing.T) {
+	marks := map[int32]*kafka.PartitionMarks{
+		0: {PartitionID: 0, Low: 0, High: 300},
+		1: {PartitionID: 1, Low: 0, High: 0},
+	}
+	requests := map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, StartOffset: 297, EndOffset: 299, MaxMessageCount: 3, LowWaterMark: 0, HighWaterMark: 300},
+	}
+
+	addFollowRequests(requests, marks)
+
+	expected := map[int32]*kafka.PartitionConsumeRequest{
+		0: {PartitionID: 0, StartOffset: 297, EndOffset: 299, MaxMessageCount: 3, LowWaterMark: 0, HighWaterMark: 300},
+		1: {PartitionID: 1, IsDrained: true, StartOffset: 0, EndOffset: -1, MaxMessageCount: 0, LowWaterMark: 0, HighWaterMark: 0},
+	}
+	assert.Equal(t, expected, requests)
+}
+
+func TestSinceStartOffset(t *testing.T) {
+	mark := &kafka.PartitionMarks{PartitionID: 0, Low: 100, High: 200}
+
+	tests := []struct {
+		name           string
+		resolvedOffset int64
+		wantOffset     int64
+		wantMessages   bool
+	}{
+		{name: "within partition", resolvedOffset: 150, wantOffset: 150, wantMessages: true},
+		{name: "earliest message is newer", resolvedOffset: 20, wantOffset: 100, wantMessages: true},
+		{name: "no newer message", resolvedOffset: -1, wantMessages: false},
+		{name: "at high water mark", resolvedOffset: 200, wantMessages: false},
+	}
+
+	for _, tc := range tests {
+		offset, hasMessages := sinceStartOffset(tc.resolvedOffset, mark)
+		assert.Equal(t, tc.wantMessages, hasMessages, tc.name)
+		if tc.wantMessages {
+			assert.Equal(t, tc.wantOffset, offset, tc.name)
+		}
+	}
+
+	now := time.Unix(1600000000, 0)
+	assert.Equal(t, int64(1599999700000), sinceTimestamp(now, 5*time.Minute))
+}
+
+func TestGroupCommittedStartOffset(t *testing.T) {
+	mark := &kafka.PartitionMarks{PartitionID: 0, Low: 100, High: 200}
+
+	tests := []struct {
+		name            string
+		committedOffset int64
+		delta           int64
+		wantOffset      int64
+	}{
+		{name: "at commit", committedOffset: 150, delta: 0, wantOffset: 150},
+		{name: "look back", committedOffset: 150, delta: -10, wantOffset: 140},
+		{name: "look ahead", committedOffset: 150, delta: 10, wantOffset: 160},
+		{name: "clamped to low water mark", committedOffset: 105, delta: -10, wantOffset: 100},
+		{name: "commit behind retention", committedOffset: 20, delta: 5, wantOffset: 100},
+		{name: "clamped to last message", committedOffset: 195, delta: 10, wantOffset: 199},
+		{name: "caught up group", committedOffset: 200, delta: 0, wantOffset: 199},
+		{name: "last message before commit", committedOffset: 200, delta: -1, wantOffset: 199},
+	}
+
+	for _, tc := range tests {
+		offset, hasMessages := groupCommittedStartOffset(tc.committedOffset, tc.delta, mark)
+		assert.True(t, hasMessages, tc.name)
+		assert.Equal(t, tc.wantOffset, offset, tc.name)
+	}
+
+	_, hasMessages := groupCommittedStartOffset(10, 0, &kafka.PartitionMarks{PartitionID: 0, Low: 10, High: 10})
+	assert.False(t, hasMessages)
+}
+
+func TestService_StartOffsetOrDefault(t *testing.T) {
+	svc := Service{defaultStartOffset: StartOffsetOldest}
+
+	assert.Equal(t, int64(0), svc.StartOffsetOrDefault(0, false))
+	assert.Equal(t, StartOffsetNewest, svc.StartOffsetOrDefault(StartOffsetNewest, false))
+	assert.Equal(t, StartOffsetOldest, svc.StartOffsetOrDefault(StartOffsetNewest, true))
+}
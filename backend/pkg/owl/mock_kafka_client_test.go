@@ -0,0 +1,26 @@
+package owl
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// mockKafkaClient is a kafka.KafkaClient which passes all requests to the handler function
+type mockKafkaClient struct {
+	handle func(ctx context.Context, req kmsg.Request) (kmsg.Response, error)
+}
+
+func (m *mockKafkaClient) Request(ctx context.Context, req kmsg.Request) (kmsg.Response, error) {
+	return m.handle(ctx, req)
+}
+
+func (m *mockKafkaClient) RequestSharded(ctx context.Context, req kmsg.Request) []kgo.ResponseShard {
+	res, err := m.handle(ctx, req)
+	return []kgo.ResponseShard{{Meta: kgo.BrokerMetadata{NodeID: -1}, Req: req, Resp: res, Err: err}}
+}
+
+func (m *mockKafkaClient) ForBroker(int32) kmsg.Requestor {
+	return m
+}
@@ -68,8 +68,14 @@ type AlterPartitionReassignmentsPartitionResponse struct {
 	ErrorMessage *string `json:"errorMessage"`
 }
 
-// ListPartitionReassignments returns all partition reassignments that are currently in progress.
+// AlterPartitionAssignments validates the requested assignments and submits them to Kafka. A *ValidationError is
+// returned if the request does not match the cluster's topics and brokers.
 func (s *Service) AlterPartitionAssignments(ctx context.Context, topics []kmsg.AlterPartitionAssignmentsRequestTopic) ([]AlterPartitionReassignmentsResponse, error) {
+	err := s.validateAdminRequestWithMetadata(ctx, adminRequest{PartitionReassignments: topics})
+	if err != nil {
+		return nil, err
+	}
+
 	kRes, err := s.kafkaSvc.AlterPartitionAssignments(ctx, topics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reassign partitions: %w", err)
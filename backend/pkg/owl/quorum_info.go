@@ -0,0 +1,66 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// QuorumInfo describes the Raft quorum of a KRaft cluster's metadata log
+type QuorumInfo struct {
+	LeaderID      int32                `json:"leaderId"`
+	LeaderEpoch   int32                `json:"leaderEpoch"`
+	HighWatermark int64                `json:"highWatermark"`
+	Voters        []QuorumReplicaState `json:"voters"`
+	Observers     []QuorumReplicaState `json:"observers"`
+}
+
+// QuorumReplicaState is the replication progress of a single voter or observer. The DescribeQuorum version we
+// support does not report fetch timestamps, hence we only report the log end offset.
+type QuorumReplicaState struct {
+	ReplicaID    int32 `json:"replicaId"`
+	LogEndOffset int64 `json:"logEndOffset"`
+}
+
+// DescribeQuorum returns the leader and the replication state of all voters and observers of the metadata quorum.
+// An error wrapping kafka.ErrUnsupportedRequest is returned for ZooKeeper based clusters.
+func (s *Service) DescribeQuorum(ctx context.Context) (*QuorumInfo, error) {
+	res, err := s.kafkaSvc.DescribeQuorum(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe quorum: %w", err)
+	}
+	err = kerr.ErrorForCode(res.ErrorCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe quorum. Inner error: %w", err)
+	}
+	if len(res.Topics) != 1 || len(res.Topics[0].Partitions) != 1 {
+		return nil, fmt.Errorf("expected exactly one partition in describe quorum response")
+	}
+
+	partition := res.Topics[0].Partitions[0]
+	err = kerr.ErrorForCode(partition.ErrorCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe quorum of metadata partition. Inner error: %w", err)
+	}
+
+	return &QuorumInfo{
+		LeaderID:      partition.LeaderID,
+		LeaderEpoch:   partition.LeaderEpoch,
+		HighWatermark: partition.HighWatermark,
+		Voters:        convertQuorumReplicaStates(partition.CurrentVoters),
+		Observers:     convertQuorumReplicaStates(partition.Observers),
+	}, nil
+}
+
+func convertQuorumReplicaStates(states []kmsg.DescribeQuorumResponseTopicPartitionReplicaState) []QuorumReplicaState {
+	converted := make([]QuorumReplicaState, len(states))
+	for i, state := range states {
+		converted[i] = QuorumReplicaState{
+			ReplicaID:    state.ReplicaID,
+			LogEndOffset: state.LogEndOffset,
+		}
+	}
+	return converted
+}
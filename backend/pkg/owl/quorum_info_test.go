@@ -0,0 +1,75 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestService_DescribeQuorum(t *testing.T) {
+	partitionRes := kmsg.DescribeQuorumResponseTopicPartition{
+		LeaderID:      1,
+		LeaderEpoch:   7,
+		HighWatermark: 420,
+		CurrentVoters: []kmsg.DescribeQuorumResponseTopicPartitionReplicaState{
+			{ReplicaID: 1, LogEndOffset: 420},
+			{ReplicaID: 2, LogEndOffset: 418},
+		},
+		Observers: []kmsg.DescribeQuorumResponseTopicPartitionReplicaState{{ReplicaID: 4, LogEndOffset: 400}},
+	}
+	quorumRes := &kmsg.DescribeQuorumResponse{Topics: []kmsg.DescribeQuorumResponseTopic{
+		{Topic: "__cluster_metadata", Partitions: []kmsg.DescribeQuorumResponseTopicPartition{partitionRes}},
+	}}
+	supportedRequests := []kmsg.Request{kmsg.NewPtrDescribeQuorumRequest()}
+	client := &mockKafkaClient{handle: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+		switch req.(type) {
+		case *kmsg.ApiVersionsRequest:
+			res := &kmsg.ApiVersionsResponse{}
+			for _, supportedReq := range supportedRequests {
+				res.ApiKeys = append(res.ApiKeys, kmsg.ApiVersionsResponseApiKey{ApiKey: supportedReq.Key(), MaxVersion: supportedReq.MaxVersion()})
+			}
+			return res, nil
+		case *kmsg.DescribeQuorumRequest:
+			return quorumRes, nil
+		}
+		return nil, fmt.Errorf("unexpected %v request", kmsg.NameForKey(req.Key()))
+	}}
+	svc := &Service{logger: zap.NewNop(), kafkaSvc: &kafka.Service{Logger: zap.NewNop(), KafkaClient: client}}
+
+	info, err := svc.DescribeQuorum(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &QuorumInfo{
+		LeaderID:      1,
+		LeaderEpoch:   7,
+		HighWatermark: 420,
+		Voters:        []QuorumReplicaState{{ReplicaID: 1, LogEndOffset: 420}, {ReplicaID: 2, LogEndOffset: 418}},
+		Observers:     []QuorumReplicaState{{ReplicaID: 4, LogEndOffset: 400}},
+	}, info)
+
+	// Errors of the metadata partition are returned rather than an empty quorum
+	quorumRes.Topics[0].Partitions[0].ErrorCode = kerr.NotLeaderForPartition.Code
+	_, err = svc.DescribeQuorum(context.Background())
+	assert.True(t, errors.Is(err, kerr.NotLeaderForPartition))
+}
+
+func TestService_DescribeQuorum_ZooKeeperCluster(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+		if _, ok := req.(*kmsg.ApiVersionsRequest); ok {
+			return &kmsg.ApiVersionsResponse{}, nil
+		}
+		return nil, fmt.Errorf("unexpected %v request", kmsg.NameForKey(req.Key()))
+	}}
+	svc := &Service{logger: zap.NewNop(), kafkaSvc: &kafka.Service{Logger: zap.NewNop(), KafkaClient: client}}
+
+	_, err := svc.DescribeQuorum(context.Background())
+	assert.True(t, errors.Is(err, kafka.ErrUnsupportedRequest))
+}
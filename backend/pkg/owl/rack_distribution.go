@@ -0,0 +1,96 @@
+package owl
+
+import (
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// unknownRackID is the rack of brokers which have no rack configured (broker.rack)
+const unknownRackID = "unknown"
+
+// RackDistribution summarizes how brokers and topic replicas are spread across racks
+type RackDistribution struct {
+	// IsRackAware is true if all brokers have a rack configured and there are at least two racks
+	IsRackAware bool           `json:"isRackAware"`
+	Racks       []RackOverview `json:"racks"`
+
+	// TopicsNotSpanningRacks are the topics which have at least one partition whose replicas are placed on fewer
+	// racks than possible, so that losing a single rack can make the partition unavailable. Topics with a
+	// replication factor of 1 are not listed. It's always empty if the cluster is not rack aware.
+	TopicsNotSpanningRacks []string `json:"topicsNotSpanningRacks"`
+}
+
+// RackOverview lists the brokers of a single rack
+type RackOverview struct {
+	RackID      string  `json:"rackId"`
+	BrokerCount int     `json:"brokerCount"`
+	BrokerIDs   []int32 `json:"brokerIds"`
+}
+
+// calculateRackDistribution groups the brokers in the metadata response by rack and checks the replica placement of
+// all topics in the response. Brokers without rack are grouped into the "unknown" rack.
+func calculateRackDistribution(metadata *kmsg.MetadataResponse) RackDistribution {
+	rackByBrokerID := make(map[int32]string, len(metadata.Brokers))
+	brokerIDsByRack := make(map[string][]int32)
+	for _, broker := range metadata.Brokers {
+		rackID := unknownRackID
+		if broker.Rack != nil && *broker.Rack != "" {
+			rackID = *broker.Rack
+		}
+		rackByBrokerID[broker.NodeID] = rackID
+		brokerIDsByRack[rackID] = append(brokerIDsByRack[rackID], broker.NodeID)
+	}
+
+	racks := make([]RackOverview, 0, len(brokerIDsByRack))
+	for rackID, brokerIDs := range brokerIDsByRack {
+		sort.Slice(brokerIDs, func(i, j int) bool { return brokerIDs[i] < brokerIDs[j] })
+		racks = append(racks, RackOverview{RackID: rackID, BrokerCount: len(brokerIDs), BrokerIDs: brokerIDs})
+	}
+	sort.Slice(racks, func(i, j int) bool { return racks[i].RackID < racks[j].RackID })
+
+	_, hasUnknownRack := brokerIDsByRack[unknownRackID]
+	distribution := RackDistribution{
+		IsRackAware:            !hasUnknownRack && len(brokerIDsByRack) >= 2,
+		Racks:                  racks,
+		TopicsNotSpanningRacks: make([]string, 0),
+	}
+	if !distribution.IsRackAware {
+		return distribution
+	}
+
+	for _, topic := range metadata.Topics {
+		for _, partition := range topic.Partitions {
+			if !spansRacks(partition.Replicas, rackByBrokerID, len(brokerIDsByRack)) {
+				distribution.TopicsNotSpanningRacks = append(distribution.TopicsNotSpanningRacks, topic.Topic)
+				break
+			}
+		}
+	}
+	sort.Strings(distribution.TopicsNotSpanningRacks)
+
+	return distribution
+}
+
+// spansRacks returns true if the replicas are placed on as many racks as possible, which is the number of replicas
+// limited by the number of racks in the cluster. Replicas on brokers which are not known are counted as unknown rack.
+func spansRacks(replicas []int32, rackByBrokerID map[int32]string, rackCount int) bool {
+	if len(replicas) < 2 {
+		return true
+	}
+
+	replicaRacks := make(map[string]struct{}, len(replicas))
+	for _, replica := range replicas {
+		rackID, exists := rackByBrokerID[replica]
+		if !exists {
+			rackID = unknownRackID
+		}
+		replicaRacks[rackID] = struct{}{}
+	}
+
+	possibleRacks := len(replicas)
+	if rackCount < possibleRacks {
+		possibleRacks = rackCount
+	}
+	return len(replicaRacks) >= possibleRacks
+}
@@ -0,0 +1,56 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestCalculateRackDistribution(t *testing.T) {
+	rackA, rackB := "a", "b"
+	metadata := &kmsg.MetadataResponse{
+		Brokers: []kmsg.MetadataResponseBroker{
+			{NodeID: 3, Rack: &rackB},
+			{NodeID: 1, Rack: &rackA},
+			{NodeID: 2, Rack: &rackA},
+		},
+		Topics: []kmsg.MetadataResponseTopic{
+			{Topic: "spread", Partitions: []kmsg.MetadataResponseTopicPartition{{Replicas: []int32{1, 3}}, {Replicas: []int32{2, 3, 1}}}},
+			{Topic: "single-rack", Partitions: []kmsg.MetadataResponseTopicPartition{{Replicas: []int32{1, 3}}, {Replicas: []int32{1, 2}}}},
+			{Topic: "unreplicated", Partitions: []kmsg.MetadataResponseTopicPartition{{Replicas: []int32{1}}}},
+		},
+	}
+
+	assert.Equal(t, RackDistribution{
+		IsRackAware: true,
+		Racks: []RackOverview{
+			{RackID: "a", BrokerCount: 2, BrokerIDs: []int32{1, 2}},
+			{RackID: "b", BrokerCount: 1, BrokerIDs: []int32{3}},
+		},
+		TopicsNotSpanningRacks: []string{"single-rack"},
+	}, calculateRackDistribution(metadata))
+}
+
+func TestCalculateRackDistribution_UnknownRack(t *testing.T) {
+	rackA, empty := "a", ""
+	metadata := &kmsg.MetadataResponse{
+		Brokers: []kmsg.MetadataResponseBroker{
+			{NodeID: 1, Rack: &rackA},
+			{NodeID: 2, Rack: &empty},
+			{NodeID: 3},
+		},
+		Topics: []kmsg.MetadataResponseTopic{
+			{Topic: "orders", Partitions: []kmsg.MetadataResponseTopicPartition{{Replicas: []int32{2, 3}}}},
+		},
+	}
+
+	assert.Equal(t, RackDistribution{
+		IsRackAware: false,
+		Racks: []RackOverview{
+			{RackID: "a", BrokerCount: 1, BrokerIDs: []int32{1}},
+			{RackID: unknownRackID, BrokerCount: 2, BrokerIDs: []int32{2, 3}},
+		},
+		TopicsNotSpanningRacks: []string{},
+	}, calculateRackDistribution(metadata))
+}
@@ -0,0 +1,124 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/linkedin/goavro/v2"
+)
+
+// SchemaValidationError is returned if a payload does not match the schema it has been validated against.
+type SchemaValidationError struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+
+	// Path is the dot separated path of the offending field, e.g. "customer.address". It's empty if the serializer
+	// does not report the field.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e *SchemaValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("payload does not match version '%v' of subject '%v': %v", e.Version, e.Subject, e.Message)
+	}
+	return fmt.Sprintf("payload does not match version '%v' of subject '%v' at field '%v': %v", e.Version, e.Subject, e.Path, e.Message)
+}
+
+// ValidatePayloadAgainstSchema checks whether the given JSON payload can be serialized with the latest schema of the
+// given subject. Avro and Protobuf schemas are supported. If the payload does not match the schema, the returned error
+// is a *SchemaValidationError.
+func (s *Service) ValidatePayloadAgainstSchema(_ context.Context, subject string, payload []byte) error {
+	if s.kafkaSvc.SchemaService == nil {
+		return ErrSchemaRegistryNotConfigured
+	}
+
+	versionedSchema, err := s.kafkaSvc.SchemaService.GetSchemaBySubject(subject, "latest")
+	if err != nil {
+		return fmt.Errorf("failed to get latest schema for given subject: %w", err)
+	}
+
+	var validationErr *SchemaValidationError
+	switch versionedSchema.Type {
+	case "", "AVRO":
+		codec, err := goavro.NewCodec(versionedSchema.Schema)
+		if err != nil {
+			return fmt.Errorf("failed to create codec from schema string: %w", err)
+		}
+		validationErr = validateAvroPayload(codec, payload)
+	case "PROTOBUF":
+		descriptors, err := s.kafkaSvc.SchemaService.GetProtoDescriptors()
+		if err != nil {
+			return fmt.Errorf("failed to get proto descriptors: %w", err)
+		}
+		fd, exists := descriptors[versionedSchema.SchemaID]
+		if !exists {
+			return fmt.Errorf("proto schema with id '%v' could not be compiled", versionedSchema.SchemaID)
+		}
+		messageTypes := fd.GetMessageTypes()
+		if len(messageTypes) == 0 {
+			return fmt.Errorf("proto schema with id '%v' does not define any message", versionedSchema.SchemaID)
+		}
+		// The Confluent serializers use the first message of a schema unless told otherwise
+		msg := dynamic.NewMessage(messageTypes[0])
+		validationErr = validateProtoPayload(msg, payload)
+	default:
+		return fmt.Errorf("validating payloads against '%v' schemas is not supported", versionedSchema.Type)
+	}
+	if validationErr != nil {
+		validationErr.Subject = subject
+		validationErr.Version = versionedSchema.Version
+		return validationErr
+	}
+
+	return nil
+}
+
+// goavro's error messages contain the names of the offending fields. Errors when decoding a JSON payload name the
+// innermost field first (`... for key: "name" for key: "customer"`), errors when encoding to the binary format name
+// the outermost field first (`record "Order" field "customer": ... record "Customer" field "name"`).
+var (
+	avroTextualFieldPattern = regexp.MustCompile(`for key: "([^"]+)"`)
+	avroBinaryFieldPattern  = regexp.MustCompile(`field "([^"]+)"`)
+)
+
+// validateAvroPayload decodes the JSON payload with the codec and encodes it into the binary format, which is what
+// the serializer would do.
+func validateAvroPayload(codec *goavro.Codec, payload []byte) *SchemaValidationError {
+	native, _, err := codec.NativeFromTextual(payload)
+	if err != nil {
+		matches := avroTextualFieldPattern.FindAllStringSubmatch(err.Error(), -1)
+		fields := make([]string, len(matches))
+		for i, match := range matches {
+			fields[len(matches)-1-i] = match[1]
+		}
+		return &SchemaValidationError{Path: strings.Join(fields, "."), Message: err.Error()}
+	}
+
+	_, err = codec.BinaryFromNative(nil, native)
+	if err != nil {
+		fields := make([]string, 0)
+		for _, match := range avroBinaryFieldPattern.FindAllStringSubmatch(err.Error(), -1) {
+			fields = append(fields, match[1])
+		}
+		return &SchemaValidationError{Path: strings.Join(fields, "."), Message: err.Error()}
+	}
+
+	return nil
+}
+
+// validateProtoPayload decodes the JSON payload into the message and encodes it into the binary format.
+func validateProtoPayload(msg *dynamic.Message, payload []byte) *SchemaValidationError {
+	err := msg.UnmarshalJSON(payload)
+	if err == nil {
+		_, err = msg.Marshal()
+	}
+	if err == nil {
+		return nil
+	}
+
+	return &SchemaValidationError{Message: err.Error()}
+}
@@ -0,0 +1,35 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAvroPayload(t *testing.T) {
+	codec, err := goavro.NewCodec(`{
+		"type": "record",
+		"name": "Order",
+		"fields": [
+			{"name": "id", "type": "long"},
+			{"name": "customer", "type": {
+				"type": "record",
+				"name": "Customer",
+				"fields": [{"name": "name", "type": "string"}]
+			}}
+		]
+	}`)
+	require.NoError(t, err)
+
+	assert.Nil(t, validateAvroPayload(codec, []byte(`{"id": 1, "customer": {"name": "jane"}}`)))
+
+	validationErr := validateAvroPayload(codec, []byte(`{"id": 1, "customer": {"name": 5}}`))
+	require.NotNil(t, validationErr)
+	assert.Equal(t, "customer.name", validationErr.Path)
+	assert.NotEmpty(t, validationErr.Message)
+
+	validationErr = validateAvroPayload(codec, []byte(`not json`))
+	require.NotNil(t, validationErr)
+}
@@ -13,6 +13,15 @@ type Service struct {
 	kafkaSvc *kafka.Service
 	gitSvc   *git.Service // Git service can be nil if not configured
 	logger   *zap.Logger
+
+	defaultStartOffset int64
+	browseConfig       ConfigBrowse
+
+	// omitEmptyAssignments omits empty assignments of group members in JSON responses
+	omitEmptyAssignments bool
+
+	// memberConnections tracks since when group members are connected, see GroupMemberDescription.ConnectedSince
+	memberConnections *memberConnectionTracker
 }
 
 // NewService for the Owl package
@@ -30,9 +39,24 @@ func NewService(cfg Config, logger *zap.Logger, kafkaSvc *kafka.Service) (*Servi
 		kafkaSvc: kafkaSvc,
 		gitSvc:   gitSvc,
 		logger:   logger,
+
+		defaultStartOffset: cfg.Browse.StartOffset(),
+		browseConfig:       cfg.Browse,
+
+		omitEmptyAssignments: cfg.ConsumerGroups.OmitEmptyAssignments,
+		memberConnections:    newMemberConnectionTracker(),
 	}, nil
 }
 
+// StartOffsetOrDefault returns the given start offset, or the configured default start offset if the request opted
+// into the default. Requests which don't opt in always use their own start offset.
+func (s *Service) StartOffsetOrDefault(startOffset int64, useDefault bool) int64 {
+	if useDefault {
+		return s.defaultStartOffset
+	}
+	return startOffset
+}
+
 // Start starts all the (background) tasks which are required for this service to work properly. If any of these
 // tasks can not be setup an error will be returned which will cause the application to exit.
 func (s *Service) Start() error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/cloudhut/common/rest"
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kmsg"
 	"go.uber.org/zap"
@@ -64,7 +65,7 @@ func (s *Service) GetTopicConfigs(ctx context.Context, topicName string, configN
 	if val, exists := response[topicName]; exists {
 		if val.Error != nil && val.Error.Code == kerr.UnknownTopicOrPartition.Code {
 			return nil, &rest.Error{
-				Err:      fmt.Errorf("the requested topic does not exist"),
+				Err:      fmt.Errorf("%w: %v", kafka.ErrTopicNotFound, topicName),
 				Status:   http.StatusNotFound,
 				Message:  fmt.Sprintf("Could not fetch topic config because the requested topic '%v' does not exist.", topicName),
 				IsSilent: false,
@@ -83,9 +84,9 @@ func (s *Service) GetTopicsConfigs(ctx context.Context, topicNames []string, con
 		return nil, err
 	}
 
-	// 3. Iterate through response's config entries and convert them into our desired format
+	// Iterate through response's config entries and convert them into our desired format
 	converted := make(map[string]*TopicConfig, len(topicNames))
-	for _, res := range response.Resources {
+	for _, res := range response {
 		kafkaErr := newKafkaError(res.ErrorCode)
 		if kafkaErr != nil {
 			s.logger.Warn("config resource response has an error", zap.String("resource_name", res.ResourceName), zap.Error(kafkaErr))
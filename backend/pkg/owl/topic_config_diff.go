@@ -0,0 +1,73 @@
+package owl
+
+import (
+	"sort"
+)
+
+// ConfigDifferenceKind describes why a config entry is reported as different between two topics.
+type ConfigDifferenceKind string
+
+const (
+	// ConfigDifferenceValue is reported if both topics have the config entry with different values
+	ConfigDifferenceValue ConfigDifferenceKind = "value"
+	// ConfigDifferenceOnlyInA is reported if only the first topic has the config entry
+	ConfigDifferenceOnlyInA ConfigDifferenceKind = "onlyInA"
+	// ConfigDifferenceOnlyInB is reported if only the second topic has the config entry
+	ConfigDifferenceOnlyInB ConfigDifferenceKind = "onlyInB"
+	// ConfigDifferenceOverride is reported if the config entry is explicitly set on one topic, but uses the default
+	// value on the other one. The values may still be equal.
+	ConfigDifferenceOverride ConfigDifferenceKind = "override"
+)
+
+// ConfigDifference is a single config entry which differs between two topics. The entries are nil if the config does
+// not exist on the respective topic.
+type ConfigDifference struct {
+	Name   string               `json:"name"`
+	Kind   ConfigDifferenceKind `json:"kind"`
+	EntryA *TopicConfigEntry    `json:"entryA"`
+	EntryB *TopicConfigEntry    `json:"entryB"`
+}
+
+// DiffTopicConfigs compares the config entries of two topics and returns all entries which differ, sorted by config
+// name. An entry which is explicitly set on one topic but not on the other is reported as override, even if the
+// values differ. Sensitive values are not known and therefore only compared by whether they are explicitly set.
+func DiffTopicConfigs(a, b []*TopicConfigEntry) []ConfigDifference {
+	entriesA := topicConfigEntriesByName(a)
+	entriesB := topicConfigEntriesByName(b)
+
+	differences := make([]ConfigDifference, 0)
+	for name, entryA := range entriesA {
+		entryB, exists := entriesB[name]
+		if !exists {
+			differences = append(differences, ConfigDifference{Name: name, Kind: ConfigDifferenceOnlyInA, EntryA: entryA})
+			continue
+		}
+
+		switch {
+		case entryA.IsExplicitlySet != entryB.IsExplicitlySet:
+			differences = append(differences, ConfigDifference{Name: name, Kind: ConfigDifferenceOverride, EntryA: entryA, EntryB: entryB})
+		case !entryA.IsSensitive && !entryB.IsSensitive && derefString(entryA.Value) != derefString(entryB.Value):
+			differences = append(differences, ConfigDifference{Name: name, Kind: ConfigDifferenceValue, EntryA: entryA, EntryB: entryB})
+		}
+	}
+	for name, entryB := range entriesB {
+		if _, exists := entriesA[name]; !exists {
+			differences = append(differences, ConfigDifference{Name: name, Kind: ConfigDifferenceOnlyInB, EntryB: entryB})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool { return differences[i].Name < differences[j].Name })
+
+	return differences
+}
+
+func topicConfigEntriesByName(entries []*TopicConfigEntry) map[string]*TopicConfigEntry {
+	entriesByName := make(map[string]*TopicConfigEntry, len(entries))
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		entriesByName[entry.Name] = entry
+	}
+	return entriesByName
+}
@@ -0,0 +1,77 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func configEntry(name string, value string, isExplicitlySet bool) *TopicConfigEntry {
+	return &TopicConfigEntry{Name: name, Value: &value, IsExplicitlySet: isExplicitlySet}
+}
+
+func TestDiffTopicConfigs(t *testing.T) {
+	secretA := &TopicConfigEntry{Name: "secret", IsSensitive: true, IsExplicitlySet: true}
+	secretB := &TopicConfigEntry{Name: "secret", IsSensitive: true, IsExplicitlySet: true}
+
+	tests := []struct {
+		name     string
+		a        []*TopicConfigEntry
+		b        []*TopicConfigEntry
+		expected []ConfigDifference
+	}{
+		{
+			name:     "both empty",
+			expected: []ConfigDifference{},
+		},
+		{
+			name:     "equal",
+			a:        []*TopicConfigEntry{configEntry("cleanup.policy", "delete", false), configEntry("retention.ms", "1000", true)},
+			b:        []*TopicConfigEntry{configEntry("retention.ms", "1000", true), configEntry("cleanup.policy", "delete", false)},
+			expected: []ConfigDifference{},
+		},
+		{
+			name: "different value",
+			a:    []*TopicConfigEntry{configEntry("retention.ms", "1000", true)},
+			b:    []*TopicConfigEntry{configEntry("retention.ms", "2000", true)},
+			expected: []ConfigDifference{
+				{Name: "retention.ms", Kind: ConfigDifferenceValue, EntryA: configEntry("retention.ms", "1000", true), EntryB: configEntry("retention.ms", "2000", true)},
+			},
+		},
+		{
+			name: "only in one",
+			a:    []*TopicConfigEntry{configEntry("retention.ms", "1000", false)},
+			b:    []*TopicConfigEntry{configEntry("cleanup.policy", "compact", true)},
+			expected: []ConfigDifference{
+				{Name: "cleanup.policy", Kind: ConfigDifferenceOnlyInB, EntryB: configEntry("cleanup.policy", "compact", true)},
+				{Name: "retention.ms", Kind: ConfigDifferenceOnlyInA, EntryA: configEntry("retention.ms", "1000", false)},
+			},
+		},
+		{
+			name: "overridden with different value",
+			a:    []*TopicConfigEntry{configEntry("cleanup.policy", "delete", false)},
+			b:    []*TopicConfigEntry{configEntry("cleanup.policy", "compact", true)},
+			expected: []ConfigDifference{
+				{Name: "cleanup.policy", Kind: ConfigDifferenceOverride, EntryA: configEntry("cleanup.policy", "delete", false), EntryB: configEntry("cleanup.policy", "compact", true)},
+			},
+		},
+		{
+			name: "overridden with default value",
+			a:    []*TopicConfigEntry{configEntry("cleanup.policy", "delete", true)},
+			b:    []*TopicConfigEntry{configEntry("cleanup.policy", "delete", false)},
+			expected: []ConfigDifference{
+				{Name: "cleanup.policy", Kind: ConfigDifferenceOverride, EntryA: configEntry("cleanup.policy", "delete", true), EntryB: configEntry("cleanup.policy", "delete", false)},
+			},
+		},
+		{
+			name:     "sensitive values are not compared",
+			a:        []*TopicConfigEntry{secretA},
+			b:        []*TopicConfigEntry{secretB},
+			expected: []ConfigDifference{},
+		},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, DiffTopicConfigs(tc.a, tc.b), tc.name)
+	}
+}
@@ -3,6 +3,8 @@ package owl
 import (
 	"context"
 	"fmt"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
 )
 
 // TopicConsumerGroup is a group along with it's accumulated topic log for a given topic
@@ -12,8 +14,17 @@ type TopicConsumerGroup struct {
 }
 
 // ListTopicConsumers returns all consumer group names along with their accumulated lag across all partitions which
-// have at least one active offset on the given topic.
+// have at least one active offset on the given topic. An error wrapping kafka.ErrTopicNotFound is returned if the topic
+// does not exist, rather than an empty list.
 func (s *Service) ListTopicConsumers(ctx context.Context, topicName string) ([]*TopicConsumerGroup, error) {
+	exists, err := s.kafkaSvc.TopicExists(ctx, topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if topic exists: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %v", kafka.ErrTopicNotFound, topicName)
+	}
+
 	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
@@ -0,0 +1,33 @@
+package owl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+)
+
+func TestService_ListTopicConsumers_TopicNotFound(t *testing.T) {
+	client := &mockKafkaClient{handle: func(_ context.Context, req kmsg.Request) (kmsg.Response, error) {
+		if metadataReq, ok := req.(*kmsg.MetadataRequest); ok {
+			if len(metadataReq.Topics) == 0 {
+				return &kmsg.MetadataResponse{}, nil // metadata refresh before retrying unknown topics
+			}
+			return &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{
+				{Topic: *metadataReq.Topics[0].Topic, ErrorCode: kerr.UnknownTopicOrPartition.Code},
+			}}, nil
+		}
+		return nil, fmt.Errorf("unexpected %v request", kmsg.NameForKey(req.Key()))
+	}}
+	svc := &Service{logger: zap.NewNop(), kafkaSvc: &kafka.Service{Logger: zap.NewNop(), KafkaClient: client}}
+
+	_, err := svc.ListTopicConsumers(context.Background(), "orders")
+	assert.True(t, errors.Is(err, kafka.ErrTopicNotFound))
+}
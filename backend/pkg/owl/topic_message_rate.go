@@ -0,0 +1,97 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MessageRates are the estimated messages per second by partition ID
+type MessageRates map[int32]float64
+
+// Total returns the summed message rate of all partitions, which is the message rate of the whole topic
+func (m MessageRates) Total() float64 {
+	var total float64
+	for _, rate := range m {
+		total += rate
+	}
+	return total
+}
+
+// EstimateMessageRate estimates the number of messages per second each partition of the topic receives, by sampling
+// the high water marks twice with `window` in between. Partitions whose high water mark could not be fetched in either
+// sample are omitted, which includes partitions that have been added in between. The rates
+// include control records of transactions and are only an estimate for compacted topics, because compaction does not
+// lower the high water mark.
+func (s *Service) EstimateMessageRate(ctx context.Context, topicName string, window time.Duration) (MessageRates, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be greater than 0")
+	}
+
+	first, err := s.highWaterMarks(ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+
+	timer := time.NewTimer(window)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return nil, fmt.Errorf("estimating message rate was cancelled: %w", ctx.Err())
+	case <-timer.C:
+	}
+
+	second, err := s.highWaterMarks(ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	return calculateMessageRates(first, second, time.Since(start)), nil
+}
+
+// highWaterMarks returns the high water mark of each of the topic's partitions. Partitions with an error are omitted.
+func (s *Service) highWaterMarks(ctx context.Context, topicName string) (map[int32]int64, error) {
+	partitionIDs, err := s.kafkaSvc.ListPartitionIDs(ctx, topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions: %w", err)
+	}
+	marks, err := s.kafkaSvc.GetPartitionMarks(ctx, topicName, partitionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watermarks: %w", err)
+	}
+
+	highWaterMarks := make(map[int32]int64, len(marks))
+	for partitionID, mark := range marks {
+		if mark.Error != "" {
+			continue
+		}
+		highWaterMarks[partitionID] = mark.High
+	}
+	return highWaterMarks, nil
+}
+
+// calculateMessageRates returns the messages per second between two high water mark samples. Partitions which are
+// missing in either sample are skipped, because the number of messages they received in between is unknown. A
+// partition may be missing in the first sample because its high water mark could not be fetched, hence it must not be
+// assumed to have started empty. A decreasing high water mark (e.g. because the topic has been recreated) results in
+// a rate of 0.
+func calculateMessageRates(first map[int32]int64, second map[int32]int64, elapsed time.Duration) MessageRates {
+	rates := make(MessageRates, len(second))
+	if elapsed <= 0 {
+		return rates
+	}
+
+	for partitionID, high := range second {
+		firstHigh, exists := first[partitionID]
+		if !exists {
+			continue
+		}
+		delta := high - firstHigh
+		if delta < 0 {
+			delta = 0
+		}
+		rates[partitionID] = float64(delta) / elapsed.Seconds()
+	}
+	return rates
+}
@@ -0,0 +1,25 @@
+package owl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateMessageRates(t *testing.T) {
+	first := map[int32]int64{0: 100, 1: 50, 2: 10, 3: 7}
+	second := map[int32]int64{0: 300, 1: 50, 2: 5, 4: 40}
+
+	rates := calculateMessageRates(first, second, 2*time.Second)
+	assert.Equal(t, MessageRates{
+		0: 100, // 200 messages in 2s
+		1: 0,
+		2: 0, // High water mark decreased, topic has likely been recreated
+		// Partition 4 is missing in the first sample, e.g. because it has been added within the window or its high
+		// water mark could not be fetched, hence its rate is unknown
+	}, rates)
+	assert.Equal(t, float64(100), rates.Total())
+
+	assert.Empty(t, calculateMessageRates(first, second, 0))
+}
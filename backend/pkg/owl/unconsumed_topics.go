@@ -0,0 +1,135 @@
+package owl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cloudhut/kowl/backend/pkg/kafka"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxConcurrentGroupOffsetRequests limits the number of OffsetFetch requests that are in flight at the same time when
+// the offsets of all groups are listed.
+const maxConcurrentGroupOffsetRequests = 10
+
+// FindUnconsumedTopics returns the names of all non-internal topics, which no consumer group has a committed offset
+// for and which are not assigned to any member of a group. The result is a point in time snapshot: consumers which
+// are currently not running and have never committed, or whose committed offsets have expired, are not taken into
+// account. Because the result is meant to find topics which can be deleted, an error is returned if the offsets or
+// assignments of any group could not be fetched, rather than returning topics which might be consumed.
+func (s *Service) FindUnconsumedTopics(ctx context.Context) ([]string, error) {
+	metadata, err := s.kafkaSvc.GetMetadata(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+
+	groups, err := s.kafkaSvc.ListConsumerGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	if groups.RequestsFailed > 0 {
+		return nil, fmt.Errorf("failed to list the consumer groups of '%v' brokers", groups.RequestsFailed)
+	}
+	groupIDs := groups.GetGroupIDs()
+
+	consumedTopics := make(map[string]struct{})
+	mutex := sync.Mutex{}
+	markConsumed := func(topicName string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		consumedTopics[topicName] = struct{}{}
+	}
+
+	// Topics with committed offsets
+	eg, egCtx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentGroupOffsetRequests)
+	for _, groupID := range groupIDs {
+		groupID := groupID
+		eg.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+			defer func() { <-sem }()
+
+			offsets, err := s.kafkaSvc.ListConsumerGroupOffsets(egCtx, groupID)
+			if err != nil {
+				return fmt.Errorf("failed to list offsets of group '%v': %w", groupID, err)
+			}
+			for _, topic := range committedTopics(offsets) {
+				markConsumed(topic)
+			}
+			return nil
+		})
+	}
+
+	// Topics assigned to active group members
+	eg.Go(func() error {
+		if len(groupIDs) == 0 {
+			return nil
+		}
+		described, err := s.kafkaSvc.DescribeConsumerGroups(egCtx, groupIDs)
+		if err != nil {
+			return fmt.Errorf("failed to describe consumer groups: %w", err)
+		}
+		if described.RequestsFailed > 0 {
+			return fmt.Errorf("failed to describe the consumer groups of '%v' coordinators", described.RequestsFailed)
+		}
+		for _, group := range described.GetDescribedGroups() {
+			if group.ProtocolType != "consumer" {
+				continue
+			}
+			for _, member := range group.Members {
+				assignment, err := kafka.DecodeMemberAssignment(member.MemberAssignment)
+				if err != nil {
+					continue
+				}
+				for _, topic := range assignment.Topics {
+					markConsumed(topic.Topic)
+				}
+			}
+		}
+		return nil
+	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return unconsumedTopics(metadata, consumedTopics), nil
+}
+
+// committedTopics returns the topics which have a committed offset for at least one partition
+func committedTopics(offsets *kmsg.OffsetFetchResponse) []string {
+	topics := make([]string, 0, len(offsets.Topics))
+	for _, topic := range offsets.Topics {
+		for _, partition := range topic.Partitions {
+			if kerr.ErrorForCode(partition.ErrorCode) == nil && partition.Offset >= 0 {
+				topics = append(topics, topic.Topic)
+				break
+			}
+		}
+	}
+	return topics
+}
+
+// unconsumedTopics returns the sorted names of all non-internal topics in the metadata which are not consumed
+func unconsumedTopics(metadata *kmsg.MetadataResponse, consumedTopics map[string]struct{}) []string {
+	topics := make([]string, 0)
+	for _, topic := range metadata.Topics {
+		if topic.IsInternal || kerr.ErrorForCode(topic.ErrorCode) != nil {
+			continue
+		}
+		if _, isConsumed := consumedTopics[topic.Topic]; isConsumed {
+			continue
+		}
+		topics = append(topics, topic.Topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
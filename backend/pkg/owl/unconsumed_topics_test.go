@@ -0,0 +1,32 @@
+package owl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+func TestCommittedTopics(t *testing.T) {
+	offsets := &kmsg.OffsetFetchResponse{Topics: []kmsg.OffsetFetchResponseTopic{
+		{Topic: "orders", Partitions: []kmsg.OffsetFetchResponseTopicPartition{{Partition: 0, Offset: -1}, {Partition: 1, Offset: 5}}},
+		{Topic: "payments", Partitions: []kmsg.OffsetFetchResponseTopicPartition{{Partition: 0, Offset: -1}}},
+		{Topic: "audit", Partitions: []kmsg.OffsetFetchResponseTopicPartition{{Partition: 0, Offset: 3, ErrorCode: kerr.UnknownTopicOrPartition.Code}}},
+	}}
+
+	assert.Equal(t, []string{"orders"}, committedTopics(offsets))
+}
+
+func TestUnconsumedTopics(t *testing.T) {
+	metadata := &kmsg.MetadataResponse{Topics: []kmsg.MetadataResponseTopic{
+		{Topic: "orders"},
+		{Topic: "payments"},
+		{Topic: "audit"},
+		{Topic: "__consumer_offsets", IsInternal: true},
+		{Topic: "broken", ErrorCode: kerr.TopicAuthorizationFailed.Code},
+	}}
+	consumed := map[string]struct{}{"orders": {}}
+
+	assert.Equal(t, []string{"audit", "payments"}, unconsumedTopics(metadata, consumed))
+}
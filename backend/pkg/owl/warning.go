@@ -0,0 +1,19 @@
+package owl
+
+// WarningCode identifies the kind of a Warning, so that clients can handle or aggregate them
+type WarningCode string
+
+const (
+	// WarningCodeMemberAssignmentDecode is reported for group members whose assignment could not be decoded, e.g.
+	// because a non-standard client does not follow the consumer protocol. The member is returned without assignments.
+	WarningCodeMemberAssignmentDecode WarningCode = "memberAssignmentDecodeFailed"
+)
+
+// Warning is a problem that did not fail the request, but made the result incomplete. Warnings are returned along with
+// the result so that they can be shown to the user, in addition to being logged.
+type Warning struct {
+	Code WarningCode `json:"code"`
+	// Subject is the entity that caused the warning, e.g. the member ID
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
@@ -107,11 +107,11 @@ func (s *Service) compileProtoSchemas(schema SchemaVersionedResponse, schemaRepo
 	for _, ref := range schema.References {
 		refSubject, exists := schemaRepository[ref.Subject]
 		if !exists {
-			return nil, fmt.Errorf("failed to resolve reference. Reference with subject '%' does not exist", ref.Subject)
+			return nil, fmt.Errorf("failed to resolve reference. Reference with subject '%v' does not exist", ref.Subject)
 		}
 		refSchema, exists := refSubject[ref.Version]
 		if !exists {
-			return nil, fmt.Errorf("failed to resolve reference. Reference with subject '%', version '%d' does not exist", ref.Subject, ref.Version)
+			return nil, fmt.Errorf("failed to resolve reference. Reference with subject '%v', version '%d' does not exist", ref.Subject, ref.Version)
 		}
 		// The reference name is the name that has been used for the import in the proto schema (e.g. 'customer.proto')
 		schemasByPath[ref.Name] = refSchema.Schema